@@ -2,8 +2,14 @@ package awsconfig
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 )
@@ -13,31 +19,178 @@ const (
 	localEnv        = "local"
 )
 
-func GetAWSConfig(ctx context.Context, env string) (aws.Config, error) {
+// Environment variables read by GetAWSConfig. Options passed in code take
+// precedence over them.
+const (
+	RegionEnvVar         = "CARE_GIVER_AWS_REGION"
+	ProfileEnvVar        = "CARE_GIVER_AWS_PROFILE"
+	RetryModeEnvVar      = "CARE_GIVER_AWS_RETRY_MODE"
+	MaxAttemptsEnvVar    = "CARE_GIVER_AWS_MAX_ATTEMPTS"
+	HTTPTimeoutEnvVar    = "CARE_GIVER_AWS_HTTP_TIMEOUT"
+	ConnectTimeoutEnvVar = "CARE_GIVER_AWS_CONNECT_TIMEOUT"
+)
+
+type Options struct {
+	Region         string
+	Profile        string
+	RetryMode      aws.RetryMode
+	MaxAttempts    int
+	HTTPTimeout    time.Duration
+	ConnectTimeout time.Duration
+}
+
+type Option func(*Options)
+
+func WithRegion(region string) Option {
+	return func(o *Options) {
+		o.Region = region
+	}
+}
+
+func WithProfile(profile string) Option {
+	return func(o *Options) {
+		o.Profile = profile
+	}
+}
+
+func WithRetryMode(mode aws.RetryMode) Option {
+	return func(o *Options) {
+		o.RetryMode = mode
+	}
+}
+
+func WithMaxAttempts(attempts int) Option {
+	return func(o *Options) {
+		o.MaxAttempts = attempts
+	}
+}
+
+// WithHTTPTimeout bounds each HTTP request, including reading the response
+// body.
+func WithHTTPTimeout(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.HTTPTimeout = timeout
+	}
+}
+
+func WithConnectTimeout(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.ConnectTimeout = timeout
+	}
+}
+
+func GetAWSConfig(ctx context.Context, env string, opts ...Option) (aws.Config, error) {
+	o, err := optionsFromEnv()
+	if err != nil {
+		return aws.Config{}, err
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	loadOpts := o.loadOptions()
 	if env == localEnv {
-		return getLocalAWSConfig(ctx)
+		return getLocalAWSConfig(ctx, loadOpts)
 	}
-	return getAWSConfig(ctx)
+	return getAWSConfig(ctx, loadOpts)
 }
 
-func getAWSConfig(ctx context.Context) (aws.Config, error) {
-	cfg, err := config.LoadDefaultConfig(ctx,
-		config.WithRegion(USEastTwoRegion),
-	)
+func getAWSConfig(ctx context.Context, loadOpts []func(*config.LoadOptions) error) (aws.Config, error) {
+	cfg, err := config.LoadDefaultConfig(ctx, loadOpts...)
 
 	return cfg, err
 }
 
-func getLocalAWSConfig(ctx context.Context) (aws.Config, error) {
+func getLocalAWSConfig(ctx context.Context, loadOpts []func(*config.LoadOptions) error) (aws.Config, error) {
 	cfg, err := config.LoadDefaultConfig(ctx,
-		config.WithRegion(USEastTwoRegion),
-		config.WithCredentialsProvider(credentials.StaticCredentialsProvider{
+		append(loadOpts, config.WithCredentialsProvider(credentials.StaticCredentialsProvider{
 			Value: aws.Credentials{
 				AccessKeyID: "dummy", SecretAccessKey: "dummy", SessionToken: "dummy",
 				Source: "Hard-coded credentials; values are irrelevant for local DynamoDB",
 			},
-		}),
+		}))...,
 	)
 
 	return cfg, err
 }
+
+// NewHTTPClient returns an HTTP client for AWS service clients. A zero timeout
+// keeps the SDK default.
+func NewHTTPClient(timeout, connectTimeout time.Duration) *awshttp.BuildableClient {
+	client := awshttp.NewBuildableClient()
+	if timeout > 0 {
+		client = client.WithTimeout(timeout)
+	}
+	if connectTimeout > 0 {
+		client = client.WithDialerOptions(func(d *net.Dialer) {
+			d.Timeout = connectTimeout
+		})
+	}
+	return client
+}
+
+func (o Options) loadOptions() []func(*config.LoadOptions) error {
+	loadOpts := []func(*config.LoadOptions) error{
+		config.WithRegion(o.Region),
+	}
+	if o.Profile != "" {
+		loadOpts = append(loadOpts, config.WithSharedConfigProfile(o.Profile))
+	}
+	if o.RetryMode != "" {
+		loadOpts = append(loadOpts, config.WithRetryMode(o.RetryMode))
+	}
+	if o.MaxAttempts > 0 {
+		loadOpts = append(loadOpts, config.WithRetryMaxAttempts(o.MaxAttempts))
+	}
+	if o.HTTPTimeout > 0 || o.ConnectTimeout > 0 {
+		loadOpts = append(loadOpts, config.WithHTTPClient(NewHTTPClient(o.HTTPTimeout, o.ConnectTimeout)))
+	}
+	return loadOpts
+}
+
+func optionsFromEnv() (Options, error) {
+	o := Options{Region: USEastTwoRegion}
+
+	if v := os.Getenv(RegionEnvVar); v != "" {
+		o.Region = v
+	}
+	o.Profile = os.Getenv(ProfileEnvVar)
+
+	if v := os.Getenv(RetryModeEnvVar); v != "" {
+		mode, err := aws.ParseRetryMode(v)
+		if err != nil {
+			return Options{}, fmt.Errorf("invalid %s: %w", RetryModeEnvVar, err)
+		}
+		o.RetryMode = mode
+	}
+
+	if v := os.Getenv(MaxAttemptsEnvVar); v != "" {
+		attempts, err := strconv.Atoi(v)
+		if err != nil || attempts < 1 {
+			return Options{}, fmt.Errorf("invalid %s: %q", MaxAttemptsEnvVar, v)
+		}
+		o.MaxAttempts = attempts
+	}
+
+	var err error
+	if o.HTTPTimeout, err = durationFromEnv(HTTPTimeoutEnvVar); err != nil {
+		return Options{}, err
+	}
+	if o.ConnectTimeout, err = durationFromEnv(ConnectTimeoutEnvVar); err != nil {
+		return Options{}, err
+	}
+
+	return o, nil
+}
+
+func durationFromEnv(envVar string) (time.Duration, error) {
+	v := os.Getenv(envVar)
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", envVar, err)
+	}
+	return d, nil
+}
@@ -3,6 +3,7 @@ package awsconfig
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/stretchr/testify/assert"
@@ -44,3 +45,73 @@ func TestGetAWSConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAWSConfig_Options(t *testing.T) {
+	tests := map[string]struct {
+		envVars             map[string]string
+		opts                []Option
+		expectedRegion      string
+		expectedMaxAttempts int
+		expectedRetryMode   aws.RetryMode
+		expectedErr         bool
+	}{
+		"Happy Path - Defaults": {
+			expectedRegion: USEastTwoRegion,
+		},
+		"Happy Path - Env Vars": {
+			envVars: map[string]string{
+				RegionEnvVar:      "eu-west-1",
+				RetryModeEnvVar:   "adaptive",
+				MaxAttemptsEnvVar: "5",
+				HTTPTimeoutEnvVar: "3s",
+			},
+			expectedRegion:      "eu-west-1",
+			expectedMaxAttempts: 5,
+			expectedRetryMode:   aws.RetryModeAdaptive,
+		},
+		"Happy Path - Options Override Env Vars": {
+			envVars: map[string]string{
+				RegionEnvVar:      "eu-west-1",
+				MaxAttemptsEnvVar: "5",
+			},
+			opts:                []Option{WithRegion("us-west-2"), WithMaxAttempts(2), WithRetryMode(aws.RetryModeStandard), WithHTTPTimeout(time.Second), WithConnectTimeout(time.Second)},
+			expectedRegion:      "us-west-2",
+			expectedMaxAttempts: 2,
+			expectedRetryMode:   aws.RetryModeStandard,
+		},
+		"Sad Path - Invalid Retry Mode": {
+			envVars:     map[string]string{RetryModeEnvVar: "sometimes"},
+			expectedErr: true,
+		},
+		"Sad Path - Invalid Max Attempts": {
+			envVars:     map[string]string{MaxAttemptsEnvVar: "zero"},
+			expectedErr: true,
+		},
+		"Sad Path - Invalid HTTP Timeout": {
+			envVars:     map[string]string{ConnectTimeoutEnvVar: "soon"},
+			expectedErr: true,
+		},
+		"Sad Path - Unknown Profile": {
+			opts:        []Option{WithProfile("care-giver-missing-profile")},
+			expectedErr: true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			for _, envVar := range []string{RegionEnvVar, ProfileEnvVar, RetryModeEnvVar, MaxAttemptsEnvVar, HTTPTimeoutEnvVar, ConnectTimeoutEnvVar} {
+				t.Setenv(envVar, tc.envVars[envVar])
+			}
+
+			cfg, err := GetAWSConfig(context.Background(), localEnv, tc.opts...)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expectedRegion, cfg.Region)
+			assert.Equal(t, tc.expectedMaxAttempts, cfg.RetryMaxAttempts)
+			assert.Equal(t, tc.expectedRetryMode, cfg.RetryMode)
+		})
+	}
+}
@@ -0,0 +1,285 @@
+// Package digest builds the daily email digests sent to care givers who
+// turned them on. Messages are returned ready to send; sending them is left
+// to the caller.
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/event"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/log"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/receiver"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/repository"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/user"
+	"go.uber.org/zap"
+)
+
+// maxPeriodLookback is how far before now a period returned by PreviousDay
+// can start: less than a day of up to 25 hours has passed since its end.
+const maxPeriodLookback = 50 * time.Hour
+
+// Period is the day a digest covers, from Start up to but excluding End.
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Line is an event as shown in a digest, at its time in the recipient's time
+// zone.
+type Line struct {
+	Time     time.Time
+	Type     string
+	Data     []event.DataPoint
+	LoggedBy string
+}
+
+// Digest is the data the templates render. Since is how far back events were
+// read; monitored event types not logged since then are critical and have no
+// LastLogged.
+type Digest struct {
+	Recipient user.User
+	Receiver  receiver.Receiver
+	Period    Period
+	Since     time.Time
+	Events    []Line
+	Monitors  []event.MonitorStatus
+}
+
+type Message struct {
+	UserID          string `json:"userId"`
+	ReceiverID      string `json:"receiverId"`
+	To              string `json:"to" log:"pii"`
+	Subject         string `json:"subject" log:"phi"`
+	HTML            string `json:"html" log:"phi"`
+	Text            string `json:"text" log:"phi"`
+	TemplateVersion string `json:"templateVersion"`
+	Period          Period `json:"period"`
+}
+
+type Builder struct {
+	Relationships   repository.RelationshipRepositoryProviderV2
+	Users           repository.UserRepositoryProviderV2
+	Receivers       repository.ReceiverRepositoryProviderV2
+	Events          repository.EventRepositoryProviderV2
+	TemplateVersion string
+
+	now    func() time.Time
+	logger *zap.Logger
+}
+
+func NewBuilder(relationships repository.RelationshipRepositoryProviderV2, users repository.UserRepositoryProviderV2, receivers repository.ReceiverRepositoryProviderV2, events repository.EventRepositoryProviderV2, logger *zap.Logger) *Builder {
+	return &Builder{
+		Relationships:   relationships,
+		Users:           users,
+		Receivers:       receivers,
+		Events:          events,
+		TemplateVersion: DefaultTemplateVersion,
+		now:             time.Now,
+		logger:          logger,
+	}
+}
+
+// Build returns a digest message for every relationship with email digests
+// turned on, covering the previous day in the recipient's time zone. The
+// messages that could be built are returned together with the errors of the
+// ones that could not.
+func (b *Builder) Build(ctx context.Context) ([]Message, error) {
+	relationships, err := b.Relationships.GetRelationshipsByEmailNotifications(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.WithTraceContext(ctx, b.logger).Info("building digests", zap.Int("relationships", len(relationships)))
+
+	s := b.newSession()
+	var messages []Message
+	var errs []error
+	for _, r := range relationships {
+		if !r.EffectiveNotifications().EmailDigest() {
+			continue
+		}
+
+		m, err := s.build(ctx, r)
+		if err != nil {
+			log.WithTraceContext(ctx, b.logger).Error("error building digest", zap.String(log.UserIDLogKey, r.UserID), zap.String(log.ReceiverIDLogKey, r.ReceiverID), zap.Error(err))
+			errs = append(errs, fmt.Errorf("building digest for user %s and receiver %s: %w", r.UserID, r.ReceiverID, err))
+			continue
+		}
+		messages = append(messages, m)
+	}
+
+	log.WithTraceContext(ctx, b.logger).Info("built digests", zap.Int("messages", len(messages)), zap.Int("failures", len(errs)))
+	return messages, errors.Join(errs...)
+}
+
+// BuildFor returns the digest message for a single relationship, whether or
+// not it has email digests turned on.
+func (b *Builder) BuildFor(ctx context.Context, r relationship.Relationship) (Message, error) {
+	return b.newSession().build(ctx, r)
+}
+
+// PreviousDay is the calendar day before the one now falls on in loc.
+func PreviousDay(now time.Time, loc *time.Location) Period {
+	local := now.In(loc)
+	end := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	return Period{Start: end.AddDate(0, 0, -1), End: end}
+}
+
+// session caches what is shared between the digests of one Build, as care
+// givers of the same receiver get digests built from the same data.
+type session struct {
+	*Builder
+	configs   []event.EventConfig
+	since     time.Time
+	until     time.Time
+	users     map[string]user.User
+	receivers map[string]receiver.Receiver
+	events    map[string][]event.Entry
+}
+
+func (b *Builder) newSession() *session {
+	return &session{
+		Builder:   b,
+		users:     map[string]user.User{},
+		receivers: map[string]receiver.Receiver{},
+		events:    map[string][]event.Entry{},
+	}
+}
+
+func (s *session) build(ctx context.Context, r relationship.Relationship) (Message, error) {
+	loc, err := time.LoadLocation(r.EffectiveNotifications().TimeZone)
+	if err != nil {
+		return Message{}, err
+	}
+	period := PreviousDay(s.now(), loc)
+
+	recipient, err := s.user(ctx, r.UserID)
+	if err != nil {
+		return Message{}, err
+	}
+	if recipient.Email == "" {
+		return Message{}, fmt.Errorf("user %s has no email", r.UserID)
+	}
+	rec, err := s.receiver(ctx, r.ReceiverID)
+	if err != nil {
+		return Message{}, err
+	}
+	if s.configs == nil {
+		if s.configs, err = event.GetAllConfigs(); err != nil {
+			return Message{}, err
+		}
+		s.until = s.now().UTC()
+		s.since = s.until.Add(-maxPeriodLookback - s.maxCritical())
+	}
+	entries, err := s.entries(ctx, r.ReceiverID)
+	if err != nil {
+		return Message{}, err
+	}
+
+	d := Digest{
+		Recipient: recipient,
+		Receiver:  rec,
+		Period:    period,
+		Since:     s.since.In(loc),
+		Monitors:  event.Monitors(s.configs, entries, period.End),
+	}
+	for i := range d.Monitors {
+		if d.Monitors[i].LastLogged.IsZero() {
+			d.Monitors[i].Level = event.AlertLevelCritical
+		}
+	}
+	for _, e := range entries {
+		start, err := time.Parse(time.RFC3339, e.StartTime)
+		if err != nil || start.Before(period.Start) || !start.Before(period.End) {
+			continue
+		}
+
+		line := Line{Time: start.In(loc), Type: e.Type, Data: e.Data}
+		if loggedBy, err := s.user(ctx, e.UserID); err == nil {
+			line.LoggedBy = strings.TrimSpace(loggedBy.FirstName + " " + loggedBy.LastName)
+		}
+		d.Events = append(d.Events, line)
+	}
+	sort.SliceStable(d.Events, func(i, j int) bool {
+		return d.Events[i].Time.Before(d.Events[j].Time)
+	})
+
+	subject, html, text, err := Render(s.TemplateVersion, d)
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		UserID:          r.UserID,
+		ReceiverID:      r.ReceiverID,
+		To:              recipient.Email,
+		Subject:         subject,
+		HTML:            html,
+		Text:            text,
+		TemplateVersion: s.TemplateVersion,
+		Period:          period,
+	}, nil
+}
+
+func (s *session) user(ctx context.Context, uid string) (user.User, error) {
+	if u, ok := s.users[uid]; ok {
+		return u, nil
+	}
+	u, err := s.Users.GetUser(ctx, uid)
+	if err != nil {
+		return u, err
+	}
+	s.users[uid] = u
+	return u, nil
+}
+
+func (s *session) receiver(ctx context.Context, rid string) (receiver.Receiver, error) {
+	if r, ok := s.receivers[rid]; ok {
+		return r, nil
+	}
+	r, err := s.Receivers.GetReceiver(ctx, rid)
+	if err != nil {
+		return r, err
+	}
+	s.receivers[rid] = r
+	return r, nil
+}
+
+// entries returns the events of the receiver logged over the 50 hours before
+// now, which hold every period a digest can cover whatever the recipient's
+// time zone, and over the longest critical threshold before that. Monitors
+// only need the last event of each type within that window: an event type
+// not logged since its start is critical by the end of any period.
+func (s *session) entries(ctx context.Context, rid string) ([]event.Entry, error) {
+	if e, ok := s.events[rid]; ok {
+		return e, nil
+	}
+
+	bound := repository.TimestampBound{
+		Lower: s.since.Format(time.RFC3339),
+		Upper: s.until.Format(time.RFC3339),
+	}
+	e, err := s.Events.GetEvents(ctx, rid, bound)
+	if err != nil {
+		return nil, err
+	}
+	s.events[rid] = e
+	return e, nil
+}
+
+// maxCritical is the longest critical threshold of the monitored event types.
+func (s *session) maxCritical() time.Duration {
+	var hours int
+	for _, c := range s.configs {
+		if c.Monitor != nil && c.Monitor.AlertThresholds != nil {
+			hours = max(hours, c.Monitor.AlertThresholds.Critical)
+		}
+	}
+	return time.Duration(hours) * time.Hour
+}
@@ -0,0 +1,145 @@
+package digest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/event"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/receiver"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/repository"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/repository/memory"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/user"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestRender(t *testing.T) {
+	d := Digest{
+		Recipient: user.User{FirstName: "Jane"},
+		Receiver:  receiver.Receiver{FirstName: "Bob", LastName: "<Smith>"},
+		Period:    Period{Start: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)},
+		Since:     time.Date(2024, 12, 27, 13, 0, 0, 0, time.UTC),
+		Monitors: []event.MonitorStatus{
+			{EventType: "Medication", Level: event.AlertLevelRed, LastLogged: time.Date(2024, 12, 31, 10, 0, 0, 0, time.UTC), Elapsed: 14 * time.Hour},
+			{EventType: "Shower", Level: event.AlertLevelCritical},
+		},
+		Events: []Line{
+			{Time: time.Date(2025, 1, 1, 9, 30, 0, 0, time.UTC), Type: "Weight", Data: []event.DataPoint{{Name: "Weight", Value: 180}}, LoggedBy: "Jane Doe"},
+		},
+	}
+
+	subject, html, text, err := Render(DefaultTemplateVersion, d)
+	assert.NoError(t, err)
+	assert.Equal(t, "Bob's daily care summary for Wed, Jan 1", subject)
+
+	assert.Contains(t, text, "Hi Jane,")
+	assert.Contains(t, text, "- Medication: Red, last logged 14 hours ago")
+	assert.Contains(t, text, "- Shower: Critical (not logged since Fri, Dec 27)")
+	assert.Contains(t, text, "- 9:30 AM Weight, Weight: 180 (logged by Jane Doe)")
+
+	assert.Contains(t, html, "&lt;Smith&gt;")
+	assert.NotContains(t, html, "<Smith>")
+	assert.Contains(t, html, "#E74C3C")
+
+	_, _, _, err = Render("v0", d)
+	assert.ErrorIs(t, err, ErrUnknownTemplateVersion)
+}
+
+func TestLoadTemplates(t *testing.T) {
+	first, err := loadTemplates(DefaultTemplateVersion)
+	assert.NoError(t, err)
+	second, err := loadTemplates(DefaultTemplateVersion)
+	assert.NoError(t, err)
+	assert.Same(t, first, second)
+}
+
+func TestTemplateVersions(t *testing.T) {
+	versions, err := TemplateVersions()
+	assert.NoError(t, err)
+	assert.Contains(t, versions, DefaultTemplateVersion)
+}
+
+func TestPreviousDay(t *testing.T) {
+	chicago, _ := time.LoadLocation("America/Chicago")
+	now := time.Date(2025, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, Period{
+		Start: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+	}, PreviousDay(now, time.UTC))
+	assert.Equal(t, Period{
+		Start: time.Date(2024, 12, 31, 0, 0, 0, 0, chicago),
+		End:   time.Date(2025, 1, 1, 0, 0, 0, 0, chicago),
+	}, PreviousDay(now, chicago))
+}
+
+// boundRecorder records the bounds events are queried with.
+type boundRecorder struct {
+	repository.EventRepositoryProviderV2
+	bounds []repository.TimestampBound
+}
+
+func (b *boundRecorder) GetEvents(ctx context.Context, rid string, bound repository.TimestampBound) ([]event.Entry, error) {
+	b.bounds = append(b.bounds, bound)
+	return b.EventRepositoryProviderV2.GetEvents(ctx, rid, bound)
+}
+
+func TestBuild(t *testing.T) {
+	ctx := context.Background()
+	users := memory.NewUserRepositoryV2()
+	receivers := memory.NewReceiverRepositoryV2()
+	relationships := memory.NewRelationshipRepositoryV2()
+	events := &boundRecorder{EventRepositoryProviderV2: memory.NewEventRepositoryV2()}
+
+	jane := user.User{UserID: "User#jane", Email: "jane@example.com", FirstName: "Jane", LastName: "Doe"}
+	john := user.User{UserID: "User#john", Email: "john@example.com", FirstName: "John", LastName: "Doe"}
+	nomail := user.User{UserID: "User#nomail", FirstName: "No"}
+	for _, u := range []user.User{jane, john, nomail} {
+		assert.NoError(t, users.CreateUser(ctx, u))
+	}
+	assert.NoError(t, receivers.CreateReceiver(ctx, receiver.Receiver{ReceiverID: "Receiver#bob", FirstName: "Bob", LastName: "Doe"}))
+
+	assert.NoError(t, relationships.AddRelationship(ctx, relationship.NewRelationship(jane.UserID, "Receiver#bob", true, true)))
+	immediate := relationship.NewRelationship(john.UserID, "Receiver#bob", false, false)
+	immediate.SetNotifications(relationship.NotificationPreferences{Channels: []relationship.Channel{relationship.ChannelSMS}, Delivery: relationship.DeliveryImmediate})
+	assert.NoError(t, relationships.AddRelationship(ctx, immediate))
+	assert.NoError(t, relationships.AddRelationship(ctx, relationship.NewRelationship(nomail.UserID, "Receiver#bob", false, true)))
+
+	for _, e := range []*event.Entry{
+		{EventID: "Event#1", ReceiverID: "Receiver#bob", UserID: jane.UserID, Type: "Medication", StartTime: "2025-01-01T10:00:00Z"},
+		{EventID: "Event#2", ReceiverID: "Receiver#bob", UserID: john.UserID, Type: "Shower", StartTime: "2024-12-30T10:00:00Z"},
+		{EventID: "Event#3", ReceiverID: "Receiver#bob", UserID: jane.UserID, Type: "Walk", StartTime: "2025-01-02T01:00:00Z"},
+		{EventID: "Event#4", ReceiverID: "Receiver#bob", UserID: jane.UserID, Type: "Bowel Movement", StartTime: "2024-12-20T10:00:00Z"},
+	} {
+		assert.NoError(t, events.AddEvent(ctx, e))
+	}
+
+	b := NewBuilder(relationships, users, receivers, events, zap.NewNop())
+	b.now = func() time.Time { return time.Date(2025, 1, 2, 3, 0, 0, 0, time.UTC) }
+
+	messages, err := b.Build(ctx)
+	assert.Error(t, err)
+	assert.Len(t, messages, 1)
+
+	m := messages[0]
+	assert.Equal(t, jane.UserID, m.UserID)
+	assert.Equal(t, "Receiver#bob", m.ReceiverID)
+	assert.Equal(t, jane.Email, m.To)
+	assert.Equal(t, DefaultTemplateVersion, m.TemplateVersion)
+	assert.Equal(t, "Bob's daily care summary for Wed, Jan 1", m.Subject)
+	assert.Contains(t, m.Text, "Events (1)")
+	assert.Contains(t, m.Text, "- 10:00 AM Medication (logged by Jane Doe)")
+	assert.Contains(t, m.Text, "- Medication: Red, last logged 14 hours ago")
+	assert.Contains(t, m.Text, "- Shower: Red, last logged 2 days ago")
+	// Bowel movements were last logged before the events read, which makes
+	// them critical rather than never logged.
+	assert.Contains(t, m.Text, "- Bowel Movement: Critical (not logged since Fri, Dec 27)")
+	assert.NotContains(t, m.Text, "Walk")
+	assert.NotEmpty(t, m.HTML)
+
+	// Events are read once per receiver, back to the longest critical
+	// threshold (84 hours for showers) before the earliest possible period.
+	assert.Equal(t, []repository.TimestampBound{{Lower: "2024-12-27T13:00:00Z", Upper: "2025-01-02T03:00:00Z"}}, events.bounds)
+}
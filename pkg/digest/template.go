@@ -0,0 +1,115 @@
+package digest
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/notify"
+)
+
+// DefaultTemplateVersion is the template version messages are rendered with
+// unless another is asked for. Released versions are never changed, so a
+// message can always be rendered again exactly as it was sent.
+const DefaultTemplateVersion = "v1"
+
+var ErrUnknownTemplateVersion = errors.New("unknown digest template version")
+
+//go:embed templates
+var templateFS embed.FS
+
+// loaded caches the templates of each version, as released versions never
+// change.
+var (
+	loadedMu sync.Mutex
+	loaded   = map[string]*templates{}
+)
+
+type templates struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+// TemplateVersions lists the template versions that can be rendered.
+func TemplateVersions() ([]string, error) {
+	entries, err := fs.ReadDir(templateFS, "templates")
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	for _, e := range entries {
+		if e.IsDir() {
+			versions = append(versions, e.Name())
+		}
+	}
+	return versions, nil
+}
+
+// loadTemplates returns the templates of version, parsing them on first use.
+func loadTemplates(version string) (*templates, error) {
+	loadedMu.Lock()
+	defer loadedMu.Unlock()
+
+	if t, ok := loaded[version]; ok {
+		return t, nil
+	}
+
+	dir := "templates/" + version
+	if _, err := fs.Stat(templateFS, dir); err != nil || version == "" {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTemplateVersion, version)
+	}
+
+	subject, err := texttemplate.New("subject.txt.tmpl").Funcs(notify.Funcs).ParseFS(templateFS, dir+"/subject.txt.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	text, err := texttemplate.New("body.txt.tmpl").Funcs(notify.Funcs).ParseFS(templateFS, dir+"/body.txt.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	html, err := htmltemplate.New("body.html.tmpl").Funcs(notify.Funcs).ParseFS(templateFS, dir+"/body.html.tmpl")
+	if err != nil {
+		return nil, err
+	}
+
+	t := &templates{subject: subject, text: text, html: html}
+	loaded[version] = t
+	return t, nil
+}
+
+// Render renders the subject, HTML body and plain-text body of d with the
+// templates of the given version.
+func Render(version string, d Digest) (subject string, html string, text string, err error) {
+	t, err := loadTemplates(version)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	var buf bytes.Buffer
+	if err := t.subject.Execute(&buf, d); err != nil {
+		return "", "", "", fmt.Errorf("rendering subject: %w", err)
+	}
+	subject = strings.TrimSpace(buf.String())
+
+	buf.Reset()
+	if err := t.html.Execute(&buf, d); err != nil {
+		return "", "", "", fmt.Errorf("rendering html body: %w", err)
+	}
+	html = buf.String()
+
+	buf.Reset()
+	if err := t.text.Execute(&buf, d); err != nil {
+		return "", "", "", fmt.Errorf("rendering text body: %w", err)
+	}
+	text = buf.String()
+
+	return subject, html, text, nil
+}
@@ -2,15 +2,22 @@ package dynamo
 
 import (
 	"context"
+	"os"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/awsconfig"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/log"
 	"go.uber.org/zap"
 )
 
 const (
 	localDockerEndpoint = "http://dynamodb-local:8000"
 	localEnv            = "local"
+
+	// EndpointEnvVar overrides the DynamoDB endpoint in every env.
+	EndpointEnvVar = "CARE_GIVER_DYNAMODB_ENDPOINT"
 )
 
 type DynamodbClientProvider interface {
@@ -18,20 +25,96 @@ type DynamodbClientProvider interface {
 	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
 	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
 	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
 	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+	BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error)
+	TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error)
+}
+
+type ClientOptions struct {
+	Endpoint       string
+	Region         string
+	RetryMode      aws.RetryMode
+	MaxAttempts    int
+	HTTPTimeout    time.Duration
+	ConnectTimeout time.Duration
+}
+
+type ClientOption func(*ClientOptions)
+
+// WithEndpoint points the client at a DynamoDB compatible endpoint such as
+// DynamoDB Local on http://localhost:8000.
+func WithEndpoint(endpoint string) ClientOption {
+	return func(o *ClientOptions) {
+		o.Endpoint = endpoint
+	}
+}
+
+func WithRegion(region string) ClientOption {
+	return func(o *ClientOptions) {
+		o.Region = region
+	}
+}
+
+func WithRetryMode(mode aws.RetryMode) ClientOption {
+	return func(o *ClientOptions) {
+		o.RetryMode = mode
+	}
+}
+
+func WithMaxAttempts(attempts int) ClientOption {
+	return func(o *ClientOptions) {
+		o.MaxAttempts = attempts
+	}
+}
+
+func WithHTTPTimeout(timeout time.Duration) ClientOption {
+	return func(o *ClientOptions) {
+		o.HTTPTimeout = timeout
+	}
 }
 
-func CreateClient(env string, awsConfig aws.Config, logger *zap.Logger) *dynamodb.Client {
+func WithConnectTimeout(timeout time.Duration) ClientOption {
+	return func(o *ClientOptions) {
+		o.ConnectTimeout = timeout
+	}
+}
+
+// CreateClient creates a client from awsConfig. In the local env the client
+// talks to DynamoDB Local in docker unless an endpoint is set with
+// WithEndpoint or EndpointEnvVar; options override the environment variable.
+func CreateClient(env string, awsConfig aws.Config, logger *zap.Logger, opts ...ClientOption) *dynamodb.Client {
+	o := ClientOptions{Endpoint: os.Getenv(EndpointEnvVar)}
+	if o.Endpoint == "" && env == localEnv {
+		o.Endpoint = localDockerEndpoint
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	if env == localEnv {
-		logger.Info("creating local dynamo db client")
-		return createLocalClient(awsConfig)
+		logger.Info("creating local dynamo db client", zap.String(log.EndpointLogKey, o.Endpoint))
+	} else {
+		logger.Info("creating dynamo db client")
 	}
-	logger.Info("creating dynamo db client")
-	return dynamodb.NewFromConfig(awsConfig)
+	return dynamodb.NewFromConfig(awsConfig, o.apply)
 }
 
-func createLocalClient(cfg aws.Config) *dynamodb.Client {
-	return dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
-		o.BaseEndpoint = aws.String(localDockerEndpoint)
-	})
+func (o ClientOptions) apply(opts *dynamodb.Options) {
+	if o.Endpoint != "" {
+		opts.BaseEndpoint = aws.String(o.Endpoint)
+	}
+	if o.Region != "" {
+		opts.Region = o.Region
+	}
+	if o.RetryMode != "" {
+		opts.RetryMode = o.RetryMode
+	}
+	if o.MaxAttempts > 0 {
+		opts.RetryMaxAttempts = o.MaxAttempts
+	}
+	if o.HTTPTimeout > 0 || o.ConnectTimeout > 0 {
+		opts.HTTPClient = awsconfig.NewHTTPClient(o.HTTPTimeout, o.ConnectTimeout)
+	}
 }
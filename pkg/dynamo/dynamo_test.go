@@ -2,6 +2,7 @@ package dynamo
 
 import (
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/stretchr/testify/assert"
@@ -10,11 +11,15 @@ import (
 
 func TestCreateClient(t *testing.T) {
 	tests := map[string]struct {
-		env               string
-		awsConfig         aws.Config
-		logger            *zap.Logger
-		expectedEndpoint  string
-		expectNilEndpoint bool
+		env                 string
+		awsConfig           aws.Config
+		logger              *zap.Logger
+		opts                []ClientOption
+		endpointEnvVar      string
+		expectedEndpoint    string
+		expectNilEndpoint   bool
+		expectedRegion      string
+		expectedMaxAttempts int
 	}{
 		"Happy Path - Local Endpoint": {
 			env:              localEnv,
@@ -28,16 +33,49 @@ func TestCreateClient(t *testing.T) {
 			logger:            zap.Must(zap.NewProduction()),
 			expectNilEndpoint: true,
 		},
+		"Happy Path - Endpoint From Env Var": {
+			env:              localEnv,
+			awsConfig:        aws.Config{},
+			logger:           zap.NewNop(),
+			endpointEnvVar:   "http://localhost:8001",
+			expectedEndpoint: "http://localhost:8001",
+		},
+		"Happy Path - Endpoint Option Overrides Env Var": {
+			env:              "dev",
+			awsConfig:        aws.Config{},
+			logger:           zap.NewNop(),
+			opts:             []ClientOption{WithEndpoint("http://localhost:8000")},
+			endpointEnvVar:   "http://localhost:8001",
+			expectedEndpoint: "http://localhost:8000",
+		},
+		"Happy Path - Client Options": {
+			env:                 "dev",
+			awsConfig:           aws.Config{Region: "us-east-2"},
+			logger:              zap.NewNop(),
+			opts:                []ClientOption{WithRegion("eu-west-1"), WithMaxAttempts(5), WithRetryMode(aws.RetryModeAdaptive), WithHTTPTimeout(time.Second)},
+			expectNilEndpoint:   true,
+			expectedRegion:      "eu-west-1",
+			expectedMaxAttempts: 5,
+		},
 	}
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			client := CreateClient(tc.env, tc.awsConfig, tc.logger)
+			t.Setenv(EndpointEnvVar, tc.endpointEnvVar)
+
+			client := CreateClient(tc.env, tc.awsConfig, tc.logger, tc.opts...)
 
 			if tc.expectNilEndpoint {
 				assert.Nil(t, client.Options().BaseEndpoint)
 			} else {
 				assert.Equal(t, tc.expectedEndpoint, *client.Options().BaseEndpoint)
 			}
+			if tc.expectedRegion != "" {
+				assert.Equal(t, tc.expectedRegion, client.Options().Region)
+			}
+			if tc.expectedMaxAttempts != 0 {
+				assert.Equal(t, tc.expectedMaxAttempts, client.Options().RetryMaxAttempts)
+				assert.Equal(t, aws.RetryModeAdaptive, client.Options().RetryMode)
+			}
 		})
 	}
 }
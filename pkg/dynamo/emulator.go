@@ -0,0 +1,1322 @@
+package dynamo
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	maxBatchWriteItems    = 25
+	maxBatchGetKeys       = 100
+	maxTransactWriteItems = 100
+)
+
+// Emulator is an in-process stand-in for DynamoDB. It stores items per table,
+// maintains sparse global secondary indexes and evaluates key condition,
+// filter, condition and update expressions. PageSize caps the number of items
+// a single Query evaluates so callers exercise pagination.
+type Emulator struct {
+	PageSize int
+
+	mu     sync.Mutex
+	tables map[string]*emulatorTable
+}
+
+type emulatorTable struct {
+	schema TableSchema
+	items  map[string]map[string]types.AttributeValue
+}
+
+func NewEmulator(schemas ...TableSchema) *Emulator {
+	e := &Emulator{tables: map[string]*emulatorTable{}}
+	for _, s := range schemas {
+		e.AddTable(s)
+	}
+	return e
+}
+
+// AddTable adds an empty table, replacing any existing table of the same
+// name.
+func (e *Emulator) AddTable(schema TableSchema) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.tables[schema.Name] = &emulatorTable{
+		schema: schema,
+		items:  map[string]map[string]types.AttributeValue{},
+	}
+}
+
+// Items returns a copy of every item stored in the table.
+func (e *Emulator) Items(tableName string) []map[string]types.AttributeValue {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	t, ok := e.tables[tableName]
+	if !ok {
+		return nil
+	}
+
+	items := make([]map[string]types.AttributeValue, 0, len(t.items))
+	for _, k := range t.sortedKeys() {
+		items = append(items, copyItem(t.items[k]))
+	}
+	return items
+}
+
+func (e *Emulator) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	t, err := e.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	cond, err := compileCondition(params.ConditionExpression, params.ExpressionAttributeNames, params.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := t.put(params.Item, cond)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &dynamodb.PutItemOutput{}
+	if params.ReturnValues == types.ReturnValueAllOld {
+		out.Attributes = existing
+	}
+	return out, nil
+}
+
+func (e *Emulator) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	t, err := e.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	projection, err := compileProjection(params.ProjectionExpression, params.ExpressionAttributeNames)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := t.keyOf(params.Key, true)
+	if err != nil {
+		return nil, err
+	}
+
+	item, ok := t.items[key]
+	if !ok {
+		return &dynamodb.GetItemOutput{}, nil
+	}
+	return &dynamodb.GetItemOutput{Item: project(item, projection)}, nil
+}
+
+func (e *Emulator) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	t, err := e.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	update, err := compileUpdate(params.UpdateExpression, params.ConditionExpression, params.ExpressionAttributeNames, params.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+
+	old, updated, err := t.update(params.Key, update)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &dynamodb.UpdateItemOutput{}
+	switch params.ReturnValues {
+	case types.ReturnValueAllOld:
+		out.Attributes = old
+	case types.ReturnValueAllNew:
+		out.Attributes = copyItem(updated)
+	case types.ReturnValueUpdatedOld:
+		out.Attributes = pick(old, update.touched())
+	case types.ReturnValueUpdatedNew:
+		out.Attributes = pick(updated, update.touched())
+	}
+	return out, nil
+}
+
+func (e *Emulator) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	t, err := e.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	cond, err := compileCondition(params.ConditionExpression, params.ExpressionAttributeNames, params.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+
+	old, err := t.delete(params.Key, cond)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &dynamodb.DeleteItemOutput{}
+	if params.ReturnValues == types.ReturnValueAllOld {
+		out.Attributes = old
+	}
+	return out, nil
+}
+
+func (e *Emulator) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	t, err := e.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	pk, sk := t.schema.PartitionKey, t.schema.SortKey
+	indexName := aws.ToString(params.IndexName)
+	if indexName != "" {
+		index, ok := t.schema.index(indexName)
+		if !ok {
+			return nil, validationError("The table does not have the specified index: %s", indexName)
+		}
+		pk, sk = index.PartitionKey, index.SortKey
+	}
+
+	if params.KeyConditionExpression == nil {
+		return nil, validationError("Either the KeyConditions or KeyConditionExpression parameter must be specified in the request.")
+	}
+
+	keyParser, err := newParser(*params.KeyConditionExpression, params.ExpressionAttributeNames, params.ExpressionAttributeValues)
+	if err != nil {
+		return nil, validationError("Invalid KeyConditionExpression: %s", err)
+	}
+	keyCond, err := keyParser.parseOr()
+	if err == nil {
+		err = keyParser.expectEOF()
+	}
+	if err != nil {
+		return nil, validationError("Invalid KeyConditionExpression: %s", err)
+	}
+	if err := validateKeyCondition(keyCond, pk.Name, sk.Name); err != nil {
+		return nil, err
+	}
+
+	parsers := []*parser{keyParser}
+	var filter condition
+	if params.FilterExpression != nil {
+		filterParser, err := newParser(*params.FilterExpression, params.ExpressionAttributeNames, params.ExpressionAttributeValues)
+		if err != nil {
+			return nil, validationError("Invalid FilterExpression: %s", err)
+		}
+		filter, err = filterParser.parseOr()
+		if err == nil {
+			err = filterParser.expectEOF()
+		}
+		if err != nil {
+			return nil, validationError("Invalid FilterExpression: %s", err)
+		}
+		parsers = append(parsers, filterParser)
+	}
+
+	projection, err := compileProjection(params.ProjectionExpression, params.ExpressionAttributeNames)
+	if err != nil {
+		return nil, err
+	}
+	if projection != nil {
+		parsers = append(parsers, projection.parser)
+	}
+
+	if err := checkUnused(params.ExpressionAttributeNames, params.ExpressionAttributeValues, parsers...); err != nil {
+		return nil, err
+	}
+
+	var candidates []map[string]types.AttributeValue
+	for _, item := range t.items {
+		if !hasKey(item, pk, sk) {
+			continue
+		}
+		matched, err := keyCond.matches(item)
+		if err != nil {
+			return nil, validationError("%s", err)
+		}
+		if matched {
+			candidates = append(candidates, item)
+		}
+	}
+
+	order := func(item map[string]types.AttributeValue) []types.AttributeValue {
+		return []types.AttributeValue{item[sk.Name], item[t.schema.PartitionKey.Name], item[t.schema.SortKey.Name]}
+	}
+	forward := params.ScanIndexForward == nil || *params.ScanIndexForward
+	sort.SliceStable(candidates, func(i, j int) bool {
+		cmp := compareTuples(order(candidates[i]), order(candidates[j]))
+		if forward {
+			return cmp < 0
+		}
+		return cmp > 0
+	})
+
+	if params.ExclusiveStartKey != nil {
+		start := order(params.ExclusiveStartKey)
+		remaining := candidates[:0]
+		for _, item := range candidates {
+			cmp := compareTuples(order(item), start)
+			if (forward && cmp > 0) || (!forward && cmp < 0) {
+				remaining = append(remaining, item)
+			}
+		}
+		candidates = remaining
+	}
+
+	limit := len(candidates)
+	if params.Limit != nil && int(*params.Limit) < limit {
+		limit = int(*params.Limit)
+	}
+	if e.PageSize > 0 && e.PageSize < limit {
+		limit = e.PageSize
+	}
+
+	out := &dynamodb.QueryOutput{ScannedCount: int32(limit)}
+	for _, item := range candidates[:limit] {
+		if filter != nil {
+			matched, err := filter.matches(item)
+			if err != nil {
+				return nil, validationError("%s", err)
+			}
+			if !matched {
+				continue
+			}
+		}
+		out.Count++
+		if params.Select != types.SelectCount {
+			out.Items = append(out.Items, project(item, projection))
+		}
+	}
+
+	if limit < len(candidates) && limit > 0 {
+		last := candidates[limit-1]
+		out.LastEvaluatedKey = pick(last, []string{t.schema.PartitionKey.Name, t.schema.SortKey.Name, pk.Name, sk.Name})
+	}
+
+	return out, nil
+}
+
+// Scan reads the items of a table or index in primary key order.
+func (e *Emulator) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	t, err := e.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	keyNames := []string{t.schema.PartitionKey.Name, t.schema.SortKey.Name}
+	inIndex := func(map[string]types.AttributeValue) bool { return true }
+	if indexName := aws.ToString(params.IndexName); indexName != "" {
+		index, ok := t.schema.index(indexName)
+		if !ok {
+			return nil, validationError("The table does not have the specified index: %s", indexName)
+		}
+		keyNames = append(keyNames, index.PartitionKey.Name, index.SortKey.Name)
+		inIndex = func(item map[string]types.AttributeValue) bool {
+			return hasKey(item, index.PartitionKey, index.SortKey)
+		}
+	}
+
+	var parsers []*parser
+	var filter condition
+	if params.FilterExpression != nil {
+		filterParser, err := newParser(*params.FilterExpression, params.ExpressionAttributeNames, params.ExpressionAttributeValues)
+		if err != nil {
+			return nil, validationError("Invalid FilterExpression: %s", err)
+		}
+		filter, err = filterParser.parseOr()
+		if err == nil {
+			err = filterParser.expectEOF()
+		}
+		if err != nil {
+			return nil, validationError("Invalid FilterExpression: %s", err)
+		}
+		parsers = append(parsers, filterParser)
+	}
+
+	projection, err := compileProjection(params.ProjectionExpression, params.ExpressionAttributeNames)
+	if err != nil {
+		return nil, err
+	}
+	if projection != nil {
+		parsers = append(parsers, projection.parser)
+	}
+
+	if err := checkUnused(params.ExpressionAttributeNames, params.ExpressionAttributeValues, parsers...); err != nil {
+		return nil, err
+	}
+
+	start := ""
+	if params.ExclusiveStartKey != nil {
+		start, err = t.keyOf(params.ExclusiveStartKey, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var candidates []map[string]types.AttributeValue
+	for _, k := range t.sortedKeys() {
+		if k > start && inIndex(t.items[k]) {
+			candidates = append(candidates, t.items[k])
+		}
+	}
+
+	limit := len(candidates)
+	if params.Limit != nil && int(*params.Limit) < limit {
+		limit = int(*params.Limit)
+	}
+	if e.PageSize > 0 && e.PageSize < limit {
+		limit = e.PageSize
+	}
+
+	out := &dynamodb.ScanOutput{ScannedCount: int32(limit)}
+	for _, item := range candidates[:limit] {
+		if filter != nil {
+			matched, err := filter.matches(item)
+			if err != nil {
+				return nil, validationError("%s", err)
+			}
+			if !matched {
+				continue
+			}
+		}
+		out.Count++
+		if params.Select != types.SelectCount {
+			out.Items = append(out.Items, project(item, projection))
+		}
+	}
+
+	if limit < len(candidates) && limit > 0 {
+		out.LastEvaluatedKey = pick(candidates[limit-1], keyNames)
+	}
+
+	return out, nil
+}
+
+func (e *Emulator) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	total := 0
+	for tableName, requests := range params.RequestItems {
+		t, err := e.table(aws.String(tableName))
+		if err != nil {
+			return nil, err
+		}
+
+		seen := map[string]bool{}
+		for _, r := range requests {
+			var key string
+			switch {
+			case r.PutRequest != nil && r.DeleteRequest == nil:
+				if err := t.validateItem(r.PutRequest.Item); err != nil {
+					return nil, err
+				}
+				key, _ = t.keyOf(r.PutRequest.Item, false)
+			case r.DeleteRequest != nil && r.PutRequest == nil:
+				key, err = t.keyOf(r.DeleteRequest.Key, true)
+				if err != nil {
+					return nil, err
+				}
+			default:
+				return nil, validationError("Supplied AttributeValue has more than one datatypes set, must contain exactly one of the supported datatypes")
+			}
+			if seen[key] {
+				return nil, validationError("Provided list of item keys contains duplicates")
+			}
+			seen[key] = true
+			total++
+		}
+	}
+
+	if total == 0 {
+		return nil, validationError("1 validation error detected: Value at 'requestItems' failed to satisfy constraint: Member must have length greater than or equal to 1")
+	}
+	if total > maxBatchWriteItems {
+		return nil, validationError("Too many items requested for the BatchWriteItem call")
+	}
+
+	for tableName, requests := range params.RequestItems {
+		t := e.tables[tableName]
+		for _, r := range requests {
+			if r.PutRequest != nil {
+				key, _ := t.keyOf(r.PutRequest.Item, false)
+				t.items[key] = copyItem(r.PutRequest.Item)
+				continue
+			}
+			key, _ := t.keyOf(r.DeleteRequest.Key, true)
+			delete(t.items, key)
+		}
+	}
+
+	return &dynamodb.BatchWriteItemOutput{UnprocessedItems: map[string][]types.WriteRequest{}}, nil
+}
+
+func (e *Emulator) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	total := 0
+	for _, ka := range params.RequestItems {
+		total += len(ka.Keys)
+	}
+	if total == 0 {
+		return nil, validationError("1 validation error detected: Value at 'requestItems' failed to satisfy constraint: Member must have length greater than or equal to 1")
+	}
+	if total > maxBatchGetKeys {
+		return nil, validationError("Too many items requested for the BatchGetItem call")
+	}
+
+	out := &dynamodb.BatchGetItemOutput{
+		Responses:       map[string][]map[string]types.AttributeValue{},
+		UnprocessedKeys: map[string]types.KeysAndAttributes{},
+	}
+	for tableName, ka := range params.RequestItems {
+		t, err := e.table(aws.String(tableName))
+		if err != nil {
+			return nil, err
+		}
+
+		projection, err := compileProjection(ka.ProjectionExpression, ka.ExpressionAttributeNames)
+		if err != nil {
+			return nil, err
+		}
+
+		seen := map[string]bool{}
+		responses := []map[string]types.AttributeValue{}
+		for _, k := range ka.Keys {
+			key, err := t.keyOf(k, true)
+			if err != nil {
+				return nil, err
+			}
+			if seen[key] {
+				return nil, validationError("Provided list of item keys contains duplicates")
+			}
+			seen[key] = true
+
+			if item, ok := t.items[key]; ok {
+				responses = append(responses, project(item, projection))
+			}
+		}
+		out.Responses[tableName] = responses
+	}
+
+	return out, nil
+}
+
+func (e *Emulator) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(params.TransactItems) == 0 || len(params.TransactItems) > maxTransactWriteItems {
+		return nil, validationError("Member must have length less than or equal to %d and greater than or equal to 1", maxTransactWriteItems)
+	}
+
+	type step struct {
+		table *emulatorTable
+		key   string
+		cond  condition
+		apply func() error
+	}
+
+	steps := make([]step, 0, len(params.TransactItems))
+	seen := map[string]bool{}
+	for _, ti := range params.TransactItems {
+		var s step
+		var err error
+		switch {
+		case ti.Put != nil:
+			s.table, err = e.table(ti.Put.TableName)
+			if err != nil {
+				return nil, err
+			}
+			if err := s.table.validateItem(ti.Put.Item); err != nil {
+				return nil, err
+			}
+			s.key, _ = s.table.keyOf(ti.Put.Item, false)
+			s.cond, err = compileCondition(ti.Put.ConditionExpression, ti.Put.ExpressionAttributeNames, ti.Put.ExpressionAttributeValues)
+			t, item := s.table, ti.Put.Item
+			s.apply = func() error {
+				_, err := t.put(item, nil)
+				return err
+			}
+		case ti.Update != nil:
+			s.table, err = e.table(ti.Update.TableName)
+			if err != nil {
+				return nil, err
+			}
+			s.key, err = s.table.keyOf(ti.Update.Key, true)
+			if err != nil {
+				return nil, err
+			}
+			var update *compiledUpdate
+			update, err = compileUpdate(ti.Update.UpdateExpression, ti.Update.ConditionExpression, ti.Update.ExpressionAttributeNames, ti.Update.ExpressionAttributeValues)
+			if err != nil {
+				return nil, err
+			}
+			s.cond = update.cond
+			t, key := s.table, ti.Update.Key
+			s.apply = func() error {
+				_, _, err := t.update(key, &compiledUpdate{actions: update.actions})
+				return err
+			}
+		case ti.Delete != nil:
+			s.table, err = e.table(ti.Delete.TableName)
+			if err != nil {
+				return nil, err
+			}
+			s.key, err = s.table.keyOf(ti.Delete.Key, true)
+			if err != nil {
+				return nil, err
+			}
+			s.cond, err = compileCondition(ti.Delete.ConditionExpression, ti.Delete.ExpressionAttributeNames, ti.Delete.ExpressionAttributeValues)
+			t, key := s.table, ti.Delete.Key
+			s.apply = func() error {
+				_, err := t.delete(key, nil)
+				return err
+			}
+		case ti.ConditionCheck != nil:
+			s.table, err = e.table(ti.ConditionCheck.TableName)
+			if err != nil {
+				return nil, err
+			}
+			s.key, err = s.table.keyOf(ti.ConditionCheck.Key, true)
+			if err != nil {
+				return nil, err
+			}
+			if ti.ConditionCheck.ConditionExpression == nil {
+				return nil, validationError("The ConditionExpression of a ConditionCheck must be set")
+			}
+			s.cond, err = compileCondition(ti.ConditionCheck.ConditionExpression, ti.ConditionCheck.ExpressionAttributeNames, ti.ConditionCheck.ExpressionAttributeValues)
+			s.apply = func() error { return nil }
+		default:
+			return nil, validationError("TransactItems can only contain one of Check, Put, Update or Delete")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		id := s.table.schema.Name + "/" + s.key
+		if seen[id] {
+			return nil, validationError("Transaction request cannot include multiple operations on one item")
+		}
+		seen[id] = true
+		steps = append(steps, s)
+	}
+
+	reasons := make([]types.CancellationReason, len(steps))
+	cancelled := false
+	for i, s := range steps {
+		reasons[i] = types.CancellationReason{Code: aws.String("None")}
+		if s.cond == nil {
+			continue
+		}
+		matched, err := s.cond.matches(itemOrEmpty(s.table.items[s.key]))
+		if err != nil {
+			return nil, validationError("%s", err)
+		}
+		if !matched {
+			cancelled = true
+			reasons[i] = types.CancellationReason{
+				Code:    aws.String("ConditionalCheckFailed"),
+				Message: aws.String("The conditional request failed"),
+			}
+		}
+	}
+
+	if cancelled {
+		codes := make([]string, len(reasons))
+		for i, r := range reasons {
+			codes[i] = *r.Code
+		}
+		return nil, &types.TransactionCanceledException{
+			Message:             aws.String(fmt.Sprintf("Transaction cancelled, please refer cancellation reasons for specific reasons [%s]", strings.Join(codes, ", "))),
+			CancellationReasons: reasons,
+		}
+	}
+
+	// Apply to copies first so a step that fails validation leaves every
+	// table untouched.
+	snapshots := map[*emulatorTable]map[string]map[string]types.AttributeValue{}
+	for _, s := range steps {
+		if _, ok := snapshots[s.table]; !ok {
+			snapshots[s.table] = s.table.items
+			s.table.items = copyItems(s.table.items)
+		}
+	}
+	for _, s := range steps {
+		if err := s.apply(); err != nil {
+			for t, items := range snapshots {
+				t.items = items
+			}
+			return nil, err
+		}
+	}
+
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+// CreateTable creates an empty table from the request's key schema and global
+// secondary indexes. Tables are ACTIVE as soon as they are created.
+func (e *Emulator) CreateTable(ctx context.Context, params *dynamodb.CreateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	name := aws.ToString(params.TableName)
+	if _, ok := e.tables[name]; ok {
+		return nil, &types.ResourceInUseException{Message: aws.String(fmt.Sprintf("Table already exists: %s", name))}
+	}
+
+	schema := schemaFromDescription(name, params.KeySchema, params.AttributeDefinitions, params.GlobalSecondaryIndexes)
+	if err := validateSchema(schema); err != nil {
+		return nil, err
+	}
+
+	t := &emulatorTable{schema: schema, items: map[string]map[string]types.AttributeValue{}}
+	e.tables[name] = t
+	return &dynamodb.CreateTableOutput{TableDescription: t.describe()}, nil
+}
+
+func (e *Emulator) DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	t, err := e.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.DescribeTableOutput{Table: t.describe()}, nil
+}
+
+// UpdateTable supports creating global secondary indexes. Existing items are
+// indexed immediately.
+func (e *Emulator) UpdateTable(ctx context.Context, params *dynamodb.UpdateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateTableOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	t, err := e.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	var creates []types.GlobalSecondaryIndex
+	for _, u := range params.GlobalSecondaryIndexUpdates {
+		if u.Create == nil {
+			return nil, validationError("Only index creation is supported")
+		}
+		creates = append(creates, types.GlobalSecondaryIndex{
+			IndexName:  u.Create.IndexName,
+			KeySchema:  u.Create.KeySchema,
+			Projection: u.Create.Projection,
+		})
+	}
+
+	schema := t.schema
+	added := schemaFromDescription(schema.Name, nil, params.AttributeDefinitions, creates).Indexes
+	for _, index := range added {
+		if _, ok := schema.index(index.Name); ok {
+			return nil, validationError("Index already exists: %s", index.Name)
+		}
+	}
+	schema.Indexes = append(append([]IndexSchema(nil), schema.Indexes...), added...)
+	if err := validateSchema(schema); err != nil {
+		return nil, err
+	}
+
+	t.schema = schema
+	return &dynamodb.UpdateTableOutput{TableDescription: t.describe()}, nil
+}
+
+// DescribeTimeToLive reports time to live as enabled for the TTL attribute of
+// the table's schema. The emulator never expires items.
+func (e *Emulator) DescribeTimeToLive(ctx context.Context, params *dynamodb.DescribeTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	t, err := e.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+	description := &types.TimeToLiveDescription{TimeToLiveStatus: types.TimeToLiveStatusDisabled}
+	if t.schema.TTLAttribute != "" {
+		description = &types.TimeToLiveDescription{
+			AttributeName:    aws.String(t.schema.TTLAttribute),
+			TimeToLiveStatus: types.TimeToLiveStatusEnabled,
+		}
+	}
+	return &dynamodb.DescribeTimeToLiveOutput{TimeToLiveDescription: description}, nil
+}
+
+// UpdateTimeToLive records the TTL attribute on the table's schema. Like
+// DynamoDB it rejects enabling time to live on a table that has it enabled.
+func (e *Emulator) UpdateTimeToLive(ctx context.Context, params *dynamodb.UpdateTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateTimeToLiveOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	t, err := e.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+	spec := params.TimeToLiveSpecification
+	if spec == nil || aws.ToString(spec.AttributeName) == "" {
+		return nil, validationError("TimeToLiveSpecification is required")
+	}
+	enabled := aws.ToBool(spec.Enabled)
+	switch {
+	case enabled && t.schema.TTLAttribute != "":
+		return nil, validationError("TimeToLive is already enabled")
+	case !enabled && t.schema.TTLAttribute == "":
+		return nil, validationError("TimeToLive is already disabled")
+	}
+
+	t.schema.TTLAttribute = ""
+	if enabled {
+		t.schema.TTLAttribute = aws.ToString(spec.AttributeName)
+	}
+	return &dynamodb.UpdateTimeToLiveOutput{TimeToLiveSpecification: spec}, nil
+}
+
+func (e *Emulator) table(name *string) (*emulatorTable, error) {
+	t, ok := e.tables[aws.ToString(name)]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("Requested resource not found")}
+	}
+	return t, nil
+}
+
+func (t *emulatorTable) describe() *types.TableDescription {
+	input := t.schema.CreateTableInput()
+	description := &types.TableDescription{
+		TableName:            input.TableName,
+		TableStatus:          types.TableStatusActive,
+		KeySchema:            input.KeySchema,
+		AttributeDefinitions: input.AttributeDefinitions,
+		ItemCount:            aws.Int64(int64(len(t.items))),
+	}
+	for _, gsi := range input.GlobalSecondaryIndexes {
+		description.GlobalSecondaryIndexes = append(description.GlobalSecondaryIndexes, types.GlobalSecondaryIndexDescription{
+			IndexName:   gsi.IndexName,
+			IndexStatus: types.IndexStatusActive,
+			KeySchema:   gsi.KeySchema,
+			Projection:  gsi.Projection,
+		})
+	}
+	return description
+}
+
+func (t *emulatorTable) put(item map[string]types.AttributeValue, cond condition) (map[string]types.AttributeValue, error) {
+	if err := t.validateItem(item); err != nil {
+		return nil, err
+	}
+	key, _ := t.keyOf(item, false)
+
+	existing := t.items[key]
+	if err := checkCondition(cond, existing); err != nil {
+		return nil, err
+	}
+
+	t.items[key] = copyItem(item)
+	return copyItem(existing), nil
+}
+
+func (t *emulatorTable) update(key map[string]types.AttributeValue, update *compiledUpdate) (map[string]types.AttributeValue, map[string]types.AttributeValue, error) {
+	k, err := t.keyOf(key, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	existing := t.items[k]
+	if err := checkCondition(update.cond, existing); err != nil {
+		return nil, nil, err
+	}
+
+	updated := copyItem(existing)
+	if updated == nil {
+		updated = copyItem(key)
+	}
+	if err := applyUpdate(updated, update.actions); err != nil {
+		return nil, nil, validationError("%s", err)
+	}
+
+	for _, attr := range []KeyAttribute{t.schema.PartitionKey, t.schema.SortKey} {
+		if attr.Name != "" && !attributeValuesEqual(updated[attr.Name], key[attr.Name]) {
+			return nil, nil, validationError("Cannot update attribute %s. This attribute is part of the key", attr.Name)
+		}
+	}
+	if err := t.validateItem(updated); err != nil {
+		return nil, nil, err
+	}
+
+	t.items[k] = updated
+	return copyItem(existing), updated, nil
+}
+
+func (t *emulatorTable) delete(key map[string]types.AttributeValue, cond condition) (map[string]types.AttributeValue, error) {
+	k, err := t.keyOf(key, true)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, ok := t.items[k]
+	if err := checkCondition(cond, existing); err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	delete(t.items, k)
+	return existing, nil
+}
+
+// keyOf encodes the primary key of item. When exact is set the map must hold
+// the key attributes and nothing else, as required for Key parameters.
+func (t *emulatorTable) keyOf(item map[string]types.AttributeValue, exact bool) (string, error) {
+	attrs := []KeyAttribute{t.schema.PartitionKey}
+	if t.schema.SortKey.Name != "" {
+		attrs = append(attrs, t.schema.SortKey)
+	}
+
+	if exact && len(item) != len(attrs) {
+		return "", validationError("The provided key element does not match the schema")
+	}
+
+	parts := make([]string, 0, len(attrs))
+	for _, attr := range attrs {
+		v, ok := item[attr.Name]
+		if !ok || !matchesKeyType(v, attr.Type) {
+			return "", validationError("The provided key element does not match the schema")
+		}
+		parts = append(parts, encodeKeyValue(v))
+	}
+	return strings.Join(parts, "|"), nil
+}
+
+func (t *emulatorTable) validateItem(item map[string]types.AttributeValue) error {
+	if _, err := t.keyOf(item, false); err != nil {
+		return validationError("One or more parameter values were invalid: Missing the key %s in the item", t.schema.PartitionKey.Name)
+	}
+
+	for _, index := range t.schema.Indexes {
+		for _, attr := range []KeyAttribute{index.PartitionKey, index.SortKey} {
+			if attr.Name == "" {
+				continue
+			}
+			if v, ok := item[attr.Name]; ok && !matchesKeyType(v, attr.Type) {
+				return validationError("One or more parameter values were invalid: Type mismatch for Index Key %s Expected: %s Actual: %s IndexName: %s", attr.Name, attr.Type, attributeType(v), index.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func (t *emulatorTable) sortedKeys() []string {
+	keys := make([]string, 0, len(t.items))
+	for k := range t.items {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type compiledUpdate struct {
+	actions []updateAction
+	cond    condition
+}
+
+func (u *compiledUpdate) touched() []string {
+	names := make([]string, 0, len(u.actions))
+	for _, a := range u.actions {
+		names = append(names, a.path[0].name)
+	}
+	return names
+}
+
+func compileUpdate(expr *string, condExpr *string, names map[string]string, values map[string]types.AttributeValue) (*compiledUpdate, error) {
+	if expr == nil {
+		return nil, validationError("UpdateExpression must be specified")
+	}
+
+	actions, updateParser, err := parseUpdate(*expr, names, values)
+	if err != nil {
+		return nil, validationError("Invalid UpdateExpression: %s", err)
+	}
+
+	parsers := []*parser{updateParser}
+	update := &compiledUpdate{actions: actions}
+	if condExpr != nil {
+		condParser, err := newParser(*condExpr, names, values)
+		if err != nil {
+			return nil, validationError("Invalid ConditionExpression: %s", err)
+		}
+		update.cond, err = condParser.parseOr()
+		if err == nil {
+			err = condParser.expectEOF()
+		}
+		if err != nil {
+			return nil, validationError("Invalid ConditionExpression: %s", err)
+		}
+		parsers = append(parsers, condParser)
+	}
+
+	return update, checkUnused(names, values, parsers...)
+}
+
+func compileCondition(expr *string, names map[string]string, values map[string]types.AttributeValue) (condition, error) {
+	if expr == nil {
+		return nil, checkUnused(names, values)
+	}
+
+	p, err := newParser(*expr, names, values)
+	if err != nil {
+		return nil, validationError("Invalid ConditionExpression: %s", err)
+	}
+	cond, err := p.parseOr()
+	if err == nil {
+		err = p.expectEOF()
+	}
+	if err != nil {
+		return nil, validationError("Invalid ConditionExpression: %s", err)
+	}
+
+	return cond, checkUnused(names, values, p)
+}
+
+type projection struct {
+	parser     *parser
+	attributes []string
+}
+
+func compileProjection(expr *string, names map[string]string) (*projection, error) {
+	if expr == nil {
+		return nil, nil
+	}
+
+	p, err := newParser(*expr, names, nil)
+	if err != nil {
+		return nil, validationError("Invalid ProjectionExpression: %s", err)
+	}
+
+	proj := &projection{parser: p}
+	for {
+		path, err := p.parsePath()
+		if err != nil {
+			return nil, validationError("Invalid ProjectionExpression: %s", err)
+		}
+		proj.attributes = append(proj.attributes, path[0].name)
+		if !p.isSymbol(",") {
+			break
+		}
+		p.next()
+	}
+	if err := p.expectEOF(); err != nil {
+		return nil, validationError("Invalid ProjectionExpression: %s", err)
+	}
+	return proj, nil
+}
+
+func project(item map[string]types.AttributeValue, proj *projection) map[string]types.AttributeValue {
+	if proj == nil {
+		return copyItem(item)
+	}
+	return pick(item, proj.attributes)
+}
+
+func pick(item map[string]types.AttributeValue, names []string) map[string]types.AttributeValue {
+	if item == nil {
+		return nil
+	}
+	picked := map[string]types.AttributeValue{}
+	for _, name := range names {
+		if v, ok := item[name]; ok && name != "" {
+			picked[name] = copyAttributeValue(v)
+		}
+	}
+	return picked
+}
+
+func checkCondition(cond condition, existing map[string]types.AttributeValue) error {
+	if cond == nil {
+		return nil
+	}
+	matched, err := cond.matches(itemOrEmpty(existing))
+	if err != nil {
+		return validationError("%s", err)
+	}
+	if !matched {
+		return &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+	}
+	return nil
+}
+
+func checkUnused(names map[string]string, values map[string]types.AttributeValue, parsers ...*parser) error {
+	used := map[string]bool{}
+	for _, p := range parsers {
+		for k := range p.used {
+			used[k] = true
+		}
+	}
+
+	var unusedNames, unusedValues []string
+	for k := range names {
+		if !used[k] {
+			unusedNames = append(unusedNames, k)
+		}
+	}
+	for k := range values {
+		if !used[k] {
+			unusedValues = append(unusedValues, k)
+		}
+	}
+	sort.Strings(unusedNames)
+	sort.Strings(unusedValues)
+
+	if len(unusedNames) > 0 {
+		return validationError("Value provided in ExpressionAttributeNames unused in expressions: keys: {%s}", strings.Join(unusedNames, ", "))
+	}
+	if len(unusedValues) > 0 {
+		return validationError("Value provided in ExpressionAttributeValues unused in expressions: keys: {%s}", strings.Join(unusedValues, ", "))
+	}
+	return nil
+}
+
+// validateKeyCondition enforces DynamoDB's key condition rules: an equality
+// on the partition key, optionally ANDed with one condition on the sort key.
+func validateKeyCondition(c condition, pk, sk string) error {
+	var parts []condition
+	var flatten func(condition) error
+	flatten = func(c condition) error {
+		if l, ok := c.(logicalCondition); ok {
+			if l.op != "AND" {
+				return validationError("Invalid operator used in KeyConditionExpression: %s", l.op)
+			}
+			if err := flatten(l.left); err != nil {
+				return err
+			}
+			return flatten(l.right)
+		}
+		parts = append(parts, c)
+		return nil
+	}
+	if err := flatten(c); err != nil {
+		return err
+	}
+
+	hasPK := false
+	for _, part := range parts {
+		var name string
+		switch p := part.(type) {
+		case compareCondition:
+			path, ok := p.left.(pathOperand)
+			if !ok || len(path.path) != 1 {
+				return validationError("Invalid KeyConditionExpression")
+			}
+			name = path.path[0].name
+			if name == pk && p.op == "=" {
+				hasPK = true
+				continue
+			}
+			if p.op == "<>" {
+				return validationError("Unsupported operator in KeyConditionExpression: <>")
+			}
+		case betweenCondition:
+			path, ok := p.value.(pathOperand)
+			if !ok || len(path.path) != 1 {
+				return validationError("Invalid KeyConditionExpression")
+			}
+			name = path.path[0].name
+		case functionCondition:
+			if p.name != "begins_with" || len(p.path) != 1 {
+				return validationError("Invalid KeyConditionExpression: %s is not supported", p.name)
+			}
+			name = p.path[0].name
+		default:
+			return validationError("Invalid KeyConditionExpression")
+		}
+
+		if sk == "" || name != sk {
+			return validationError("Query key condition not supported")
+		}
+	}
+
+	if !hasPK || len(parts) > 2 {
+		return validationError("Query condition missed key schema element: %s", pk)
+	}
+	return nil
+}
+
+func hasKey(item map[string]types.AttributeValue, pk, sk KeyAttribute) bool {
+	if _, ok := item[pk.Name]; !ok {
+		return false
+	}
+	if sk.Name == "" {
+		return true
+	}
+	_, ok := item[sk.Name]
+	return ok
+}
+
+func compareTuples(a, b []types.AttributeValue) int {
+	for i := range a {
+		if a[i] == nil || b[i] == nil {
+			continue
+		}
+		if cmp, _ := compareAttributeValues(a[i], b[i]); cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+func matchesKeyType(v types.AttributeValue, t types.ScalarAttributeType) bool {
+	switch t {
+	case types.ScalarAttributeTypeN:
+		_, ok := v.(*types.AttributeValueMemberN)
+		return ok
+	case types.ScalarAttributeTypeB:
+		_, ok := v.(*types.AttributeValueMemberB)
+		return ok
+	default:
+		s, ok := v.(*types.AttributeValueMemberS)
+		return ok && s.Value != ""
+	}
+}
+
+func encodeKeyValue(v types.AttributeValue) string {
+	switch v := v.(type) {
+	case *types.AttributeValueMemberS:
+		return "S:" + base64.StdEncoding.EncodeToString([]byte(v.Value))
+	case *types.AttributeValueMemberN:
+		n, _ := addNumbers(v.Value, "0", false)
+		return "N:" + n
+	case *types.AttributeValueMemberB:
+		return "B:" + base64.StdEncoding.EncodeToString(v.Value)
+	}
+	return ""
+}
+
+func itemOrEmpty(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	if item == nil {
+		return map[string]types.AttributeValue{}
+	}
+	return item
+}
+
+func copyItems(items map[string]map[string]types.AttributeValue) map[string]map[string]types.AttributeValue {
+	copied := make(map[string]map[string]types.AttributeValue, len(items))
+	for k, v := range items {
+		copied[k] = v
+	}
+	return copied
+}
+
+func validateSchema(schema TableSchema) error {
+	if schema.PartitionKey.Name == "" {
+		return validationError("No hash key specified for table: %s", schema.Name)
+	}
+	keys := []KeyAttribute{schema.PartitionKey, schema.SortKey}
+	for _, index := range schema.Indexes {
+		if index.PartitionKey.Name == "" {
+			return validationError("No hash key specified for index: %s", index.Name)
+		}
+		keys = append(keys, index.PartitionKey, index.SortKey)
+	}
+	for _, k := range keys {
+		if k.Name != "" && k.Type == "" {
+			return validationError("No attribute definition for key attribute: %s", k.Name)
+		}
+	}
+	return nil
+}
+
+func validationError(format string, args ...any) error {
+	return &smithy.GenericAPIError{
+		Code:    "ValidationException",
+		Message: fmt.Sprintf(format, args...),
+		Fault:   smithy.FaultClient,
+	}
+}
@@ -0,0 +1,540 @@
+package dynamo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+)
+
+var testEventSchema = TableSchema{
+	Name:         "event-table",
+	PartitionKey: StringKey("receiver_id"),
+	SortKey:      StringKey("event_id"),
+	Indexes: []IndexSchema{
+		{Name: "receiver-start-time", PartitionKey: StringKey("receiver_id"), SortKey: StringKey("start_time")},
+		{Name: "flagged", PartitionKey: NumberKey("flagged_gsi_pk")},
+	},
+}
+
+func testEvent(rid, eid, startTime string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"receiver_id": &types.AttributeValueMemberS{Value: rid},
+		"event_id":    &types.AttributeValueMemberS{Value: eid},
+		"start_time":  &types.AttributeValueMemberS{Value: startTime},
+	}
+}
+
+func seededEmulator(t *testing.T) *Emulator {
+	e := NewEmulator(testEventSchema)
+	for i := 0; i < 5; i++ {
+		item := testEvent("Receiver#1", fmt.Sprintf("Event#%d", i), fmt.Sprintf("2025-01-0%dT00:00:00Z", 5-i))
+		if i%2 == 0 {
+			item["flagged_gsi_pk"] = &types.AttributeValueMemberN{Value: "1"}
+		}
+		_, err := e.PutItem(context.Background(), &dynamodb.PutItemInput{TableName: aws.String("event-table"), Item: item})
+		assert.NoError(t, err)
+	}
+	_, err := e.PutItem(context.Background(), &dynamodb.PutItemInput{TableName: aws.String("event-table"), Item: testEvent("Receiver#2", "Event#9", "2025-01-01T00:00:00Z")})
+	assert.NoError(t, err)
+	return e
+}
+
+func errorCode(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+	return ""
+}
+
+func eventIDs(items []map[string]types.AttributeValue) []string {
+	ids := []string{}
+	for _, item := range items {
+		ids = append(ids, item["event_id"].(*types.AttributeValueMemberS).Value)
+	}
+	return ids
+}
+
+func TestEmulator_PutItem(t *testing.T) {
+	tests := map[string]struct {
+		input        *dynamodb.PutItemInput
+		expectedCode string
+	}{
+		"Happy Path - New Item With Condition": {
+			input: &dynamodb.PutItemInput{
+				TableName:                aws.String("event-table"),
+				Item:                     testEvent("Receiver#1", "Event#new", "2025-01-01T00:00:00Z"),
+				ConditionExpression:      aws.String("attribute_not_exists(#eid)"),
+				ExpressionAttributeNames: map[string]string{"#eid": "event_id"},
+			},
+		},
+		"Sad Path - Condition Failed": {
+			input: &dynamodb.PutItemInput{
+				TableName:                aws.String("event-table"),
+				Item:                     testEvent("Receiver#1", "Event#0", "2025-01-01T00:00:00Z"),
+				ConditionExpression:      aws.String("attribute_not_exists(#eid)"),
+				ExpressionAttributeNames: map[string]string{"#eid": "event_id"},
+			},
+			expectedCode: "ConditionalCheckFailedException",
+		},
+		"Sad Path - Missing Sort Key": {
+			input: &dynamodb.PutItemInput{
+				TableName: aws.String("event-table"),
+				Item:      map[string]types.AttributeValue{"receiver_id": &types.AttributeValueMemberS{Value: "Receiver#1"}},
+			},
+			expectedCode: "ValidationException",
+		},
+		"Sad Path - Index Key Type Mismatch": {
+			input: &dynamodb.PutItemInput{
+				TableName: aws.String("event-table"),
+				Item: map[string]types.AttributeValue{
+					"receiver_id":    &types.AttributeValueMemberS{Value: "Receiver#1"},
+					"event_id":       &types.AttributeValueMemberS{Value: "Event#new"},
+					"flagged_gsi_pk": &types.AttributeValueMemberS{Value: "1"},
+				},
+			},
+			expectedCode: "ValidationException",
+		},
+		"Sad Path - Unused Expression Value": {
+			input: &dynamodb.PutItemInput{
+				TableName:                 aws.String("event-table"),
+				Item:                      testEvent("Receiver#1", "Event#new", "2025-01-01T00:00:00Z"),
+				ConditionExpression:       aws.String("attribute_not_exists(event_id)"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{":unused": &types.AttributeValueMemberS{Value: "x"}},
+			},
+			expectedCode: "ValidationException",
+		},
+		"Sad Path - Unknown Table": {
+			input: &dynamodb.PutItemInput{
+				TableName: aws.String("missing-table"),
+				Item:      testEvent("Receiver#1", "Event#new", "2025-01-01T00:00:00Z"),
+			},
+			expectedCode: "ResourceNotFoundException",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := seededEmulator(t)
+
+			_, err := e.PutItem(context.Background(), tc.input)
+			if tc.expectedCode != "" {
+				assert.Equal(t, tc.expectedCode, errorCode(err))
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEmulator_GetItem(t *testing.T) {
+	e := seededEmulator(t)
+
+	out, err := e.GetItem(context.Background(), &dynamodb.GetItemInput{
+		TableName: aws.String("event-table"),
+		Key: map[string]types.AttributeValue{
+			"receiver_id": &types.AttributeValueMemberS{Value: "Receiver#1"},
+			"event_id":    &types.AttributeValueMemberS{Value: "Event#1"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, testEvent("Receiver#1", "Event#1", "2025-01-04T00:00:00Z"), out.Item)
+
+	// Mutating the returned item must not change what is stored.
+	out.Item["start_time"] = &types.AttributeValueMemberS{Value: "changed"}
+	again, err := e.GetItem(context.Background(), &dynamodb.GetItemInput{
+		TableName: aws.String("event-table"),
+		Key: map[string]types.AttributeValue{
+			"receiver_id": &types.AttributeValueMemberS{Value: "Receiver#1"},
+			"event_id":    &types.AttributeValueMemberS{Value: "Event#1"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "2025-01-04T00:00:00Z"}, again.Item["start_time"])
+
+	missing, err := e.GetItem(context.Background(), &dynamodb.GetItemInput{
+		TableName: aws.String("event-table"),
+		Key: map[string]types.AttributeValue{
+			"receiver_id": &types.AttributeValueMemberS{Value: "Receiver#1"},
+			"event_id":    &types.AttributeValueMemberS{Value: "Event#missing"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, missing.Item)
+
+	_, err = e.GetItem(context.Background(), &dynamodb.GetItemInput{
+		TableName: aws.String("event-table"),
+		Key:       map[string]types.AttributeValue{"receiver_id": &types.AttributeValueMemberS{Value: "Receiver#1"}},
+	})
+	assert.Equal(t, "ValidationException", errorCode(err))
+}
+
+func TestEmulator_Query(t *testing.T) {
+	tests := map[string]struct {
+		input       *dynamodb.QueryInput
+		pageSize    int
+		expectedIDs []string
+		expectedErr string
+	}{
+		"Happy Path - Base Table Sorted By Sort Key": {
+			input: &dynamodb.QueryInput{
+				KeyConditionExpression:    aws.String("receiver_id = :rid"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{":rid": &types.AttributeValueMemberS{Value: "Receiver#1"}},
+			},
+			expectedIDs: []string{"Event#0", "Event#1", "Event#2", "Event#3", "Event#4"},
+		},
+		"Happy Path - Index Sorted By Index Sort Key Descending": {
+			input: &dynamodb.QueryInput{
+				IndexName:              aws.String("receiver-start-time"),
+				KeyConditionExpression: aws.String("receiver_id = :rid AND start_time BETWEEN :lower AND :upper"),
+				ScanIndexForward:       aws.Bool(false),
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":rid":   &types.AttributeValueMemberS{Value: "Receiver#1"},
+					":lower": &types.AttributeValueMemberS{Value: "2025-01-02T00:00:00Z"},
+					":upper": &types.AttributeValueMemberS{Value: "2025-01-04T00:00:00Z"},
+				},
+			},
+			expectedIDs: []string{"Event#1", "Event#2", "Event#3"},
+		},
+		"Happy Path - Sparse Index Only Holds Items With Key": {
+			input: &dynamodb.QueryInput{
+				IndexName:                 aws.String("flagged"),
+				KeyConditionExpression:    aws.String("flagged_gsi_pk = :one"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{":one": &types.AttributeValueMemberN{Value: "1"}},
+			},
+			expectedIDs: []string{"Event#0", "Event#2", "Event#4"},
+		},
+		"Happy Path - Paginated With Filter": {
+			input: &dynamodb.QueryInput{
+				KeyConditionExpression:    aws.String("receiver_id = :rid"),
+				FilterExpression:          aws.String("attribute_exists(flagged_gsi_pk)"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{":rid": &types.AttributeValueMemberS{Value: "Receiver#1"}},
+			},
+			pageSize:    2,
+			expectedIDs: []string{"Event#0", "Event#2", "Event#4"},
+		},
+		"Happy Path - Paginated Index": {
+			input: &dynamodb.QueryInput{
+				IndexName:                 aws.String("flagged"),
+				KeyConditionExpression:    aws.String("flagged_gsi_pk = :one"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{":one": &types.AttributeValueMemberN{Value: "1"}},
+			},
+			pageSize:    1,
+			expectedIDs: []string{"Event#0", "Event#2", "Event#4"},
+		},
+		"Sad Path - Missing Partition Key Condition": {
+			input: &dynamodb.QueryInput{
+				KeyConditionExpression:    aws.String("event_id = :eid"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{":eid": &types.AttributeValueMemberS{Value: "Event#1"}},
+			},
+			expectedErr: "ValidationException",
+		},
+		"Sad Path - Non Key Attribute In Key Condition": {
+			input: &dynamodb.QueryInput{
+				KeyConditionExpression: aws.String("receiver_id = :rid AND start_time = :ts"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":rid": &types.AttributeValueMemberS{Value: "Receiver#1"},
+					":ts":  &types.AttributeValueMemberS{Value: "2025-01-01T00:00:00Z"},
+				},
+			},
+			expectedErr: "ValidationException",
+		},
+		"Sad Path - Unknown Index": {
+			input: &dynamodb.QueryInput{
+				IndexName:                 aws.String("missing"),
+				KeyConditionExpression:    aws.String("receiver_id = :rid"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{":rid": &types.AttributeValueMemberS{Value: "Receiver#1"}},
+			},
+			expectedErr: "ValidationException",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := seededEmulator(t)
+			e.PageSize = tc.pageSize
+			tc.input.TableName = aws.String("event-table")
+
+			var items []map[string]types.AttributeValue
+			pages := 0
+			paginator := dynamodb.NewQueryPaginator(e, tc.input)
+			for paginator.HasMorePages() {
+				page, err := paginator.NextPage(context.Background())
+				if tc.expectedErr != "" {
+					assert.Equal(t, tc.expectedErr, errorCode(err))
+					return
+				}
+				assert.NoError(t, err)
+				items = append(items, page.Items...)
+				pages++
+			}
+
+			assert.Equal(t, tc.expectedIDs, eventIDs(items))
+			if tc.pageSize > 0 {
+				assert.Greater(t, pages, 1)
+			}
+		})
+	}
+}
+
+func TestEmulator_Scan(t *testing.T) {
+	tests := map[string]struct {
+		input       *dynamodb.ScanInput
+		pageSize    int
+		expectedIDs []string
+		expectedErr string
+	}{
+		"Happy Path - Whole Table": {
+			input:       &dynamodb.ScanInput{},
+			expectedIDs: []string{"Event#0", "Event#1", "Event#2", "Event#3", "Event#4", "Event#9"},
+		},
+		"Happy Path - Paginated With Filter": {
+			input: &dynamodb.ScanInput{
+				FilterExpression:          aws.String("receiver_id = :rid"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{":rid": &types.AttributeValueMemberS{Value: "Receiver#2"}},
+			},
+			pageSize:    2,
+			expectedIDs: []string{"Event#9"},
+		},
+		"Happy Path - Paginated Sparse Index": {
+			input:       &dynamodb.ScanInput{IndexName: aws.String("flagged")},
+			pageSize:    1,
+			expectedIDs: []string{"Event#0", "Event#2", "Event#4"},
+		},
+		"Sad Path - Unknown Index": {
+			input:       &dynamodb.ScanInput{IndexName: aws.String("missing")},
+			expectedErr: "ValidationException",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := seededEmulator(t)
+			e.PageSize = tc.pageSize
+			tc.input.TableName = aws.String("event-table")
+
+			var items []map[string]types.AttributeValue
+			pages := 0
+			paginator := dynamodb.NewScanPaginator(e, tc.input)
+			for paginator.HasMorePages() {
+				page, err := paginator.NextPage(context.Background())
+				if tc.expectedErr != "" {
+					assert.Equal(t, tc.expectedErr, errorCode(err))
+					return
+				}
+				assert.NoError(t, err)
+				items = append(items, page.Items...)
+				pages++
+			}
+
+			assert.Equal(t, tc.expectedIDs, eventIDs(items))
+			if tc.pageSize > 0 {
+				assert.Greater(t, pages, 1)
+			}
+		})
+	}
+}
+
+func TestEmulator_UpdateItem(t *testing.T) {
+	key := map[string]types.AttributeValue{
+		"receiver_id": &types.AttributeValueMemberS{Value: "Receiver#1"},
+		"event_id":    &types.AttributeValueMemberS{Value: "Event#1"},
+	}
+
+	tests := map[string]struct {
+		input         *dynamodb.UpdateItemInput
+		expectedAttrs map[string]types.AttributeValue
+		expectedCode  string
+	}{
+		"Happy Path - Set With Condition Returns New": {
+			input: &dynamodb.UpdateItemInput{
+				UpdateExpression:          aws.String("SET note = :note"),
+				ConditionExpression:       aws.String("attribute_exists(receiver_id)"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{":note": &types.AttributeValueMemberS{Value: "hello"}},
+				ReturnValues:              types.ReturnValueUpdatedNew,
+			},
+			expectedAttrs: map[string]types.AttributeValue{"note": &types.AttributeValueMemberS{Value: "hello"}},
+		},
+		"Happy Path - Remove Returns Old": {
+			input: &dynamodb.UpdateItemInput{
+				UpdateExpression: aws.String("REMOVE start_time"),
+				ReturnValues:     types.ReturnValueUpdatedOld,
+			},
+			expectedAttrs: map[string]types.AttributeValue{"start_time": &types.AttributeValueMemberS{Value: "2025-01-04T00:00:00Z"}},
+		},
+		"Sad Path - Condition Failed": {
+			input: &dynamodb.UpdateItemInput{
+				UpdateExpression:          aws.String("SET note = :note"),
+				ConditionExpression:       aws.String("start_time = :other"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{":note": &types.AttributeValueMemberS{Value: "hello"}, ":other": &types.AttributeValueMemberS{Value: "x"}},
+			},
+			expectedCode: "ConditionalCheckFailedException",
+		},
+		"Sad Path - Updating Key Attribute": {
+			input: &dynamodb.UpdateItemInput{
+				UpdateExpression:          aws.String("SET event_id = :eid"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{":eid": &types.AttributeValueMemberS{Value: "Event#2"}},
+			},
+			expectedCode: "ValidationException",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := seededEmulator(t)
+			tc.input.TableName = aws.String("event-table")
+			tc.input.Key = key
+
+			out, err := e.UpdateItem(context.Background(), tc.input)
+			if tc.expectedCode != "" {
+				assert.Equal(t, tc.expectedCode, errorCode(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedAttrs, out.Attributes)
+		})
+	}
+}
+
+func TestEmulator_DeleteItem(t *testing.T) {
+	e := seededEmulator(t)
+	key := map[string]types.AttributeValue{
+		"receiver_id": &types.AttributeValueMemberS{Value: "Receiver#1"},
+		"event_id":    &types.AttributeValueMemberS{Value: "Event#1"},
+	}
+
+	_, err := e.DeleteItem(context.Background(), &dynamodb.DeleteItemInput{
+		TableName:                 aws.String("event-table"),
+		Key:                       key,
+		ConditionExpression:       aws.String("start_time = :ts"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":ts": &types.AttributeValueMemberS{Value: "other"}},
+	})
+	assert.Equal(t, "ConditionalCheckFailedException", errorCode(err))
+
+	out, err := e.DeleteItem(context.Background(), &dynamodb.DeleteItemInput{
+		TableName:    aws.String("event-table"),
+		Key:          key,
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, testEvent("Receiver#1", "Event#1", "2025-01-04T00:00:00Z"), out.Attributes)
+	assert.Len(t, e.Items("event-table"), 5)
+}
+
+func TestEmulator_BatchWriteAndGet(t *testing.T) {
+	e := NewEmulator(testEventSchema)
+
+	requests := []types.WriteRequest{}
+	for i := 0; i < 3; i++ {
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: testEvent("Receiver#1", fmt.Sprintf("Event#%d", i), "2025-01-01T00:00:00Z")}})
+	}
+	out, err := e.BatchWriteItem(context.Background(), &dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]types.WriteRequest{"event-table": requests},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, out.UnprocessedItems)
+
+	_, err = e.BatchWriteItem(context.Background(), &dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]types.WriteRequest{"event-table": {requests[0], requests[0]}},
+	})
+	assert.Equal(t, "ValidationException", errorCode(err))
+
+	tooMany := []types.WriteRequest{}
+	for i := 0; i < maxBatchWriteItems+1; i++ {
+		tooMany = append(tooMany, types.WriteRequest{PutRequest: &types.PutRequest{Item: testEvent("Receiver#1", fmt.Sprintf("Event#%d", i), "2025-01-01T00:00:00Z")}})
+	}
+	_, err = e.BatchWriteItem(context.Background(), &dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]types.WriteRequest{"event-table": tooMany},
+	})
+	assert.Equal(t, "ValidationException", errorCode(err))
+
+	got, err := e.BatchGetItem(context.Background(), &dynamodb.BatchGetItemInput{
+		RequestItems: map[string]types.KeysAndAttributes{
+			"event-table": {
+				Keys: []map[string]types.AttributeValue{
+					{"receiver_id": &types.AttributeValueMemberS{Value: "Receiver#1"}, "event_id": &types.AttributeValueMemberS{Value: "Event#0"}},
+					{"receiver_id": &types.AttributeValueMemberS{Value: "Receiver#1"}, "event_id": &types.AttributeValueMemberS{Value: "Event#missing"}},
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Event#0"}, eventIDs(got.Responses["event-table"]))
+}
+
+func TestEmulator_TransactWriteItems(t *testing.T) {
+	tests := map[string]struct {
+		items           []types.TransactWriteItem
+		expectedReasons []string
+		expectedCode    string
+		expectedCount   int
+	}{
+		"Happy Path - All Applied": {
+			items: []types.TransactWriteItem{
+				{Put: &types.Put{TableName: aws.String("event-table"), Item: testEvent("Receiver#3", "Event#1", "2025-01-01T00:00:00Z"), ConditionExpression: aws.String("attribute_not_exists(event_id)")}},
+				{Delete: &types.Delete{TableName: aws.String("event-table"), Key: map[string]types.AttributeValue{
+					"receiver_id": &types.AttributeValueMemberS{Value: "Receiver#2"},
+					"event_id":    &types.AttributeValueMemberS{Value: "Event#9"},
+				}}},
+			},
+			expectedCount: 6,
+		},
+		"Sad Path - Condition Failure Cancels Everything": {
+			items: []types.TransactWriteItem{
+				{Put: &types.Put{TableName: aws.String("event-table"), Item: testEvent("Receiver#3", "Event#1", "2025-01-01T00:00:00Z")}},
+				{ConditionCheck: &types.ConditionCheck{TableName: aws.String("event-table"), ConditionExpression: aws.String("attribute_not_exists(event_id)"), Key: map[string]types.AttributeValue{
+					"receiver_id": &types.AttributeValueMemberS{Value: "Receiver#2"},
+					"event_id":    &types.AttributeValueMemberS{Value: "Event#9"},
+				}}},
+			},
+			expectedReasons: []string{"None", "ConditionalCheckFailed"},
+			expectedCount:   6,
+		},
+		"Sad Path - Same Item Twice": {
+			items: []types.TransactWriteItem{
+				{Put: &types.Put{TableName: aws.String("event-table"), Item: testEvent("Receiver#3", "Event#1", "2025-01-01T00:00:00Z")}},
+				{Put: &types.Put{TableName: aws.String("event-table"), Item: testEvent("Receiver#3", "Event#1", "2025-01-02T00:00:00Z")}},
+			},
+			expectedCode:  "ValidationException",
+			expectedCount: 6,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := seededEmulator(t)
+
+			_, err := e.TransactWriteItems(context.Background(), &dynamodb.TransactWriteItemsInput{TransactItems: tc.items})
+			switch {
+			case tc.expectedReasons != nil:
+				var tce *types.TransactionCanceledException
+				assert.ErrorAs(t, err, &tce)
+				codes := []string{}
+				for _, r := range tce.CancellationReasons {
+					codes = append(codes, aws.ToString(r.Code))
+				}
+				assert.Equal(t, tc.expectedReasons, codes)
+			case tc.expectedCode != "":
+				assert.Equal(t, tc.expectedCode, errorCode(err))
+			default:
+				assert.NoError(t, err)
+			}
+			assert.Len(t, e.Items("event-table"), tc.expectedCount)
+		})
+	}
+}
+
+func TestEmulator_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewEmulator(testEventSchema).GetItem(ctx, &dynamodb.GetItemInput{TableName: aws.String("event-table")})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,1243 @@
+package dynamo
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type token struct {
+	kind  string
+	value string
+}
+
+const (
+	tokenIdent  = "ident"
+	tokenName   = "name"
+	tokenValue  = "value"
+	tokenNumber = "number"
+	tokenSymbol = "symbol"
+	tokenEOF    = "eof"
+)
+
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := rune(expr[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '#' || c == ':':
+			j := i + 1
+			for j < len(expr) && isIdentChar(rune(expr[j])) {
+				j++
+			}
+			if j == i+1 {
+				return nil, fmt.Errorf("invalid expression %q: empty placeholder at %d", expr, i)
+			}
+			kind := tokenName
+			if c == ':' {
+				kind = tokenValue
+			}
+			tokens = append(tokens, token{kind: kind, value: expr[i:j]})
+			i = j
+		case unicode.IsDigit(c):
+			j := i
+			for j < len(expr) && unicode.IsDigit(rune(expr[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: expr[i:j]})
+			i = j
+		case isIdentChar(c):
+			j := i
+			for j < len(expr) && isIdentChar(rune(expr[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, value: expr[i:j]})
+			i = j
+		case strings.HasPrefix(expr[i:], "<>") || strings.HasPrefix(expr[i:], "<=") || strings.HasPrefix(expr[i:], ">="):
+			tokens = append(tokens, token{kind: tokenSymbol, value: expr[i : i+2]})
+			i += 2
+		case strings.ContainsRune("()[],.=<>+-", c):
+			tokens = append(tokens, token{kind: tokenSymbol, value: string(c)})
+			i++
+		default:
+			return nil, fmt.Errorf("invalid expression %q: unexpected character %q", expr, c)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF}), nil
+}
+
+func isIdentChar(c rune) bool {
+	return c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+type pathElement struct {
+	name  string
+	index int
+}
+
+type attributePath []pathElement
+
+func (p attributePath) String() string {
+	var b strings.Builder
+	for i, e := range p {
+		if e.name == "" {
+			fmt.Fprintf(&b, "[%d]", e.index)
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(e.name)
+	}
+	return b.String()
+}
+
+type operand interface {
+	evaluate(item map[string]types.AttributeValue) (types.AttributeValue, bool, error)
+}
+
+type pathOperand struct {
+	path attributePath
+}
+
+type valueOperand struct {
+	value types.AttributeValue
+}
+
+type sizeOperand struct {
+	path attributePath
+}
+
+type arithmeticOperand struct {
+	op          string
+	left, right operand
+}
+
+type ifNotExistsOperand struct {
+	path     attributePath
+	fallback operand
+}
+
+type listAppendOperand struct {
+	left, right operand
+}
+
+type condition interface {
+	matches(item map[string]types.AttributeValue) (bool, error)
+}
+
+type logicalCondition struct {
+	op          string
+	left, right condition
+}
+
+type notCondition struct {
+	inner condition
+}
+
+type compareCondition struct {
+	op          string
+	left, right operand
+}
+
+type betweenCondition struct {
+	value, low, high operand
+}
+
+type inCondition struct {
+	value   operand
+	options []operand
+}
+
+type functionCondition struct {
+	name string
+	path attributePath
+	arg  operand
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	names  map[string]string
+	values map[string]types.AttributeValue
+	used   map[string]bool
+}
+
+func newParser(expr string, names map[string]string, values map[string]types.AttributeValue) (*parser, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &parser{tokens: tokens, names: names, values: values, used: map[string]bool{}}, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isKeyword(word string) bool {
+	t := p.peek()
+	return t.kind == tokenIdent && strings.EqualFold(t.value, word)
+}
+
+func (p *parser) isSymbol(symbol string) bool {
+	t := p.peek()
+	return t.kind == tokenSymbol && t.value == symbol
+}
+
+func (p *parser) expectSymbol(symbol string) error {
+	t := p.next()
+	if t.kind != tokenSymbol || t.value != symbol {
+		return fmt.Errorf("invalid expression: expected %q, found %q", symbol, t.value)
+	}
+	return nil
+}
+
+func (p *parser) expectEOF() error {
+	if t := p.peek(); t.kind != tokenEOF {
+		return fmt.Errorf("invalid expression: unexpected token %q", t.value)
+	}
+	return nil
+}
+
+func (p *parser) parsePath() (attributePath, error) {
+	var path attributePath
+
+	first := p.next()
+	name, err := p.resolveName(first)
+	if err != nil {
+		return nil, err
+	}
+	path = append(path, pathElement{name: name})
+
+	for {
+		switch {
+		case p.isSymbol("."):
+			p.next()
+			name, err := p.resolveName(p.next())
+			if err != nil {
+				return nil, err
+			}
+			path = append(path, pathElement{name: name})
+		case p.isSymbol("["):
+			p.next()
+			t := p.next()
+			if t.kind != tokenNumber {
+				return nil, fmt.Errorf("invalid expression: expected list index, found %q", t.value)
+			}
+			index, _ := strconv.Atoi(t.value)
+			if err := p.expectSymbol("]"); err != nil {
+				return nil, err
+			}
+			path = append(path, pathElement{index: index})
+		default:
+			return path, nil
+		}
+	}
+}
+
+func (p *parser) resolveName(t token) (string, error) {
+	switch t.kind {
+	case tokenIdent:
+		return t.value, nil
+	case tokenName:
+		name, ok := p.names[t.value]
+		if !ok {
+			return "", fmt.Errorf("invalid expression: attribute name %s is not defined", t.value)
+		}
+		p.used[t.value] = true
+		return name, nil
+	default:
+		return "", fmt.Errorf("invalid expression: expected attribute name, found %q", t.value)
+	}
+}
+
+func (p *parser) parseOperand() (operand, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokenValue:
+		p.next()
+		v, ok := p.values[t.value]
+		if !ok {
+			return nil, fmt.Errorf("invalid expression: attribute value %s is not defined", t.value)
+		}
+		p.used[t.value] = true
+		return valueOperand{value: v}, nil
+	case t.kind == tokenIdent && strings.EqualFold(t.value, "size") && p.tokens[p.pos+1].value == "(":
+		p.next()
+		p.next()
+		path, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+		return sizeOperand{path: path}, nil
+	default:
+		path, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		return pathOperand{path: path}, nil
+	}
+}
+
+func parseCondition(expr string, names map[string]string, values map[string]types.AttributeValue) (condition, error) {
+	p, err := newParser(expr, names, values)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectEOF(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (p *parser) parseOr() (condition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalCondition{op: "OR", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (condition, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("AND") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalCondition{op: "AND", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (condition, error) {
+	if p.isKeyword("NOT") {
+		p.next()
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notCondition{inner: inner}, nil
+	}
+	return p.parsePrimary()
+}
+
+var conditionFunctions = map[string]bool{
+	"attribute_exists":     true,
+	"attribute_not_exists": true,
+	"attribute_type":       true,
+	"begins_with":          true,
+	"contains":             true,
+}
+
+func (p *parser) parsePrimary() (condition, error) {
+	if p.isSymbol("(") {
+		p.next()
+		c, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+
+	t := p.peek()
+	if t.kind == tokenIdent && conditionFunctions[strings.ToLower(t.value)] && p.tokens[p.pos+1].value == "(" {
+		return p.parseFunction()
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.isKeyword("BETWEEN"):
+		p.next()
+		low, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if !p.isKeyword("AND") {
+			return nil, fmt.Errorf("invalid expression: expected AND in BETWEEN")
+		}
+		p.next()
+		high, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return betweenCondition{value: left, low: low, high: high}, nil
+	case p.isKeyword("IN"):
+		p.next()
+		if err := p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+		var options []operand
+		for {
+			o, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			options = append(options, o)
+			if !p.isSymbol(",") {
+				break
+			}
+			p.next()
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+		return inCondition{value: left, options: options}, nil
+	}
+
+	op := p.next()
+	switch op.value {
+	case "=", "<>", "<", "<=", ">", ">=":
+	default:
+		return nil, fmt.Errorf("invalid expression: expected comparator, found %q", op.value)
+	}
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return compareCondition{op: op.value, left: left, right: right}, nil
+}
+
+func (p *parser) parseFunction() (condition, error) {
+	name := strings.ToLower(p.next().value)
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+
+	path, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+
+	f := functionCondition{name: name, path: path}
+	if name != "attribute_exists" && name != "attribute_not_exists" {
+		if err := p.expectSymbol(","); err != nil {
+			return nil, err
+		}
+		f.arg, err = p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.expectSymbol(")"); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (c logicalCondition) matches(item map[string]types.AttributeValue) (bool, error) {
+	left, err := c.left.matches(item)
+	if err != nil {
+		return false, err
+	}
+	if c.op == "AND" && !left {
+		return false, nil
+	}
+	if c.op == "OR" && left {
+		return true, nil
+	}
+	return c.right.matches(item)
+}
+
+func (c notCondition) matches(item map[string]types.AttributeValue) (bool, error) {
+	matched, err := c.inner.matches(item)
+	return !matched, err
+}
+
+func (c compareCondition) matches(item map[string]types.AttributeValue) (bool, error) {
+	left, lok, err := c.left.evaluate(item)
+	if err != nil {
+		return false, err
+	}
+	right, rok, err := c.right.evaluate(item)
+	if err != nil {
+		return false, err
+	}
+	if !lok || !rok {
+		return c.op == "<>" && lok != rok, nil
+	}
+
+	switch c.op {
+	case "=":
+		return attributeValuesEqual(left, right), nil
+	case "<>":
+		return !attributeValuesEqual(left, right), nil
+	}
+
+	cmp, ok := compareAttributeValues(left, right)
+	if !ok {
+		return false, nil
+	}
+	switch c.op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+func (c betweenCondition) matches(item map[string]types.AttributeValue) (bool, error) {
+	value, ok, err := c.value.evaluate(item)
+	if err != nil || !ok {
+		return false, err
+	}
+	low, _, err := c.low.evaluate(item)
+	if err != nil {
+		return false, err
+	}
+	high, _, err := c.high.evaluate(item)
+	if err != nil {
+		return false, err
+	}
+
+	lowCmp, lok := compareAttributeValues(value, low)
+	highCmp, hok := compareAttributeValues(value, high)
+	return lok && hok && lowCmp >= 0 && highCmp <= 0, nil
+}
+
+func (c inCondition) matches(item map[string]types.AttributeValue) (bool, error) {
+	value, ok, err := c.value.evaluate(item)
+	if err != nil || !ok {
+		return false, err
+	}
+	for _, o := range c.options {
+		option, ok, err := o.evaluate(item)
+		if err != nil {
+			return false, err
+		}
+		if ok && attributeValuesEqual(value, option) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c functionCondition) matches(item map[string]types.AttributeValue) (bool, error) {
+	value, exists := resolvePath(item, c.path)
+
+	switch c.name {
+	case "attribute_exists":
+		return exists, nil
+	case "attribute_not_exists":
+		return !exists, nil
+	}
+
+	arg, ok, err := c.arg.evaluate(item)
+	if err != nil || !ok || !exists {
+		return false, err
+	}
+
+	switch c.name {
+	case "attribute_type":
+		s, ok := arg.(*types.AttributeValueMemberS)
+		return ok && attributeType(value) == s.Value, nil
+	case "begins_with":
+		switch v := value.(type) {
+		case *types.AttributeValueMemberS:
+			prefix, ok := arg.(*types.AttributeValueMemberS)
+			return ok && strings.HasPrefix(v.Value, prefix.Value), nil
+		case *types.AttributeValueMemberB:
+			prefix, ok := arg.(*types.AttributeValueMemberB)
+			return ok && bytes.HasPrefix(v.Value, prefix.Value), nil
+		}
+		return false, nil
+	default:
+		switch v := value.(type) {
+		case *types.AttributeValueMemberS:
+			sub, ok := arg.(*types.AttributeValueMemberS)
+			return ok && strings.Contains(v.Value, sub.Value), nil
+		case *types.AttributeValueMemberSS:
+			sub, ok := arg.(*types.AttributeValueMemberS)
+			return ok && containsString(v.Value, sub.Value), nil
+		case *types.AttributeValueMemberNS:
+			sub, ok := arg.(*types.AttributeValueMemberN)
+			return ok && containsNumber(v.Value, sub.Value), nil
+		case *types.AttributeValueMemberL:
+			for _, element := range v.Value {
+				if attributeValuesEqual(element, arg) {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+	}
+}
+
+func (o pathOperand) evaluate(item map[string]types.AttributeValue) (types.AttributeValue, bool, error) {
+	v, ok := resolvePath(item, o.path)
+	return v, ok, nil
+}
+
+func (o valueOperand) evaluate(map[string]types.AttributeValue) (types.AttributeValue, bool, error) {
+	return o.value, true, nil
+}
+
+func (o sizeOperand) evaluate(item map[string]types.AttributeValue) (types.AttributeValue, bool, error) {
+	v, ok := resolvePath(item, o.path)
+	if !ok {
+		return nil, false, nil
+	}
+
+	var size int
+	switch v := v.(type) {
+	case *types.AttributeValueMemberS:
+		size = len(v.Value)
+	case *types.AttributeValueMemberB:
+		size = len(v.Value)
+	case *types.AttributeValueMemberL:
+		size = len(v.Value)
+	case *types.AttributeValueMemberM:
+		size = len(v.Value)
+	case *types.AttributeValueMemberSS:
+		size = len(v.Value)
+	case *types.AttributeValueMemberNS:
+		size = len(v.Value)
+	case *types.AttributeValueMemberBS:
+		size = len(v.Value)
+	default:
+		return nil, false, nil
+	}
+	return &types.AttributeValueMemberN{Value: strconv.Itoa(size)}, true, nil
+}
+
+func (o arithmeticOperand) evaluate(item map[string]types.AttributeValue) (types.AttributeValue, bool, error) {
+	left, lok, err := o.left.evaluate(item)
+	if err != nil {
+		return nil, false, err
+	}
+	right, rok, err := o.right.evaluate(item)
+	if err != nil {
+		return nil, false, err
+	}
+	if !lok || !rok {
+		return nil, false, fmt.Errorf("the provided expression refers to an attribute that does not exist in the item")
+	}
+
+	l, lok := left.(*types.AttributeValueMemberN)
+	r, rok := right.(*types.AttributeValueMemberN)
+	if !lok || !rok {
+		return nil, false, fmt.Errorf("an operand in the update expression has an incorrect data type")
+	}
+
+	sum, err := addNumbers(l.Value, r.Value, o.op == "-")
+	if err != nil {
+		return nil, false, err
+	}
+	return &types.AttributeValueMemberN{Value: sum}, true, nil
+}
+
+func (o ifNotExistsOperand) evaluate(item map[string]types.AttributeValue) (types.AttributeValue, bool, error) {
+	if v, ok := resolvePath(item, o.path); ok {
+		return v, true, nil
+	}
+	return o.fallback.evaluate(item)
+}
+
+func (o listAppendOperand) evaluate(item map[string]types.AttributeValue) (types.AttributeValue, bool, error) {
+	left, lok, err := o.left.evaluate(item)
+	if err != nil {
+		return nil, false, err
+	}
+	right, rok, err := o.right.evaluate(item)
+	if err != nil {
+		return nil, false, err
+	}
+
+	l, lisList := left.(*types.AttributeValueMemberL)
+	r, risList := right.(*types.AttributeValueMemberL)
+	if !lok || !rok || !lisList || !risList {
+		return nil, false, fmt.Errorf("an operand in the update expression has an incorrect data type")
+	}
+
+	joined := make([]types.AttributeValue, 0, len(l.Value)+len(r.Value))
+	joined = append(joined, l.Value...)
+	joined = append(joined, r.Value...)
+	return &types.AttributeValueMemberL{Value: joined}, true, nil
+}
+
+type updateAction struct {
+	kind  string
+	path  attributePath
+	value operand
+}
+
+func parseUpdate(expr string, names map[string]string, values map[string]types.AttributeValue) ([]updateAction, *parser, error) {
+	p, err := newParser(expr, names, values)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var actions []updateAction
+	seen := map[string]bool{}
+	for p.peek().kind != tokenEOF {
+		clause := strings.ToUpper(p.next().value)
+		if seen[clause] {
+			return nil, nil, fmt.Errorf("invalid update expression: the %s section can only be used once", clause)
+		}
+		seen[clause] = true
+
+		for {
+			path, err := p.parsePath()
+			if err != nil {
+				return nil, nil, err
+			}
+
+			action := updateAction{kind: clause, path: path}
+			switch clause {
+			case "SET":
+				if err := p.expectSymbol("="); err != nil {
+					return nil, nil, err
+				}
+				action.value, err = p.parseSetValue()
+			case "ADD", "DELETE":
+				action.value, err = p.parseOperand()
+			case "REMOVE":
+			default:
+				return nil, nil, fmt.Errorf("invalid update expression: unknown clause %q", clause)
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			actions = append(actions, action)
+
+			if !p.isSymbol(",") {
+				break
+			}
+			p.next()
+		}
+	}
+
+	if len(actions) == 0 {
+		return nil, nil, fmt.Errorf("invalid update expression: no actions")
+	}
+	return actions, p, nil
+}
+
+func (p *parser) parseSetValue() (operand, error) {
+	left, err := p.parseSetTerm()
+	if err != nil {
+		return nil, err
+	}
+	if p.isSymbol("+") || p.isSymbol("-") {
+		op := p.next().value
+		right, err := p.parseSetTerm()
+		if err != nil {
+			return nil, err
+		}
+		return arithmeticOperand{op: op, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseSetTerm() (operand, error) {
+	t := p.peek()
+	if t.kind != tokenIdent || p.tokens[p.pos+1].value != "(" {
+		return p.parseOperand()
+	}
+
+	switch strings.ToLower(t.value) {
+	case "if_not_exists":
+		p.next()
+		p.next()
+		path, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectSymbol(","); err != nil {
+			return nil, err
+		}
+		fallback, err := p.parseSetValue()
+		if err != nil {
+			return nil, err
+		}
+		return ifNotExistsOperand{path: path, fallback: fallback}, p.expectSymbol(")")
+	case "list_append":
+		p.next()
+		p.next()
+		left, err := p.parseSetValue()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectSymbol(","); err != nil {
+			return nil, err
+		}
+		right, err := p.parseSetValue()
+		if err != nil {
+			return nil, err
+		}
+		return listAppendOperand{left: left, right: right}, p.expectSymbol(")")
+	}
+	return p.parseOperand()
+}
+
+func applyUpdate(item map[string]types.AttributeValue, actions []updateAction) error {
+	// Every operand is evaluated against the item as it was before the
+	// update, matching DynamoDB's semantics for a single UpdateItem call.
+	original := copyItem(item)
+
+	for _, a := range actions {
+		switch a.kind {
+		case "SET":
+			v, ok, err := a.value.evaluate(original)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("the provided expression refers to an attribute that does not exist in the item")
+			}
+			if err := setPath(item, a.path, copyAttributeValue(v)); err != nil {
+				return err
+			}
+		case "REMOVE":
+			removePath(item, a.path)
+		case "ADD":
+			v, _, err := a.value.evaluate(original)
+			if err != nil {
+				return err
+			}
+			current, exists := resolvePath(original, a.path)
+			updated, err := addValue(current, exists, v)
+			if err != nil {
+				return err
+			}
+			if err := setPath(item, a.path, updated); err != nil {
+				return err
+			}
+		case "DELETE":
+			v, _, err := a.value.evaluate(original)
+			if err != nil {
+				return err
+			}
+			current, exists := resolvePath(original, a.path)
+			if !exists {
+				continue
+			}
+			updated, empty, err := deleteFromSet(current, v)
+			if err != nil {
+				return err
+			}
+			if empty {
+				removePath(item, a.path)
+				continue
+			}
+			if err := setPath(item, a.path, updated); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func resolvePath(item map[string]types.AttributeValue, path attributePath) (types.AttributeValue, bool) {
+	var current types.AttributeValue = &types.AttributeValueMemberM{Value: item}
+	for _, e := range path {
+		if e.name != "" {
+			m, ok := current.(*types.AttributeValueMemberM)
+			if !ok {
+				return nil, false
+			}
+			current, ok = m.Value[e.name]
+			if !ok {
+				return nil, false
+			}
+			continue
+		}
+
+		l, ok := current.(*types.AttributeValueMemberL)
+		if !ok || e.index >= len(l.Value) {
+			return nil, false
+		}
+		current = l.Value[e.index]
+	}
+	return current, true
+}
+
+func setPath(item map[string]types.AttributeValue, path attributePath, value types.AttributeValue) error {
+	parent, ok := resolvePath(item, path[:len(path)-1])
+	if !ok {
+		return fmt.Errorf("the document path provided in the update expression is invalid for update: %s", path)
+	}
+
+	last := path[len(path)-1]
+	switch p := parent.(type) {
+	case *types.AttributeValueMemberM:
+		if last.name == "" {
+			break
+		}
+		p.Value[last.name] = value
+		return nil
+	case *types.AttributeValueMemberL:
+		if last.name != "" {
+			break
+		}
+		if last.index >= len(p.Value) {
+			p.Value = append(p.Value, value)
+		} else {
+			p.Value[last.index] = value
+		}
+		return nil
+	}
+	return fmt.Errorf("the document path provided in the update expression is invalid for update: %s", path)
+}
+
+func removePath(item map[string]types.AttributeValue, path attributePath) {
+	parent, ok := resolvePath(item, path[:len(path)-1])
+	if !ok {
+		return
+	}
+
+	last := path[len(path)-1]
+	switch p := parent.(type) {
+	case *types.AttributeValueMemberM:
+		delete(p.Value, last.name)
+	case *types.AttributeValueMemberL:
+		if last.name == "" && last.index < len(p.Value) {
+			p.Value = append(p.Value[:last.index], p.Value[last.index+1:]...)
+		}
+	}
+}
+
+func addValue(current types.AttributeValue, exists bool, v types.AttributeValue) (types.AttributeValue, error) {
+	switch v := v.(type) {
+	case *types.AttributeValueMemberN:
+		if !exists {
+			return v, nil
+		}
+		c, ok := current.(*types.AttributeValueMemberN)
+		if !ok {
+			break
+		}
+		sum, err := addNumbers(c.Value, v.Value, false)
+		return &types.AttributeValueMemberN{Value: sum}, err
+	case *types.AttributeValueMemberSS:
+		if !exists {
+			return v, nil
+		}
+		c, ok := current.(*types.AttributeValueMemberSS)
+		if !ok {
+			break
+		}
+		merged := append([]string{}, c.Value...)
+		for _, s := range v.Value {
+			if !containsString(merged, s) {
+				merged = append(merged, s)
+			}
+		}
+		return &types.AttributeValueMemberSS{Value: merged}, nil
+	case *types.AttributeValueMemberNS:
+		if !exists {
+			return v, nil
+		}
+		c, ok := current.(*types.AttributeValueMemberNS)
+		if !ok {
+			break
+		}
+		merged := append([]string{}, c.Value...)
+		for _, n := range v.Value {
+			if !containsNumber(merged, n) {
+				merged = append(merged, n)
+			}
+		}
+		return &types.AttributeValueMemberNS{Value: merged}, nil
+	}
+	return nil, fmt.Errorf("an operand in the update expression has an incorrect data type")
+}
+
+func deleteFromSet(current types.AttributeValue, v types.AttributeValue) (types.AttributeValue, bool, error) {
+	switch c := current.(type) {
+	case *types.AttributeValueMemberSS:
+		remove, ok := v.(*types.AttributeValueMemberSS)
+		if !ok {
+			break
+		}
+		var kept []string
+		for _, s := range c.Value {
+			if !containsString(remove.Value, s) {
+				kept = append(kept, s)
+			}
+		}
+		return &types.AttributeValueMemberSS{Value: kept}, len(kept) == 0, nil
+	case *types.AttributeValueMemberNS:
+		remove, ok := v.(*types.AttributeValueMemberNS)
+		if !ok {
+			break
+		}
+		var kept []string
+		for _, n := range c.Value {
+			if !containsNumber(remove.Value, n) {
+				kept = append(kept, n)
+			}
+		}
+		return &types.AttributeValueMemberNS{Value: kept}, len(kept) == 0, nil
+	}
+	return nil, false, fmt.Errorf("an operand in the update expression has an incorrect data type")
+}
+
+func addNumbers(a, b string, subtract bool) (string, error) {
+	x, ok := new(big.Float).SetPrec(256).SetString(a)
+	if !ok {
+		return "", fmt.Errorf("invalid number %q", a)
+	}
+	y, ok := new(big.Float).SetPrec(256).SetString(b)
+	if !ok {
+		return "", fmt.Errorf("invalid number %q", b)
+	}
+	if subtract {
+		return x.Sub(x, y).Text('f', -1), nil
+	}
+	return x.Add(x, y).Text('f', -1), nil
+}
+
+func compareNumbers(a, b string) (int, bool) {
+	x, ok := new(big.Float).SetPrec(256).SetString(a)
+	if !ok {
+		return 0, false
+	}
+	y, ok := new(big.Float).SetPrec(256).SetString(b)
+	if !ok {
+		return 0, false
+	}
+	return x.Cmp(y), true
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsNumber(values []string, n string) bool {
+	for _, v := range values {
+		if cmp, ok := compareNumbers(v, n); ok && cmp == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func compareAttributeValues(a, b types.AttributeValue) (int, bool) {
+	switch a := a.(type) {
+	case *types.AttributeValueMemberS:
+		b, ok := b.(*types.AttributeValueMemberS)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(a.Value, b.Value), true
+	case *types.AttributeValueMemberN:
+		b, ok := b.(*types.AttributeValueMemberN)
+		if !ok {
+			return 0, false
+		}
+		return compareNumbers(a.Value, b.Value)
+	case *types.AttributeValueMemberB:
+		b, ok := b.(*types.AttributeValueMemberB)
+		if !ok {
+			return 0, false
+		}
+		return bytes.Compare(a.Value, b.Value), true
+	}
+	return 0, false
+}
+
+func attributeValuesEqual(a, b types.AttributeValue) bool {
+	switch a := a.(type) {
+	case *types.AttributeValueMemberS:
+		b, ok := b.(*types.AttributeValueMemberS)
+		return ok && a.Value == b.Value
+	case *types.AttributeValueMemberN:
+		b, ok := b.(*types.AttributeValueMemberN)
+		if !ok {
+			return false
+		}
+		cmp, ok := compareNumbers(a.Value, b.Value)
+		return ok && cmp == 0
+	case *types.AttributeValueMemberB:
+		b, ok := b.(*types.AttributeValueMemberB)
+		return ok && bytes.Equal(a.Value, b.Value)
+	case *types.AttributeValueMemberBOOL:
+		b, ok := b.(*types.AttributeValueMemberBOOL)
+		return ok && a.Value == b.Value
+	case *types.AttributeValueMemberNULL:
+		_, ok := b.(*types.AttributeValueMemberNULL)
+		return ok
+	case *types.AttributeValueMemberL:
+		b, ok := b.(*types.AttributeValueMemberL)
+		if !ok || len(a.Value) != len(b.Value) {
+			return false
+		}
+		for i := range a.Value {
+			if !attributeValuesEqual(a.Value[i], b.Value[i]) {
+				return false
+			}
+		}
+		return true
+	case *types.AttributeValueMemberM:
+		b, ok := b.(*types.AttributeValueMemberM)
+		if !ok || len(a.Value) != len(b.Value) {
+			return false
+		}
+		for k, v := range a.Value {
+			other, ok := b.Value[k]
+			if !ok || !attributeValuesEqual(v, other) {
+				return false
+			}
+		}
+		return true
+	case *types.AttributeValueMemberSS:
+		b, ok := b.(*types.AttributeValueMemberSS)
+		if !ok || len(a.Value) != len(b.Value) {
+			return false
+		}
+		for _, s := range a.Value {
+			if !containsString(b.Value, s) {
+				return false
+			}
+		}
+		return true
+	case *types.AttributeValueMemberNS:
+		b, ok := b.(*types.AttributeValueMemberNS)
+		if !ok || len(a.Value) != len(b.Value) {
+			return false
+		}
+		for _, n := range a.Value {
+			if !containsNumber(b.Value, n) {
+				return false
+			}
+		}
+		return true
+	case *types.AttributeValueMemberBS:
+		b, ok := b.(*types.AttributeValueMemberBS)
+		if !ok || len(a.Value) != len(b.Value) {
+			return false
+		}
+		for i := range a.Value {
+			if !bytes.Equal(a.Value[i], b.Value[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func attributeType(v types.AttributeValue) string {
+	switch v.(type) {
+	case *types.AttributeValueMemberS:
+		return "S"
+	case *types.AttributeValueMemberN:
+		return "N"
+	case *types.AttributeValueMemberB:
+		return "B"
+	case *types.AttributeValueMemberBOOL:
+		return "BOOL"
+	case *types.AttributeValueMemberNULL:
+		return "NULL"
+	case *types.AttributeValueMemberL:
+		return "L"
+	case *types.AttributeValueMemberM:
+		return "M"
+	case *types.AttributeValueMemberSS:
+		return "SS"
+	case *types.AttributeValueMemberNS:
+		return "NS"
+	case *types.AttributeValueMemberBS:
+		return "BS"
+	}
+	return ""
+}
+
+func copyItem(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	if item == nil {
+		return nil
+	}
+	copied := make(map[string]types.AttributeValue, len(item))
+	for k, v := range item {
+		copied[k] = copyAttributeValue(v)
+	}
+	return copied
+}
+
+func copyAttributeValue(v types.AttributeValue) types.AttributeValue {
+	switch v := v.(type) {
+	case *types.AttributeValueMemberS:
+		return &types.AttributeValueMemberS{Value: v.Value}
+	case *types.AttributeValueMemberN:
+		return &types.AttributeValueMemberN{Value: v.Value}
+	case *types.AttributeValueMemberB:
+		return &types.AttributeValueMemberB{Value: bytes.Clone(v.Value)}
+	case *types.AttributeValueMemberBOOL:
+		return &types.AttributeValueMemberBOOL{Value: v.Value}
+	case *types.AttributeValueMemberNULL:
+		return &types.AttributeValueMemberNULL{Value: v.Value}
+	case *types.AttributeValueMemberL:
+		l := make([]types.AttributeValue, len(v.Value))
+		for i, e := range v.Value {
+			l[i] = copyAttributeValue(e)
+		}
+		return &types.AttributeValueMemberL{Value: l}
+	case *types.AttributeValueMemberM:
+		return &types.AttributeValueMemberM{Value: copyItem(v.Value)}
+	case *types.AttributeValueMemberSS:
+		return &types.AttributeValueMemberSS{Value: append([]string{}, v.Value...)}
+	case *types.AttributeValueMemberNS:
+		return &types.AttributeValueMemberNS{Value: append([]string{}, v.Value...)}
+	case *types.AttributeValueMemberBS:
+		bs := make([][]byte, len(v.Value))
+		for i, b := range v.Value {
+			bs[i] = bytes.Clone(b)
+		}
+		return &types.AttributeValueMemberBS{Value: bs}
+	}
+	return v
+}
@@ -0,0 +1,175 @@
+package dynamo
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditionExpression(t *testing.T) {
+	item := map[string]types.AttributeValue{
+		"id":    &types.AttributeValueMemberS{Value: "Item#1"},
+		"count": &types.AttributeValueMemberN{Value: "10"},
+		"name":  &types.AttributeValueMemberS{Value: "Shower"},
+		"tags":  &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+		"data": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"list": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+				&types.AttributeValueMemberS{Value: "first"},
+			}},
+		}},
+	}
+	values := map[string]types.AttributeValue{
+		":one":    &types.AttributeValueMemberN{Value: "1"},
+		":ten":    &types.AttributeValueMemberN{Value: "10.0"},
+		":twenty": &types.AttributeValueMemberN{Value: "20"},
+		":prefix": &types.AttributeValueMemberS{Value: "Sho"},
+		":tag":    &types.AttributeValueMemberS{Value: "b"},
+		":first":  &types.AttributeValueMemberS{Value: "first"},
+		":type":   &types.AttributeValueMemberS{Value: "SS"},
+	}
+	names := map[string]string{"#n": "name", "#c": "count"}
+
+	tests := map[string]struct {
+		expression    string
+		expectedMatch bool
+		expectError   bool
+	}{
+		"Equality On Numbers Compares Values": {expression: "#c = :ten", expectedMatch: true},
+		"Not Equal":                           {expression: "#c <> :one", expectedMatch: true},
+		"Less Than":                           {expression: "#c < :twenty", expectedMatch: true},
+		"Between":                             {expression: "#c BETWEEN :one AND :twenty", expectedMatch: true},
+		"In":                                  {expression: "#c IN (:one, :ten)", expectedMatch: true},
+		"And Short Circuits":                  {expression: "#c = :one AND #c = :ten", expectedMatch: false},
+		"Or":                                  {expression: "#c = :one OR #c = :ten", expectedMatch: true},
+		"Not With Parentheses":                {expression: "NOT (#c = :one)", expectedMatch: true},
+		"Attribute Exists":                    {expression: "attribute_exists(id)", expectedMatch: true},
+		"Attribute Not Exists":                {expression: "attribute_not_exists(missing)", expectedMatch: true},
+		"Begins With":                         {expression: "begins_with(#n, :prefix)", expectedMatch: true},
+		"Contains On Set":                     {expression: "contains(tags, :tag)", expectedMatch: true},
+		"Attribute Type":                      {expression: "attribute_type(tags, :type)", expectedMatch: true},
+		"Size":                                {expression: "size(tags) > :one", expectedMatch: true},
+		"Nested Path":                         {expression: "data.list[0] = :first", expectedMatch: true},
+		"Missing Attribute Never Equal":       {expression: "missing = :one", expectedMatch: false},
+		"Missing Attribute Is Not Equal":      {expression: "missing <> :one", expectedMatch: true},
+		"Sad Path - Undefined Value":          {expression: "#c = :missing", expectError: true},
+		"Sad Path - Undefined Name":           {expression: "#missing = :one", expectError: true},
+		"Sad Path - Trailing Tokens":          {expression: "#c = :one :ten", expectError: true},
+		"Sad Path - Unexpected Character":     {expression: "#c == :one", expectError: true},
+		"Sad Path - Missing Closing Paren":    {expression: "(#c = :one", expectError: true},
+		"Sad Path - Between Without And":      {expression: "#c BETWEEN :one :twenty", expectError: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cond, err := parseCondition(tc.expression, names, values)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			matched, err := cond.matches(item)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedMatch, matched)
+		})
+	}
+}
+
+func TestUpdateExpression(t *testing.T) {
+	tests := map[string]struct {
+		expression   string
+		values       map[string]types.AttributeValue
+		expectedItem map[string]types.AttributeValue
+		expectError  bool
+	}{
+		"Happy Path - Set And Remove": {
+			expression: "SET #n = :name REMOVE note",
+			values:     map[string]types.AttributeValue{":name": &types.AttributeValueMemberS{Value: "Bath"}},
+			expectedItem: map[string]types.AttributeValue{
+				"name":  &types.AttributeValueMemberS{Value: "Bath"},
+				"count": &types.AttributeValueMemberN{Value: "1"},
+				"list":  &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "a"}}},
+				"gone":  &types.AttributeValueMemberSS{Value: []string{"y"}},
+			},
+		},
+		"Happy Path - Arithmetic Uses Original Values": {
+			expression: "SET #c = #c + :inc, other = #c",
+			values:     map[string]types.AttributeValue{":inc": &types.AttributeValueMemberN{Value: "2"}},
+			expectedItem: map[string]types.AttributeValue{
+				"name":  &types.AttributeValueMemberS{Value: "Shower"},
+				"count": &types.AttributeValueMemberN{Value: "3"},
+				"other": &types.AttributeValueMemberN{Value: "1"},
+				"note":  &types.AttributeValueMemberS{Value: "note"},
+				"list":  &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "a"}}},
+				"gone":  &types.AttributeValueMemberSS{Value: []string{"y"}},
+			},
+		},
+		"Happy Path - If Not Exists And List Append": {
+			expression: "SET created = if_not_exists(created, :now), list = list_append(list, :more)",
+			values: map[string]types.AttributeValue{
+				":now":  &types.AttributeValueMemberS{Value: "today"},
+				":more": &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "b"}}},
+			},
+			expectedItem: map[string]types.AttributeValue{
+				"name":    &types.AttributeValueMemberS{Value: "Shower"},
+				"count":   &types.AttributeValueMemberN{Value: "1"},
+				"note":    &types.AttributeValueMemberS{Value: "note"},
+				"created": &types.AttributeValueMemberS{Value: "today"},
+				"list": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+					&types.AttributeValueMemberS{Value: "a"},
+					&types.AttributeValueMemberS{Value: "b"},
+				}},
+				"gone": &types.AttributeValueMemberSS{Value: []string{"y"}},
+			},
+		},
+		"Happy Path - Add And Delete": {
+			expression: "ADD #c :inc, tags :tags DELETE gone :gone",
+			values: map[string]types.AttributeValue{
+				":inc":  &types.AttributeValueMemberN{Value: "-1"},
+				":tags": &types.AttributeValueMemberSS{Value: []string{"x"}},
+				":gone": &types.AttributeValueMemberSS{Value: []string{"y"}},
+			},
+			expectedItem: map[string]types.AttributeValue{
+				"name":  &types.AttributeValueMemberS{Value: "Shower"},
+				"count": &types.AttributeValueMemberN{Value: "0"},
+				"note":  &types.AttributeValueMemberS{Value: "note"},
+				"tags":  &types.AttributeValueMemberSS{Value: []string{"x"}},
+				"list":  &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "a"}}},
+			},
+		},
+		"Sad Path - Arithmetic On String": {
+			expression:  "SET #n = #n + :inc",
+			values:      map[string]types.AttributeValue{":inc": &types.AttributeValueMemberN{Value: "2"}},
+			expectError: true,
+		},
+		"Sad Path - Repeated Clause": {
+			expression:  "SET #n = :v SET #c = :v",
+			values:      map[string]types.AttributeValue{":v": &types.AttributeValueMemberN{Value: "2"}},
+			expectError: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			item := map[string]types.AttributeValue{
+				"name":  &types.AttributeValueMemberS{Value: "Shower"},
+				"count": &types.AttributeValueMemberN{Value: "1"},
+				"note":  &types.AttributeValueMemberS{Value: "note"},
+				"list":  &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "a"}}},
+				"gone":  &types.AttributeValueMemberSS{Value: []string{"y"}},
+			}
+
+			actions, _, err := parseUpdate(tc.expression, map[string]string{"#n": "name", "#c": "count"}, tc.values)
+			if err == nil {
+				err = applyUpdate(item, actions)
+			}
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedItem, item)
+		})
+	}
+}
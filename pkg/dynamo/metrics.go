@@ -0,0 +1,238 @@
+package dynamo
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/metrics"
+)
+
+const (
+	OperationDurationMetric = "dynamodb_operation_duration_seconds"
+	OperationErrorsMetric   = "dynamodb_operation_errors_total"
+	ConsumedCapacityMetric  = "dynamodb_consumed_capacity_units_total"
+
+	OperationLabel = "operation"
+	TableLabel     = "table"
+	ErrorLabel     = "error"
+)
+
+// ErrorType names the kind of err for metrics: the DynamoDB error code when
+// there is one, otherwise a fixed name so label values stay bounded.
+func ErrorType(err error) string {
+	var apiErr smithy.APIError
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr.ErrorCode()
+	case errors.Is(err, context.Canceled):
+		return "ContextCanceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "ContextDeadlineExceeded"
+	default:
+		return "Unknown"
+	}
+}
+
+// MetricsClient is a DynamodbClientProvider that records the latency, errors
+// and consumed capacity of every operation of the client it wraps. Requests
+// that do not ask for consumed capacity are sent asking for the total.
+type MetricsClient struct {
+	Client DynamodbClientProvider
+	Sink   metrics.Sink
+
+	now func() time.Time
+}
+
+func NewMetricsClient(client DynamodbClientProvider, sink metrics.Sink) *MetricsClient {
+	return &MetricsClient{
+		Client: client,
+		Sink:   sink,
+		now:    time.Now,
+	}
+}
+
+func (c *MetricsClient) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	in := *params
+	in.ReturnConsumedCapacity = returnConsumedCapacity(in.ReturnConsumedCapacity)
+	return measure(c, "PutItem", tableLabel(in.TableName), func() (*dynamodb.PutItemOutput, error) {
+		return c.Client.PutItem(ctx, &in, optFns...)
+	}, func(out *dynamodb.PutItemOutput) []types.ConsumedCapacity {
+		return consumed(out.ConsumedCapacity)
+	})
+}
+
+func (c *MetricsClient) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	in := *params
+	in.ReturnConsumedCapacity = returnConsumedCapacity(in.ReturnConsumedCapacity)
+	return measure(c, "GetItem", tableLabel(in.TableName), func() (*dynamodb.GetItemOutput, error) {
+		return c.Client.GetItem(ctx, &in, optFns...)
+	}, func(out *dynamodb.GetItemOutput) []types.ConsumedCapacity {
+		return consumed(out.ConsumedCapacity)
+	})
+}
+
+func (c *MetricsClient) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	in := *params
+	in.ReturnConsumedCapacity = returnConsumedCapacity(in.ReturnConsumedCapacity)
+	return measure(c, "UpdateItem", tableLabel(in.TableName), func() (*dynamodb.UpdateItemOutput, error) {
+		return c.Client.UpdateItem(ctx, &in, optFns...)
+	}, func(out *dynamodb.UpdateItemOutput) []types.ConsumedCapacity {
+		return consumed(out.ConsumedCapacity)
+	})
+}
+
+func (c *MetricsClient) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	in := *params
+	in.ReturnConsumedCapacity = returnConsumedCapacity(in.ReturnConsumedCapacity)
+	return measure(c, "Query", tableLabel(in.TableName), func() (*dynamodb.QueryOutput, error) {
+		return c.Client.Query(ctx, &in, optFns...)
+	}, func(out *dynamodb.QueryOutput) []types.ConsumedCapacity {
+		return consumed(out.ConsumedCapacity)
+	})
+}
+
+func (c *MetricsClient) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	in := *params
+	in.ReturnConsumedCapacity = returnConsumedCapacity(in.ReturnConsumedCapacity)
+	return measure(c, "Scan", tableLabel(in.TableName), func() (*dynamodb.ScanOutput, error) {
+		return c.Client.Scan(ctx, &in, optFns...)
+	}, func(out *dynamodb.ScanOutput) []types.ConsumedCapacity {
+		return consumed(out.ConsumedCapacity)
+	})
+}
+
+func (c *MetricsClient) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	in := *params
+	in.ReturnConsumedCapacity = returnConsumedCapacity(in.ReturnConsumedCapacity)
+	return measure(c, "DeleteItem", tableLabel(in.TableName), func() (*dynamodb.DeleteItemOutput, error) {
+		return c.Client.DeleteItem(ctx, &in, optFns...)
+	}, func(out *dynamodb.DeleteItemOutput) []types.ConsumedCapacity {
+		return consumed(out.ConsumedCapacity)
+	})
+}
+
+func (c *MetricsClient) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	in := *params
+	in.ReturnConsumedCapacity = returnConsumedCapacity(in.ReturnConsumedCapacity)
+	tables := make([]string, 0, len(in.RequestItems))
+	for name := range in.RequestItems {
+		tables = append(tables, name)
+	}
+	return measure(c, "BatchWriteItem", tablesLabel(tables), func() (*dynamodb.BatchWriteItemOutput, error) {
+		return c.Client.BatchWriteItem(ctx, &in, optFns...)
+	}, func(out *dynamodb.BatchWriteItemOutput) []types.ConsumedCapacity {
+		return out.ConsumedCapacity
+	})
+}
+
+func (c *MetricsClient) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	in := *params
+	in.ReturnConsumedCapacity = returnConsumedCapacity(in.ReturnConsumedCapacity)
+	tables := make([]string, 0, len(in.RequestItems))
+	for name := range in.RequestItems {
+		tables = append(tables, name)
+	}
+	return measure(c, "BatchGetItem", tablesLabel(tables), func() (*dynamodb.BatchGetItemOutput, error) {
+		return c.Client.BatchGetItem(ctx, &in, optFns...)
+	}, func(out *dynamodb.BatchGetItemOutput) []types.ConsumedCapacity {
+		return out.ConsumedCapacity
+	})
+}
+
+func (c *MetricsClient) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	in := *params
+	in.ReturnConsumedCapacity = returnConsumedCapacity(in.ReturnConsumedCapacity)
+	var tables []string
+	for _, item := range in.TransactItems {
+		tables = append(tables, transactTable(item))
+	}
+	return measure(c, "TransactWriteItems", tablesLabel(tables), func() (*dynamodb.TransactWriteItemsOutput, error) {
+		return c.Client.TransactWriteItems(ctx, &in, optFns...)
+	}, func(out *dynamodb.TransactWriteItemsOutput) []types.ConsumedCapacity {
+		return out.ConsumedCapacity
+	})
+}
+
+func measure[Out any](c *MetricsClient, operation, table string, call func() (*Out, error), capacity func(*Out) []types.ConsumedCapacity) (*Out, error) {
+	start := c.now()
+	out, err := call()
+	labels := metrics.Labels{OperationLabel: operation, TableLabel: table}
+	c.Sink.Observe(OperationDurationMetric, labels, c.now().Sub(start).Seconds())
+
+	if err != nil {
+		c.Sink.Add(OperationErrorsMetric, metrics.Labels{OperationLabel: operation, TableLabel: table, ErrorLabel: ErrorType(err)}, 1)
+		return out, err
+	}
+
+	if out != nil {
+		for _, cc := range capacity(out) {
+			if cc.CapacityUnits == nil {
+				continue
+			}
+			name := table
+			if cc.TableName != nil {
+				name = *cc.TableName
+			}
+			c.Sink.Add(ConsumedCapacityMetric, metrics.Labels{OperationLabel: operation, TableLabel: name}, *cc.CapacityUnits)
+		}
+	}
+	return out, nil
+}
+
+func returnConsumedCapacity(requested types.ReturnConsumedCapacity) types.ReturnConsumedCapacity {
+	if requested == "" {
+		return types.ReturnConsumedCapacityTotal
+	}
+	return requested
+}
+
+func consumed(cc *types.ConsumedCapacity) []types.ConsumedCapacity {
+	if cc == nil {
+		return nil
+	}
+	return []types.ConsumedCapacity{*cc}
+}
+
+func tableLabel(name *string) string {
+	return aws.ToString(name)
+}
+
+// tablesLabel names the tables of a multi-table request, sorted and joined
+// with commas.
+func tablesLabel(tables []string) string {
+	return strings.Join(uniqueTables(tables), ",")
+}
+
+func uniqueTables(tables []string) []string {
+	seen := map[string]bool{}
+	var unique []string
+	for _, t := range tables {
+		if t != "" && !seen[t] {
+			seen[t] = true
+			unique = append(unique, t)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
+
+func transactTable(item types.TransactWriteItem) string {
+	switch {
+	case item.Put != nil:
+		return aws.ToString(item.Put.TableName)
+	case item.Update != nil:
+		return aws.ToString(item.Update.TableName)
+	case item.Delete != nil:
+		return aws.ToString(item.Delete.TableName)
+	case item.ConditionCheck != nil:
+		return aws.ToString(item.ConditionCheck.TableName)
+	}
+	return ""
+}
@@ -0,0 +1,127 @@
+package dynamo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMetricsClient(client DynamodbClientProvider) (*MetricsClient, *metrics.Memory) {
+	sink := metrics.NewMemory()
+	c := NewMetricsClient(client, sink)
+	now := time.Now()
+	c.now = func() time.Time {
+		now = now.Add(50 * time.Millisecond)
+		return now
+	}
+	return c, sink
+}
+
+func TestMetricsClient(t *testing.T) {
+	tests := map[string]struct {
+		mock              *Mock
+		call              func(c *MetricsClient) error
+		operation         string
+		table             string
+		expectedErrorType string
+		expectedCapacity  map[string]float64
+		assertCall        func(t *testing.T, m *Mock)
+	}{
+		"Happy Path - Records Latency And Capacity": {
+			mock: &Mock{PutOutput: &dynamodb.PutItemOutput{ConsumedCapacity: &types.ConsumedCapacity{TableName: aws.String("user-table"), CapacityUnits: aws.Float64(1.5)}}},
+			call: func(c *MetricsClient) error {
+				_, err := c.PutItem(context.Background(), &dynamodb.PutItemInput{TableName: aws.String("user-table")})
+				return err
+			},
+			operation:        "PutItem",
+			table:            "user-table",
+			expectedCapacity: map[string]float64{"user-table": 1.5},
+			assertCall: func(t *testing.T, m *Mock) {
+				assert.Equal(t, types.ReturnConsumedCapacityTotal, m.OnPut.Calls[0].ReturnConsumedCapacity)
+			},
+		},
+		"Happy Path - Keeps Requested Capacity Detail": {
+			mock: &Mock{QueryOutput: &dynamodb.QueryOutput{}},
+			call: func(c *MetricsClient) error {
+				_, err := c.Query(context.Background(), &dynamodb.QueryInput{TableName: aws.String("event-table"), ReturnConsumedCapacity: types.ReturnConsumedCapacityIndexes})
+				return err
+			},
+			operation: "Query",
+			table:     "event-table",
+			assertCall: func(t *testing.T, m *Mock) {
+				assert.Equal(t, types.ReturnConsumedCapacityIndexes, m.OnQuery.Calls[0].ReturnConsumedCapacity)
+			},
+		},
+		"Happy Path - Transaction Across Tables": {
+			mock: &Mock{TransactOutput: &dynamodb.TransactWriteItemsOutput{ConsumedCapacity: []types.ConsumedCapacity{
+				{TableName: aws.String("receiver-table"), CapacityUnits: aws.Float64(2)},
+				{TableName: aws.String("relationship-table"), CapacityUnits: aws.Float64(4)},
+			}}},
+			call: func(c *MetricsClient) error {
+				_, err := c.TransactWriteItems(context.Background(), &dynamodb.TransactWriteItemsInput{TransactItems: []types.TransactWriteItem{
+					{Put: &types.Put{TableName: aws.String("relationship-table")}},
+					{Put: &types.Put{TableName: aws.String("receiver-table")}},
+				}})
+				return err
+			},
+			operation:        "TransactWriteItems",
+			table:            "receiver-table,relationship-table",
+			expectedCapacity: map[string]float64{"receiver-table": 2, "relationship-table": 4},
+		},
+		"Sad Path - Counts Error By Type": {
+			mock: &Mock{OnGet: MockMethod[dynamodb.GetItemInput, dynamodb.GetItemOutput]{Err: errThrottled}},
+			call: func(c *MetricsClient) error {
+				_, err := c.GetItem(context.Background(), &dynamodb.GetItemInput{TableName: aws.String("user-table")})
+				return err
+			},
+			operation:         "GetItem",
+			table:             "user-table",
+			expectedErrorType: "ProvisionedThroughputExceededException",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, sink := newTestMetricsClient(tc.mock)
+
+			err := tc.call(client)
+
+			labels := metrics.Labels{OperationLabel: tc.operation, TableLabel: tc.table}
+			assert.Equal(t, []float64{0.05}, sink.Observations(OperationDurationMetric, labels))
+			if tc.expectedErrorType != "" {
+				assert.Error(t, err)
+				assert.Equal(t, float64(1), sink.Counter(OperationErrorsMetric, metrics.Labels{OperationLabel: tc.operation, TableLabel: tc.table, ErrorLabel: tc.expectedErrorType}))
+			} else {
+				assert.NoError(t, err)
+			}
+			for table, units := range tc.expectedCapacity {
+				assert.Equal(t, units, sink.Counter(ConsumedCapacityMetric, metrics.Labels{OperationLabel: tc.operation, TableLabel: table}))
+			}
+			if tc.assertCall != nil {
+				tc.assertCall(t, tc.mock)
+			}
+		})
+	}
+}
+
+func TestMetricsClient_DoesNotModifyInput(t *testing.T) {
+	client, _ := newTestMetricsClient(&Mock{DeleteOutput: &dynamodb.DeleteItemOutput{}})
+	in := &dynamodb.DeleteItemInput{TableName: aws.String("event-table")}
+
+	_, err := client.DeleteItem(context.Background(), in)
+
+	assert.NoError(t, err)
+	assert.Empty(t, in.ReturnConsumedCapacity)
+}
+
+func TestErrorType(t *testing.T) {
+	assert.Equal(t, "ConditionalCheckFailedException", ErrorType(&types.ConditionalCheckFailedException{}))
+	assert.Equal(t, "ContextCanceled", ErrorType(context.Canceled))
+	assert.Equal(t, "ContextDeadlineExceeded", ErrorType(context.DeadlineExceeded))
+	assert.Equal(t, "Unknown", ErrorType(assert.AnError))
+}
@@ -2,43 +2,177 @@ package dynamo
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
 
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 )
 
+var ErrUnexpectedCall = errors.New("unexpected call to dynamo mock")
+
+// TestingT is the subset of testing.TB the mock reports unexpected calls to.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// MockMethod scripts a single Mock method. For the nth call (starting at 0)
+// Func takes precedence over everything else; otherwise the error is Errs[n],
+// then Err, then Mock.Err, and the output is Outputs[n], then Output. Every
+// call's input is appended to Calls.
+type MockMethod[In any, Out any] struct {
+	Output  *Out
+	Outputs []*Out
+	Err     error
+	Errs    map[int]error
+	Func    func(ctx context.Context, params *In) (*Out, error)
+	Calls   []*In
+}
+
+// Mock is a scriptable DynamodbClientProvider. The flat Output and Err fields
+// apply to every call of a method; the On* fields script individual methods
+// and calls. When T is set any call without a configured output or error
+// fails the test, and running past the end of a list of outputs always does.
 type Mock struct {
 	Err          error
 	QueryOutput  *dynamodb.QueryOutput
 	QueryOutputs []*dynamodb.QueryOutput
 	QueryCallNum int
+	ScanOutput   *dynamodb.ScanOutput
 	PutOutput    *dynamodb.PutItemOutput
 	GetOutput    *dynamodb.GetItemOutput
 	UpdateOutput *dynamodb.UpdateItemOutput
 	DeleteOutput *dynamodb.DeleteItemOutput
+
+	BatchWriteOutput  *dynamodb.BatchWriteItemOutput
+	BatchWriteOutputs []*dynamodb.BatchWriteItemOutput
+	BatchWriteCallNum int
+	BatchGetOutput    *dynamodb.BatchGetItemOutput
+	BatchGetOutputs   []*dynamodb.BatchGetItemOutput
+	BatchGetCallNum   int
+	TransactOutput    *dynamodb.TransactWriteItemsOutput
+
+	OnPut        MockMethod[dynamodb.PutItemInput, dynamodb.PutItemOutput]
+	OnGet        MockMethod[dynamodb.GetItemInput, dynamodb.GetItemOutput]
+	OnUpdate     MockMethod[dynamodb.UpdateItemInput, dynamodb.UpdateItemOutput]
+	OnQuery      MockMethod[dynamodb.QueryInput, dynamodb.QueryOutput]
+	OnScan       MockMethod[dynamodb.ScanInput, dynamodb.ScanOutput]
+	OnDelete     MockMethod[dynamodb.DeleteItemInput, dynamodb.DeleteItemOutput]
+	OnBatchWrite MockMethod[dynamodb.BatchWriteItemInput, dynamodb.BatchWriteItemOutput]
+	OnBatchGet   MockMethod[dynamodb.BatchGetItemInput, dynamodb.BatchGetItemOutput]
+	OnTransact   MockMethod[dynamodb.TransactWriteItemsInput, dynamodb.TransactWriteItemsOutput]
+
+	T TestingT
+
+	mu sync.Mutex
+}
+
+type legacyOutputs[Out any] struct {
+	output  *Out
+	outputs []*Out
+	callNum *int
 }
 
 func (m *Mock) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
-	return m.PutOutput, m.Err
+	return call(ctx, m, "PutItem", &m.OnPut, params, legacyOutputs[dynamodb.PutItemOutput]{output: m.PutOutput})
 }
 
 func (m *Mock) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
-	return m.GetOutput, m.Err
+	return call(ctx, m, "GetItem", &m.OnGet, params, legacyOutputs[dynamodb.GetItemOutput]{output: m.GetOutput})
 }
 
 func (m *Mock) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
-	return m.UpdateOutput, m.Err
+	return call(ctx, m, "UpdateItem", &m.OnUpdate, params, legacyOutputs[dynamodb.UpdateItemOutput]{output: m.UpdateOutput})
 }
 
 func (m *Mock) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
-	if len(m.QueryOutputs) > 0 {
-		output := m.QueryOutputs[m.QueryCallNum]
-		m.QueryCallNum++
-		return output, m.Err
-	}
+	return call(ctx, m, "Query", &m.OnQuery, params, legacyOutputs[dynamodb.QueryOutput]{
+		output:  m.QueryOutput,
+		outputs: m.QueryOutputs,
+		callNum: &m.QueryCallNum,
+	})
+}
 
-	return m.QueryOutput, m.Err
+func (m *Mock) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	return call(ctx, m, "Scan", &m.OnScan, params, legacyOutputs[dynamodb.ScanOutput]{output: m.ScanOutput})
 }
 
 func (m *Mock) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
-	return m.DeleteOutput, m.Err
+	return call(ctx, m, "DeleteItem", &m.OnDelete, params, legacyOutputs[dynamodb.DeleteItemOutput]{output: m.DeleteOutput})
+}
+
+func (m *Mock) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	return call(ctx, m, "BatchWriteItem", &m.OnBatchWrite, params, legacyOutputs[dynamodb.BatchWriteItemOutput]{
+		output:  m.BatchWriteOutput,
+		outputs: m.BatchWriteOutputs,
+		callNum: &m.BatchWriteCallNum,
+	})
+}
+
+func (m *Mock) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	return call(ctx, m, "BatchGetItem", &m.OnBatchGet, params, legacyOutputs[dynamodb.BatchGetItemOutput]{
+		output:  m.BatchGetOutput,
+		outputs: m.BatchGetOutputs,
+		callNum: &m.BatchGetCallNum,
+	})
+}
+
+func (m *Mock) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	return call(ctx, m, "TransactWriteItems", &m.OnTransact, params, legacyOutputs[dynamodb.TransactWriteItemsOutput]{output: m.TransactOutput})
+}
+
+func call[In any, Out any](ctx context.Context, m *Mock, name string, method *MockMethod[In, Out], params *In, legacy legacyOutputs[Out]) (*Out, error) {
+	m.mu.Lock()
+
+	n := len(method.Calls)
+	method.Calls = append(method.Calls, params)
+
+	if method.Func != nil {
+		fn := method.Func
+		m.mu.Unlock()
+		return fn(ctx, params)
+	}
+	defer m.mu.Unlock()
+
+	err := method.Errs[n]
+	if err == nil {
+		err = method.Err
+	}
+	if err == nil {
+		err = m.Err
+	}
+
+	var output *Out
+	exhausted := false
+	switch {
+	case n < len(method.Outputs):
+		output = method.Outputs[n]
+	case method.Output != nil:
+		output = method.Output
+	case len(method.Outputs) > 0:
+		exhausted = true
+	case len(legacy.outputs) > 0:
+		if *legacy.callNum >= len(legacy.outputs) {
+			exhausted = true
+			break
+		}
+		output = legacy.outputs[*legacy.callNum]
+		*legacy.callNum++
+	default:
+		output = legacy.output
+	}
+
+	if err != nil || (output != nil && !exhausted) {
+		return output, err
+	}
+	if !exhausted && m.T == nil {
+		return nil, nil
+	}
+
+	if m.T != nil {
+		m.T.Helper()
+		m.T.Errorf("dynamo.Mock: unexpected %s call #%d with input %+v", name, n+1, params)
+	}
+	return nil, fmt.Errorf("%w: %s call #%d has no configured output", ErrUnexpectedCall, name, n+1)
 }
@@ -3,8 +3,11 @@ package dynamo
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
@@ -153,6 +156,20 @@ func TestMock_Query(t *testing.T) {
 		}
 	})
 
+	t.Run("exhausted outputs", func(t *testing.T) {
+		mock := &Mock{QueryOutputs: []*dynamodb.QueryOutput{{}}}
+
+		_, _ = mock.Query(ctx, &dynamodb.QueryInput{})
+		output, err := mock.Query(ctx, &dynamodb.QueryInput{})
+
+		if !errors.Is(err, ErrUnexpectedCall) {
+			t.Errorf("expected unexpected call error, got %v", err)
+		}
+		if output != nil {
+			t.Errorf("expected no output, got %v", output)
+		}
+	})
+
 	t.Run("error", func(t *testing.T) {
 		expectedErr := errors.New("query error")
 		mock := &Mock{Err: expectedErr}
@@ -165,6 +182,35 @@ func TestMock_Query(t *testing.T) {
 	})
 }
 
+func TestMock_Scan(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		expectedOutput := &dynamodb.ScanOutput{}
+		mock := &Mock{ScanOutput: expectedOutput}
+
+		output, err := mock.Scan(ctx, &dynamodb.ScanInput{})
+
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if output != expectedOutput {
+			t.Errorf("expected output %v, got %v", expectedOutput, output)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		expectedErr := errors.New("scan error")
+		mock := &Mock{Err: expectedErr}
+
+		_, err := mock.Scan(ctx, &dynamodb.ScanInput{})
+
+		if err != expectedErr {
+			t.Errorf("expected error %v, got %v", expectedErr, err)
+		}
+	})
+}
+
 func TestMock_DeleteItem(t *testing.T) {
 	ctx := context.Background()
 
@@ -193,3 +239,213 @@ func TestMock_DeleteItem(t *testing.T) {
 		}
 	})
 }
+
+func TestMock_BatchWriteItem(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("multiple outputs", func(t *testing.T) {
+		output1 := &dynamodb.BatchWriteItemOutput{}
+		output2 := &dynamodb.BatchWriteItemOutput{}
+		mock := &Mock{BatchWriteOutputs: []*dynamodb.BatchWriteItemOutput{output1, output2}}
+
+		result1, _ := mock.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{})
+		result2, _ := mock.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{})
+
+		if result1 != output1 || result2 != output2 {
+			t.Errorf("expected outputs in order, got %v and %v", result1, result2)
+		}
+		if mock.BatchWriteCallNum != 2 {
+			t.Errorf("expected 2 calls, got %d", mock.BatchWriteCallNum)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		expectedErr := errors.New("batch write error")
+		mock := &Mock{Err: expectedErr}
+
+		_, err := mock.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{})
+
+		if err != expectedErr {
+			t.Errorf("expected error %v, got %v", expectedErr, err)
+		}
+	})
+}
+
+func TestMock_BatchGetItem(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("single output", func(t *testing.T) {
+		expectedOutput := &dynamodb.BatchGetItemOutput{}
+		mock := &Mock{BatchGetOutput: expectedOutput}
+
+		output, err := mock.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{})
+
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if output != expectedOutput {
+			t.Errorf("expected output %v, got %v", expectedOutput, output)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		expectedErr := errors.New("batch get error")
+		mock := &Mock{Err: expectedErr}
+
+		_, err := mock.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{})
+
+		if err != expectedErr {
+			t.Errorf("expected error %v, got %v", expectedErr, err)
+		}
+	})
+}
+
+func TestMock_TransactWriteItems(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		expectedOutput := &dynamodb.TransactWriteItemsOutput{}
+		mock := &Mock{TransactOutput: expectedOutput}
+
+		output, err := mock.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{})
+
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if output != expectedOutput {
+			t.Errorf("expected output %v, got %v", expectedOutput, output)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		expectedErr := errors.New("transact error")
+		mock := &Mock{Err: expectedErr}
+
+		_, err := mock.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{})
+
+		if err != expectedErr {
+			t.Errorf("expected error %v, got %v", expectedErr, err)
+		}
+	})
+}
+
+type recordingT struct {
+	errors []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestMock_OnMethod(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("records inputs", func(t *testing.T) {
+		mock := &Mock{}
+		first := &dynamodb.PutItemInput{TableName: aws.String("first")}
+		second := &dynamodb.PutItemInput{TableName: aws.String("second")}
+
+		_, _ = mock.PutItem(ctx, first)
+		_, _ = mock.PutItem(ctx, second)
+
+		if len(mock.OnPut.Calls) != 2 || mock.OnPut.Calls[0] != first || mock.OnPut.Calls[1] != second {
+			t.Errorf("expected both inputs in order, got %v", mock.OnPut.Calls)
+		}
+		if len(mock.OnGet.Calls) != 0 {
+			t.Errorf("expected no get calls, got %d", len(mock.OnGet.Calls))
+		}
+	})
+
+	t.Run("per call outputs and errors", func(t *testing.T) {
+		expectedErr := errors.New("second call error")
+		output1 := &dynamodb.GetItemOutput{}
+		output3 := &dynamodb.GetItemOutput{}
+		mock := &Mock{
+			Err: errors.New("shared error"),
+			OnGet: MockMethod[dynamodb.GetItemInput, dynamodb.GetItemOutput]{
+				Outputs: []*dynamodb.GetItemOutput{output1, nil, output3},
+				Errs:    map[int]error{1: expectedErr},
+			},
+			OnPut: MockMethod[dynamodb.PutItemInput, dynamodb.PutItemOutput]{
+				Output: &dynamodb.PutItemOutput{},
+			},
+		}
+
+		result1, err1 := mock.GetItem(ctx, &dynamodb.GetItemInput{})
+		_, err2 := mock.GetItem(ctx, &dynamodb.GetItemInput{})
+		result3, _ := mock.GetItem(ctx, &dynamodb.GetItemInput{})
+		_, putErr := mock.PutItem(ctx, &dynamodb.PutItemInput{})
+
+		if result1 != output1 || result3 != output3 {
+			t.Errorf("expected outputs by call index, got %v and %v", result1, result3)
+		}
+		if err1 != mock.Err {
+			t.Errorf("expected shared error on first call, got %v", err1)
+		}
+		if err2 != expectedErr {
+			t.Errorf("expected error %v, got %v", expectedErr, err2)
+		}
+		if putErr != mock.Err {
+			t.Errorf("expected shared error for put, got %v", putErr)
+		}
+	})
+
+	t.Run("method error overrides shared error", func(t *testing.T) {
+		expectedErr := errors.New("delete error")
+		mock := &Mock{
+			Err:      errors.New("shared error"),
+			OnDelete: MockMethod[dynamodb.DeleteItemInput, dynamodb.DeleteItemOutput]{Err: expectedErr},
+		}
+
+		_, err := mock.DeleteItem(ctx, &dynamodb.DeleteItemInput{})
+
+		if err != expectedErr {
+			t.Errorf("expected error %v, got %v", expectedErr, err)
+		}
+	})
+
+	t.Run("function hook", func(t *testing.T) {
+		mock := &Mock{
+			OnQuery: MockMethod[dynamodb.QueryInput, dynamodb.QueryOutput]{
+				Func: func(ctx context.Context, params *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+					return &dynamodb.QueryOutput{Count: int32(len(aws.ToString(params.IndexName)))}, nil
+				},
+			},
+		}
+
+		output, err := mock.Query(ctx, &dynamodb.QueryInput{IndexName: aws.String("email")})
+
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		if output.Count != 5 {
+			t.Errorf("expected hook output, got %v", output)
+		}
+		if len(mock.OnQuery.Calls) != 1 {
+			t.Errorf("expected hook call to be recorded, got %d", len(mock.OnQuery.Calls))
+		}
+	})
+
+	t.Run("strict mode reports unexpected calls", func(t *testing.T) {
+		rt := &recordingT{}
+		mock := &Mock{
+			T:     rt,
+			OnGet: MockMethod[dynamodb.GetItemInput, dynamodb.GetItemOutput]{Output: &dynamodb.GetItemOutput{}},
+		}
+
+		_, getErr := mock.GetItem(ctx, &dynamodb.GetItemInput{})
+		_, err := mock.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{})
+
+		if getErr != nil {
+			t.Errorf("expected no error for configured method, got %v", getErr)
+		}
+		if !errors.Is(err, ErrUnexpectedCall) {
+			t.Errorf("expected unexpected call error, got %v", err)
+		}
+		if len(rt.errors) != 1 || !strings.Contains(rt.errors[0], "unexpected TransactWriteItems call #1") {
+			t.Errorf("expected one failure naming the method, got %v", rt.errors)
+		}
+	})
+}
@@ -0,0 +1,281 @@
+package dynamo
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/log"
+	"go.uber.org/zap"
+)
+
+var (
+	throttlingCodes = map[string]bool{
+		"ProvisionedThroughputExceededException": true,
+		"RequestLimitExceeded":                   true,
+		"ThrottlingException":                    true,
+		"Throttling":                             true,
+	}
+	transientCodes = map[string]bool{
+		"TransactionConflictException":   true,
+		"TransactionInProgressException": true,
+		"InternalServerError":            true,
+		"InternalFailure":                true,
+		"ServiceUnavailable":             true,
+	}
+	// Cancellation reason codes that make a cancelled transaction worth
+	// retrying. Any other reason, such as ConditionalCheckFailed, fails the
+	// transaction for good.
+	retryableCancellationCodes = map[string]bool{
+		"None":                          true,
+		"TransactionConflict":           true,
+		"ThrottlingError":               true,
+		"ProvisionedThroughputExceeded": true,
+	}
+)
+
+// IsThrottling reports whether err means DynamoDB rejected the request for
+// exceeding throughput or request limits.
+func IsThrottling(err error) bool {
+	var tce *types.TransactionCanceledException
+	if errors.As(err, &tce) {
+		for _, reason := range tce.CancellationReasons {
+			code := aws.ToString(reason.Code)
+			if code == "ThrottlingError" || code == "ProvisionedThroughputExceeded" {
+				return true
+			}
+		}
+		return false
+	}
+
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && throttlingCodes[apiErr.ErrorCode()]
+}
+
+// IsRetryable reports whether the same request may succeed if sent again.
+// Conditional check failures, validation errors and cancelled contexts are
+// never retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var tce *types.TransactionCanceledException
+	if errors.As(err, &tce) {
+		retryable := false
+		for _, reason := range tce.CancellationReasons {
+			code := aws.ToString(reason.Code)
+			if !retryableCancellationCodes[code] {
+				return false
+			}
+			retryable = retryable || code != "None"
+		}
+		return retryable
+	}
+
+	if IsThrottling(err) {
+		return true
+	}
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && transientCodes[apiErr.ErrorCode()]
+}
+
+// RetryPolicy bounds how RetryClient retries a single operation. Zero fields
+// take the values of DefaultRetryPolicy.
+type RetryPolicy struct {
+	// MaxAttempts includes the first attempt.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry of a transient error.
+	// It doubles with every retry up to MaxDelay.
+	BaseDelay time.Duration
+	// ThrottleBaseDelay replaces BaseDelay when the error is throttling.
+	ThrottleBaseDelay time.Duration
+	MaxDelay          time.Duration
+	// Budget caps the time spent on one operation across all attempts. A
+	// retry is not attempted if its backoff would end after the budget or
+	// the context deadline.
+	Budget time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:       5,
+	BaseDelay:         25 * time.Millisecond,
+	ThrottleBaseDelay: 100 * time.Millisecond,
+	MaxDelay:          2 * time.Second,
+	Budget:            10 * time.Second,
+}
+
+// WithDefaults returns p with its zero fields set from DefaultRetryPolicy.
+func (p RetryPolicy) WithDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultRetryPolicy.BaseDelay
+	}
+	if p.ThrottleBaseDelay <= 0 {
+		p.ThrottleBaseDelay = DefaultRetryPolicy.ThrottleBaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultRetryPolicy.MaxDelay
+	}
+	if p.Budget <= 0 {
+		p.Budget = DefaultRetryPolicy.Budget
+	}
+	return p
+}
+
+// Backoff returns a randomized delay before the given retry, starting at 0,
+// for callers that retry on their own, such as partially processed batches.
+func (p RetryPolicy) Backoff(retry int, throttled bool) time.Duration {
+	return p.WithDefaults().backoff(retry, throttled, rand.Float64())
+}
+
+// backoff returns a full jitter delay for the given retry, starting at 0.
+func (p RetryPolicy) backoff(retry int, throttled bool, jitter float64) time.Duration {
+	delay := p.BaseDelay
+	if throttled {
+		delay = p.ThrottleBaseDelay
+	}
+	for i := 0; i < retry && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, p.MaxDelay)
+	return time.Duration(jitter * float64(delay))
+}
+
+// RetryClient is a DynamodbClientProvider that retries throttled and
+// transient failures of the client it wraps. UpdateItem is only retried when
+// throttled: an update that failed with a server error may still have been
+// applied, and updates such as counter increments must not be applied twice.
+type RetryClient struct {
+	Client DynamodbClientProvider
+	Policy RetryPolicy
+	logger *zap.Logger
+
+	now    func() time.Time
+	sleep  func(ctx context.Context, d time.Duration) error
+	jitter func() float64
+}
+
+func NewRetryClient(client DynamodbClientProvider, policy RetryPolicy, logger *zap.Logger) *RetryClient {
+	return &RetryClient{
+		Client: client,
+		Policy: policy.WithDefaults(),
+		logger: logger,
+		now:    time.Now,
+		sleep:  sleep,
+		jitter: rand.Float64,
+	}
+}
+
+func (c *RetryClient) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	return retry(ctx, c, "PutItem", IsRetryable, func(ctx context.Context) (*dynamodb.PutItemOutput, error) {
+		return c.Client.PutItem(ctx, params, optFns...)
+	})
+}
+
+func (c *RetryClient) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return retry(ctx, c, "GetItem", IsRetryable, func(ctx context.Context) (*dynamodb.GetItemOutput, error) {
+		return c.Client.GetItem(ctx, params, optFns...)
+	})
+}
+
+func (c *RetryClient) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	return retry(ctx, c, "UpdateItem", IsThrottling, func(ctx context.Context) (*dynamodb.UpdateItemOutput, error) {
+		return c.Client.UpdateItem(ctx, params, optFns...)
+	})
+}
+
+func (c *RetryClient) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	return retry(ctx, c, "Query", IsRetryable, func(ctx context.Context) (*dynamodb.QueryOutput, error) {
+		return c.Client.Query(ctx, params, optFns...)
+	})
+}
+
+func (c *RetryClient) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	return retry(ctx, c, "Scan", IsRetryable, func(ctx context.Context) (*dynamodb.ScanOutput, error) {
+		return c.Client.Scan(ctx, params, optFns...)
+	})
+}
+
+func (c *RetryClient) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	return retry(ctx, c, "DeleteItem", IsRetryable, func(ctx context.Context) (*dynamodb.DeleteItemOutput, error) {
+		return c.Client.DeleteItem(ctx, params, optFns...)
+	})
+}
+
+func (c *RetryClient) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	return retry(ctx, c, "BatchWriteItem", IsRetryable, func(ctx context.Context) (*dynamodb.BatchWriteItemOutput, error) {
+		return c.Client.BatchWriteItem(ctx, params, optFns...)
+	})
+}
+
+func (c *RetryClient) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	return retry(ctx, c, "BatchGetItem", IsRetryable, func(ctx context.Context) (*dynamodb.BatchGetItemOutput, error) {
+		return c.Client.BatchGetItem(ctx, params, optFns...)
+	})
+}
+
+func (c *RetryClient) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	return retry(ctx, c, "TransactWriteItems", IsRetryable, func(ctx context.Context) (*dynamodb.TransactWriteItemsOutput, error) {
+		return c.Client.TransactWriteItems(ctx, params, optFns...)
+	})
+}
+
+// retry calls call until it succeeds or fails with an error retryable does
+// not accept, the attempts are exhausted or the budget is spent.
+func retry[Out any](ctx context.Context, c *RetryClient, operation string, retryable func(error) bool, call func(ctx context.Context) (Out, error)) (Out, error) {
+	logger := c.logger.With(zap.String(log.OperationLogKey, operation))
+	start := c.now()
+	deadline := start.Add(c.Policy.Budget)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	for attempt := 1; ; attempt++ {
+		out, err := call(ctx)
+		if err == nil {
+			if attempt > 1 {
+				logger.Info("dynamo db operation succeeded after retries", zap.Int(log.RetriesLogKey, attempt-1))
+			}
+			return out, nil
+		}
+		if !retryable(err) {
+			return out, err
+		}
+
+		if attempt >= c.Policy.MaxAttempts {
+			logger.Warn("dynamo db operation failed after exhausting retries", zap.Int(log.RetriesLogKey, attempt-1), zap.Error(err))
+			return out, err
+		}
+
+		delay := c.Policy.backoff(attempt-1, IsThrottling(err), c.jitter())
+		if c.now().Add(delay).After(deadline) {
+			logger.Warn("dynamo db operation failed with retry budget spent", zap.Int(log.RetriesLogKey, attempt-1), zap.Error(err))
+			return out, err
+		}
+
+		logger.Info("retrying dynamo db operation", zap.Int(log.RetriesLogKey, attempt), zap.Duration(log.DelayLogKey, delay), zap.Error(err))
+		if err := c.sleep(ctx, delay); err != nil {
+			return out, err
+		}
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,258 @@
+package dynamo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+var (
+	errThrottled = &types.ProvisionedThroughputExceededException{Message: aws.String("throttled")}
+	errConflict  = &smithy.GenericAPIError{Code: "TransactionConflictException", Message: "conflict"}
+)
+
+func cancelled(codes ...string) error {
+	tce := &types.TransactionCanceledException{}
+	for _, code := range codes {
+		tce.CancellationReasons = append(tce.CancellationReasons, types.CancellationReason{Code: aws.String(code)})
+	}
+	return tce
+}
+
+// newTestRetryClient returns a RetryClient whose clock only advances while
+// it sleeps. The returned slice collects every backoff.
+func newTestRetryClient(client DynamodbClientProvider, policy RetryPolicy, logger *zap.Logger) (*RetryClient, *[]time.Duration) {
+	var slept []time.Duration
+	now := time.Now()
+
+	c := NewRetryClient(client, policy, logger)
+	c.now = func() time.Time { return now }
+	c.jitter = func() float64 { return 1 }
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		now = now.Add(d)
+		return ctx.Err()
+	}
+	return c, &slept
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := map[string]struct {
+		err               error
+		expectedRetryable bool
+		expectedThrottled bool
+	}{
+		"Happy Path - Throughput Exceeded": {
+			err:               errThrottled,
+			expectedRetryable: true,
+			expectedThrottled: true,
+		},
+		"Happy Path - Request Limit Exceeded": {
+			err:               &types.RequestLimitExceeded{},
+			expectedRetryable: true,
+			expectedThrottled: true,
+		},
+		"Happy Path - Transaction Conflict": {
+			err:               errConflict,
+			expectedRetryable: true,
+		},
+		"Happy Path - Internal Server Error": {
+			err:               &types.InternalServerError{},
+			expectedRetryable: true,
+		},
+		"Happy Path - Cancelled By Conflict": {
+			err:               cancelled("None", "TransactionConflict"),
+			expectedRetryable: true,
+		},
+		"Happy Path - Cancelled By Throttling": {
+			err:               cancelled("ThrottlingError", "None"),
+			expectedRetryable: true,
+			expectedThrottled: true,
+		},
+		"Sad Path - Cancelled By Condition Check": {
+			err: cancelled("ConditionalCheckFailed", "TransactionConflict"),
+		},
+		"Sad Path - Conditional Check Failed": {
+			err: &types.ConditionalCheckFailedException{},
+		},
+		"Sad Path - Validation Error": {
+			err: &smithy.GenericAPIError{Code: "ValidationException"},
+		},
+		"Sad Path - Context Cancelled": {
+			err: context.Canceled,
+		},
+		"Sad Path - Nil Error": {},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedRetryable, IsRetryable(tc.err))
+			assert.Equal(t, tc.expectedThrottled, IsThrottling(tc.err))
+		})
+	}
+}
+
+func TestRetryClient(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:       4,
+		BaseDelay:         10 * time.Millisecond,
+		ThrottleBaseDelay: 100 * time.Millisecond,
+		MaxDelay:          250 * time.Millisecond,
+		Budget:            time.Second,
+	}
+
+	tests := map[string]struct {
+		policy        RetryPolicy
+		errs          map[int]error
+		expectedErr   error
+		expectedCalls int
+		expectedSlept []time.Duration
+	}{
+		"Happy Path - No Retry Needed": {
+			policy:        policy,
+			expectedCalls: 1,
+		},
+		"Happy Path - Retries Throttling With Backoff": {
+			policy:        policy,
+			errs:          map[int]error{0: errThrottled, 1: errThrottled, 2: errThrottled},
+			expectedCalls: 4,
+			expectedSlept: []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 250 * time.Millisecond},
+		},
+		"Happy Path - Retries Transient Error": {
+			policy:        policy,
+			errs:          map[int]error{0: errConflict},
+			expectedCalls: 2,
+			expectedSlept: []time.Duration{10 * time.Millisecond},
+		},
+		"Sad Path - Non Retryable Error": {
+			policy:        policy,
+			errs:          map[int]error{0: &types.ConditionalCheckFailedException{}},
+			expectedErr:   &types.ConditionalCheckFailedException{},
+			expectedCalls: 1,
+		},
+		"Sad Path - Attempts Exhausted": {
+			policy:        policy,
+			errs:          map[int]error{0: errConflict, 1: errConflict, 2: errConflict, 3: errConflict},
+			expectedErr:   errConflict,
+			expectedCalls: 4,
+			expectedSlept: []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond},
+		},
+		"Sad Path - Budget Spent": {
+			policy:        RetryPolicy{MaxAttempts: 10, ThrottleBaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, Budget: 350 * time.Millisecond},
+			errs:          map[int]error{0: errThrottled, 1: errThrottled, 2: errThrottled},
+			expectedErr:   errThrottled,
+			expectedCalls: 3,
+			expectedSlept: []time.Duration{100 * time.Millisecond, 200 * time.Millisecond},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			mock := &Mock{PutOutput: &dynamodb.PutItemOutput{}, OnPut: MockMethod[dynamodb.PutItemInput, dynamodb.PutItemOutput]{Errs: tc.errs}}
+			client, slept := newTestRetryClient(mock, tc.policy, zap.NewNop())
+
+			_, err := client.PutItem(context.Background(), &dynamodb.PutItemInput{})
+
+			if tc.expectedErr != nil {
+				assert.Equal(t, tc.expectedErr, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Len(t, mock.OnPut.Calls, tc.expectedCalls)
+			assert.Equal(t, tc.expectedSlept, *slept)
+		})
+	}
+}
+
+func TestRetryClient_UpdateItemRetriesOnlyThrottling(t *testing.T) {
+	tests := map[string]struct {
+		errs          map[int]error
+		expectedErr   error
+		expectedCalls int
+	}{
+		"Happy Path - Retries Throttling": {
+			errs:          map[int]error{0: errThrottled},
+			expectedCalls: 2,
+		},
+		"Sad Path - Internal Server Error Is Not Retried": {
+			errs:          map[int]error{0: &types.InternalServerError{}},
+			expectedErr:   &types.InternalServerError{},
+			expectedCalls: 1,
+		},
+		"Sad Path - Service Unavailable Is Not Retried": {
+			errs:          map[int]error{0: &smithy.GenericAPIError{Code: "ServiceUnavailable"}},
+			expectedErr:   &smithy.GenericAPIError{Code: "ServiceUnavailable"},
+			expectedCalls: 1,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			mock := &Mock{UpdateOutput: &dynamodb.UpdateItemOutput{}, OnUpdate: MockMethod[dynamodb.UpdateItemInput, dynamodb.UpdateItemOutput]{Errs: tc.errs}}
+			client, _ := newTestRetryClient(mock, RetryPolicy{}, zap.NewNop())
+
+			_, err := client.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{})
+
+			if tc.expectedErr != nil {
+				assert.Equal(t, tc.expectedErr, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Len(t, mock.OnUpdate.Calls, tc.expectedCalls)
+		})
+	}
+}
+
+func TestRetryClient_ContextDeadline(t *testing.T) {
+	mock := &Mock{OnQuery: MockMethod[dynamodb.QueryInput, dynamodb.QueryOutput]{Err: errThrottled}}
+	client, slept := newTestRetryClient(mock, RetryPolicy{MaxAttempts: 10, ThrottleBaseDelay: 100 * time.Millisecond, Budget: time.Minute}, zap.NewNop())
+
+	ctx, cancel := context.WithDeadline(context.Background(), client.now().Add(250*time.Millisecond))
+	defer cancel()
+	_, err := client.Query(ctx, &dynamodb.QueryInput{})
+
+	assert.ErrorIs(t, err, errThrottled)
+	assert.Len(t, mock.OnQuery.Calls, 2)
+	assert.Equal(t, []time.Duration{100 * time.Millisecond}, *slept)
+}
+
+func TestRetryClient_ContextCancelledWhileWaiting(t *testing.T) {
+	mock := &Mock{OnGet: MockMethod[dynamodb.GetItemInput, dynamodb.GetItemOutput]{Err: errThrottled}}
+	client := NewRetryClient(mock, RetryPolicy{ThrottleBaseDelay: time.Hour, MaxDelay: time.Hour, Budget: 2 * time.Hour}, zap.NewNop())
+	client.jitter = func() float64 { return 1 }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	_, err := client.GetItem(ctx, &dynamodb.GetItemInput{})
+
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestRetryClient_LogsRetryCount(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	mock := &Mock{TransactOutput: &dynamodb.TransactWriteItemsOutput{}, OnTransact: MockMethod[dynamodb.TransactWriteItemsInput, dynamodb.TransactWriteItemsOutput]{
+		Errs: map[int]error{0: cancelled("TransactionConflict"), 1: cancelled("TransactionConflict")},
+	}}
+	client, _ := newTestRetryClient(mock, RetryPolicy{}, zap.New(core))
+
+	_, err := client.TransactWriteItems(context.Background(), &dynamodb.TransactWriteItemsInput{})
+	assert.NoError(t, err)
+
+	succeeded := logs.FilterMessage("dynamo db operation succeeded after retries").All()
+	if assert.Len(t, succeeded, 1) {
+		fields := succeeded[0].ContextMap()
+		assert.Equal(t, int64(2), fields[log.RetriesLogKey])
+		assert.Equal(t, "TransactWriteItems", fields[log.OperationLogKey])
+	}
+	assert.Equal(t, 2, logs.FilterMessage("retrying dynamo db operation").Len())
+}
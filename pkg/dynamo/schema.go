@@ -0,0 +1,131 @@
+package dynamo
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type KeyAttribute struct {
+	Name string
+	Type types.ScalarAttributeType
+}
+
+type IndexSchema struct {
+	Name         string
+	PartitionKey KeyAttribute
+	SortKey      KeyAttribute
+}
+
+// TableSchema describes a table. TTLAttribute, when set, names the number
+// attribute holding the epoch second an item expires at, which EnsureTables
+// turns time to live on for.
+type TableSchema struct {
+	Name         string
+	PartitionKey KeyAttribute
+	SortKey      KeyAttribute
+	Indexes      []IndexSchema
+	TTLAttribute string
+}
+
+func StringKey(name string) KeyAttribute {
+	return KeyAttribute{Name: name, Type: types.ScalarAttributeTypeS}
+}
+
+func NumberKey(name string) KeyAttribute {
+	return KeyAttribute{Name: name, Type: types.ScalarAttributeTypeN}
+}
+
+// CreateTableInput describes the table with on-demand billing and indexes
+// that project every attribute.
+func (s TableSchema) CreateTableInput() *dynamodb.CreateTableInput {
+	input := &dynamodb.CreateTableInput{
+		TableName:            aws.String(s.Name),
+		AttributeDefinitions: s.attributeDefinitions(),
+		KeySchema:            keySchema(s.PartitionKey, s.SortKey),
+		BillingMode:          types.BillingModePayPerRequest,
+	}
+	for _, index := range s.Indexes {
+		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, index.globalSecondaryIndex())
+	}
+	return input
+}
+
+func (s TableSchema) index(name string) (IndexSchema, bool) {
+	for _, index := range s.Indexes {
+		if index.Name == name {
+			return index, true
+		}
+	}
+	return IndexSchema{}, false
+}
+
+func (s TableSchema) attributeDefinitions() []types.AttributeDefinition {
+	attrs := []KeyAttribute{s.PartitionKey, s.SortKey}
+	for _, index := range s.Indexes {
+		attrs = append(attrs, index.PartitionKey, index.SortKey)
+	}
+
+	var definitions []types.AttributeDefinition
+	seen := map[string]bool{}
+	for _, attr := range attrs {
+		if attr.Name == "" || seen[attr.Name] {
+			continue
+		}
+		seen[attr.Name] = true
+		definitions = append(definitions, types.AttributeDefinition{
+			AttributeName: aws.String(attr.Name),
+			AttributeType: attr.Type,
+		})
+	}
+	return definitions
+}
+
+func (i IndexSchema) globalSecondaryIndex() types.GlobalSecondaryIndex {
+	return types.GlobalSecondaryIndex{
+		IndexName:  aws.String(i.Name),
+		KeySchema:  keySchema(i.PartitionKey, i.SortKey),
+		Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+	}
+}
+
+func keySchema(partitionKey, sortKey KeyAttribute) []types.KeySchemaElement {
+	elements := []types.KeySchemaElement{
+		{AttributeName: aws.String(partitionKey.Name), KeyType: types.KeyTypeHash},
+	}
+	if sortKey.Name != "" {
+		elements = append(elements, types.KeySchemaElement{AttributeName: aws.String(sortKey.Name), KeyType: types.KeyTypeRange})
+	}
+	return elements
+}
+
+// schemaFromDescription rebuilds a TableSchema from the key schemas and
+// attribute definitions DynamoDB reports for a table.
+func schemaFromDescription(name string, keys []types.KeySchemaElement, definitions []types.AttributeDefinition, indexes []types.GlobalSecondaryIndex) TableSchema {
+	attrTypes := map[string]types.ScalarAttributeType{}
+	for _, d := range definitions {
+		attrTypes[aws.ToString(d.AttributeName)] = d.AttributeType
+	}
+
+	keyAttributes := func(elements []types.KeySchemaElement) (KeyAttribute, KeyAttribute) {
+		var pk, sk KeyAttribute
+		for _, e := range elements {
+			attr := KeyAttribute{Name: aws.ToString(e.AttributeName), Type: attrTypes[aws.ToString(e.AttributeName)]}
+			if e.KeyType == types.KeyTypeRange {
+				sk = attr
+			} else {
+				pk = attr
+			}
+		}
+		return pk, sk
+	}
+
+	schema := TableSchema{Name: name}
+	schema.PartitionKey, schema.SortKey = keyAttributes(keys)
+	for _, gsi := range indexes {
+		index := IndexSchema{Name: aws.ToString(gsi.IndexName)}
+		index.PartitionKey, index.SortKey = keyAttributes(gsi.KeySchema)
+		schema.Indexes = append(schema.Indexes, index)
+	}
+	return schema
+}
@@ -0,0 +1,182 @@
+package dynamo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/log"
+	"go.uber.org/zap"
+)
+
+var (
+	tableWaitInterval = 500 * time.Millisecond
+	tableWaitTimeout  = 2 * time.Minute
+)
+
+type TableAdminProvider interface {
+	CreateTable(ctx context.Context, params *dynamodb.CreateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error)
+	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+	UpdateTable(ctx context.Context, params *dynamodb.UpdateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateTableOutput, error)
+	DescribeTimeToLive(ctx context.Context, params *dynamodb.DescribeTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error)
+	UpdateTimeToLive(ctx context.Context, params *dynamodb.UpdateTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateTimeToLiveOutput, error)
+}
+
+// EnsureTables creates every table that does not exist, adds any global
+// secondary index missing from an existing table and waits until the tables
+// and their indexes are ACTIVE. Time to live is turned on for tables with a
+// TTL attribute. Existing key schemas are never changed.
+func EnsureTables(ctx context.Context, client TableAdminProvider, logger *zap.Logger, schemas ...TableSchema) error {
+	for _, schema := range schemas {
+		if err := ensureTable(ctx, client, logger.With(zap.String(log.TableNameLogKey, schema.Name)), schema); err != nil {
+			return fmt.Errorf("ensuring table %s: %w", schema.Name, err)
+		}
+	}
+	return nil
+}
+
+func ensureTable(ctx context.Context, client TableAdminProvider, logger *zap.Logger, schema TableSchema) error {
+	description, err := describeTable(ctx, client, schema.Name)
+	if err != nil {
+		return err
+	}
+
+	if description == nil {
+		logger.Info("creating table")
+		if _, err := client.CreateTable(ctx, schema.CreateTableInput()); err != nil {
+			var inUse *types.ResourceInUseException
+			if !errors.As(err, &inUse) {
+				return err
+			}
+		}
+		if _, err := waitForTable(ctx, client, schema.Name); err != nil {
+			return err
+		}
+		return ensureTimeToLive(ctx, client, logger, schema)
+	}
+
+	if description.TableStatus != types.TableStatusActive {
+		if description, err = waitForTable(ctx, client, schema.Name); err != nil {
+			return err
+		}
+	}
+
+	existing := map[string]bool{}
+	for _, gsi := range description.GlobalSecondaryIndexes {
+		existing[aws.ToString(gsi.IndexName)] = true
+	}
+
+	// DynamoDB allows one index creation per UpdateTable call, and the table
+	// must be ACTIVE again before the next one.
+	for _, index := range schema.Indexes {
+		if existing[index.Name] {
+			continue
+		}
+
+		logger.Info("creating index", zap.String(log.IndexNameLogKey, index.Name))
+		gsi := index.globalSecondaryIndex()
+		_, err := client.UpdateTable(ctx, &dynamodb.UpdateTableInput{
+			TableName: aws.String(schema.Name),
+			AttributeDefinitions: TableSchema{
+				PartitionKey: index.PartitionKey,
+				SortKey:      index.SortKey,
+			}.attributeDefinitions(),
+			GlobalSecondaryIndexUpdates: []types.GlobalSecondaryIndexUpdate{
+				{
+					Create: &types.CreateGlobalSecondaryIndexAction{
+						IndexName:  gsi.IndexName,
+						KeySchema:  gsi.KeySchema,
+						Projection: gsi.Projection,
+					},
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := waitForTable(ctx, client, schema.Name); err != nil {
+			return err
+		}
+	}
+
+	return ensureTimeToLive(ctx, client, logger, schema)
+}
+
+// ensureTimeToLive turns time to live on for the TTL attribute of schema
+// unless it already is.
+func ensureTimeToLive(ctx context.Context, client TableAdminProvider, logger *zap.Logger, schema TableSchema) error {
+	if schema.TTLAttribute == "" {
+		return nil
+	}
+
+	out, err := client.DescribeTimeToLive(ctx, &dynamodb.DescribeTimeToLiveInput{TableName: aws.String(schema.Name)})
+	if err != nil {
+		return err
+	}
+	if d := out.TimeToLiveDescription; d != nil && aws.ToString(d.AttributeName) == schema.TTLAttribute {
+		switch d.TimeToLiveStatus {
+		case types.TimeToLiveStatusEnabled, types.TimeToLiveStatusEnabling:
+			return nil
+		}
+	}
+
+	logger.Info("enabling time to live", zap.String("attribute", schema.TTLAttribute))
+	_, err = client.UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String(schema.Name),
+		TimeToLiveSpecification: &types.TimeToLiveSpecification{
+			AttributeName: aws.String(schema.TTLAttribute),
+			Enabled:       aws.Bool(true),
+		},
+	})
+	return err
+}
+
+// describeTable returns nil when the table does not exist.
+func describeTable(ctx context.Context, client TableAdminProvider, name string) (*types.TableDescription, error) {
+	out, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(name)})
+	if err != nil {
+		var notFound *types.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return out.Table, nil
+}
+
+func waitForTable(ctx context.Context, client TableAdminProvider, name string) (*types.TableDescription, error) {
+	ctx, cancel := context.WithTimeout(ctx, tableWaitTimeout)
+	defer cancel()
+
+	for {
+		description, err := describeTable(ctx, client, name)
+		if err != nil {
+			return nil, err
+		}
+		if description != nil && tableActive(description) {
+			return description, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for table to become active: %w", ctx.Err())
+		case <-time.After(tableWaitInterval):
+		}
+	}
+}
+
+func tableActive(description *types.TableDescription) bool {
+	if description.TableStatus != types.TableStatusActive {
+		return false
+	}
+	for _, gsi := range description.GlobalSecondaryIndexes {
+		if gsi.IndexStatus != types.IndexStatusActive {
+			return false
+		}
+	}
+	return true
+}
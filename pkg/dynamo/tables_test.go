@@ -0,0 +1,182 @@
+package dynamo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// slowTables reports a table as CREATING for the first pending describes,
+// like DynamoDB does while a table or index is being built.
+type slowTables struct {
+	*Emulator
+	pending   int
+	describes int
+	err       error
+}
+
+func (s *slowTables) DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	s.describes++
+	if s.err != nil {
+		return nil, s.err
+	}
+	out, err := s.Emulator.DescribeTable(ctx, params, optFns...)
+	if err != nil {
+		return nil, err
+	}
+	if s.pending > 0 {
+		s.pending--
+		out.Table.TableStatus = types.TableStatusCreating
+	}
+	return out, nil
+}
+
+func withTableWait(t *testing.T, interval, timeout time.Duration) {
+	oldInterval, oldTimeout := tableWaitInterval, tableWaitTimeout
+	tableWaitInterval, tableWaitTimeout = interval, timeout
+	t.Cleanup(func() {
+		tableWaitInterval, tableWaitTimeout = oldInterval, oldTimeout
+	})
+}
+
+func indexNames(t *testing.T, e *Emulator, tableName string) []string {
+	out, err := e.DescribeTable(context.Background(), &dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+	if !assert.NoError(t, err) {
+		return nil
+	}
+	names := []string{}
+	for _, gsi := range out.Table.GlobalSecondaryIndexes {
+		names = append(names, aws.ToString(gsi.IndexName))
+	}
+	return names
+}
+
+func TestEnsureTables(t *testing.T) {
+	withTableWait(t, time.Millisecond, 100*time.Millisecond)
+
+	withoutFlagged := testEventSchema
+	withoutFlagged.Indexes = testEventSchema.Indexes[:1]
+
+	tests := map[string]struct {
+		existing        []TableSchema
+		pending         int
+		describeErr     error
+		expectedIndexes []string
+		expectedErr     bool
+	}{
+		"Happy Path - Creates Missing Table": {
+			expectedIndexes: []string{"receiver-start-time", "flagged"},
+		},
+		"Happy Path - Adds Missing Index": {
+			existing:        []TableSchema{withoutFlagged},
+			expectedIndexes: []string{"receiver-start-time", "flagged"},
+		},
+		"Happy Path - Existing Table Is Unchanged": {
+			existing:        []TableSchema{testEventSchema},
+			expectedIndexes: []string{"receiver-start-time", "flagged"},
+		},
+		"Happy Path - Waits For Active Table": {
+			pending:         3,
+			expectedIndexes: []string{"receiver-start-time", "flagged"},
+		},
+		"Sad Path - Describe Fails": {
+			describeErr: errors.New("an error occurred"),
+			expectedErr: true,
+		},
+		"Sad Path - Table Never Becomes Active": {
+			pending:     1 << 20,
+			expectedErr: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := NewEmulator(tc.existing...)
+			client := &slowTables{Emulator: e, pending: tc.pending, err: tc.describeErr}
+
+			err := EnsureTables(context.Background(), client, zap.NewNop(), testEventSchema)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedIndexes, indexNames(t, e, testEventSchema.Name))
+			if tc.pending > 0 {
+				assert.Greater(t, client.describes, tc.pending)
+			}
+		})
+	}
+}
+
+func TestEnsureTables_IndexesExistingItems(t *testing.T) {
+	withoutFlagged := testEventSchema
+	withoutFlagged.Indexes = testEventSchema.Indexes[:1]
+	e := NewEmulator(withoutFlagged)
+	item := testEvent("Receiver#1", "Event#1", "2025-01-01T00:00:00Z")
+	item["flagged_gsi_pk"] = &types.AttributeValueMemberN{Value: "1"}
+	_, err := e.PutItem(context.Background(), &dynamodb.PutItemInput{TableName: aws.String(testEventSchema.Name), Item: item})
+	assert.NoError(t, err)
+
+	assert.NoError(t, EnsureTables(context.Background(), e, zap.NewNop(), testEventSchema))
+
+	out, err := e.Query(context.Background(), &dynamodb.QueryInput{
+		TableName:                 aws.String(testEventSchema.Name),
+		IndexName:                 aws.String("flagged"),
+		KeyConditionExpression:    aws.String("flagged_gsi_pk = :one"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":one": &types.AttributeValueMemberN{Value: "1"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Event#1"}, eventIDs(out.Items))
+}
+
+func TestEmulator_TableAdmin(t *testing.T) {
+	ctx := context.Background()
+	e := NewEmulator(testEventSchema)
+
+	_, err := e.CreateTable(ctx, testEventSchema.CreateTableInput())
+	var inUse *types.ResourceInUseException
+	assert.ErrorAs(t, err, &inUse)
+
+	_, err = e.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String("missing")})
+	var notFound *types.ResourceNotFoundException
+	assert.ErrorAs(t, err, &notFound)
+
+	_, err = e.UpdateTable(ctx, &dynamodb.UpdateTableInput{
+		TableName: aws.String(testEventSchema.Name),
+		GlobalSecondaryIndexUpdates: []types.GlobalSecondaryIndexUpdate{
+			{Create: &types.CreateGlobalSecondaryIndexAction{
+				IndexName: aws.String("flagged"),
+				KeySchema: []types.KeySchemaElement{{AttributeName: aws.String("flagged_gsi_pk"), KeyType: types.KeyTypeHash}},
+			}},
+		},
+	})
+	assert.Equal(t, "ValidationException", errorCode(err))
+}
+
+func TestEnsureTables_TimeToLive(t *testing.T) {
+	ctx := context.Background()
+	withTTL := testEventSchema
+	withTTL.TTLAttribute = "expires_at"
+	e := NewEmulator(testEventSchema)
+
+	assert.NoError(t, EnsureTables(ctx, e, zap.NewNop(), withTTL))
+	assert.NoError(t, EnsureTables(ctx, e, zap.NewNop(), withTTL))
+
+	out, err := e.DescribeTimeToLive(ctx, &dynamodb.DescribeTimeToLiveInput{TableName: aws.String(withTTL.Name)})
+	assert.NoError(t, err)
+	assert.Equal(t, "expires_at", aws.ToString(out.TimeToLiveDescription.AttributeName))
+	assert.Equal(t, types.TimeToLiveStatusEnabled, out.TimeToLiveDescription.TimeToLiveStatus)
+
+	created := NewEmulator()
+	assert.NoError(t, EnsureTables(ctx, created, zap.NewNop(), withTTL))
+	out, err = created.DescribeTimeToLive(ctx, &dynamodb.DescribeTimeToLiveInput{TableName: aws.String(withTTL.Name)})
+	assert.NoError(t, err)
+	assert.Equal(t, "expires_at", aws.ToString(out.TimeToLiveDescription.AttributeName))
+}
@@ -0,0 +1,184 @@
+package dynamo
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	TracerName = "github.com/care-giver-app/care-giver-golang-common/pkg/dynamo"
+
+	unprocessedItemsKey = "unprocessed item count"
+)
+
+// TracingClient is a DynamodbClientProvider that wraps every operation of
+// the client it wraps in a client span named after the operation.
+type TracingClient struct {
+	Client DynamodbClientProvider
+	tracer trace.Tracer
+}
+
+// NewTracingClient creates spans with tp, or with the global tracer provider
+// when tp is nil.
+func NewTracingClient(client DynamodbClientProvider, tp trace.TracerProvider) *TracingClient {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return &TracingClient{
+		Client: client,
+		tracer: tp.Tracer(TracerName),
+	}
+}
+
+func (c *TracingClient) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	return traced(ctx, c, "PutItem", tableNames(params.TableName), func(ctx context.Context) (*dynamodb.PutItemOutput, error) {
+		return c.Client.PutItem(ctx, params, optFns...)
+	}, nil)
+}
+
+func (c *TracingClient) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return traced(ctx, c, "GetItem", tableNames(params.TableName), func(ctx context.Context) (*dynamodb.GetItemOutput, error) {
+		return c.Client.GetItem(ctx, params, optFns...)
+	}, func(out *dynamodb.GetItemOutput) []attribute.KeyValue {
+		found := 0
+		if out.Item != nil {
+			found = 1
+		}
+		return []attribute.KeyValue{semconv.AWSDynamoDBCount(found)}
+	})
+}
+
+func (c *TracingClient) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	return traced(ctx, c, "UpdateItem", tableNames(params.TableName), func(ctx context.Context) (*dynamodb.UpdateItemOutput, error) {
+		return c.Client.UpdateItem(ctx, params, optFns...)
+	}, nil)
+}
+
+func (c *TracingClient) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	attrs := tableNames(params.TableName)
+	if params.IndexName != nil {
+		attrs = append(attrs, semconv.AWSDynamoDBIndexName(*params.IndexName))
+	}
+	return traced(ctx, c, "Query", attrs, func(ctx context.Context) (*dynamodb.QueryOutput, error) {
+		return c.Client.Query(ctx, params, optFns...)
+	}, func(out *dynamodb.QueryOutput) []attribute.KeyValue {
+		return []attribute.KeyValue{
+			semconv.AWSDynamoDBCount(int(out.Count)),
+			semconv.AWSDynamoDBScannedCount(int(out.ScannedCount)),
+		}
+	})
+}
+
+func (c *TracingClient) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	attrs := tableNames(params.TableName)
+	if params.IndexName != nil {
+		attrs = append(attrs, semconv.AWSDynamoDBIndexName(*params.IndexName))
+	}
+	return traced(ctx, c, "Scan", attrs, func(ctx context.Context) (*dynamodb.ScanOutput, error) {
+		return c.Client.Scan(ctx, params, optFns...)
+	}, func(out *dynamodb.ScanOutput) []attribute.KeyValue {
+		return []attribute.KeyValue{
+			semconv.AWSDynamoDBCount(int(out.Count)),
+			semconv.AWSDynamoDBScannedCount(int(out.ScannedCount)),
+		}
+	})
+}
+
+func (c *TracingClient) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	return traced(ctx, c, "DeleteItem", tableNames(params.TableName), func(ctx context.Context) (*dynamodb.DeleteItemOutput, error) {
+		return c.Client.DeleteItem(ctx, params, optFns...)
+	}, nil)
+}
+
+func (c *TracingClient) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	var tables []string
+	count := 0
+	for name, requests := range params.RequestItems {
+		tables = append(tables, name)
+		count += len(requests)
+	}
+	attrs := []attribute.KeyValue{
+		semconv.AWSDynamoDBTableNames(uniqueTables(tables)...),
+		attribute.Int(log.ItemCountLogKey, count),
+	}
+	return traced(ctx, c, "BatchWriteItem", attrs, func(ctx context.Context) (*dynamodb.BatchWriteItemOutput, error) {
+		return c.Client.BatchWriteItem(ctx, params, optFns...)
+	}, func(out *dynamodb.BatchWriteItemOutput) []attribute.KeyValue {
+		unprocessed := 0
+		for _, requests := range out.UnprocessedItems {
+			unprocessed += len(requests)
+		}
+		return []attribute.KeyValue{attribute.Int(unprocessedItemsKey, unprocessed)}
+	})
+}
+
+func (c *TracingClient) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	var tables []string
+	count := 0
+	for name, keys := range params.RequestItems {
+		tables = append(tables, name)
+		count += len(keys.Keys)
+	}
+	attrs := []attribute.KeyValue{
+		semconv.AWSDynamoDBTableNames(uniqueTables(tables)...),
+		attribute.Int(log.ItemCountLogKey, count),
+	}
+	return traced(ctx, c, "BatchGetItem", attrs, func(ctx context.Context) (*dynamodb.BatchGetItemOutput, error) {
+		return c.Client.BatchGetItem(ctx, params, optFns...)
+	}, func(out *dynamodb.BatchGetItemOutput) []attribute.KeyValue {
+		found, unprocessed := 0, 0
+		for _, items := range out.Responses {
+			found += len(items)
+		}
+		for _, keys := range out.UnprocessedKeys {
+			unprocessed += len(keys.Keys)
+		}
+		return []attribute.KeyValue{semconv.AWSDynamoDBCount(found), attribute.Int(unprocessedItemsKey, unprocessed)}
+	})
+}
+
+func (c *TracingClient) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	var tables []string
+	for _, item := range params.TransactItems {
+		tables = append(tables, transactTable(item))
+	}
+	attrs := []attribute.KeyValue{
+		semconv.AWSDynamoDBTableNames(uniqueTables(tables)...),
+		attribute.Int(log.ItemCountLogKey, len(params.TransactItems)),
+	}
+	return traced(ctx, c, "TransactWriteItems", attrs, func(ctx context.Context) (*dynamodb.TransactWriteItemsOutput, error) {
+		return c.Client.TransactWriteItems(ctx, params, optFns...)
+	}, nil)
+}
+
+func traced[Out any](ctx context.Context, c *TracingClient, operation string, attrs []attribute.KeyValue, call func(ctx context.Context) (*Out, error), result func(*Out) []attribute.KeyValue) (*Out, error) {
+	ctx, span := c.tracer.Start(ctx, "DynamoDB."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemDynamoDB, semconv.DBOperationName(operation)),
+		trace.WithAttributes(attrs...),
+	)
+	defer span.End()
+
+	out, err := call(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, ErrorType(err))
+		return out, err
+	}
+	if out != nil && result != nil {
+		span.SetAttributes(result(out)...)
+	}
+	return out, nil
+}
+
+func tableNames(name *string) []attribute.KeyValue {
+	return []attribute.KeyValue{semconv.AWSDynamoDBTableNames(aws.ToString(name))}
+}
@@ -0,0 +1,106 @@
+package dynamo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestTracingClient(t *testing.T) {
+	tests := map[string]struct {
+		call               func(c *TracingClient) error
+		expectedName       string
+		expectedAttributes map[attribute.Key]attribute.Value
+		expectedStatus     codes.Code
+	}{
+		"Happy Path - Query Records Index And Count": {
+			call: func(c *TracingClient) error {
+				_, err := c.Query(context.Background(), &dynamodb.QueryInput{
+					TableName:                 aws.String("event-table"),
+					IndexName:                 aws.String("flagged"),
+					KeyConditionExpression:    aws.String("flagged_gsi_pk = :one"),
+					ExpressionAttributeValues: map[string]types.AttributeValue{":one": &types.AttributeValueMemberN{Value: "1"}},
+				})
+				return err
+			},
+			expectedName: "DynamoDB.Query",
+			expectedAttributes: map[attribute.Key]attribute.Value{
+				"db.system":                attribute.StringValue("dynamodb"),
+				"db.operation.name":        attribute.StringValue("Query"),
+				"aws.dynamodb.table_names": attribute.StringSliceValue([]string{"event-table"}),
+				"aws.dynamodb.index_name":  attribute.StringValue("flagged"),
+				"aws.dynamodb.count":       attribute.IntValue(3),
+			},
+			expectedStatus: codes.Unset,
+		},
+		"Happy Path - Batch Write Records Item Count": {
+			call: func(c *TracingClient) error {
+				_, err := c.BatchWriteItem(context.Background(), &dynamodb.BatchWriteItemInput{RequestItems: map[string][]types.WriteRequest{
+					"event-table": {
+						{PutRequest: &types.PutRequest{Item: testEvent("Receiver#3", "Event#1", "2025-01-01T00:00:00Z")}},
+						{PutRequest: &types.PutRequest{Item: testEvent("Receiver#3", "Event#2", "2025-01-01T00:00:00Z")}},
+					},
+				}})
+				return err
+			},
+			expectedName: "DynamoDB.BatchWriteItem",
+			expectedAttributes: map[attribute.Key]attribute.Value{
+				"aws.dynamodb.table_names": attribute.StringSliceValue([]string{"event-table"}),
+				"item count":               attribute.IntValue(2),
+				unprocessedItemsKey:        attribute.IntValue(0),
+			},
+			expectedStatus: codes.Unset,
+		},
+		"Sad Path - Error Sets Status": {
+			call: func(c *TracingClient) error {
+				_, err := c.GetItem(context.Background(), &dynamodb.GetItemInput{TableName: aws.String("missing-table")})
+				return err
+			},
+			expectedName: "DynamoDB.GetItem",
+			expectedAttributes: map[attribute.Key]attribute.Value{
+				"aws.dynamodb.table_names": attribute.StringSliceValue([]string{"missing-table"}),
+			},
+			expectedStatus: codes.Error,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			client := NewTracingClient(seededEmulator(t), sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+			err := tc.call(client)
+
+			if tc.expectedStatus == codes.Error {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			spans := recorder.Ended()
+			if !assert.Len(t, spans, 1) {
+				return
+			}
+			assert.Equal(t, tc.expectedName, spans[0].Name())
+			assert.Equal(t, tc.expectedStatus, spans[0].Status().Code)
+			attrs := spanAttributes(spans[0])
+			for k, v := range tc.expectedAttributes {
+				assert.Equal(t, v, attrs[k], k)
+			}
+		})
+	}
+}
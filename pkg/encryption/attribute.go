@@ -0,0 +1,179 @@
+package encryption
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// wireValue is the DynamoDB JSON form of an attribute value. Encrypting the
+// wire form rather than the Go value keeps every attribute type, including
+// number precision and sets, intact through a round trip.
+type wireValue struct {
+	S    *string              `json:"S,omitempty"`
+	N    *string              `json:"N,omitempty"`
+	B    []byte               `json:"B,omitempty"`
+	BOOL *bool                `json:"BOOL,omitempty"`
+	NULL bool                 `json:"NULL,omitempty"`
+	L    []wireValue          `json:"L,omitempty"`
+	M    map[string]wireValue `json:"M,omitempty"`
+	SS   []string             `json:"SS,omitempty"`
+	NS   []string             `json:"NS,omitempty"`
+	BS   [][]byte             `json:"BS,omitempty"`
+	Kind string               `json:"k"`
+}
+
+func marshalAttribute(av types.AttributeValue) ([]byte, error) {
+	w, err := toWire(av)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(w)
+}
+
+func unmarshalAttribute(data []byte) (types.AttributeValue, error) {
+	var w wireValue
+	if err := json.Unmarshal(data, &w); err != nil {
+		return nil, err
+	}
+	return fromWire(w)
+}
+
+func toWire(av types.AttributeValue) (wireValue, error) {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return wireValue{Kind: "S", S: &v.Value}, nil
+	case *types.AttributeValueMemberN:
+		return wireValue{Kind: "N", N: &v.Value}, nil
+	case *types.AttributeValueMemberB:
+		return wireValue{Kind: "B", B: v.Value}, nil
+	case *types.AttributeValueMemberBOOL:
+		return wireValue{Kind: "BOOL", BOOL: &v.Value}, nil
+	case *types.AttributeValueMemberNULL:
+		return wireValue{Kind: "NULL", NULL: v.Value}, nil
+	case *types.AttributeValueMemberSS:
+		return wireValue{Kind: "SS", SS: v.Value}, nil
+	case *types.AttributeValueMemberNS:
+		return wireValue{Kind: "NS", NS: v.Value}, nil
+	case *types.AttributeValueMemberBS:
+		return wireValue{Kind: "BS", BS: v.Value}, nil
+	case *types.AttributeValueMemberL:
+		l := make([]wireValue, 0, len(v.Value))
+		for _, item := range v.Value {
+			w, err := toWire(item)
+			if err != nil {
+				return wireValue{}, err
+			}
+			l = append(l, w)
+		}
+		return wireValue{Kind: "L", L: l}, nil
+	case *types.AttributeValueMemberM:
+		m := make(map[string]wireValue, len(v.Value))
+		for k, item := range v.Value {
+			w, err := toWire(item)
+			if err != nil {
+				return wireValue{}, err
+			}
+			m[k] = w
+		}
+		return wireValue{Kind: "M", M: m}, nil
+	}
+	return wireValue{}, fmt.Errorf("unsupported attribute value %T", av)
+}
+
+func fromWire(w wireValue) (types.AttributeValue, error) {
+	switch w.Kind {
+	case "S":
+		return &types.AttributeValueMemberS{Value: deref(w.S)}, nil
+	case "N":
+		return &types.AttributeValueMemberN{Value: deref(w.N)}, nil
+	case "B":
+		return &types.AttributeValueMemberB{Value: w.B}, nil
+	case "BOOL":
+		return &types.AttributeValueMemberBOOL{Value: w.BOOL != nil && *w.BOOL}, nil
+	case "NULL":
+		return &types.AttributeValueMemberNULL{Value: w.NULL}, nil
+	case "SS":
+		return &types.AttributeValueMemberSS{Value: w.SS}, nil
+	case "NS":
+		return &types.AttributeValueMemberNS{Value: w.NS}, nil
+	case "BS":
+		return &types.AttributeValueMemberBS{Value: w.BS}, nil
+	case "L":
+		l := make([]types.AttributeValue, 0, len(w.L))
+		for _, item := range w.L {
+			av, err := fromWire(item)
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, av)
+		}
+		return &types.AttributeValueMemberL{Value: l}, nil
+	case "M":
+		m := make(map[string]types.AttributeValue, len(w.M))
+		for k, item := range w.M {
+			av, err := fromWire(item)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = av
+		}
+		return &types.AttributeValueMemberM{Value: m}, nil
+	}
+	return nil, fmt.Errorf("unsupported attribute kind %q", w.Kind)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func (env Envelope) attributeValue() types.AttributeValue {
+	return &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+		"v":   &types.AttributeValueMemberN{Value: strconv.Itoa(env.Version)},
+		"kid": &types.AttributeValueMemberS{Value: env.KeyID},
+		"ek":  &types.AttributeValueMemberB{Value: env.EncryptedKey},
+		"n":   &types.AttributeValueMemberB{Value: env.Nonce},
+		"ct":  &types.AttributeValueMemberB{Value: env.Ciphertext},
+	}}
+}
+
+// envelopeFromAttribute reports whether av holds an envelope, which is a map
+// with exactly the envelope's attributes.
+func envelopeFromAttribute(av types.AttributeValue) (Envelope, bool) {
+	m, ok := av.(*types.AttributeValueMemberM)
+	if !ok || len(m.Value) != 5 {
+		return Envelope{}, false
+	}
+
+	version, ok := m.Value["v"].(*types.AttributeValueMemberN)
+	if !ok {
+		return Envelope{}, false
+	}
+	kid, ok := m.Value["kid"].(*types.AttributeValueMemberS)
+	if !ok {
+		return Envelope{}, false
+	}
+	ek, ok := m.Value["ek"].(*types.AttributeValueMemberB)
+	if !ok {
+		return Envelope{}, false
+	}
+	nonce, ok := m.Value["n"].(*types.AttributeValueMemberB)
+	if !ok {
+		return Envelope{}, false
+	}
+	ct, ok := m.Value["ct"].(*types.AttributeValueMemberB)
+	if !ok {
+		return Envelope{}, false
+	}
+
+	v, err := strconv.Atoi(version.Value)
+	if err != nil {
+		return Envelope{}, false
+	}
+	return Envelope{Version: v, KeyID: kid.Value, EncryptedKey: ek.Value, Nonce: nonce.Value, Ciphertext: ct.Value}, true
+}
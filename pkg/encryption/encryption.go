@@ -0,0 +1,314 @@
+// Package encryption implements client-side envelope encryption of item
+// attributes. Values are encrypted with data keys generated by a
+// KeyProvider; each data key is stored wrapped by the provider's master key,
+// together with the master key's ID, next to the ciphertext so items written
+// before a key rotation can still be decrypted.
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	// EnvelopeVersion is written with every envelope so the format can change
+	// without breaking reads of older items.
+	EnvelopeVersion = 1
+
+	// Tag marks a struct field whose attribute is encrypted, e.g.
+	// `dynamodbav:"note" encrypted:"true"`.
+	Tag = "encrypted"
+
+	dataKeySize      = 32
+	maxCachedKeys    = 1000
+	defaultKeyMaxAge = 5 * time.Minute
+)
+
+var (
+	ErrUnknownKey      = errors.New("unknown master key")
+	ErrInvalidEnvelope = errors.New("invalid envelope")
+	ErrUnsupported     = errors.New("unsupported envelope version")
+)
+
+// DataKey is a key used to encrypt items, in plaintext and wrapped by the
+// master key named by KeyID.
+type DataKey struct {
+	KeyID     string
+	Plaintext []byte
+	Encrypted []byte
+}
+
+// KeyProvider generates data keys under its current master key and unwraps
+// data keys generated under any master key it has held.
+type KeyProvider interface {
+	GenerateDataKey(ctx context.Context) (DataKey, error)
+	DecryptDataKey(ctx context.Context, keyID string, encrypted []byte) ([]byte, error)
+}
+
+// Envelope is an encrypted value. It is stored in DynamoDB as a map with the
+// attributes v, kid, ek, n and ct.
+type Envelope struct {
+	Version      int
+	KeyID        string
+	EncryptedKey []byte
+	Nonce        []byte
+	Ciphertext   []byte
+}
+
+// Encryptor encrypts values under data keys from Provider. A generated data
+// key is reused for KeyMaxAge to bound the number of provider calls, and
+// unwrapped data keys are cached for reads.
+type Encryptor struct {
+	Provider  KeyProvider
+	KeyMaxAge time.Duration
+
+	now func() time.Time
+
+	mu        sync.Mutex
+	current   *DataKey
+	generated time.Time
+	unwrapped map[string][]byte
+}
+
+func NewEncryptor(provider KeyProvider) *Encryptor {
+	return &Encryptor{
+		Provider:  provider,
+		KeyMaxAge: defaultKeyMaxAge,
+		now:       time.Now,
+		unwrapped: map[string][]byte{},
+	}
+}
+
+// Encrypt seals plaintext, binding it to aad so the envelope cannot be moved
+// to another item or attribute.
+func (e *Encryptor) Encrypt(ctx context.Context, plaintext, aad []byte) (Envelope, error) {
+	key, err := e.dataKey(ctx)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	gcm, err := newGCM(key.Plaintext)
+	if err != nil {
+		return Envelope{}, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return Envelope{}, err
+	}
+
+	return Envelope{
+		Version:      EnvelopeVersion,
+		KeyID:        key.KeyID,
+		EncryptedKey: key.Encrypted,
+		Nonce:        nonce,
+		Ciphertext:   gcm.Seal(nil, nonce, plaintext, aad),
+	}, nil
+}
+
+func (e *Encryptor) Decrypt(ctx context.Context, env Envelope, aad []byte) ([]byte, error) {
+	if env.Version != EnvelopeVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupported, env.Version)
+	}
+
+	key, err := e.unwrap(ctx, env.KeyID, env.EncryptedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("%w: nonce size %d", ErrInvalidEnvelope, len(env.Nonce))
+	}
+	plaintext, err := gcm.Open(nil, env.Nonce, env.Ciphertext, aad)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidEnvelope, err)
+	}
+	return plaintext, nil
+}
+
+// EncryptAttributes replaces each of the named attributes of item with an
+// envelope. itemID is bound to every envelope along with the attribute name.
+func (e *Encryptor) EncryptAttributes(ctx context.Context, item map[string]types.AttributeValue, itemID string, names []string) error {
+	for _, name := range names {
+		av, ok := item[name]
+		if !ok {
+			continue
+		}
+
+		plaintext, err := marshalAttribute(av)
+		if err != nil {
+			return fmt.Errorf("encrypting %s: %w", name, err)
+		}
+		env, err := e.Encrypt(ctx, plaintext, additionalData(itemID, name))
+		if err != nil {
+			return fmt.Errorf("encrypting %s: %w", name, err)
+		}
+		item[name] = env.attributeValue()
+	}
+	return nil
+}
+
+// DecryptAttributes replaces each of the named attributes of item that holds
+// an envelope with the value it encrypts. Attributes written before
+// encryption was enabled are left as they are.
+func (e *Encryptor) DecryptAttributes(ctx context.Context, item map[string]types.AttributeValue, itemID string, names []string) error {
+	for _, name := range names {
+		env, ok := envelopeFromAttribute(item[name])
+		if !ok {
+			continue
+		}
+
+		plaintext, err := e.Decrypt(ctx, env, additionalData(itemID, name))
+		if err != nil {
+			return fmt.Errorf("decrypting %s: %w", name, err)
+		}
+		av, err := unmarshalAttribute(plaintext)
+		if err != nil {
+			return fmt.Errorf("decrypting %s: %w", name, err)
+		}
+		item[name] = av
+	}
+	return nil
+}
+
+// KeyIDs returns the IDs of the master keys the named attributes of item are
+// encrypted under, so items still under a retired key can be found and
+// rewritten.
+func KeyIDs(item map[string]types.AttributeValue, names []string) []string {
+	var ids []string
+	for _, name := range names {
+		if env, ok := envelopeFromAttribute(item[name]); ok {
+			ids = append(ids, env.KeyID)
+		}
+	}
+	return ids
+}
+
+// TaggedAttributes returns the attribute names of the fields of t, a struct
+// type, that are tagged as encrypted.
+func TaggedAttributes(t reflect.Type) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Tag.Get(Tag) != "true" {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("dynamodbav"), ",")
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func (e *Encryptor) dataKey(ctx context.Context) (DataKey, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.current != nil && e.now().Sub(e.generated) < e.KeyMaxAge {
+		return *e.current, nil
+	}
+
+	key, err := e.Provider.GenerateDataKey(ctx)
+	if err != nil {
+		return DataKey{}, err
+	}
+	e.current = &key
+	e.generated = e.now()
+	e.cache(key.KeyID, key.Encrypted, key.Plaintext)
+	return key, nil
+}
+
+func (e *Encryptor) unwrap(ctx context.Context, keyID string, encrypted []byte) ([]byte, error) {
+	e.mu.Lock()
+	key, ok := e.unwrapped[cacheKey(keyID, encrypted)]
+	e.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	key, err := e.Provider.DecryptDataKey(ctx, keyID, encrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	e.cache(keyID, encrypted, key)
+	e.mu.Unlock()
+	return key, nil
+}
+
+// cache must be called with mu held. The cache is cleared rather than
+// evicted from when full; data keys are cheap to unwrap again.
+func (e *Encryptor) cache(keyID string, encrypted, plaintext []byte) {
+	if len(e.unwrapped) >= maxCachedKeys {
+		clear(e.unwrapped)
+	}
+	e.unwrapped[cacheKey(keyID, encrypted)] = plaintext
+}
+
+func cacheKey(keyID string, encrypted []byte) string {
+	return keyID + "\x00" + string(encrypted)
+}
+
+func additionalData(itemID, name string) []byte {
+	return []byte(itemID + "\x00" + name)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal and open wrap data keys under a master key for the local key ring.
+func seal(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(key, sealed []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, ErrInvalidEnvelope
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidEnvelope, err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,183 @@
+package encryption
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingProvider struct {
+	KeyProvider
+	generated int
+	decrypted int
+}
+
+func (p *countingProvider) GenerateDataKey(ctx context.Context) (DataKey, error) {
+	p.generated++
+	return p.KeyProvider.GenerateDataKey(ctx)
+}
+
+func (p *countingProvider) DecryptDataKey(ctx context.Context, keyID string, encrypted []byte) ([]byte, error) {
+	p.decrypted++
+	return p.KeyProvider.DecryptDataKey(ctx, keyID, encrypted)
+}
+
+func newTestEncryptor(t *testing.T) (*Encryptor, *KeyRing) {
+	keys, err := NewKeyRing()
+	require.NoError(t, err)
+	return NewEncryptor(keys), keys
+}
+
+func TestEncryptor(t *testing.T) {
+	ctx := context.Background()
+	e, _ := newTestEncryptor(t)
+
+	env, err := e.Encrypt(ctx, []byte("felt dizzy"), []byte("Event#1"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(env.Ciphertext), "dizzy")
+
+	tests := map[string]struct {
+		env         Envelope
+		aad         []byte
+		expected    []byte
+		expectedErr error
+	}{
+		"Happy Path - Round Trip": {
+			env:      env,
+			aad:      []byte("Event#1"),
+			expected: []byte("felt dizzy"),
+		},
+		"Sad Path - Different Additional Data": {
+			env:         env,
+			aad:         []byte("Event#2"),
+			expectedErr: ErrInvalidEnvelope,
+		},
+		"Sad Path - Unknown Key": {
+			env:         Envelope{Version: EnvelopeVersion, KeyID: "missing", EncryptedKey: []byte("key"), Nonce: env.Nonce, Ciphertext: env.Ciphertext},
+			aad:         []byte("Event#1"),
+			expectedErr: ErrUnknownKey,
+		},
+		"Sad Path - Unsupported Version": {
+			env:         Envelope{Version: 2},
+			expectedErr: ErrUnsupported,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			plaintext, err := e.Decrypt(ctx, tc.env, tc.aad)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, plaintext)
+		})
+	}
+}
+
+func TestEncryptor_Rotation(t *testing.T) {
+	ctx := context.Background()
+	keys, err := NewKeyRing()
+	require.NoError(t, err)
+	provider := &countingProvider{KeyProvider: keys}
+	e := NewEncryptor(provider)
+
+	now := time.Now()
+	e.now = func() time.Time { return now }
+
+	before, err := e.Encrypt(ctx, []byte("before"), nil)
+	require.NoError(t, err)
+	_, err = e.Encrypt(ctx, []byte("reused"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, provider.generated)
+
+	_, err = keys.Rotate()
+	require.NoError(t, err)
+	now = now.Add(e.KeyMaxAge)
+
+	after, err := e.Encrypt(ctx, []byte("after"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, provider.generated)
+	assert.NotEqual(t, before.KeyID, after.KeyID)
+	assert.Equal(t, keys.CurrentKeyID(), after.KeyID)
+
+	reader := NewEncryptor(provider)
+	for _, env := range []Envelope{before, after, before} {
+		_, err := reader.Decrypt(ctx, env, nil)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, provider.decrypted)
+}
+
+func TestEncryptor_Attributes(t *testing.T) {
+	ctx := context.Background()
+	e, _ := newTestEncryptor(t)
+
+	note := &types.AttributeValueMemberS{Value: "felt dizzy"}
+	data := &types.AttributeValueMemberL{Value: []types.AttributeValue{
+		&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"name":  &types.AttributeValueMemberS{Value: "Systolic"},
+			"value": &types.AttributeValueMemberN{Value: "140.50"},
+		}},
+		&types.AttributeValueMemberBOOL{Value: true},
+		&types.AttributeValueMemberNULL{Value: true},
+		&types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+		&types.AttributeValueMemberB{Value: []byte{0x01}},
+	}}
+	item := map[string]types.AttributeValue{
+		"event_id": &types.AttributeValueMemberS{Value: "Event#1"},
+		"note":     note,
+		"data":     data,
+	}
+
+	require.NoError(t, e.EncryptAttributes(ctx, item, "Event#1", []string{"note", "data", "missing"}))
+	assert.Len(t, KeyIDs(item, []string{"note", "data"}), 2)
+	assert.NotContains(t, item, "missing")
+
+	moved := map[string]types.AttributeValue{"note": item["note"]}
+	assert.ErrorIs(t, e.DecryptAttributes(ctx, moved, "Event#2", []string{"note"}), ErrInvalidEnvelope)
+
+	require.NoError(t, e.DecryptAttributes(ctx, item, "Event#1", []string{"note", "data"}))
+	assert.Equal(t, note, item["note"])
+	assert.Equal(t, data, item["data"])
+
+	require.NoError(t, e.DecryptAttributes(ctx, item, "Event#1", []string{"note"}))
+	assert.Equal(t, note, item["note"])
+}
+
+func TestTaggedAttributes(t *testing.T) {
+	type tagged struct {
+		ID   string `dynamodbav:"id"`
+		Note string `dynamodbav:"note,omitempty" encrypted:"true"`
+		Name string `encrypted:"true"`
+		Off  string `dynamodbav:"off" encrypted:"false"`
+	}
+
+	assert.Equal(t, []string{"note", "Name"}, TaggedAttributes(reflect.TypeFor[*tagged]()))
+	assert.Nil(t, TaggedAttributes(reflect.TypeFor[string]()))
+}
+
+func TestEncryptor_ProviderError(t *testing.T) {
+	e := NewEncryptor(&failingProvider{err: errors.New("kms unavailable")})
+
+	_, err := e.Encrypt(context.Background(), []byte("note"), nil)
+	assert.EqualError(t, err, "kms unavailable")
+}
+
+type failingProvider struct {
+	err error
+}
+
+func (p *failingProvider) GenerateDataKey(ctx context.Context) (DataKey, error) {
+	return DataKey{}, p.err
+}
+
+func (p *failingProvider) DecryptDataKey(ctx context.Context, keyID string, encrypted []byte) ([]byte, error) {
+	return nil, p.err
+}
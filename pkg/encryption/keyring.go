@@ -0,0 +1,116 @@
+package encryption
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// KeyRing is a KeyProvider holding its master keys locally, for tests and
+// local development. It can be loaded from and saved to a JSON file:
+//
+//	{"current": "<key id>", "keys": {"<key id>": "<base64 key>"}}
+type KeyRing struct {
+	mu      sync.RWMutex
+	current string
+	keys    map[string][]byte
+}
+
+type keyRingFile struct {
+	Current string            `json:"current"`
+	Keys    map[string][]byte `json:"keys"`
+}
+
+// NewKeyRing returns a key ring holding a single new master key.
+func NewKeyRing() (*KeyRing, error) {
+	k := &KeyRing{keys: map[string][]byte{}}
+	if _, err := k.Rotate(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+func LoadKeyRing(path string) (*KeyRing, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f keyRingFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("reading key ring %s: %w", path, err)
+	}
+	if _, ok := f.Keys[f.Current]; !ok {
+		return nil, fmt.Errorf("reading key ring %s: %w %q", path, ErrUnknownKey, f.Current)
+	}
+	for id, key := range f.Keys {
+		if len(key) != dataKeySize {
+			return nil, fmt.Errorf("reading key ring %s: key %q is %d bytes, want %d", path, id, len(key), dataKeySize)
+		}
+	}
+
+	return &KeyRing{current: f.Current, keys: f.Keys}, nil
+}
+
+func (k *KeyRing) Save(path string) error {
+	k.mu.RLock()
+	data, err := json.MarshalIndent(keyRingFile{Current: k.current, Keys: k.keys}, "", "  ")
+	k.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// Rotate adds a new master key and makes it current. Earlier keys are kept
+// so data keys they wrapped can still be unwrapped.
+func (k *KeyRing) Rotate() (string, error) {
+	key := make([]byte, dataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	id := uuid.New().String()
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys[id] = key
+	k.current = id
+	return id, nil
+}
+
+func (k *KeyRing) CurrentKeyID() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.current
+}
+
+func (k *KeyRing) GenerateDataKey(ctx context.Context) (DataKey, error) {
+	k.mu.RLock()
+	id, master := k.current, k.keys[k.current]
+	k.mu.RUnlock()
+
+	plaintext := make([]byte, dataKeySize)
+	if _, err := rand.Read(plaintext); err != nil {
+		return DataKey{}, err
+	}
+	encrypted, err := seal(master, plaintext)
+	if err != nil {
+		return DataKey{}, err
+	}
+	return DataKey{KeyID: id, Plaintext: plaintext, Encrypted: encrypted}, nil
+}
+
+func (k *KeyRing) DecryptDataKey(ctx context.Context, keyID string, encrypted []byte) ([]byte, error) {
+	k.mu.RLock()
+	master, ok := k.keys[keyID]
+	k.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, keyID)
+	}
+	return open(master, encrypted)
+}
@@ -0,0 +1,68 @@
+package encryption
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyRing_SaveAndLoad(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "keys.json")
+
+	keys, err := NewKeyRing()
+	require.NoError(t, err)
+	first, err := keys.GenerateDataKey(ctx)
+	require.NoError(t, err)
+	_, err = keys.Rotate()
+	require.NoError(t, err)
+	require.NoError(t, keys.Save(path))
+
+	loaded, err := LoadKeyRing(path)
+	require.NoError(t, err)
+	assert.Equal(t, keys.CurrentKeyID(), loaded.CurrentKeyID())
+
+	plaintext, err := loaded.DecryptDataKey(ctx, first.KeyID, first.Encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, first.Plaintext, plaintext)
+}
+
+func TestLoadKeyRing(t *testing.T) {
+	tests := map[string]struct {
+		contents    string
+		expectedErr bool
+	}{
+		"Happy Path - Valid File": {
+			contents: `{"current": "k1", "keys": {"k1": "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="}}`,
+		},
+		"Sad Path - Current Key Missing": {
+			contents:    `{"current": "k2", "keys": {"k1": "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="}}`,
+			expectedErr: true,
+		},
+		"Sad Path - Short Key": {
+			contents:    `{"current": "k1", "keys": {"k1": "c2hvcnQ="}}`,
+			expectedErr: true,
+		},
+		"Sad Path - Invalid JSON": {
+			contents:    `{`,
+			expectedErr: true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "keys.json")
+			require.NoError(t, os.WriteFile(path, []byte(tc.contents), 0o600))
+
+			_, err := LoadKeyRing(path)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
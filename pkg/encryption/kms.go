@@ -0,0 +1,52 @@
+package encryption
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+type KMSClientProvider interface {
+	GenerateDataKey(ctx context.Context, params *kms.GenerateDataKeyInput, optFns ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error)
+	Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+// KMSKeyProvider generates data keys under the KMS key KeyID. Rotating to a
+// new KMS key only needs KeyID changed: data keys record the ARN of the key
+// that wrapped them, and KMS's own automatic rotation is transparent.
+type KMSKeyProvider struct {
+	Client KMSClientProvider
+	KeyID  string
+}
+
+func NewKMSKeyProvider(client KMSClientProvider, keyID string) *KMSKeyProvider {
+	return &KMSKeyProvider{Client: client, KeyID: keyID}
+}
+
+func (p *KMSKeyProvider) GenerateDataKey(ctx context.Context) (DataKey, error) {
+	out, err := p.Client.GenerateDataKey(ctx, &kms.GenerateDataKeyInput{
+		KeyId:   aws.String(p.KeyID),
+		KeySpec: kmstypes.DataKeySpecAes256,
+	})
+	if err != nil {
+		return DataKey{}, err
+	}
+	return DataKey{
+		KeyID:     aws.ToString(out.KeyId),
+		Plaintext: out.Plaintext,
+		Encrypted: out.CiphertextBlob,
+	}, nil
+}
+
+func (p *KMSKeyProvider) DecryptDataKey(ctx context.Context, keyID string, encrypted []byte) ([]byte, error) {
+	out, err := p.Client.Decrypt(ctx, &kms.DecryptInput{
+		KeyId:          aws.String(keyID),
+		CiphertextBlob: encrypted,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Plaintext, nil
+}
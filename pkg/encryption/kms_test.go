@@ -0,0 +1,60 @@
+package encryption
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKMS wraps data keys with a local key ring, standing in for KMS.
+type fakeKMS struct {
+	keys *KeyRing
+}
+
+func (f *fakeKMS) GenerateDataKey(ctx context.Context, params *kms.GenerateDataKeyInput, optFns ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error) {
+	if params.KeySpec != kmstypes.DataKeySpecAes256 {
+		return nil, &kmstypes.InvalidKeyUsageException{}
+	}
+	key, err := f.keys.GenerateDataKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &kms.GenerateDataKeyOutput{
+		KeyId:          aws.String("arn:aws:kms:us-east-2:123456789012:key/" + key.KeyID),
+		Plaintext:      key.Plaintext,
+		CiphertextBlob: key.Encrypted,
+	}, nil
+}
+
+func (f *fakeKMS) Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	id := aws.ToString(params.KeyId)[len("arn:aws:kms:us-east-2:123456789012:key/"):]
+	plaintext, err := f.keys.DecryptDataKey(ctx, id, params.CiphertextBlob)
+	if err != nil {
+		return nil, &kmstypes.IncorrectKeyException{}
+	}
+	return &kms.DecryptOutput{KeyId: params.KeyId, Plaintext: plaintext}, nil
+}
+
+func TestKMSKeyProvider(t *testing.T) {
+	ctx := context.Background()
+	keys, err := NewKeyRing()
+	require.NoError(t, err)
+	provider := NewKMSKeyProvider(&fakeKMS{keys: keys}, "alias/care-giver")
+
+	key, err := provider.GenerateDataKey(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "arn:aws:kms:us-east-2:123456789012:key/"+keys.CurrentKeyID(), key.KeyID)
+
+	plaintext, err := provider.DecryptDataKey(ctx, key.KeyID, key.Encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, key.Plaintext, plaintext)
+
+	_, err = provider.DecryptDataKey(ctx, key.KeyID, []byte("tampered"))
+	var incorrect *kmstypes.IncorrectKeyException
+	assert.ErrorAs(t, err, &incorrect)
+}
@@ -0,0 +1,155 @@
+// Package escalation describes how an unanswered alert on a receiver is
+// escalated: the primary care giver is notified when a monitored event type
+// turns red, every care giver when it turns critical, and the receiver's
+// emergency contact when it has stayed critical and unacknowledged for a
+// while.
+package escalation
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/event"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+)
+
+// DefaultEmergencyAfter is how long an alert stays critical and
+// unacknowledged before the emergency contact is notified, unless the
+// policy says otherwise.
+const DefaultEmergencyAfter = 30 * time.Minute
+
+// Step is how far an alert has been escalated. Steps are ordered from
+// StepNone to StepEmergency.
+type Step string
+
+const (
+	StepNone       Step = ""
+	StepPrimary    Step = "primary"
+	StepCareGivers Step = "care_givers"
+	StepEmergency  Step = "emergency"
+)
+
+var steps = []Step{StepNone, StepPrimary, StepCareGivers, StepEmergency}
+
+// Before reports whether s comes before other.
+func (s Step) Before(other Step) bool {
+	return slices.Index(steps, s) < slices.Index(steps, other)
+}
+
+// Contact is someone outside the care givers of a receiver who is notified
+// last, by email or SMS.
+type Contact struct {
+	Name    string               `json:"name" dynamodbav:"name" log:"pii"`
+	Channel relationship.Channel `json:"channel" dynamodbav:"channel"`
+	To      string               `json:"to" dynamodbav:"to" log:"pii"`
+}
+
+// Policy is the escalation policy of a receiver. It covers the event types
+// in EventTypes, or every monitored event type when it is empty. Without an
+// emergency contact escalation stops at the care givers.
+type Policy struct {
+	ReceiverID            string   `json:"receiverId" dynamodbav:"receiver_id"`
+	EventTypes            []string `json:"eventTypes,omitempty" dynamodbav:"event_types,omitempty"`
+	EmergencyContact      *Contact `json:"emergencyContact,omitempty" dynamodbav:"emergency_contact,omitempty"`
+	EmergencyAfterMinutes int      `json:"emergencyAfterMinutes,omitempty" dynamodbav:"emergency_after_minutes,omitempty"`
+}
+
+func (p Policy) Validate() error {
+	if p.ReceiverID == "" {
+		return errors.New("receiver id is required")
+	}
+	if p.EmergencyAfterMinutes < 0 {
+		return fmt.Errorf("emergency after minutes must not be negative: %d", p.EmergencyAfterMinutes)
+	}
+	if c := p.EmergencyContact; c != nil {
+		if c.Channel != relationship.ChannelEmail && c.Channel != relationship.ChannelSMS {
+			return fmt.Errorf("emergency contact channel must be email or sms: %q", c.Channel)
+		}
+		if c.To == "" {
+			return errors.New("emergency contact address is required")
+		}
+	}
+	return nil
+}
+
+func (p Policy) Covers(eventType string) bool {
+	return len(p.EventTypes) == 0 || slices.Contains(p.EventTypes, eventType)
+}
+
+func (p Policy) EmergencyAfter() time.Duration {
+	if p.EmergencyAfterMinutes == 0 {
+		return DefaultEmergencyAfter
+	}
+	return time.Duration(p.EmergencyAfterMinutes) * time.Minute
+}
+
+// State is the escalation of the alert on an event type of a receiver, from
+// the moment the type turned red until it is logged again. An acknowledgment
+// holds escalation back until the alert gets more severe than the level it
+// was acknowledged at.
+type State struct {
+	ReceiverID        string           `json:"receiverId" dynamodbav:"receiver_id"`
+	EventType         string           `json:"eventType" dynamodbav:"event_type"`
+	Level             event.AlertLevel `json:"level" dynamodbav:"level"`
+	Step              Step             `json:"step" dynamodbav:"step"`
+	RaisedAt          time.Time        `json:"raisedAt" dynamodbav:"raised_at"`
+	CriticalAt        time.Time        `json:"criticalAt,omitempty" dynamodbav:"critical_at,omitempty"`
+	AcknowledgedBy    string           `json:"acknowledgedBy,omitempty" dynamodbav:"acknowledged_by,omitempty"`
+	AcknowledgedAt    time.Time        `json:"acknowledgedAt,omitempty" dynamodbav:"acknowledged_at,omitempty"`
+	AcknowledgedLevel event.AlertLevel `json:"acknowledgedLevel,omitempty" dynamodbav:"acknowledged_level,omitempty"`
+}
+
+func (s *State) Acknowledge(uid string, now time.Time) {
+	s.AcknowledgedBy = uid
+	s.AcknowledgedAt = now.UTC()
+	s.AcknowledgedLevel = s.Level
+}
+
+func (s State) Acknowledged() bool {
+	return s.AcknowledgedBy != "" && s.AcknowledgedLevel.AtLeast(s.Level)
+}
+
+// Advance returns the state of the alert on status's event type once status
+// is observed at now, given its current state, which is nil when there is no
+// alert, and the steps to notify in order. The returned state is nil when
+// the alert is over. Its Step is the last step returned; callers that fail
+// to notify a step should leave it at the step before, so it is retried.
+// When the primary care giver and every care giver are due together only the
+// care givers are notified, as they include the primary care giver.
+func (p Policy) Advance(current *State, status event.MonitorStatus, now time.Time) (*State, []Step) {
+	if !p.Covers(status.EventType) || !status.Level.AtLeast(event.AlertLevelRed) {
+		return nil, nil
+	}
+
+	s := State{ReceiverID: p.ReceiverID, EventType: status.EventType, RaisedAt: now.UTC()}
+	if current != nil {
+		s = *current
+	}
+	s.Level = status.Level
+	if s.Level == event.AlertLevelCritical && s.CriticalAt.IsZero() {
+		s.CriticalAt = now.UTC()
+	}
+	if s.Acknowledged() {
+		return &s, nil
+	}
+
+	var due []Step
+	if s.Step.Before(StepPrimary) {
+		due = append(due, StepPrimary)
+	}
+	if s.Level == event.AlertLevelCritical {
+		if s.Step.Before(StepCareGivers) {
+			due = append(slices.DeleteFunc(due, func(step Step) bool { return step == StepPrimary }), StepCareGivers)
+		}
+		if p.EmergencyContact != nil && s.Step.Before(StepEmergency) && now.Sub(s.CriticalAt) >= p.EmergencyAfter() {
+			due = append(due, StepEmergency)
+		}
+	}
+
+	if len(due) > 0 {
+		s.Step = due[len(due)-1]
+	}
+	return &s, due
+}
@@ -0,0 +1,156 @@
+package escalation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/event"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyValidate(t *testing.T) {
+	tests := map[string]struct {
+		policy      Policy
+		expectError bool
+	}{
+		"Happy Path - Emergency Contact": {
+			policy: Policy{ReceiverID: "Receiver#1", EmergencyContact: &Contact{Name: "Ann", Channel: relationship.ChannelSMS, To: "+15555550100"}, EmergencyAfterMinutes: 15},
+		},
+		"Happy Path - Care Givers Only": {
+			policy: Policy{ReceiverID: "Receiver#1", EventTypes: []string{"Medication"}},
+		},
+		"Sad Path - Missing Receiver": {
+			policy:      Policy{},
+			expectError: true,
+		},
+		"Sad Path - Push Emergency Contact": {
+			policy:      Policy{ReceiverID: "Receiver#1", EmergencyContact: &Contact{Channel: relationship.ChannelPush, To: "device"}},
+			expectError: true,
+		},
+		"Sad Path - Emergency Contact Without Address": {
+			policy:      Policy{ReceiverID: "Receiver#1", EmergencyContact: &Contact{Channel: relationship.ChannelEmail}},
+			expectError: true,
+		},
+		"Sad Path - Negative Delay": {
+			policy:      Policy{ReceiverID: "Receiver#1", EmergencyAfterMinutes: -1},
+			expectError: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.policy.Validate()
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPolicyAdvance(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	policy := Policy{
+		ReceiverID:       "Receiver#1",
+		EventTypes:       []string{"Medication"},
+		EmergencyContact: &Contact{Channel: relationship.ChannelSMS, To: "+15555550100"},
+	}
+	red := event.MonitorStatus{EventType: "Medication", Level: event.AlertLevelRed}
+	critical := event.MonitorStatus{EventType: "Medication", Level: event.AlertLevelCritical}
+
+	tests := map[string]struct {
+		policy        Policy
+		current       *State
+		status        event.MonitorStatus
+		expectedState *State
+		expectedSteps []Step
+	}{
+		"Red Notifies Primary": {
+			policy:        policy,
+			status:        red,
+			expectedState: &State{ReceiverID: "Receiver#1", EventType: "Medication", Level: event.AlertLevelRed, Step: StepPrimary, RaisedAt: now},
+			expectedSteps: []Step{StepPrimary},
+		},
+		"Red Already Notified": {
+			policy:        policy,
+			current:       &State{ReceiverID: "Receiver#1", EventType: "Medication", Level: event.AlertLevelRed, Step: StepPrimary, RaisedAt: now.Add(-time.Hour)},
+			status:        red,
+			expectedState: &State{ReceiverID: "Receiver#1", EventType: "Medication", Level: event.AlertLevelRed, Step: StepPrimary, RaisedAt: now.Add(-time.Hour)},
+		},
+		"Critical Notifies Care Givers": {
+			policy:        policy,
+			current:       &State{ReceiverID: "Receiver#1", EventType: "Medication", Level: event.AlertLevelRed, Step: StepPrimary, RaisedAt: now.Add(-time.Hour)},
+			status:        critical,
+			expectedState: &State{ReceiverID: "Receiver#1", EventType: "Medication", Level: event.AlertLevelCritical, Step: StepCareGivers, RaisedAt: now.Add(-time.Hour), CriticalAt: now},
+			expectedSteps: []Step{StepCareGivers},
+		},
+		"Critical First Skips Primary": {
+			policy:        policy,
+			status:        critical,
+			expectedState: &State{ReceiverID: "Receiver#1", EventType: "Medication", Level: event.AlertLevelCritical, Step: StepCareGivers, RaisedAt: now, CriticalAt: now},
+			expectedSteps: []Step{StepCareGivers},
+		},
+		"Critical Waits For Emergency Delay": {
+			policy:        policy,
+			current:       &State{ReceiverID: "Receiver#1", EventType: "Medication", Level: event.AlertLevelCritical, Step: StepCareGivers, RaisedAt: now.Add(-time.Hour), CriticalAt: now.Add(-29 * time.Minute)},
+			status:        critical,
+			expectedState: &State{ReceiverID: "Receiver#1", EventType: "Medication", Level: event.AlertLevelCritical, Step: StepCareGivers, RaisedAt: now.Add(-time.Hour), CriticalAt: now.Add(-29 * time.Minute)},
+		},
+		"Critical Unacknowledged Notifies Emergency Contact": {
+			policy:        policy,
+			current:       &State{ReceiverID: "Receiver#1", EventType: "Medication", Level: event.AlertLevelCritical, Step: StepCareGivers, RaisedAt: now.Add(-time.Hour), CriticalAt: now.Add(-30 * time.Minute)},
+			status:        critical,
+			expectedState: &State{ReceiverID: "Receiver#1", EventType: "Medication", Level: event.AlertLevelCritical, Step: StepEmergency, RaisedAt: now.Add(-time.Hour), CriticalAt: now.Add(-30 * time.Minute)},
+			expectedSteps: []Step{StepEmergency},
+		},
+		"Critical Acknowledged Holds Back Emergency Contact": {
+			policy:        policy,
+			current:       &State{ReceiverID: "Receiver#1", EventType: "Medication", Level: event.AlertLevelCritical, Step: StepCareGivers, RaisedAt: now.Add(-time.Hour), CriticalAt: now.Add(-time.Hour), AcknowledgedBy: "User#1", AcknowledgedAt: now.Add(-time.Minute), AcknowledgedLevel: event.AlertLevelCritical},
+			status:        critical,
+			expectedState: &State{ReceiverID: "Receiver#1", EventType: "Medication", Level: event.AlertLevelCritical, Step: StepCareGivers, RaisedAt: now.Add(-time.Hour), CriticalAt: now.Add(-time.Hour), AcknowledgedBy: "User#1", AcknowledgedAt: now.Add(-time.Minute), AcknowledgedLevel: event.AlertLevelCritical},
+		},
+		"Red Acknowledgment Does Not Hold Back Critical": {
+			policy:        policy,
+			current:       &State{ReceiverID: "Receiver#1", EventType: "Medication", Level: event.AlertLevelRed, Step: StepPrimary, RaisedAt: now.Add(-time.Hour), AcknowledgedBy: "User#1", AcknowledgedAt: now.Add(-time.Minute), AcknowledgedLevel: event.AlertLevelRed},
+			status:        critical,
+			expectedState: &State{ReceiverID: "Receiver#1", EventType: "Medication", Level: event.AlertLevelCritical, Step: StepCareGivers, RaisedAt: now.Add(-time.Hour), CriticalAt: now, AcknowledgedBy: "User#1", AcknowledgedAt: now.Add(-time.Minute), AcknowledgedLevel: event.AlertLevelRed},
+			expectedSteps: []Step{StepCareGivers},
+		},
+		"Critical Without Emergency Contact Stops At Care Givers": {
+			policy:        Policy{ReceiverID: "Receiver#1"},
+			current:       &State{ReceiverID: "Receiver#1", EventType: "Medication", Level: event.AlertLevelCritical, Step: StepCareGivers, RaisedAt: now.Add(-time.Hour), CriticalAt: now.Add(-time.Hour)},
+			status:        critical,
+			expectedState: &State{ReceiverID: "Receiver#1", EventType: "Medication", Level: event.AlertLevelCritical, Step: StepCareGivers, RaisedAt: now.Add(-time.Hour), CriticalAt: now.Add(-time.Hour)},
+		},
+		"Logged Again Closes Alert": {
+			policy:  policy,
+			current: &State{ReceiverID: "Receiver#1", EventType: "Medication", Level: event.AlertLevelCritical, Step: StepEmergency},
+			status:  event.MonitorStatus{EventType: "Medication", Level: event.AlertLevelGreen},
+		},
+		"Uncovered Event Type": {
+			policy: policy,
+			status: event.MonitorStatus{EventType: "Shower", Level: event.AlertLevelCritical},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, due := tc.policy.Advance(tc.current, tc.status, now)
+			assert.Equal(t, tc.expectedState, s)
+			assert.Equal(t, tc.expectedSteps, due)
+		})
+	}
+}
+
+func TestStateAcknowledge(t *testing.T) {
+	s := State{Level: event.AlertLevelRed}
+	assert.False(t, s.Acknowledged())
+
+	s.Acknowledge("User#1", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	assert.True(t, s.Acknowledged())
+
+	s.Level = event.AlertLevelCritical
+	assert.False(t, s.Acknowledged())
+}
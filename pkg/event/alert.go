@@ -0,0 +1,42 @@
+package event
+
+import (
+	"slices"
+	"time"
+)
+
+type AlertLevel string
+
+const (
+	AlertLevelGreen    AlertLevel = "green"
+	AlertLevelYellow   AlertLevel = "yellow"
+	AlertLevelRed      AlertLevel = "red"
+	AlertLevelCritical AlertLevel = "critical"
+)
+
+// AlertLevels lists every alert level from least to most severe.
+var AlertLevels = []AlertLevel{AlertLevelGreen, AlertLevelYellow, AlertLevelRed, AlertLevelCritical}
+
+func (l AlertLevel) Valid() bool {
+	return slices.Contains(AlertLevels, l)
+}
+
+// AtLeast reports whether l is as severe as other or more.
+func (l AlertLevel) AtLeast(other AlertLevel) bool {
+	return slices.Index(AlertLevels, l) >= slices.Index(AlertLevels, other)
+}
+
+// Level is the alert level of a monitored event type when elapsed time has
+// passed since it was last logged. Thresholds are in hours.
+func (t AlertThresholds) Level(elapsed time.Duration) AlertLevel {
+	switch hours := elapsed.Hours(); {
+	case hours >= float64(t.Critical):
+		return AlertLevelCritical
+	case hours >= float64(t.Red):
+		return AlertLevelRed
+	case hours >= float64(t.Yellow):
+		return AlertLevelYellow
+	default:
+		return AlertLevelGreen
+	}
+}
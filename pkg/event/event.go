@@ -18,8 +18,8 @@ type Entry struct {
 	StartTime  string      `json:"startTime" dynamodbav:"start_time"`
 	EndTime    string      `json:"endTime" dynamodbav:"end_time"`
 	Type       string      `json:"type" dynamodbav:"type"`
-	Data       []DataPoint `json:"data,omitempty" dynamodbav:"data,omitempty"`
-	Note       string      `json:"note,omitempty" dynamodbav:"note,omitempty"`
+	Data       []DataPoint `json:"data,omitempty" dynamodbav:"data,omitempty" log:"phi" encrypted:"true"`
+	Note       string      `json:"note,omitempty" dynamodbav:"note,omitempty" log:"phi" encrypted:"true"`
 }
 
 type DataPoint struct {
@@ -3,6 +3,7 @@ package event
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -269,3 +270,71 @@ func TestGetAllConfigs(t *testing.T) {
 		assert.Equal(t, 30, c.Upcoming.LookAheadDays)
 	})
 }
+
+func TestAlertThresholdsLevel(t *testing.T) {
+	thresholds := AlertThresholds{Yellow: 6, Red: 12, Critical: 18}
+
+	tests := map[string]struct {
+		elapsed  time.Duration
+		expected AlertLevel
+	}{
+		"Below Yellow": {
+			elapsed:  5*time.Hour + 59*time.Minute,
+			expected: AlertLevelGreen,
+		},
+		"At Yellow": {
+			elapsed:  6 * time.Hour,
+			expected: AlertLevelYellow,
+		},
+		"At Red": {
+			elapsed:  12 * time.Hour,
+			expected: AlertLevelRed,
+		},
+		"Past Critical": {
+			elapsed:  48 * time.Hour,
+			expected: AlertLevelCritical,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, thresholds.Level(tc.elapsed))
+		})
+	}
+
+	assert.True(t, AlertLevelCritical.AtLeast(AlertLevelRed))
+	assert.True(t, AlertLevelRed.AtLeast(AlertLevelRed))
+	assert.False(t, AlertLevelYellow.AtLeast(AlertLevelRed))
+}
+
+func TestMonitors(t *testing.T) {
+	configs := []EventConfig{
+		{Type: "Shower", Monitor: &MonitorConfig{AlertThresholds: &AlertThresholds{Yellow: 36, Red: 60, Critical: 84}}},
+		{Type: "Medication", Monitor: &MonitorConfig{AlertThresholds: &AlertThresholds{Yellow: 6, Red: 12, Critical: 18}}},
+		{Type: "Weight", Monitor: &MonitorConfig{ShowLastValue: true}},
+		{Type: "Walk"},
+	}
+	entries := []Entry{
+		{Type: "Medication", StartTime: "2025-01-01T00:00:00Z"},
+		{Type: "Medication", StartTime: "2025-01-01T06:00:00Z"},
+		{Type: "Medication", StartTime: "2025-01-02T06:00:00Z"},
+		{Type: "Medication", StartTime: "not a time"},
+		{Type: "Weight", StartTime: "2025-01-01T06:00:00Z"},
+	}
+	at := time.Date(2025, 1, 1, 20, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, []MonitorStatus{
+		{
+			EventType:  "Medication",
+			Thresholds: AlertThresholds{Yellow: 6, Red: 12, Critical: 18},
+			Level:      AlertLevelRed,
+			LastLogged: time.Date(2025, 1, 1, 6, 0, 0, 0, time.UTC),
+			Elapsed:    14 * time.Hour,
+		},
+		{
+			EventType:  "Shower",
+			Thresholds: AlertThresholds{Yellow: 36, Red: 60, Critical: 84},
+			Level:      AlertLevelGreen,
+		},
+	}, Monitors(configs, entries, at))
+}
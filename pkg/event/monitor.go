@@ -0,0 +1,57 @@
+package event
+
+import (
+	"sort"
+	"time"
+)
+
+// MonitorStatus is the state of a monitored event type at a point in time.
+// LastLogged is zero when the type was never logged, which is reported as
+// green.
+type MonitorStatus struct {
+	EventType  string
+	Thresholds AlertThresholds
+	Level      AlertLevel
+	LastLogged time.Time
+	Elapsed    time.Duration
+}
+
+// Monitors computes at time at the status of every event type in configs
+// that has alert thresholds, from entries logged up to at. Entries with an
+// unparsable start time are ignored. Statuses are sorted by event type.
+func Monitors(configs []EventConfig, entries []Entry, at time.Time) []MonitorStatus {
+	last := map[string]time.Time{}
+	for _, e := range entries {
+		start, err := time.Parse(time.RFC3339, e.StartTime)
+		if err != nil || start.After(at) {
+			continue
+		}
+		if start.After(last[e.Type]) {
+			last[e.Type] = start
+		}
+	}
+
+	var statuses []MonitorStatus
+	for _, c := range configs {
+		if c.Monitor == nil || c.Monitor.AlertThresholds == nil {
+			continue
+		}
+
+		s := MonitorStatus{
+			EventType:  c.Type,
+			Thresholds: *c.Monitor.AlertThresholds,
+			Level:      AlertLevelGreen,
+			LastLogged: last[c.Type],
+		}
+		if !s.LastLogged.IsZero() {
+			s.Elapsed = at.Sub(s.LastLogged)
+			s.Level = s.Thresholds.Level(s.Elapsed)
+		}
+		statuses = append(statuses, s)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].EventType < statuses[j].EventType
+	})
+	return statuses
+}
@@ -0,0 +1,95 @@
+package invitation
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"github.com/google/uuid"
+)
+
+const (
+	DBPrefix = "Invitation"
+	ParamID  = "invitationId"
+
+	// DefaultTTL is how long an invitation can be accepted for.
+	DefaultTTL = 7 * 24 * time.Hour
+
+	tokenSize = 32
+)
+
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusAccepted Status = "accepted"
+	StatusRevoked  Status = "revoked"
+)
+
+// Invitation invites Email to care for ReceiverID with Role. Only the hash
+// of its token is stored; the token itself is handed to the invitee once.
+type Invitation struct {
+	InvitationID string            `json:"invitationId" dynamodbav:"invitation_id"`
+	ReceiverID   string            `json:"receiverId" dynamodbav:"receiver_id"`
+	Email        string            `json:"email" dynamodbav:"email" log:"pii"`
+	Role         relationship.Role `json:"role" dynamodbav:"role"`
+	InvitedBy    string            `json:"invitedBy" dynamodbav:"invited_by"`
+	TokenHash    string            `json:"-" dynamodbav:"token_hash"`
+	Status       Status            `json:"status" dynamodbav:"status"`
+	CreatedAt    time.Time         `json:"createdAt" dynamodbav:"created_at"`
+	ExpiresAt    time.Time         `json:"expiresAt" dynamodbav:"expires_at"`
+	AcceptedBy   string            `json:"acceptedBy,omitempty" dynamodbav:"accepted_by,omitempty"`
+}
+
+// NewInvitation returns a pending invitation expiring ttl after now, and the
+// token the invitee accepts it with.
+func NewInvitation(rid, email string, role relationship.Role, invitedBy string, now time.Time, ttl time.Duration) (*Invitation, string, error) {
+	token, err := NewToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &Invitation{
+		InvitationID: fmt.Sprintf("%s#%s", DBPrefix, uuid.New()),
+		ReceiverID:   rid,
+		Email:        email,
+		Role:         role,
+		InvitedBy:    invitedBy,
+		TokenHash:    HashToken(token),
+		Status:       StatusPending,
+		CreatedAt:    now.UTC(),
+		ExpiresAt:    now.Add(ttl).UTC(),
+	}, token, nil
+}
+
+func NewToken() (string, error) {
+	b := make([]byte, tokenSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (i Invitation) Expired(now time.Time) bool {
+	return !now.Before(i.ExpiresAt)
+}
+
+// Acceptable reports whether the invitation is pending and not expired.
+func (i Invitation) Acceptable(now time.Time) bool {
+	return i.Status == StatusPending && !i.Expired(now)
+}
+
+// For reports whether the invitation was issued to email, ignoring case.
+func (i Invitation) For(email string) bool {
+	return strings.EqualFold(i.Email, email)
+}
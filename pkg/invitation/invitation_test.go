@@ -0,0 +1,72 @@
+package invitation
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewInvitation(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	inv, token, err := NewInvitation("Receiver#123", "Demo.Daniel@email.com", relationship.RoleEditor, "User#123", now, DefaultTTL)
+	assert.Nil(t, err)
+
+	assert.True(t, strings.HasPrefix(inv.InvitationID, DBPrefix+"#"))
+	assert.Equal(t, StatusPending, inv.Status)
+	assert.Equal(t, now.Add(DefaultTTL), inv.ExpiresAt)
+	assert.Equal(t, HashToken(token), inv.TokenHash)
+	assert.NotContains(t, inv.TokenHash, token)
+
+	_, other, err := NewInvitation("Receiver#123", "Demo.Daniel@email.com", relationship.RoleEditor, "User#123", now, DefaultTTL)
+	assert.Nil(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+func TestAcceptable(t *testing.T) {
+	expiresAt := time.Date(2025, 1, 8, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		status   Status
+		now      time.Time
+		expected bool
+	}{
+		"Pending and not expired": {
+			status:   StatusPending,
+			now:      expiresAt.Add(-time.Second),
+			expected: true,
+		},
+		"Pending and expired": {
+			status:   StatusPending,
+			now:      expiresAt,
+			expected: false,
+		},
+		"Revoked": {
+			status:   StatusRevoked,
+			now:      expiresAt.Add(-time.Hour),
+			expected: false,
+		},
+		"Accepted": {
+			status:   StatusAccepted,
+			now:      expiresAt.Add(-time.Hour),
+			expected: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			inv := Invitation{Status: tc.status, ExpiresAt: expiresAt}
+			assert.Equal(t, tc.expected, inv.Acceptable(tc.now))
+		})
+	}
+}
+
+func TestFor(t *testing.T) {
+	inv := Invitation{Email: "Demo.Daniel@email.com"}
+
+	assert.True(t, inv.For("demo.daniel@EMAIL.com"))
+	assert.False(t, inv.For("someone@email.com"))
+}
@@ -1,6 +1,9 @@
 package log
 
 import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -22,7 +25,16 @@ var (
 
 	EnvLogKey             = "env"
 	TableNameLogKey       = "table name"
+	IndexNameLogKey       = "index name"
+	EndpointLogKey        = "endpoint"
+	OperationLogKey       = "operation"
+	RetriesLogKey         = "retries"
+	DelayLogKey           = "delay"
+	ItemCountLogKey       = "item count"
+	TraceIDLogKey         = "trace id"
+	SpanIDLogKey          = "span id"
 	UserIDLogKey          = "user id"
+	TargetUserIDLogKey    = "target user id"
 	ReceiverIDLogKey      = "receiver id"
 	EventIDLogKey         = "event id"
 	EventLogKey           = "event name"
@@ -31,6 +43,8 @@ var (
 	PathParametersLogKey  = "path parameters"
 	MethodLogKey          = "method"
 	ParamIDLogKey         = "param id"
+	ChannelLogKey         = "channel"
+	MessageIDLogKey       = "message id"
 )
 
 func GetLogger(level string) (*zap.Logger, error) {
@@ -51,6 +65,16 @@ func GetLoggerWithEnv(level string, env string) (*zap.Logger, error) {
 	return logger, err
 }
 
+// WithTraceContext adds the trace and span IDs of the span in ctx to logger.
+// When ctx carries no span the logger is returned unchanged.
+func WithTraceContext(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return logger
+	}
+	return logger.With(zap.String(TraceIDLogKey, sc.TraceID().String()), zap.String(SpanIDLogKey, sc.SpanID().String()))
+}
+
 func newLogger(level string) *zap.Logger {
 	encoderCfg := zap.NewProductionEncoderConfig()
 	encoderCfg.TimeKey = "timestamp"
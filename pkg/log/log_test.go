@@ -1,10 +1,13 @@
 package log
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestGetLogger(t *testing.T) {
@@ -18,3 +21,36 @@ func TestGetLoggerWithEnv(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, logger.Level(), zap.InfoLevel)
 }
+
+func TestWithTraceContext(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x01, 0x02},
+		SpanID:  trace.SpanID{0x03},
+	})
+
+	tests := map[string]struct {
+		ctx            context.Context
+		expectedFields map[string]any
+	}{
+		"Happy Path - Span In Context": {
+			ctx: trace.ContextWithSpanContext(context.Background(), sc),
+			expectedFields: map[string]any{
+				TraceIDLogKey: "01020000000000000000000000000000",
+				SpanIDLogKey:  "0300000000000000",
+			},
+		},
+		"Happy Path - No Span In Context": {
+			ctx:            context.Background(),
+			expectedFields: map[string]any{},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+
+			WithTraceContext(tc.ctx, zap.New(core)).Info("message")
+
+			assert.Equal(t, tc.expectedFields, logs.All()[0].ContextMap())
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package log
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Classification says how sensitive a field is. Fields are classified with a
+// `log` struct tag, e.g. `log:"pii"`; untagged fields are Safe.
+type Classification string
+
+const (
+	Safe Classification = "safe"
+	PII  Classification = "pii"
+	PHI  Classification = "phi"
+
+	classificationTag = "log"
+)
+
+// RedactionMode is what a Redactor does with the value of a classified field.
+type RedactionMode string
+
+const (
+	// RedactNone logs the value unchanged.
+	RedactNone RedactionMode = "none"
+	// RedactMask keeps the first character (and the domain of an email
+	// address) and masks the rest.
+	RedactMask RedactionMode = "mask"
+	// RedactHash replaces the value with a salted hash, so the same value can
+	// be correlated across log lines without being readable. Without a salt
+	// the value is masked entirely, as an unsalted hash of an email address
+	// is easily reversed.
+	RedactHash RedactionMode = "hash"
+	// RedactDrop leaves the field out entirely.
+	RedactDrop RedactionMode = "drop"
+)
+
+const (
+	PIIRedactionEnvVar  = "CARE_GIVER_LOG_PII"
+	PHIRedactionEnvVar  = "CARE_GIVER_LOG_PHI"
+	RedactionSaltEnvVar = "CARE_GIVER_LOG_REDACTION_SALT"
+
+	maskedValue = "***"
+	hashBytes   = 16
+)
+
+var ErrRedactionSaltRequired = errors.New("redaction salt is required to hash log values")
+
+type Redactor struct {
+	PII  RedactionMode
+	PHI  RedactionMode
+	Salt string
+}
+
+var (
+	// DefaultRedactor is used for environments without an entry in
+	// RedactorsByEnv. Its hashes need a salt from RedactionSaltEnvVar.
+	DefaultRedactor = Redactor{PII: RedactHash, PHI: RedactDrop}
+
+	RedactorsByEnv = map[string]Redactor{
+		"local": {PII: RedactNone, PHI: RedactNone},
+		"test":  {PII: RedactMask, PHI: RedactMask},
+		"dev":   {PII: RedactMask, PHI: RedactDrop},
+	}
+
+	redactor atomic.Pointer[Redactor]
+)
+
+func init() {
+	SetRedactor(DefaultRedactor)
+}
+
+// RedactorForEnv returns the redactor configured for env, with the modes and
+// salt overridden by the CARE_GIVER_LOG_* environment variables when set.
+// Outside the local environment a redactor that hashes requires a salt;
+// without one the returned redactor masks instead, and the error wraps
+// ErrRedactionSaltRequired.
+func RedactorForEnv(env string) (Redactor, error) {
+	r, ok := RedactorsByEnv[env]
+	if !ok {
+		r = DefaultRedactor
+	}
+
+	var err error
+	if r.PII, err = redactionModeFromEnv(PIIRedactionEnvVar, r.PII); err != nil {
+		return r, err
+	}
+	if r.PHI, err = redactionModeFromEnv(PHIRedactionEnvVar, r.PHI); err != nil {
+		return r, err
+	}
+	if salt, ok := os.LookupEnv(RedactionSaltEnvVar); ok {
+		r.Salt = salt
+	}
+	if env != "local" && r.Salt == "" && (r.PII == RedactHash || r.PHI == RedactHash) {
+		if r.PII == RedactHash {
+			r.PII = RedactMask
+		}
+		if r.PHI == RedactHash {
+			r.PHI = RedactMask
+		}
+		return r, fmt.Errorf("%w: set %s", ErrRedactionSaltRequired, RedactionSaltEnvVar)
+	}
+	return r, nil
+}
+
+// SetRedactorForEnv makes the redactor configured for env the one used by
+// Redacted and RedactedString. Without a salt it falls back to masking and
+// warns about it on logger.
+func SetRedactorForEnv(env string, logger *zap.Logger) error {
+	r, err := RedactorForEnv(env)
+	if errors.Is(err, ErrRedactionSaltRequired) {
+		logger.Warn("masking log values instead of hashing them", zap.Error(err))
+	} else if err != nil {
+		return err
+	}
+	SetRedactor(r)
+	return nil
+}
+
+func redactionModeFromEnv(envVar string, fallback RedactionMode) (RedactionMode, error) {
+	v, ok := os.LookupEnv(envVar)
+	if !ok || v == "" {
+		return fallback, nil
+	}
+	mode := RedactionMode(strings.ToLower(v))
+	switch mode {
+	case RedactNone, RedactMask, RedactHash, RedactDrop:
+		return mode, nil
+	}
+	return fallback, fmt.Errorf("invalid %s %q", envVar, v)
+}
+
+// SetRedactor replaces the redactor used by Redacted and RedactedString.
+func SetRedactor(r Redactor) {
+	redactor.Store(&r)
+}
+
+func CurrentRedactor() Redactor {
+	return *redactor.Load()
+}
+
+// Redacted logs v under key as an object whose classified fields are redacted
+// by the current redactor. v is usually a struct or pointer to one; anything
+// else is logged as a string of the given classification would be.
+func Redacted(key string, v any) zap.Field {
+	return CurrentRedactor().Field(key, v)
+}
+
+// RedactedString logs a single value of the given classification, e.g. an
+// email address a lookup was made by.
+func RedactedString(key string, class Classification, value string) zap.Field {
+	r := CurrentRedactor()
+	if r.mode(class) == RedactDrop {
+		return zap.Skip()
+	}
+	return zap.String(key, r.redact(class, value))
+}
+
+func (r Redactor) Field(key string, v any) zap.Field {
+	return zap.Object(key, redactedObject{redactor: r, value: reflect.ValueOf(v)})
+}
+
+func (r Redactor) mode(class Classification) RedactionMode {
+	switch class {
+	case PII:
+		return r.PII
+	case PHI:
+		return r.PHI
+	}
+	return RedactNone
+}
+
+func (r Redactor) redact(class Classification, value string) string {
+	switch r.mode(class) {
+	case RedactMask:
+		return mask(value)
+	case RedactHash:
+		return r.hash(value)
+	case RedactDrop:
+		return ""
+	}
+	return value
+}
+
+func (r Redactor) hash(value string) string {
+	if r.Salt == "" {
+		return maskedValue
+	}
+	mac := hmac.New(sha256.New, []byte(r.Salt))
+	mac.Write([]byte(value))
+	return "sha256:" + hex.EncodeToString(mac.Sum(nil)[:hashBytes])
+}
+
+func mask(value string) string {
+	if value == "" {
+		return ""
+	}
+	_, first := utf8.DecodeRuneInString(value)
+	if at := strings.LastIndex(value, "@"); at > 0 {
+		return value[:first] + maskedValue + value[at:]
+	}
+	return value[:first] + maskedValue
+}
+
+type redactedObject struct {
+	redactor Redactor
+	value    reflect.Value
+}
+
+func (o redactedObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	v := o.value
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot redact %s", v.Kind())
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := fieldName(field)
+		if name == "" {
+			continue
+		}
+
+		class := Classification(field.Tag.Get(classificationTag))
+		mode := o.redactor.mode(class)
+		fv := v.Field(i)
+		switch {
+		case mode == RedactDrop:
+		case mode == RedactNone:
+			if err := enc.AddReflected(name, fv.Interface()); err != nil {
+				return err
+			}
+		case fv.Kind() == reflect.String:
+			enc.AddString(name, o.redactor.redact(class, fv.String()))
+		case isEmpty(fv):
+			enc.AddString(name, "")
+		default:
+			// Structured values such as data points cannot be masked piecewise,
+			// so they are hashed or masked as a whole.
+			enc.AddString(name, o.redactor.redact(class, fmt.Sprint(fv.Interface())))
+		}
+	}
+	return nil
+}
+
+// fieldName is the JSON name of field, or "" when it is not serialised.
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
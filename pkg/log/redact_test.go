@@ -0,0 +1,158 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type classified struct {
+	ID      string   `json:"id"`
+	Email   string   `json:"email" log:"pii"`
+	Note    string   `json:"note,omitempty" log:"phi"`
+	Values  []string `json:"values,omitempty" log:"phi"`
+	Ignored string   `json:"-" log:"pii"`
+}
+
+func TestRedactor(t *testing.T) {
+	item := classified{ID: "Item#123", Email: "jane@example.com", Note: "blood pressure high", Values: []string{"140/90"}, Ignored: "secret"}
+
+	tests := map[string]struct {
+		redactor       Redactor
+		expectedFields map[string]any
+	}{
+		"Happy Path - None": {
+			redactor: Redactor{PII: RedactNone, PHI: RedactNone},
+			expectedFields: map[string]any{
+				"id":     "Item#123",
+				"email":  "jane@example.com",
+				"note":   "blood pressure high",
+				"values": []string{"140/90"},
+			},
+		},
+		"Happy Path - Mask": {
+			redactor: Redactor{PII: RedactMask, PHI: RedactMask},
+			expectedFields: map[string]any{
+				"id":     "Item#123",
+				"email":  "j***@example.com",
+				"note":   "b***",
+				"values": "[***",
+			},
+		},
+		"Happy Path - Hash And Drop": {
+			redactor: Redactor{PII: RedactHash, PHI: RedactDrop, Salt: "salt"},
+			expectedFields: map[string]any{
+				"id":    "Item#123",
+				"email": Redactor{Salt: "salt"}.hash("jane@example.com"),
+			},
+		},
+		"Happy Path - Hash Without Salt Masks": {
+			redactor: Redactor{PII: RedactHash, PHI: RedactDrop},
+			expectedFields: map[string]any{
+				"id":    "Item#123",
+				"email": "***",
+			},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+
+			zap.New(core).Info("message", tc.redactor.Field("item", &item))
+
+			assert.Equal(t, tc.expectedFields, logs.All()[0].ContextMap()["item"])
+		})
+	}
+}
+
+func TestRedactorHash(t *testing.T) {
+	r := Redactor{PII: RedactHash, Salt: "salt"}
+
+	assert.Equal(t, r.redact(PII, "jane@example.com"), r.redact(PII, "jane@example.com"))
+	assert.NotEqual(t, r.redact(PII, "jane@example.com"), Redactor{PII: RedactHash, Salt: "pepper"}.redact(PII, "jane@example.com"))
+	assert.NotContains(t, r.redact(PII, "jane@example.com"), "jane")
+}
+
+func TestRedactorForEnv(t *testing.T) {
+	tests := map[string]struct {
+		env         string
+		envVars     map[string]string
+		expected    Redactor
+		expectedErr bool
+	}{
+		"Happy Path - Local": {
+			env:      "local",
+			expected: Redactor{PII: RedactNone, PHI: RedactNone},
+		},
+		"Happy Path - Unknown Env Uses Default": {
+			env:      "prod",
+			envVars:  map[string]string{RedactionSaltEnvVar: "salt"},
+			expected: Redactor{PII: DefaultRedactor.PII, PHI: DefaultRedactor.PHI, Salt: "salt"},
+		},
+		"Happy Path - Env Var Overrides": {
+			env:      "dev",
+			envVars:  map[string]string{PIIRedactionEnvVar: "HASH", RedactionSaltEnvVar: "salt"},
+			expected: Redactor{PII: RedactHash, PHI: RedactDrop, Salt: "salt"},
+		},
+		"Sad Path - Hash Without Salt Masks": {
+			env:         "prod",
+			expected:    Redactor{PII: RedactMask, PHI: RedactDrop},
+			expectedErr: true,
+		},
+		"Sad Path - Invalid Mode": {
+			env:         "dev",
+			envVars:     map[string]string{PHIRedactionEnvVar: "shred"},
+			expectedErr: true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			for k, v := range tc.envVars {
+				t.Setenv(k, v)
+			}
+
+			r, err := RedactorForEnv(tc.env)
+			if tc.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			if tc.expected != (Redactor{}) {
+				assert.Equal(t, tc.expected, r)
+			}
+		})
+	}
+}
+
+func TestSetRedactorForEnv(t *testing.T) {
+	defer SetRedactor(CurrentRedactor())
+
+	core, logs := observer.New(zap.WarnLevel)
+	assert.NoError(t, SetRedactorForEnv("prod", zap.New(core)))
+	assert.Equal(t, Redactor{PII: RedactMask, PHI: RedactDrop}, CurrentRedactor())
+	assert.Len(t, logs.All(), 1)
+
+	t.Setenv(PHIRedactionEnvVar, "shred")
+	assert.Error(t, SetRedactorForEnv("dev", zap.New(core)))
+	assert.Equal(t, Redactor{PII: RedactMask, PHI: RedactDrop}, CurrentRedactor())
+}
+
+func TestMask(t *testing.T) {
+	assert.Equal(t, "", mask(""))
+	assert.Equal(t, "j***@example.com", mask("jane@example.com"))
+	assert.Equal(t, "J***", mask("Jane"))
+	assert.Equal(t, "é***@example.com", mask("élodie@example.com"))
+	assert.Equal(t, "日***", mask("日本"))
+}
+
+func TestRedactedString(t *testing.T) {
+	defer SetRedactor(CurrentRedactor())
+
+	SetRedactor(Redactor{PII: RedactMask, PHI: RedactDrop})
+
+	assert.Equal(t, zap.String("email", "j***@example.com"), RedactedString("email", PII, "jane@example.com"))
+	assert.Equal(t, zap.Skip(), RedactedString("note", PHI, "blood pressure high"))
+	assert.Equal(t, zap.String("id", "Item#123"), RedactedString("id", Safe, "Item#123"))
+}
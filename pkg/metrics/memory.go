@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"sync"
+)
+
+// Memory is a Sink that keeps every sample in memory.
+type Memory struct {
+	mu           sync.Mutex
+	counters     map[string]float64
+	observations map[string][]float64
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		counters:     map[string]float64{},
+		observations: map[string][]float64{},
+	}
+}
+
+func (m *Memory) Observe(name string, labels Labels, value float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := seriesKey(name, labels)
+	m.observations[key] = append(m.observations[key], value)
+}
+
+func (m *Memory) Add(name string, labels Labels, delta float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.counters[seriesKey(name, labels)] += delta
+}
+
+// Counter returns the value of the counter with exactly these labels.
+func (m *Memory) Counter(name string, labels Labels) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.counters[seriesKey(name, labels)]
+}
+
+// Observations returns the samples of the histogram with exactly these
+// labels, in the order they were recorded.
+func (m *Memory) Observations(name string, labels Labels) []float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]float64(nil), m.observations[seriesKey(name, labels)]...)
+}
+
+// Series returns the keys of every recorded series, such as
+// `dynamodb_operation_errors_total{error="ValidationException"}`.
+func (m *Memory) Series() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := sortedKeys(m.counters)
+	keys = append(keys, sortedKeys(m.observations)...)
+	return keys
+}
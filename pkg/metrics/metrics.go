@@ -0,0 +1,66 @@
+// Package metrics records counters and histograms through a pluggable Sink.
+// Prometheus renders them in the Prometheus text exposition format and Memory
+// keeps them for assertions in tests.
+package metrics
+
+import (
+	"sort"
+	"strings"
+)
+
+type Labels map[string]string
+
+type Sink interface {
+	// Observe records one sample of a histogram.
+	Observe(name string, labels Labels, value float64)
+	// Add increments a counter by delta.
+	Add(name string, labels Labels, delta float64)
+}
+
+// Nop discards every metric.
+type Nop struct{}
+
+func (Nop) Observe(string, Labels, float64) {}
+
+func (Nop) Add(string, Labels, float64) {}
+
+// seriesKey identifies a series by its name and labels, formatted as in the
+// Prometheus text format.
+func seriesKey(name string, labels Labels) string {
+	return name + formatLabels(labels)
+}
+
+func formatLabels(labels Labels, extra ...string) string {
+	pairs := make([]string, 0, len(labels)+len(extra)/2)
+	for _, k := range sortedKeys(labels) {
+		pairs = append(pairs, k+`="`+escapeLabelValue(labels[k])+`"`)
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, extra[i]+`="`+escapeLabelValue(extra[i+1])+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func escapeLabelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func copyLabels(labels Labels) Labels {
+	copied := make(Labels, len(labels))
+	for k, v := range labels {
+		copied[k] = v
+	}
+	return copied
+}
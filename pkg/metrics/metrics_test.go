@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheus_WriteTo(t *testing.T) {
+	p := NewPrometheus(0.1, 0.5)
+	p.Add("requests_total", Labels{"table": "users"}, 1)
+	p.Add("requests_total", Labels{"table": "users"}, 2)
+	p.Add("requests_total", Labels{"table": `say "hi"`}, 1)
+	p.Observe("latency_seconds", Labels{"op": "Get"}, 0.05)
+	p.Observe("latency_seconds", Labels{"op": "Get"}, 0.3)
+	p.Observe("latency_seconds", Labels{"op": "Get"}, 2)
+	p.Add("unlabelled_total", nil, 4)
+
+	var b strings.Builder
+	n, err := p.WriteTo(&b)
+
+	expected := `# TYPE requests_total counter
+requests_total{table="say \"hi\""} 1
+requests_total{table="users"} 3
+# TYPE unlabelled_total counter
+unlabelled_total 4
+# TYPE latency_seconds histogram
+latency_seconds_bucket{op="Get",le="0.1"} 1
+latency_seconds_bucket{op="Get",le="0.5"} 2
+latency_seconds_bucket{op="Get",le="+Inf"} 3
+latency_seconds_sum{op="Get"} 2.35
+latency_seconds_count{op="Get"} 3
+`
+	assert.NoError(t, err)
+	assert.Equal(t, expected, b.String())
+	assert.Equal(t, int64(len(expected)), n)
+}
+
+func TestPrometheus_ServeHTTP(t *testing.T) {
+	p := NewPrometheus()
+	p.Add("requests_total", nil, 1)
+
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "# TYPE requests_total counter\nrequests_total 1\n", rec.Body.String())
+}
+
+func TestMemory(t *testing.T) {
+	m := NewMemory()
+	m.Add("errors_total", Labels{"error": "Throttled", "op": "Put"}, 1)
+	m.Add("errors_total", Labels{"op": "Put", "error": "Throttled"}, 1)
+	m.Observe("latency_seconds", Labels{"op": "Put"}, 0.2)
+	m.Observe("latency_seconds", Labels{"op": "Put"}, 0.1)
+
+	assert.Equal(t, float64(2), m.Counter("errors_total", Labels{"op": "Put", "error": "Throttled"}))
+	assert.Equal(t, float64(0), m.Counter("errors_total", Labels{"op": "Get"}))
+	assert.Equal(t, []float64{0.2, 0.1}, m.Observations("latency_seconds", Labels{"op": "Put"}))
+	assert.Equal(t, []string{`errors_total{error="Throttled",op="Put"}`, `latency_seconds{op="Put"}`}, m.Series())
+}
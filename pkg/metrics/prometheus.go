@@ -0,0 +1,165 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// DefaultBuckets are histogram upper bounds in seconds suited to DynamoDB
+// request latencies.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Prometheus is a Sink that aggregates metrics and renders them in the
+// Prometheus text exposition format. It is an http.Handler so it can be
+// mounted as a scrape endpoint.
+type Prometheus struct {
+	buckets []float64
+
+	mu         sync.Mutex
+	counters   map[string]map[string]*counterSeries
+	histograms map[string]map[string]*histogramSeries
+}
+
+type counterSeries struct {
+	labels Labels
+	value  float64
+}
+
+type histogramSeries struct {
+	labels Labels
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewPrometheus returns a sink whose histograms use buckets, or
+// DefaultBuckets when none are given.
+func NewPrometheus(buckets ...float64) *Prometheus {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &Prometheus{
+		buckets:    buckets,
+		counters:   map[string]map[string]*counterSeries{},
+		histograms: map[string]map[string]*histogramSeries{},
+	}
+}
+
+func (p *Prometheus) Observe(name string, labels Labels, value float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	series, ok := p.histograms[name]
+	if !ok {
+		series = map[string]*histogramSeries{}
+		p.histograms[name] = series
+	}
+	key := formatLabels(labels)
+	h, ok := series[key]
+	if !ok {
+		h = &histogramSeries{labels: copyLabels(labels), counts: make([]uint64, len(p.buckets))}
+		series[key] = h
+	}
+
+	for i, upper := range p.buckets {
+		if value <= upper {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += value
+}
+
+func (p *Prometheus) Add(name string, labels Labels, delta float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	series, ok := p.counters[name]
+	if !ok {
+		series = map[string]*counterSeries{}
+		p.counters[name] = series
+	}
+	key := formatLabels(labels)
+	c, ok := series[key]
+	if !ok {
+		c = &counterSeries{labels: copyLabels(labels)}
+		series[key] = c
+	}
+	c.value += delta
+}
+
+// WriteTo writes every metric in the text exposition format, sorted by
+// name and labels.
+func (p *Prometheus) WriteTo(w io.Writer) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+
+	for _, name := range sortedKeys(p.counters) {
+		cw.line("# TYPE ", name, " counter")
+		series := p.counters[name]
+		for _, key := range sortedKeys(series) {
+			cw.line(name, key, " ", formatFloat(series[key].value))
+		}
+	}
+
+	for _, name := range sortedKeys(p.histograms) {
+		cw.line("# TYPE ", name, " histogram")
+		series := p.histograms[name]
+		for _, key := range sortedKeys(series) {
+			h := series[key]
+			for i, upper := range p.buckets {
+				cw.line(name, "_bucket", formatLabels(h.labels, "le", formatFloat(upper)), " ", strconv.FormatUint(h.counts[i], 10))
+			}
+			cw.line(name, "_bucket", formatLabels(h.labels, "le", "+Inf"), " ", strconv.FormatUint(h.count, 10))
+			cw.line(name, "_sum", key, " ", formatFloat(h.sum))
+			cw.line(name, "_count", key, " ", strconv.FormatUint(h.count, 10))
+		}
+	}
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+	return cw.n, cw.err
+}
+
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = p.WriteTo(w)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) line(parts ...string) {
+	for _, part := range append(parts, "\n") {
+		if c.err != nil {
+			return
+		}
+		n, err := c.w.WriteString(part)
+		c.n += int64(n)
+		c.err = err
+	}
+}
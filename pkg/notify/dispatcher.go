@@ -0,0 +1,171 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/log"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"go.uber.org/zap"
+)
+
+const (
+	DefaultDedupeWindow = time.Hour
+	DefaultRateLimit    = 10
+	DefaultRatePeriod   = time.Hour
+	DefaultRetention    = 30 * 24 * time.Hour
+)
+
+// Dispatcher sends messages with the sender of their channel. A message is
+// not sent when a message with the same dedupe key was sent to the recipient
+// within the same DedupeWindow, or when RateLimit messages were sent to the
+// recipient within the same RatePeriod; a zero RateLimit turns rate limiting
+// off. Windows and periods are fixed, starting at multiples of their length
+// since the zero time. Both are claimed in the delivery log before sending,
+// so concurrent dispatchers cannot send the same message twice or exceed the
+// rate limit. Messages that fail to send give their dedupe key back but stay
+// counted. Every message is recorded in the delivery log, whose entries
+// expire after Retention.
+type Dispatcher struct {
+	Senders      map[relationship.Channel]Sender
+	Deliveries   DeliveryLogProvider
+	DedupeWindow time.Duration
+	RateLimit    int
+	RatePeriod   time.Duration
+	Retention    time.Duration
+
+	now    func() time.Time
+	logger *zap.Logger
+}
+
+func NewDispatcher(senders map[relationship.Channel]Sender, deliveries DeliveryLogProvider, logger *zap.Logger) *Dispatcher {
+	return &Dispatcher{
+		Senders:      senders,
+		Deliveries:   deliveries,
+		DedupeWindow: DefaultDedupeWindow,
+		RateLimit:    DefaultRateLimit,
+		RatePeriod:   DefaultRatePeriod,
+		Retention:    DefaultRetention,
+		now:          time.Now,
+		logger:       logger,
+	}
+}
+
+// Send sends m, returning ErrDuplicate or ErrRateLimited when it was held
+// back. Messages without an ID are given one.
+func (d *Dispatcher) Send(ctx context.Context, m Message) error {
+	if m.ID == "" {
+		m.ID = newMessageID()
+	}
+	logger := log.WithTraceContext(ctx, d.logger).With(
+		zap.String(log.MessageIDLogKey, m.ID),
+		zap.String(log.ChannelLogKey, string(m.Channel)),
+		zap.String(log.UserIDLogKey, m.UserID),
+		zap.String(log.ReceiverIDLogKey, m.ReceiverID))
+
+	if m.To == "" {
+		return ErrNoRecipient
+	}
+	sender, ok := d.Senders[m.Channel]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrNoSender, m.Channel)
+	}
+
+	if m.Template != "" {
+		version := m.TemplateVersion
+		if version == "" {
+			version = DefaultTemplateVersion
+		}
+		c, err := Render(version, m.Template, m.Data)
+		if err != nil {
+			logger.Error("error rendering message", zap.Error(err))
+			return err
+		}
+		m.Subject, m.Text, m.HTML, m.TemplateVersion = c.Subject, c.Text, c.HTML, version
+	}
+
+	now := d.now()
+	if status, err := d.claim(ctx, logger, m, now); err != nil {
+		return err
+	} else if status != "" {
+		logger.Info("holding back message", zap.String("status", string(status)))
+		d.record(ctx, logger, m, status, nil, now)
+		if status == StatusDuplicate {
+			return ErrDuplicate
+		}
+		return ErrRateLimited
+	}
+
+	logger.Info("sending message")
+	if err := sender.Send(ctx, m); err != nil {
+		logger.Error("error sending message", zap.Error(err))
+		d.release(ctx, logger, m, now)
+		d.record(ctx, logger, m, StatusFailed, err, now)
+		return err
+	}
+	d.record(ctx, logger, m, StatusSent, nil, now)
+	return nil
+}
+
+// claim claims the dedupe key of m and counts m against the rate limit of its
+// recipient. It returns the status m is held back with, or an empty status
+// when it can be sent.
+func (d *Dispatcher) claim(ctx context.Context, logger *zap.Logger, m Message, now time.Time) (Status, error) {
+	recipient := Recipient(m.Channel, m.To)
+	deduped := m.DedupeKey != "" && d.DedupeWindow > 0
+	if deduped {
+		window := now.Truncate(d.DedupeWindow)
+		err := d.Deliveries.ClaimDedupeKey(ctx, recipient, m.DedupeKey, window, window.Add(d.DedupeWindow))
+		if errors.Is(err, ErrDuplicate) {
+			return StatusDuplicate, nil
+		}
+		if err != nil {
+			logger.Error("error claiming dedupe key", zap.Error(err))
+			return "", err
+		}
+	}
+
+	if d.RateLimit > 0 && d.RatePeriod > 0 {
+		window := now.Truncate(d.RatePeriod)
+		err := d.Deliveries.CountDelivery(ctx, recipient, window, d.RateLimit, window.Add(d.RatePeriod))
+		if err != nil {
+			if deduped {
+				d.release(ctx, logger, m, now)
+			}
+			if errors.Is(err, ErrRateLimited) {
+				return StatusRateLimited, nil
+			}
+			logger.Error("error counting message", zap.Error(err))
+			return "", err
+		}
+	}
+
+	return "", nil
+}
+
+// release gives back the dedupe key claimed for m, so that it can be sent
+// again within the window.
+func (d *Dispatcher) release(ctx context.Context, logger *zap.Logger, m Message, now time.Time) {
+	if m.DedupeKey == "" || d.DedupeWindow <= 0 {
+		return
+	}
+	err := d.Deliveries.ReleaseDedupeKey(ctx, Recipient(m.Channel, m.To), m.DedupeKey, now.Truncate(d.DedupeWindow))
+	if err != nil {
+		logger.Error("error releasing dedupe key", zap.Error(err))
+	}
+}
+
+// record writes the delivery log entry of m. A message that was sent is not
+// failed because it could not be recorded, as retrying it would send it
+// again.
+func (d *Dispatcher) record(ctx context.Context, logger *zap.Logger, m Message, status Status, sendErr error, now time.Time) {
+	dl := NewDelivery(m, status, sendErr, now)
+	if d.Retention > 0 {
+		dl.ExpiresAt = now.Add(d.Retention).Unix()
+	}
+	if err := d.Deliveries.RecordDelivery(ctx, dl); err != nil {
+		logger.Error("error recording delivery", zap.String("status", string(status)), zap.Error(err))
+	}
+}
@@ -0,0 +1,252 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/event"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/receiver"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// fakeDeliveryLog keeps deliveries in memory; the memory package can't be
+// imported here as it depends on this one.
+type fakeDeliveryLog struct {
+	mu         sync.Mutex
+	deliveries []Delivery
+	claims     map[string]bool
+	counts     map[string]int
+	err        error
+}
+
+func (f *fakeDeliveryLog) RecordDelivery(ctx context.Context, d Delivery) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	f.deliveries = append(f.deliveries, d)
+	return nil
+}
+
+func (f *fakeDeliveryLog) GetDeliveries(ctx context.Context, recipient string, since time.Time) ([]Delivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var deliveries []Delivery
+	for _, d := range f.deliveries {
+		if d.Recipient == recipient && d.DeliveryID >= DeliveryIDAt(since) {
+			deliveries = append(deliveries, d)
+		}
+	}
+	return deliveries, nil
+}
+
+func (f *fakeDeliveryLog) ClaimDedupeKey(ctx context.Context, recipient string, key string, window time.Time, expiresAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	claim := recipient + "#" + key + "#" + window.String()
+	if f.claims[claim] {
+		return ErrDuplicate
+	}
+	f.claims[claim] = true
+	return nil
+}
+
+func (f *fakeDeliveryLog) ReleaseDedupeKey(ctx context.Context, recipient string, key string, window time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.claims, recipient+"#"+key+"#"+window.String())
+	return nil
+}
+
+func (f *fakeDeliveryLog) CountDelivery(ctx context.Context, recipient string, window time.Time, limit int, expiresAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	count := recipient + "#" + window.String()
+	if f.counts[count] >= limit {
+		return ErrRateLimited
+	}
+	f.counts[count]++
+	return nil
+}
+
+func (f *fakeDeliveryLog) statuses() []Status {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var statuses []Status
+	for _, d := range f.deliveries {
+		statuses = append(statuses, d.Status)
+	}
+	return statuses
+}
+
+func newTestDispatcher(now *time.Time) (*Dispatcher, *MemorySender, *fakeDeliveryLog) {
+	sender := NewMemorySender()
+	deliveries := &fakeDeliveryLog{claims: map[string]bool{}, counts: map[string]int{}}
+	d := NewDispatcher(map[relationship.Channel]Sender{relationship.ChannelSMS: sender}, deliveries, zap.NewNop())
+	d.now = func() time.Time { return *now }
+	return d, sender, deliveries
+}
+
+func TestDispatcherSend(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	d, sender, deliveries := newTestDispatcher(&now)
+
+	m := Message{
+		Channel:    relationship.ChannelSMS,
+		To:         "+15555550100",
+		UserID:     "User#1",
+		ReceiverID: "Receiver#1",
+		DedupeKey:  "Receiver#1#Medication#red",
+		Template:   AlertTemplate,
+		Data: Alert{
+			Receiver:   receiver.Receiver{FirstName: "Bob", LastName: "Doe"},
+			EventType:  "Medication",
+			Level:      event.AlertLevelRed,
+			LastLogged: now.Add(-13 * time.Hour),
+			Elapsed:    13 * time.Hour,
+		},
+	}
+
+	assert.NoError(t, d.Send(ctx, m))
+	sent := sender.Messages()
+	assert.Len(t, sent, 1)
+	assert.NotEmpty(t, sent[0].ID)
+	assert.Equal(t, "Bob Doe: Medication is Red, last logged 13 hours ago.", sent[0].Text)
+	assert.Equal(t, DefaultTemplateVersion, sent[0].TemplateVersion)
+
+	now = now.Add(30 * time.Minute)
+	assert.ErrorIs(t, d.Send(ctx, m), ErrDuplicate)
+
+	now = now.Add(time.Hour)
+	assert.NoError(t, d.Send(ctx, m))
+	assert.Len(t, sender.Messages(), 2)
+	assert.Equal(t, []Status{StatusSent, StatusDuplicate, StatusSent}, deliveries.statuses())
+}
+
+func TestDispatcherRateLimit(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	d, sender, deliveries := newTestDispatcher(&now)
+	d.RateLimit = 2
+
+	m := Message{Channel: relationship.ChannelSMS, To: "+15555550100", Text: "hello"}
+	assert.NoError(t, d.Send(ctx, m))
+	assert.NoError(t, d.Send(ctx, m))
+	assert.ErrorIs(t, d.Send(ctx, m), ErrRateLimited)
+
+	other := Message{Channel: relationship.ChannelSMS, To: "+15555550101", Text: "hello"}
+	assert.NoError(t, d.Send(ctx, other))
+
+	now = now.Add(time.Hour)
+	assert.NoError(t, d.Send(ctx, m))
+	assert.Len(t, sender.Messages(), 4)
+	assert.Equal(t, []Status{StatusSent, StatusSent, StatusRateLimited, StatusSent, StatusSent}, deliveries.statuses())
+}
+
+func TestDispatcherConcurrentSends(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	d, sender, _ := newTestDispatcher(&now)
+	d.RateLimit = 3
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = d.Send(ctx, Message{Channel: relationship.ChannelSMS, To: "+15555550100", DedupeKey: "alert", Text: "hello"})
+		}()
+		go func() {
+			defer wg.Done()
+			_ = d.Send(ctx, Message{Channel: relationship.ChannelSMS, To: "+15555550101", DedupeKey: fmt.Sprintf("alert-%d", i), Text: "hello"})
+		}()
+	}
+	wg.Wait()
+
+	perRecipient := map[string]int{}
+	for _, m := range sender.Messages() {
+		perRecipient[m.To]++
+	}
+	assert.Equal(t, map[string]int{"+15555550100": 1, "+15555550101": 3}, perRecipient)
+}
+
+func TestDispatcherSendFailureReleasesDedupeKey(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	d, sender, deliveries := newTestDispatcher(&now)
+	m := Message{Channel: relationship.ChannelSMS, To: "+15555550100", DedupeKey: "alert", Text: "hello"}
+
+	sender.Err = errors.New("boom")
+	assert.Error(t, d.Send(ctx, m))
+
+	sender.Err = nil
+	assert.NoError(t, d.Send(ctx, m))
+	assert.ErrorIs(t, d.Send(ctx, m), ErrDuplicate)
+	assert.Equal(t, []Status{StatusFailed, StatusSent, StatusDuplicate}, deliveries.statuses())
+}
+
+func TestDispatcherErrors(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	sendErr := errors.New("boom")
+
+	tests := map[string]struct {
+		message      Message
+		setup        func(*Dispatcher, *MemorySender, *fakeDeliveryLog)
+		expectedErr  error
+		expectStatus []Status
+	}{
+		"Sad Path - No Sender For Channel": {
+			message:     Message{Channel: relationship.ChannelEmail, To: "jane@example.com"},
+			expectedErr: ErrNoSender,
+		},
+		"Sad Path - No Recipient": {
+			message:     Message{Channel: relationship.ChannelSMS},
+			expectedErr: ErrNoRecipient,
+		},
+		"Sad Path - Unknown Template": {
+			message:     Message{Channel: relationship.ChannelSMS, To: "+15555550100", Template: "missing"},
+			expectedErr: ErrUnknownTemplate,
+		},
+		"Sad Path - Sender Fails": {
+			message: Message{Channel: relationship.ChannelSMS, To: "+15555550100", Text: "hello"},
+			setup: func(d *Dispatcher, s *MemorySender, l *fakeDeliveryLog) {
+				s.Err = sendErr
+			},
+			expectedErr:  sendErr,
+			expectStatus: []Status{StatusFailed},
+		},
+		"Happy Path - Delivery Log Fails After Sending": {
+			message: Message{Channel: relationship.ChannelSMS, To: "+15555550100", Text: "hello"},
+			setup: func(d *Dispatcher, s *MemorySender, l *fakeDeliveryLog) {
+				d.RateLimit = 0
+				l.err = errors.New("log unavailable")
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			d, sender, deliveries := newTestDispatcher(&now)
+			if tc.setup != nil {
+				tc.setup(d, sender, deliveries)
+			}
+
+			err := d.Send(ctx, tc.message)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectStatus, deliveries.statuses())
+		})
+	}
+}
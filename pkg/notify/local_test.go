@@ -0,0 +1,37 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSender(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages.jsonl")
+	s := NewFileSender(path)
+
+	first := Message{ID: "Message#1", Channel: relationship.ChannelEmail, To: "jane@example.com", Subject: "One"}
+	second := Message{ID: "Message#2", Channel: relationship.ChannelSMS, To: "+15555550100", Text: "Two"}
+	require.NoError(t, s.Send(context.Background(), first))
+	require.NoError(t, s.Send(context.Background(), second))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var messages []Message
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var m Message
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &m))
+		messages = append(messages, m)
+	}
+	assert.Equal(t, []Message{first, second}, messages)
+}
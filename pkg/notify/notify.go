@@ -0,0 +1,132 @@
+// Package notify delivers messages to care givers over email, SMS and
+// webhooks. Alerting code sends through the Sender interface; a Dispatcher
+// routes each message to the sender of its channel after rendering it,
+// dropping duplicates and enforcing a per-recipient rate limit, and records
+// the outcome of every message in a delivery log.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"github.com/google/uuid"
+)
+
+const (
+	DBPrefix = "Message"
+)
+
+var (
+	ErrNoSender    = errors.New("no sender for channel")
+	ErrNoRecipient = errors.New("message has no recipient")
+	ErrDuplicate   = errors.New("message was already sent to the recipient")
+	ErrRateLimited = errors.New("recipient has reached the message rate limit")
+)
+
+// Message is a single message to one recipient. To is an email address for
+// email, a phone number in E.164 format for SMS, and whatever the webhook
+// expects otherwise. When Template is set the Dispatcher renders Subject,
+// Text and HTML from it and Data. Messages with the same DedupeKey are sent
+// to a recipient at most once per de-duplication window.
+type Message struct {
+	ID              string               `json:"messageId"`
+	Channel         relationship.Channel `json:"channel"`
+	To              string               `json:"to" log:"pii"`
+	UserID          string               `json:"userId,omitempty"`
+	ReceiverID      string               `json:"receiverId,omitempty"`
+	DedupeKey       string               `json:"dedupeKey,omitempty"`
+	Subject         string               `json:"subject,omitempty" log:"phi"`
+	Text            string               `json:"text,omitempty" log:"phi"`
+	HTML            string               `json:"html,omitempty" log:"phi"`
+	Template        string               `json:"template,omitempty"`
+	TemplateVersion string               `json:"templateVersion,omitempty"`
+	Data            any                  `json:"-"`
+}
+
+type Sender interface {
+	Send(ctx context.Context, m Message) error
+}
+
+func newMessageID() string {
+	return fmt.Sprintf("%s#%s", DBPrefix, uuid.New())
+}
+
+type Status string
+
+const (
+	StatusSent        Status = "sent"
+	StatusFailed      Status = "failed"
+	StatusDuplicate   Status = "duplicate"
+	StatusRateLimited Status = "rate_limited"
+)
+
+// Delivery is the delivery log entry of a message. Entries of a recipient
+// are sorted by DeliveryID, which starts with the time the message was
+// handled.
+type Delivery struct {
+	Recipient  string               `json:"recipient" dynamodbav:"recipient" log:"pii"`
+	DeliveryID string               `json:"deliveryId" dynamodbav:"delivery_id"`
+	MessageID  string               `json:"messageId" dynamodbav:"message_id"`
+	Channel    relationship.Channel `json:"channel" dynamodbav:"channel"`
+	UserID     string               `json:"userId,omitempty" dynamodbav:"user_id,omitempty"`
+	ReceiverID string               `json:"receiverId,omitempty" dynamodbav:"receiver_id,omitempty"`
+	DedupeKey  string               `json:"dedupeKey,omitempty" dynamodbav:"dedupe_key,omitempty"`
+	Status     Status               `json:"status" dynamodbav:"status"`
+	Error      string               `json:"error,omitempty" dynamodbav:"error,omitempty"`
+	CreatedAt  time.Time            `json:"createdAt" dynamodbav:"created_at"`
+	ExpiresAt  int64                `json:"expiresAt,omitempty" dynamodbav:"expires_at,omitempty"`
+}
+
+// deliveryIDTimeFormat is RFC 3339 with fixed width fractional seconds, so
+// delivery IDs sort by time.
+const deliveryIDTimeFormat = "2006-01-02T15:04:05.000000000Z07:00"
+
+// Recipient is the delivery log key of the recipient to on channel.
+func Recipient(channel relationship.Channel, to string) string {
+	return fmt.Sprintf("%s#%s", channel, to)
+}
+
+// DeliveryIDAt is the smallest delivery ID of a message handled at or after t.
+func DeliveryIDAt(t time.Time) string {
+	return t.UTC().Format(deliveryIDTimeFormat)
+}
+
+func NewDelivery(m Message, status Status, sendErr error, at time.Time) Delivery {
+	d := Delivery{
+		Recipient:  Recipient(m.Channel, m.To),
+		DeliveryID: DeliveryIDAt(at) + "#" + m.ID,
+		MessageID:  m.ID,
+		Channel:    m.Channel,
+		UserID:     m.UserID,
+		ReceiverID: m.ReceiverID,
+		DedupeKey:  m.DedupeKey,
+		Status:     status,
+		CreatedAt:  at.UTC(),
+	}
+	if sendErr != nil {
+		d.Error = sendErr.Error()
+	}
+	return d
+}
+
+// DeliveryLogProvider stores the delivery log. GetDeliveries returns the
+// entries of a recipient handled at or after since, oldest first.
+//
+// It also holds what the Dispatcher claims before sending, each within the
+// fixed window starting at window and kept until at least expiresAt.
+// ClaimDedupeKey claims a dedupe key of a recipient and fails with
+// ErrDuplicate when it was claimed already; ReleaseDedupeKey gives the claim
+// back. CountDelivery counts a message to a recipient and fails with
+// ErrRateLimited, without counting it, once limit messages were counted.
+// Claims and counts must be atomic, as messages to a recipient can be sent
+// concurrently.
+type DeliveryLogProvider interface {
+	RecordDelivery(ctx context.Context, d Delivery) error
+	GetDeliveries(ctx context.Context, recipient string, since time.Time) ([]Delivery, error)
+	ClaimDedupeKey(ctx context.Context, recipient string, key string, window time.Time, expiresAt time.Time) error
+	ReleaseDedupeKey(ctx context.Context, recipient string, key string, window time.Time) error
+	CountDelivery(ctx context.Context, recipient string, window time.Time, limit int, expiresAt time.Time) error
+}
@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	sestypes "github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+)
+
+type SESClientProvider interface {
+	SendEmail(ctx context.Context, params *sesv2.SendEmailInput, optFns ...func(*sesv2.Options)) (*sesv2.SendEmailOutput, error)
+}
+
+// SESSender sends email messages with SES from From. ConfigurationSet is
+// optional.
+type SESSender struct {
+	Client           SESClientProvider
+	From             string
+	ConfigurationSet string
+}
+
+func NewSESSender(client SESClientProvider, from string) *SESSender {
+	return &SESSender{Client: client, From: from}
+}
+
+func (s *SESSender) Send(ctx context.Context, m Message) error {
+	body := &sestypes.Body{Text: sesContent(m.Text)}
+	if m.HTML != "" {
+		body.Html = sesContent(m.HTML)
+	}
+
+	input := &sesv2.SendEmailInput{
+		FromEmailAddress: aws.String(s.From),
+		Destination:      &sestypes.Destination{ToAddresses: []string{m.To}},
+		Content: &sestypes.EmailContent{
+			Simple: &sestypes.Message{
+				Subject: sesContent(m.Subject),
+				Body:    body,
+			},
+		},
+	}
+	if s.ConfigurationSet != "" {
+		input.ConfigurationSetName = aws.String(s.ConfigurationSet)
+	}
+
+	_, err := s.Client.SendEmail(ctx, input)
+	return err
+}
+
+func sesContent(data string) *sestypes.Content {
+	return &sestypes.Content{Data: aws.String(data), Charset: aws.String("UTF-8")}
+}
@@ -0,0 +1,39 @@
+package notify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSES struct {
+	input *sesv2.SendEmailInput
+}
+
+func (f *fakeSES) SendEmail(ctx context.Context, params *sesv2.SendEmailInput, optFns ...func(*sesv2.Options)) (*sesv2.SendEmailOutput, error) {
+	f.input = params
+	return &sesv2.SendEmailOutput{MessageId: aws.String("ses-1")}, nil
+}
+
+func TestSESSender(t *testing.T) {
+	client := &fakeSES{}
+	s := NewSESSender(client, "alerts@example.com")
+	s.ConfigurationSet = "alerts"
+
+	err := s.Send(context.Background(), Message{Channel: relationship.ChannelEmail, To: "jane@example.com", Subject: "Hi", Text: "text", HTML: "<p>html</p>"})
+	assert.NoError(t, err)
+	assert.Equal(t, "alerts@example.com", aws.ToString(client.input.FromEmailAddress))
+	assert.Equal(t, []string{"jane@example.com"}, client.input.Destination.ToAddresses)
+	assert.Equal(t, "alerts", aws.ToString(client.input.ConfigurationSetName))
+	assert.Equal(t, "Hi", aws.ToString(client.input.Content.Simple.Subject.Data))
+	assert.Equal(t, "text", aws.ToString(client.input.Content.Simple.Body.Text.Data))
+	assert.Equal(t, "<p>html</p>", aws.ToString(client.input.Content.Simple.Body.Html.Data))
+
+	err = s.Send(context.Background(), Message{Channel: relationship.ChannelEmail, To: "jane@example.com", Subject: "Hi", Text: "text"})
+	assert.NoError(t, err)
+	assert.Nil(t, client.input.Content.Simple.Body.Html)
+}
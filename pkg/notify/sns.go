@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	snstypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+)
+
+type SNSClientProvider interface {
+	Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error)
+}
+
+// SNSSender sends the text of SMS messages with SNS as transactional
+// messages, which are delivered ahead of promotional ones. SenderID is
+// optional and only shown where carriers support it.
+type SNSSender struct {
+	Client   SNSClientProvider
+	SenderID string
+}
+
+func NewSNSSender(client SNSClientProvider) *SNSSender {
+	return &SNSSender{Client: client}
+}
+
+func (s *SNSSender) Send(ctx context.Context, m Message) error {
+	attributes := map[string]snstypes.MessageAttributeValue{
+		"AWS.SNS.SMS.SMSType": {DataType: aws.String("String"), StringValue: aws.String("Transactional")},
+	}
+	if s.SenderID != "" {
+		attributes["AWS.SNS.SMS.SenderID"] = snstypes.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(s.SenderID)}
+	}
+
+	_, err := s.Client.Publish(ctx, &sns.PublishInput{
+		PhoneNumber:       aws.String(m.To),
+		Message:           aws.String(m.Text),
+		MessageAttributes: attributes,
+	})
+	return err
+}
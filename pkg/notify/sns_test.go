@@ -0,0 +1,33 @@
+package notify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSNS struct {
+	input *sns.PublishInput
+}
+
+func (f *fakeSNS) Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	f.input = params
+	return &sns.PublishOutput{MessageId: aws.String("sns-1")}, nil
+}
+
+func TestSNSSender(t *testing.T) {
+	client := &fakeSNS{}
+	s := NewSNSSender(client)
+	s.SenderID = "CareGiver"
+
+	err := s.Send(context.Background(), Message{Channel: relationship.ChannelSMS, To: "+15555550100", Subject: "ignored", Text: "text"})
+	assert.NoError(t, err)
+	assert.Equal(t, "+15555550100", aws.ToString(client.input.PhoneNumber))
+	assert.Equal(t, "text", aws.ToString(client.input.Message))
+	assert.Equal(t, "Transactional", aws.ToString(client.input.MessageAttributes["AWS.SNS.SMS.SMSType"].StringValue))
+	assert.Equal(t, "CareGiver", aws.ToString(client.input.MessageAttributes["AWS.SNS.SMS.SenderID"].StringValue))
+}
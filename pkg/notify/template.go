@@ -0,0 +1,160 @@
+package notify
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/fs"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/event"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/receiver"
+)
+
+// DefaultTemplateVersion is the template version messages are rendered with
+// unless another is asked for. Released versions are never changed.
+const DefaultTemplateVersion = "v1"
+
+// AlertTemplate renders an Alert.
+const AlertTemplate = "alert"
+
+var ErrUnknownTemplate = errors.New("unknown message template")
+
+//go:embed templates
+var templateFS embed.FS
+
+// parsed caches the templates parsed from templateFS by path, as released
+// versions never change.
+var (
+	parsedMu sync.Mutex
+	parsed   = map[string]executor{}
+)
+
+// executor is a parsed text or HTML template.
+type executor interface {
+	Execute(w io.Writer, data any) error
+}
+
+// Funcs are the functions available to message templates.
+var Funcs = map[string]any{
+	"level": func(l event.AlertLevel) string {
+		if l == "" {
+			return ""
+		}
+		return strings.ToUpper(string(l[:1])) + string(l[1:])
+	},
+	"color": func(l event.AlertLevel) string {
+		return levelColors[l]
+	},
+	"ago": func(d time.Duration) string {
+		switch hours := int(d.Hours()); {
+		case hours >= 48:
+			return fmt.Sprintf("%d days", hours/24)
+		case hours >= 1:
+			return fmt.Sprintf("%d hours", hours)
+		default:
+			return fmt.Sprintf("%d minutes", int(d.Minutes()))
+		}
+	},
+}
+
+var levelColors = map[event.AlertLevel]string{
+	event.AlertLevelGreen:    "#27AE60",
+	event.AlertLevelYellow:   "#F1C40F",
+	event.AlertLevelRed:      "#E74C3C",
+	event.AlertLevelCritical: "#8E0000",
+}
+
+// Alert is the data of AlertTemplate: an event type of a receiver that has
+// reached Level.
+type Alert struct {
+	Receiver   receiver.Receiver
+	EventType  string
+	Level      event.AlertLevel
+	LastLogged time.Time
+	Elapsed    time.Duration
+}
+
+// Content is a rendered message. HTML is empty for templates without an HTML
+// body.
+type Content struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Render renders the template name of the given version with data. A
+// template is made of name.subject.txt.tmpl, name.txt.tmpl and, optionally,
+// name.html.tmpl.
+func Render(version string, name string, data any) (Content, error) {
+	dir := "templates/" + version
+	if version == "" || name == "" {
+		return Content{}, fmt.Errorf("%w: %q version %q", ErrUnknownTemplate, name, version)
+	}
+	if _, err := fs.Stat(templateFS, dir+"/"+name+".txt.tmpl"); err != nil {
+		return Content{}, fmt.Errorf("%w: %q version %q", ErrUnknownTemplate, name, version)
+	}
+
+	var c Content
+	var err error
+	if c.Subject, err = render(dir+"/"+name+".subject.txt.tmpl", data); err != nil {
+		return Content{}, fmt.Errorf("rendering subject: %w", err)
+	}
+	c.Subject = strings.TrimSpace(c.Subject)
+	if c.Text, err = render(dir+"/"+name+".txt.tmpl", data); err != nil {
+		return Content{}, fmt.Errorf("rendering text body: %w", err)
+	}
+	c.Text = strings.TrimSpace(c.Text)
+
+	html := dir + "/" + name + ".html.tmpl"
+	if _, err := fs.Stat(templateFS, html); err == nil {
+		if c.HTML, err = render(html, data); err != nil {
+			return Content{}, fmt.Errorf("rendering html body: %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+func render(path string, data any) (string, error) {
+	t, err := parse(path)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// parse returns the template at path, parsing it on first use. Paths ending
+// in .html.tmpl are parsed as HTML templates.
+func parse(path string) (executor, error) {
+	parsedMu.Lock()
+	defer parsedMu.Unlock()
+
+	if t, ok := parsed[path]; ok {
+		return t, nil
+	}
+
+	name := path[strings.LastIndex(path, "/")+1:]
+	var t executor
+	var err error
+	if strings.HasSuffix(name, ".html.tmpl") {
+		t, err = htmltemplate.New(name).Funcs(Funcs).ParseFS(templateFS, path)
+	} else {
+		t, err = texttemplate.New(name).Funcs(Funcs).ParseFS(templateFS, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	parsed[path] = t
+	return t, nil
+}
@@ -0,0 +1,49 @@
+package notify
+
+import (
+	htmltemplate "html/template"
+	"testing"
+	"time"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/event"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/receiver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	alert := Alert{
+		Receiver:   receiver.Receiver{FirstName: "Bob", LastName: "<Doe>"},
+		EventType:  "Medication",
+		Level:      event.AlertLevelCritical,
+		LastLogged: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Elapsed:    50 * time.Hour,
+	}
+
+	c, err := Render(DefaultTemplateVersion, AlertTemplate, alert)
+	assert.NoError(t, err)
+	assert.Equal(t, "Critical alert: Bob's Medication", c.Subject)
+	assert.Equal(t, "Bob <Doe>: Medication is Critical, last logged 2 days ago.", c.Text)
+	assert.Contains(t, c.HTML, "&lt;Doe&gt;")
+	assert.Contains(t, c.HTML, levelColors[event.AlertLevelCritical])
+
+	c, err = Render(DefaultTemplateVersion, AlertTemplate, Alert{Receiver: receiver.Receiver{FirstName: "Bob"}, EventType: "Shower", Level: event.AlertLevelGreen})
+	assert.NoError(t, err)
+	assert.Contains(t, c.Text, "Shower is Green, never logged.")
+
+	_, err = Render("v0", AlertTemplate, alert)
+	assert.ErrorIs(t, err, ErrUnknownTemplate)
+	_, err = Render(DefaultTemplateVersion, "missing", alert)
+	assert.ErrorIs(t, err, ErrUnknownTemplate)
+}
+
+func TestParse(t *testing.T) {
+	first, err := parse("templates/v1/alert.html.tmpl")
+	assert.NoError(t, err)
+	second, err := parse("templates/v1/alert.html.tmpl")
+	assert.NoError(t, err)
+	assert.Same(t, first, second)
+	assert.IsType(t, &htmltemplate.Template{}, first)
+
+	_, err = parse("templates/v1/missing.txt.tmpl")
+	assert.Error(t, err)
+}
@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// SignatureHeader carries the hex encoded HMAC-SHA256 of the request body
+// when the webhook has a secret.
+const SignatureHeader = "X-Care-Giver-Signature"
+
+type HTTPClientProvider interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// WebhookSender posts messages as JSON to URL. Any response other than a 2xx
+// is an error.
+type WebhookSender struct {
+	Client HTTPClientProvider
+	URL    string
+	Secret []byte
+}
+
+func NewWebhookSender(client HTTPClientProvider, url string, secret []byte) *WebhookSender {
+	return &WebhookSender{Client: client, URL: url, Secret: secret}
+}
+
+func (s *WebhookSender) Send(ctx context.Context, m Message) error {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(s.Secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(s.Secret, body))
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the signature of body sent in SignatureHeader.
+func Sign(secret []byte, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
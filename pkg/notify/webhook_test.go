@@ -0,0 +1,39 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookSender(t *testing.T) {
+	secret := []byte("secret")
+	var received Message
+	var signature string
+	status := http.StatusNoContent
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signature = r.Header.Get(SignatureHeader)
+		assert.Equal(t, Sign(secret, body), signature)
+		assert.NoError(t, json.Unmarshal(body, &received))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	s := NewWebhookSender(server.Client(), server.URL, secret)
+	m := Message{ID: "Message#1", Channel: relationship.ChannelPush, To: "device-1", Subject: "Hi", Text: "text"}
+
+	assert.NoError(t, s.Send(context.Background(), m))
+	assert.Equal(t, m, received)
+	assert.NotEmpty(t, signature)
+
+	status = http.StatusInternalServerError
+	assert.Error(t, s.Send(context.Background(), m))
+}
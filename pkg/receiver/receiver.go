@@ -13,8 +13,8 @@ const (
 
 type Receiver struct {
 	ReceiverID string `json:"receiverId" dynamodbav:"receiver_id"`
-	FirstName  string `json:"firstName" dynamodbav:"first_name"`
-	LastName   string `json:"lastName" dynamodbav:"last_name"`
+	FirstName  string `json:"firstName" dynamodbav:"first_name" log:"pii" encrypted:"true"`
+	LastName   string `json:"lastName" dynamodbav:"last_name" log:"pii" encrypted:"true"`
 }
 
 func NewReceiver(firstName string, lastName string) *Receiver {
@@ -0,0 +1,153 @@
+package relationship
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/event"
+)
+
+type Channel string
+
+const (
+	ChannelEmail Channel = "email"
+	ChannelSMS   Channel = "sms"
+	ChannelPush  Channel = "push"
+)
+
+var Channels = []Channel{ChannelEmail, ChannelSMS, ChannelPush}
+
+type Delivery string
+
+const (
+	DeliveryImmediate Delivery = "immediate"
+	DeliveryDigest    Delivery = "digest"
+)
+
+const clockLayout = "15:04"
+
+// QuietHours is a daily window, given as "HH:MM" clock times in the time zone
+// of the preferences, during which immediate notifications are held back. A
+// window whose end is before its start runs past midnight.
+type QuietHours struct {
+	Start string `json:"start" dynamodbav:"start"`
+	End   string `json:"end" dynamodbav:"end"`
+}
+
+// NotificationPreferences decide which notifications about a receiver a care
+// giver gets and how. Empty EventTypes and AlertLevels match every event type
+// and alert level. TimeZone is an IANA time zone name and defaults to UTC.
+type NotificationPreferences struct {
+	Channels    []Channel          `json:"channels,omitempty" dynamodbav:"channels,omitempty"`
+	EventTypes  []string           `json:"eventTypes,omitempty" dynamodbav:"event_types,omitempty"`
+	AlertLevels []event.AlertLevel `json:"alertLevels,omitempty" dynamodbav:"alert_levels,omitempty"`
+	Delivery    Delivery           `json:"delivery" dynamodbav:"delivery"`
+	QuietHours  *QuietHours        `json:"quietHours,omitempty" dynamodbav:"quiet_hours,omitempty"`
+	TimeZone    string             `json:"timeZone,omitempty" dynamodbav:"time_zone,omitempty"`
+}
+
+// Subject is what a notification is about: an event of EventType being
+// logged, or, when Level is set, the monitor of EventType reaching Level.
+type Subject struct {
+	EventType string
+	Level     event.AlertLevel
+}
+
+// Decision is how a care giver is notified about a subject. Immediate
+// notifications that fall in quiet hours are held until NotBefore.
+type Decision struct {
+	Notify    bool
+	Channels  []Channel
+	Delivery  Delivery
+	NotBefore time.Time
+}
+
+func (p NotificationPreferences) Validate() error {
+	var errs []error
+	for _, c := range p.Channels {
+		if !slices.Contains(Channels, c) {
+			errs = append(errs, fmt.Errorf("unknown channel %q", c))
+		}
+	}
+	for _, l := range p.AlertLevels {
+		if !l.Valid() {
+			errs = append(errs, fmt.Errorf("unknown alert level %q", l))
+		}
+	}
+	if p.Delivery != DeliveryImmediate && p.Delivery != DeliveryDigest {
+		errs = append(errs, fmt.Errorf("unknown delivery %q", p.Delivery))
+	}
+	if p.QuietHours != nil {
+		for _, clock := range []string{p.QuietHours.Start, p.QuietHours.End} {
+			if _, err := time.Parse(clockLayout, clock); err != nil {
+				errs = append(errs, fmt.Errorf("invalid quiet hours time %q", clock))
+			}
+		}
+	}
+	if _, err := time.LoadLocation(p.TimeZone); err != nil {
+		errs = append(errs, fmt.Errorf("invalid time zone %q", p.TimeZone))
+	}
+	return errors.Join(errs...)
+}
+
+// EmailDigest reports whether the preferences ask for a daily email digest,
+// which is what EmailNotifications records.
+func (p NotificationPreferences) EmailDigest() bool {
+	return p.Delivery == DeliveryDigest && slices.Contains(p.Channels, ChannelEmail)
+}
+
+// Resolve decides whether and how to notify about s at now. Green monitor
+// levels never notify, and critical alerts are not held back by quiet hours.
+func (p NotificationPreferences) Resolve(s Subject, now time.Time) Decision {
+	switch {
+	case len(p.Channels) == 0,
+		s.Level == event.AlertLevelGreen,
+		len(p.EventTypes) > 0 && !slices.Contains(p.EventTypes, s.EventType),
+		s.Level != "" && len(p.AlertLevels) > 0 && !slices.Contains(p.AlertLevels, s.Level):
+		return Decision{}
+	}
+
+	d := Decision{Notify: true, Channels: p.Channels, Delivery: p.Delivery}
+	if p.Delivery == DeliveryImmediate && s.Level != event.AlertLevelCritical {
+		if end, ok := p.quietUntil(now); ok {
+			d.NotBefore = end
+		}
+	}
+	return d
+}
+
+// quietUntil returns when the quiet hours now falls in end.
+func (p NotificationPreferences) quietUntil(now time.Time) (time.Time, bool) {
+	if p.QuietHours == nil {
+		return time.Time{}, false
+	}
+	loc, err := time.LoadLocation(p.TimeZone)
+	if err != nil {
+		return time.Time{}, false
+	}
+	start, errStart := time.Parse(clockLayout, p.QuietHours.Start)
+	end, errEnd := time.Parse(clockLayout, p.QuietHours.End)
+	if errStart != nil || errEnd != nil || start.Equal(end) {
+		return time.Time{}, false
+	}
+
+	local := now.In(loc)
+	at := func(day time.Time, clock time.Time) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+	}
+
+	// The window that may contain now started today or, when it runs past
+	// midnight, yesterday.
+	for _, day := range []time.Time{local, local.AddDate(0, 0, -1)} {
+		from, until := at(day, start), at(day, end)
+		if !until.After(from) {
+			until = at(day.AddDate(0, 0, 1), end)
+		}
+		if !local.Before(from) && local.Before(until) {
+			return until.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
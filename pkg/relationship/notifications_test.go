@@ -0,0 +1,159 @@
+package relationship
+
+import (
+	"testing"
+	"time"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationPreferencesValidate(t *testing.T) {
+	tests := map[string]struct {
+		prefs       NotificationPreferences
+		expectError bool
+	}{
+		"Happy Path - Full Preferences": {
+			prefs: NotificationPreferences{
+				Channels:    []Channel{ChannelEmail, ChannelSMS},
+				AlertLevels: []event.AlertLevel{event.AlertLevelRed},
+				Delivery:    DeliveryImmediate,
+				QuietHours:  &QuietHours{Start: "22:00", End: "07:00"},
+				TimeZone:    "America/Chicago",
+			},
+		},
+		"Happy Path - Defaults": {
+			prefs: NotificationPreferences{Delivery: DeliveryDigest},
+		},
+		"Sad Path - Unknown Channel": {
+			prefs:       NotificationPreferences{Channels: []Channel{"pager"}, Delivery: DeliveryDigest},
+			expectError: true,
+		},
+		"Sad Path - Missing Delivery": {
+			prefs:       NotificationPreferences{},
+			expectError: true,
+		},
+		"Sad Path - Invalid Quiet Hours": {
+			prefs:       NotificationPreferences{Delivery: DeliveryImmediate, QuietHours: &QuietHours{Start: "10pm", End: "07:00"}},
+			expectError: true,
+		},
+		"Sad Path - Unknown Time Zone": {
+			prefs:       NotificationPreferences{Delivery: DeliveryImmediate, TimeZone: "Mars/Olympus"},
+			expectError: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.prefs.Validate()
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNotificationPreferencesResolve(t *testing.T) {
+	chicago, _ := time.LoadLocation("America/Chicago")
+	night := time.Date(2025, 1, 1, 23, 30, 0, 0, chicago)
+	day := time.Date(2025, 1, 1, 12, 0, 0, 0, chicago)
+
+	immediate := NotificationPreferences{
+		Channels:    []Channel{ChannelSMS, ChannelPush},
+		EventTypes:  []string{"Medication"},
+		AlertLevels: []event.AlertLevel{event.AlertLevelRed, event.AlertLevelCritical},
+		Delivery:    DeliveryImmediate,
+		QuietHours:  &QuietHours{Start: "22:00", End: "07:00"},
+		TimeZone:    "America/Chicago",
+	}
+
+	tests := map[string]struct {
+		prefs    NotificationPreferences
+		subject  Subject
+		now      time.Time
+		expected Decision
+	}{
+		"Immediate Outside Quiet Hours": {
+			prefs:    immediate,
+			subject:  Subject{EventType: "Medication", Level: event.AlertLevelRed},
+			now:      day,
+			expected: Decision{Notify: true, Channels: immediate.Channels, Delivery: DeliveryImmediate},
+		},
+		"Immediate Held Until Quiet Hours End": {
+			prefs:   immediate,
+			subject: Subject{EventType: "Medication", Level: event.AlertLevelRed},
+			now:     night,
+			expected: Decision{
+				Notify:    true,
+				Channels:  immediate.Channels,
+				Delivery:  DeliveryImmediate,
+				NotBefore: time.Date(2025, 1, 2, 7, 0, 0, 0, chicago).UTC(),
+			},
+		},
+		"Immediate Held After Midnight": {
+			prefs:   immediate,
+			subject: Subject{EventType: "Medication"},
+			now:     time.Date(2025, 1, 2, 3, 0, 0, 0, chicago),
+			expected: Decision{
+				Notify:    true,
+				Channels:  immediate.Channels,
+				Delivery:  DeliveryImmediate,
+				NotBefore: time.Date(2025, 1, 2, 7, 0, 0, 0, chicago).UTC(),
+			},
+		},
+		"Critical Ignores Quiet Hours": {
+			prefs:    immediate,
+			subject:  Subject{EventType: "Medication", Level: event.AlertLevelCritical},
+			now:      night,
+			expected: Decision{Notify: true, Channels: immediate.Channels, Delivery: DeliveryImmediate},
+		},
+		"Filtered Event Type": {
+			prefs:    immediate,
+			subject:  Subject{EventType: "Shower", Level: event.AlertLevelCritical},
+			now:      day,
+			expected: Decision{},
+		},
+		"Filtered Alert Level": {
+			prefs:    immediate,
+			subject:  Subject{EventType: "Medication", Level: event.AlertLevelYellow},
+			now:      day,
+			expected: Decision{},
+		},
+		"Green Never Notifies": {
+			prefs:    NotificationPreferences{Channels: []Channel{ChannelEmail}, Delivery: DeliveryDigest},
+			subject:  Subject{EventType: "Medication", Level: event.AlertLevelGreen},
+			now:      day,
+			expected: Decision{},
+		},
+		"Digest": {
+			prefs:    NotificationPreferences{Channels: []Channel{ChannelEmail}, Delivery: DeliveryDigest, QuietHours: immediate.QuietHours},
+			subject:  Subject{EventType: "Shower"},
+			now:      night,
+			expected: Decision{Notify: true, Channels: []Channel{ChannelEmail}, Delivery: DeliveryDigest},
+		},
+		"No Channels": {
+			prefs:    NotificationPreferences{Delivery: DeliveryImmediate},
+			subject:  Subject{EventType: "Shower"},
+			now:      day,
+			expected: Decision{},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.prefs.Resolve(tc.subject, tc.now))
+		})
+	}
+}
+
+func TestEffectiveNotifications(t *testing.T) {
+	legacy := NewRelationship("User#123", "Receiver#123", false, true)
+	assert.Equal(t, NotificationPreferences{Channels: []Channel{ChannelEmail}, Delivery: DeliveryDigest}, legacy.EffectiveNotifications())
+
+	r := NewRelationship("User#123", "Receiver#123", false, true)
+	r.SetNotifications(NotificationPreferences{Channels: []Channel{ChannelSMS}, Delivery: DeliveryImmediate})
+	assert.False(t, r.EmailNotifications)
+	assert.Equal(t, NotificationPreferences{Channels: []Channel{ChannelSMS}, Delivery: DeliveryImmediate}, r.EffectiveNotifications())
+}
@@ -1,37 +1,111 @@
 package relationship
 
 type Relationship struct {
-	UserID             string `json:"userId" dynamodbav:"user_id"`
-	ReceiverID         string `json:"receiverId" dynamodbav:"receiver_id"`
-	PrimaryCareGiver   bool   `json:"primaryCareGiver" dynamodbav:"primary_care_giver"`
-	EmailNotifications bool   `json:"emailNotifications" dynamodbav:"email_notifications"`
+	UserID     string `json:"userId" dynamodbav:"user_id"`
+	ReceiverID string `json:"receiverId" dynamodbav:"receiver_id"`
+	Role       Role   `json:"role,omitempty" dynamodbav:"role,omitempty"`
+	// PrimaryCareGiver is kept in step with Role, true only for owners, for
+	// readers that predate roles. Relationships stored without a role are
+	// read through EffectiveRole.
+	PrimaryCareGiver bool `json:"primaryCareGiver" dynamodbav:"primary_care_giver"`
+	// EmailNotifications is kept in step with Notifications, true when they
+	// ask for an email digest, and selects the relationships digests go to.
+	EmailNotifications bool                     `json:"emailNotifications" dynamodbav:"email_notifications"`
+	Notifications      *NotificationPreferences `json:"notifications,omitempty" dynamodbav:"notifications,omitempty"`
 }
 
+// NewRelationship creates an owner relationship for a primary care giver and
+// a contributor relationship otherwise.
 func NewRelationship(uid, rid string, primaryCareGiver, emailNotifications bool) *Relationship {
+	role := RoleContributor
+	if primaryCareGiver {
+		role = RoleOwner
+	}
+	return NewRelationshipWithRole(uid, rid, role, emailNotifications)
+}
+
+func NewRelationshipWithRole(uid, rid string, role Role, emailNotifications bool) *Relationship {
 	return &Relationship{
 		UserID:             uid,
 		ReceiverID:         rid,
-		PrimaryCareGiver:   primaryCareGiver,
+		Role:               role,
+		PrimaryCareGiver:   role == RoleOwner,
 		EmailNotifications: emailNotifications,
 	}
 }
 
-func IsACareGiver(uid string, rid string, relationships []Relationship) bool {
-	for _, r := range relationships {
-		if r.UserID == uid && r.ReceiverID == rid {
-			return true
-		}
+// EffectiveRole is the role of r, derived from PrimaryCareGiver when r was
+// stored before roles existed.
+func (r Relationship) EffectiveRole() Role {
+	switch {
+	case r.Role != "":
+		return r.Role
+	case r.PrimaryCareGiver:
+		return RoleOwner
+	default:
+		return RoleContributor
 	}
+}
+
+// EffectiveNotifications are the notification preferences of r. Without
+// stored preferences they are an email digest when EmailNotifications is set
+// and no notifications otherwise.
+func (r Relationship) EffectiveNotifications() NotificationPreferences {
+	switch {
+	case r.Notifications != nil:
+		return *r.Notifications
+	case r.EmailNotifications:
+		return NotificationPreferences{Channels: []Channel{ChannelEmail}, Delivery: DeliveryDigest}
+	default:
+		return NotificationPreferences{Delivery: DeliveryDigest}
+	}
+}
+
+// SetNotifications stores p on r and keeps EmailNotifications in step.
+func (r *Relationship) SetNotifications(p NotificationPreferences) {
+	r.Notifications = &p
+	r.EmailNotifications = p.EmailDigest()
+}
 
-	return false
+// Migrate returns r with its role set from EffectiveRole and
+// PrimaryCareGiver in step with it.
+func Migrate(r Relationship) Relationship {
+	r.Role = r.EffectiveRole()
+	r.PrimaryCareGiver = r.Role == RoleOwner
+	return r
+}
+
+func IsACareGiver(uid string, rid string, relationships []Relationship) bool {
+	_, ok := find(uid, rid, relationships)
+	return ok
 }
 
 func IsAPrimaryCareGiver(uid string, rid string, relationships []Relationship) bool {
+	r, ok := find(uid, rid, relationships)
+	return ok && r.EffectiveRole() == RoleOwner
+}
+
+// RoleOf returns the role uid holds for rid, if any.
+func RoleOf(uid string, rid string, relationships []Relationship) (Role, bool) {
+	r, ok := find(uid, rid, relationships)
+	if !ok {
+		return "", false
+	}
+	return r.EffectiveRole(), true
+}
+
+// Can reports whether uid may perform action on rid given relationships.
+// Users without a relationship to rid may do nothing.
+func Can(uid string, rid string, action Action, relationships []Relationship) bool {
+	role, ok := RoleOf(uid, rid, relationships)
+	return ok && role.Can(action)
+}
+
+func find(uid string, rid string, relationships []Relationship) (Relationship, bool) {
 	for _, r := range relationships {
-		if r.UserID == uid && r.ReceiverID == rid && r.PrimaryCareGiver {
-			return true
+		if r.UserID == uid && r.ReceiverID == rid {
+			return r, true
 		}
 	}
-
-	return false
+	return Relationship{}, false
 }
@@ -12,9 +12,18 @@ func TestNewRelationship(t *testing.T) {
 	assert.Equal(t, "User#123", r.UserID)
 	assert.Equal(t, "Receiver#123", r.ReceiverID)
 	assert.True(t, r.PrimaryCareGiver)
+	assert.Equal(t, RoleOwner, r.Role)
 	assert.False(t, r.EmailNotifications)
 }
 
+func TestNewRelationshipWithRole(t *testing.T) {
+	r := NewRelationshipWithRole("User#123", "Receiver#123", RoleEditor, true)
+
+	assert.Equal(t, RoleEditor, r.Role)
+	assert.False(t, r.PrimaryCareGiver)
+	assert.True(t, r.EmailNotifications)
+}
+
 func TestIsACareGiver(t *testing.T) {
 	tests := map[string]struct {
 		uid      string
@@ -106,3 +115,107 @@ func TestIsAPrimaryCareGiver(t *testing.T) {
 		})
 	}
 }
+
+func TestEffectiveRole(t *testing.T) {
+	tests := map[string]struct {
+		relationship Relationship
+		expected     Role
+	}{
+		"Role set": {
+			relationship: Relationship{Role: RoleViewer},
+			expected:     RoleViewer,
+		},
+		"Legacy primary caregiver": {
+			relationship: Relationship{PrimaryCareGiver: true},
+			expected:     RoleOwner,
+		},
+		"Legacy caregiver": {
+			relationship: Relationship{},
+			expected:     RoleContributor,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.relationship.EffectiveRole())
+
+			migrated := Migrate(tc.relationship)
+			assert.Equal(t, tc.expected, migrated.Role)
+			assert.Equal(t, tc.expected == RoleOwner, migrated.PrimaryCareGiver)
+		})
+	}
+}
+
+func TestCan(t *testing.T) {
+	relationships := []Relationship{
+		{UserID: "User#owner", ReceiverID: "Receiver#123", PrimaryCareGiver: true},
+		{UserID: "User#editor", ReceiverID: "Receiver#123", Role: RoleEditor},
+		{UserID: "User#contributor", ReceiverID: "Receiver#123"},
+		{UserID: "User#viewer", ReceiverID: "Receiver#123", Role: RoleViewer},
+	}
+
+	tests := map[string]struct {
+		uid      string
+		rid      string
+		action   Action
+		expected bool
+	}{
+		"Owner can delete receiver": {
+			uid:      "User#owner",
+			rid:      "Receiver#123",
+			action:   ActionDeleteReceiver,
+			expected: true,
+		},
+		"Editor can delete others' events": {
+			uid:      "User#editor",
+			rid:      "Receiver#123",
+			action:   ActionDeleteOthersEvents,
+			expected: true,
+		},
+		"Editor cannot manage caregivers": {
+			uid:      "User#editor",
+			rid:      "Receiver#123",
+			action:   ActionManageCareGivers,
+			expected: false,
+		},
+		"Contributor can log events": {
+			uid:      "User#contributor",
+			rid:      "Receiver#123",
+			action:   ActionLogEvents,
+			expected: true,
+		},
+		"Contributor cannot export data": {
+			uid:      "User#contributor",
+			rid:      "Receiver#123",
+			action:   ActionExportData,
+			expected: false,
+		},
+		"Viewer cannot log events": {
+			uid:      "User#viewer",
+			rid:      "Receiver#123",
+			action:   ActionLogEvents,
+			expected: false,
+		},
+		"Not a caregiver cannot view events": {
+			uid:      "User#owner",
+			rid:      "Receiver#456",
+			action:   ActionViewEvents,
+			expected: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Can(tc.uid, tc.rid, tc.action, relationships))
+		})
+	}
+}
+
+func TestRoleValid(t *testing.T) {
+	for _, role := range Roles {
+		assert.True(t, role.Valid())
+		assert.True(t, role.Can(ActionViewEvents))
+	}
+	assert.False(t, Role("admin").Valid())
+	assert.False(t, Role("admin").Can(ActionViewEvents))
+}
@@ -0,0 +1,62 @@
+package relationship
+
+import "slices"
+
+type Role string
+
+const (
+	RoleOwner       Role = "owner"
+	RoleEditor      Role = "editor"
+	RoleContributor Role = "contributor"
+	RoleViewer      Role = "viewer"
+)
+
+type Action string
+
+const (
+	ActionViewEvents         Action = "view_events"
+	ActionLogEvents          Action = "log_events"
+	ActionEditOthersEvents   Action = "edit_others_events"
+	ActionDeleteOthersEvents Action = "delete_others_events"
+	ActionManageCareGivers   Action = "manage_care_givers"
+	ActionDeleteReceiver     Action = "delete_receiver"
+	ActionExportData         Action = "export_data"
+)
+
+// Roles lists every role from most to least privileged.
+var Roles = []Role{RoleOwner, RoleEditor, RoleContributor, RoleViewer}
+
+// Permissions is the permission matrix: the actions each role may perform.
+var Permissions = map[Role][]Action{
+	RoleOwner: {
+		ActionViewEvents,
+		ActionLogEvents,
+		ActionEditOthersEvents,
+		ActionDeleteOthersEvents,
+		ActionManageCareGivers,
+		ActionDeleteReceiver,
+		ActionExportData,
+	},
+	RoleEditor: {
+		ActionViewEvents,
+		ActionLogEvents,
+		ActionEditOthersEvents,
+		ActionDeleteOthersEvents,
+		ActionExportData,
+	},
+	RoleContributor: {
+		ActionViewEvents,
+		ActionLogEvents,
+	},
+	RoleViewer: {
+		ActionViewEvents,
+	},
+}
+
+func (r Role) Valid() bool {
+	return slices.Contains(Roles, r)
+}
+
+func (r Role) Can(action Action) bool {
+	return slices.Contains(Permissions[r], action)
+}
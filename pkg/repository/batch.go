@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/dynamo"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/log"
+	"go.uber.org/zap"
+)
+
+const (
+	batchWriteLimit = 25
+	batchGetLimit   = 100
+)
+
+var (
+	ErrUnprocessed = errors.New("item was not processed by dynamo db")
+)
+
+type BatchItemError struct {
+	ID  string
+	Err error
+}
+
+type BatchError struct {
+	Failures []BatchItemError
+}
+
+func (e *BatchError) Error() string {
+	ids := make([]string, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		ids = append(ids, f.ID)
+	}
+	return fmt.Sprintf("%d batch items failed: %s", len(e.Failures), strings.Join(ids, ", "))
+}
+
+func newBatchError(failures []BatchItemError) error {
+	if len(failures) == 0 {
+		return nil
+	}
+	return &BatchError{Failures: failures}
+}
+
+func batchWrite(ctx context.Context, client DynamodbClientProvider, logger *zap.Logger, policy dynamo.RetryPolicy, tableName string, requests []types.WriteRequest, idOf func(types.WriteRequest) string) []BatchItemError {
+	logger = log.WithTraceContext(ctx, logger)
+	policy = policy.WithDefaults()
+	var failures []BatchItemError
+
+	for start := 0; start < len(requests); start += batchWriteLimit {
+		end := min(start+batchWriteLimit, len(requests))
+		pending := requests[start:end]
+
+		for attempt := 1; len(pending) > 0; attempt++ {
+			logger.Info("writing batch to db", zap.Int(log.ItemCountLogKey, len(pending)), zap.Int(log.RetriesLogKey, attempt-1))
+			output, err := client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+				RequestItems: map[string][]types.WriteRequest{tableName: pending},
+			})
+			if err != nil {
+				logger.Error("error writing batch", zap.Error(err))
+				for _, r := range pending {
+					failures = append(failures, BatchItemError{ID: idOf(r), Err: err})
+				}
+				break
+			}
+
+			pending = nil
+			if output != nil {
+				pending = output.UnprocessedItems[tableName]
+			}
+			if len(pending) == 0 {
+				break
+			}
+
+			if attempt == policy.MaxAttempts || waitForRetry(ctx, policy, attempt) != nil {
+				for _, r := range pending {
+					failures = append(failures, BatchItemError{ID: idOf(r), Err: ErrUnprocessed})
+				}
+				break
+			}
+		}
+	}
+
+	return failures
+}
+
+func batchGet(ctx context.Context, client DynamodbClientProvider, logger *zap.Logger, policy dynamo.RetryPolicy, tableName string, keys []map[string]types.AttributeValue, idOf func(map[string]types.AttributeValue) string) ([]map[string]types.AttributeValue, []BatchItemError) {
+	logger = log.WithTraceContext(ctx, logger)
+	policy = policy.WithDefaults()
+	var items []map[string]types.AttributeValue
+	var failures []BatchItemError
+
+	for start := 0; start < len(keys); start += batchGetLimit {
+		end := min(start+batchGetLimit, len(keys))
+		pending := keys[start:end]
+
+		for attempt := 1; len(pending) > 0; attempt++ {
+			logger.Info("reading batch from db", zap.Int(log.ItemCountLogKey, len(pending)), zap.Int(log.RetriesLogKey, attempt-1))
+			output, err := client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{
+				RequestItems: map[string]types.KeysAndAttributes{tableName: {Keys: pending}},
+			})
+			if err != nil {
+				logger.Error("error reading batch", zap.Error(err))
+				for _, k := range pending {
+					failures = append(failures, BatchItemError{ID: idOf(k), Err: err})
+				}
+				break
+			}
+
+			pending = nil
+			if output != nil {
+				items = append(items, output.Responses[tableName]...)
+				pending = output.UnprocessedKeys[tableName].Keys
+			}
+			if len(pending) == 0 {
+				break
+			}
+
+			if attempt == policy.MaxAttempts || waitForRetry(ctx, policy, attempt) != nil {
+				for _, k := range pending {
+					failures = append(failures, BatchItemError{ID: idOf(k), Err: ErrUnprocessed})
+				}
+				break
+			}
+		}
+	}
+
+	return items, failures
+}
+
+// waitForRetry waits out the backoff after attempt left items unprocessed.
+// Unprocessed items mean the table is short of capacity, so they back off
+// like throttling.
+func waitForRetry(ctx context.Context, policy dynamo.RetryPolicy, attempt int) error {
+	timer := time.NewTimer(policy.Backoff(attempt-1, true))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func stringKey(key map[string]types.AttributeValue, name string) string {
+	if v, ok := key[name].(*types.AttributeValueMemberS); ok {
+		return v.Value
+	}
+	return ""
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/dynamo"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+var testBatchRetry = dynamo.RetryPolicy{BaseDelay: time.Millisecond, ThrottleBaseDelay: time.Millisecond}
+
+func putRequest(id string) types.WriteRequest {
+	return types.WriteRequest{PutRequest: &types.PutRequest{Item: map[string]types.AttributeValue{
+		eventID: &types.AttributeValueMemberS{Value: id},
+	}}}
+}
+
+func putRequestID(r types.WriteRequest) string {
+	return stringKey(r.PutRequest.Item, eventID)
+}
+
+func TestBatchWrite(t *testing.T) {
+	manyRequests := make([]types.WriteRequest, 30)
+	for i := range manyRequests {
+		manyRequests[i] = putRequest("Event#many")
+	}
+
+	tests := map[string]struct {
+		requests         []types.WriteRequest
+		mockDynamo       *dynamo.Mock
+		expectedCalls    int
+		expectedFailures []BatchItemError
+	}{
+		"Happy Path - Chunked": {
+			requests: manyRequests,
+			mockDynamo: &dynamo.Mock{
+				BatchWriteOutputs: []*dynamodb.BatchWriteItemOutput{{}, {}},
+			},
+			expectedCalls: 2,
+		},
+		"Happy Path - Unprocessed Items Retried": {
+			requests: []types.WriteRequest{putRequest("Event#1"), putRequest("Event#2")},
+			mockDynamo: &dynamo.Mock{
+				BatchWriteOutputs: []*dynamodb.BatchWriteItemOutput{
+					{UnprocessedItems: map[string][]types.WriteRequest{"event-table": {putRequest("Event#2")}}},
+					{},
+				},
+			},
+			expectedCalls: 2,
+		},
+		"Sad Path - Unprocessed Items Exhausted": {
+			requests: []types.WriteRequest{putRequest("Event#1")},
+			mockDynamo: &dynamo.Mock{
+				BatchWriteOutput: &dynamodb.BatchWriteItemOutput{
+					UnprocessedItems: map[string][]types.WriteRequest{"event-table": {putRequest("Event#1")}},
+				},
+			},
+			expectedFailures: []BatchItemError{{ID: "Event#1", Err: ErrUnprocessed}},
+		},
+		"Sad Path - Batch Write Error": {
+			requests: []types.WriteRequest{putRequest("Event#1")},
+			mockDynamo: &dynamo.Mock{
+				Err: errors.New("An error occured during Batch Write"),
+			},
+			expectedFailures: []BatchItemError{{ID: "Event#1", Err: errors.New("An error occured during Batch Write")}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			failures := batchWrite(context.Background(), tc.mockDynamo, zap.NewNop(), testBatchRetry, "event-table", tc.requests, putRequestID)
+			assert.Equal(t, tc.expectedFailures, failures)
+			assert.Equal(t, tc.expectedCalls, tc.mockDynamo.BatchWriteCallNum)
+		})
+	}
+}
+
+func TestBatchGet(t *testing.T) {
+	key := func(id string) map[string]types.AttributeValue {
+		return map[string]types.AttributeValue{receiverID: &types.AttributeValueMemberS{Value: id}}
+	}
+	keyID := func(k map[string]types.AttributeValue) string {
+		return stringKey(k, receiverID)
+	}
+
+	tests := map[string]struct {
+		keys             []map[string]types.AttributeValue
+		mockDynamo       *dynamo.Mock
+		expectedItems    []map[string]types.AttributeValue
+		expectedFailures []BatchItemError
+	}{
+		"Happy Path - Unprocessed Keys Retried": {
+			keys: []map[string]types.AttributeValue{key("Receiver#1"), key("Receiver#2")},
+			mockDynamo: &dynamo.Mock{
+				BatchGetOutputs: []*dynamodb.BatchGetItemOutput{
+					{
+						Responses:       map[string][]map[string]types.AttributeValue{"receiver-table": {key("Receiver#1")}},
+						UnprocessedKeys: map[string]types.KeysAndAttributes{"receiver-table": {Keys: []map[string]types.AttributeValue{key("Receiver#2")}}},
+					},
+					{
+						Responses: map[string][]map[string]types.AttributeValue{"receiver-table": {key("Receiver#2")}},
+					},
+				},
+			},
+			expectedItems: []map[string]types.AttributeValue{key("Receiver#1"), key("Receiver#2")},
+		},
+		"Sad Path - Unprocessed Keys Exhausted": {
+			keys: []map[string]types.AttributeValue{key("Receiver#1")},
+			mockDynamo: &dynamo.Mock{
+				BatchGetOutput: &dynamodb.BatchGetItemOutput{
+					UnprocessedKeys: map[string]types.KeysAndAttributes{"receiver-table": {Keys: []map[string]types.AttributeValue{key("Receiver#1")}}},
+				},
+			},
+			expectedFailures: []BatchItemError{{ID: "Receiver#1", Err: ErrUnprocessed}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			items, failures := batchGet(context.Background(), tc.mockDynamo, zap.NewNop(), testBatchRetry, "receiver-table", tc.keys, keyID)
+			assert.Equal(t, tc.expectedItems, items)
+			assert.Equal(t, tc.expectedFailures, failures)
+		})
+	}
+}
+
+func TestBatchError(t *testing.T) {
+	err := newBatchError([]BatchItemError{{ID: "Event#1", Err: ErrUnprocessed}, {ID: "Event#2", Err: ErrUnprocessed}})
+	assert.EqualError(t, err, "2 batch items failed: Event#1, Event#2")
+	assert.Nil(t, newBatchError(nil))
+}
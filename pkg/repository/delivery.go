@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/log"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/notify"
+	"go.uber.org/zap"
+)
+
+const (
+	deliveryRecipient = "recipient"
+	deliveryID        = "delivery_id"
+	deliveryExpiresAt = "expires_at"
+
+	deliveryClaimPrefix = "Claim#"
+)
+
+var (
+	deliveryKeySchema = CompositeKeySchema(deliveryRecipient, deliveryID, func(k DeliveryKey) (string, string) {
+		return k.Recipient, k.DeliveryID
+	})
+)
+
+type DeliveryKey struct {
+	Recipient  string
+	DeliveryID string
+}
+
+// deliveryClaim is a dedupe key claim or rate counter of a recipient. Claims
+// are stored under the recipient prefixed with deliveryClaimPrefix, so they
+// are kept apart from the recipient's delivery log entries.
+type deliveryClaim struct {
+	Recipient  string `dynamodbav:"recipient" log:"pii"`
+	DeliveryID string `dynamodbav:"delivery_id"`
+	Count      int    `dynamodbav:"count,omitempty"`
+	ExpiresAt  int64  `dynamodbav:"expires_at"`
+}
+
+func dedupeClaimKey(recipient string, key string, window time.Time) DeliveryKey {
+	return DeliveryKey{Recipient: deliveryClaimPrefix + recipient, DeliveryID: "Dedupe#" + notify.DeliveryIDAt(window) + "#" + key}
+}
+
+func rateClaimKey(recipient string, window time.Time) DeliveryKey {
+	return DeliveryKey{Recipient: deliveryClaimPrefix + recipient, DeliveryID: "Rate#" + notify.DeliveryIDAt(window)}
+}
+
+// DeliveryRepository is the delivery log of notify.Dispatcher. Entries and
+// claims carry an expires_at attribute for the table's time to live.
+type DeliveryRepository struct {
+	Client    DynamodbClientProvider
+	TableName string
+	logger    *zap.Logger
+}
+
+func NewDeliveryRepository(tableName string, client DynamodbClientProvider, logger *zap.Logger) *DeliveryRepository {
+	return &DeliveryRepository{
+		Client:    client,
+		TableName: tableName,
+		logger:    logger.With(zap.String(log.TableNameLogKey, tableName)),
+	}
+}
+
+func (dr *DeliveryRepository) table() *Table[notify.Delivery, DeliveryKey] {
+	return &Table[notify.Delivery, DeliveryKey]{
+		Name:   dr.TableName,
+		Client: dr.Client,
+		Schema: deliveryKeySchema,
+		logger: dr.logger,
+	}
+}
+
+func (dr *DeliveryRepository) RecordDelivery(ctx context.Context, d notify.Delivery) error {
+	log.WithTraceContext(ctx, dr.logger).Info("recording delivery", zap.String(log.MessageIDLogKey, d.MessageID), zap.String("status", string(d.Status)))
+	return dr.table().Put(ctx, d)
+}
+
+func (dr *DeliveryRepository) GetDeliveries(ctx context.Context, recipient string, since time.Time) ([]notify.Delivery, error) {
+	log.WithTraceContext(ctx, dr.logger).Info("retrieving deliveries from db")
+
+	if recipient == "" {
+		return nil, fmt.Errorf("recipient is required")
+	}
+
+	return dr.table().Query(ctx, QueryParams{
+		KeyCondition: "#recipient = :recipient AND #id >= :since",
+		Names: map[string]string{
+			"#recipient": deliveryRecipient,
+			"#id":        deliveryID,
+		},
+		Values: map[string]types.AttributeValue{
+			":recipient": &types.AttributeValueMemberS{Value: recipient},
+			":since":     &types.AttributeValueMemberS{Value: notify.DeliveryIDAt(since)},
+		},
+	})
+}
+
+func (dr *DeliveryRepository) claims() *Table[deliveryClaim, DeliveryKey] {
+	return &Table[deliveryClaim, DeliveryKey]{
+		Name:   dr.TableName,
+		Client: dr.Client,
+		Schema: deliveryKeySchema,
+		logger: dr.logger,
+	}
+}
+
+func (dr *DeliveryRepository) ClaimDedupeKey(ctx context.Context, recipient string, key string, window time.Time, expiresAt time.Time) error {
+	log.WithTraceContext(ctx, dr.logger).Info("claiming dedupe key")
+
+	k := dedupeClaimKey(recipient, key, window)
+	err := dr.claims().Put(ctx, deliveryClaim{Recipient: k.Recipient, DeliveryID: k.DeliveryID, ExpiresAt: expiresAt.Unix()}, Condition{
+		Expression: "attribute_not_exists(#pk)",
+		Names:      map[string]string{"#pk": deliveryRecipient},
+	})
+	if errors.Is(err, ErrConditionFailed) {
+		return notify.ErrDuplicate
+	}
+	return err
+}
+
+func (dr *DeliveryRepository) ReleaseDedupeKey(ctx context.Context, recipient string, key string, window time.Time) error {
+	log.WithTraceContext(ctx, dr.logger).Info("releasing dedupe key")
+	return dr.claims().Delete(ctx, dedupeClaimKey(recipient, key, window))
+}
+
+// CountDelivery increments the rate counter of the window in one conditional
+// update, which leaves it alone once it has reached limit.
+func (dr *DeliveryRepository) CountDelivery(ctx context.Context, recipient string, window time.Time, limit int, expiresAt time.Time) error {
+	log.WithTraceContext(ctx, dr.logger).Info("counting delivery")
+
+	t := dr.claims()
+	_, err := t.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(t.Name),
+		Key:                 t.Schema.Key(rateClaimKey(recipient, window)),
+		UpdateExpression:    aws.String("ADD #count :one SET #expires = :expires"),
+		ConditionExpression: aws.String("attribute_not_exists(#count) OR #count < :limit"),
+		ExpressionAttributeNames: map[string]string{
+			"#count":   "count",
+			"#expires": deliveryExpiresAt,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":one":     &types.AttributeValueMemberN{Value: "1"},
+			":limit":   &types.AttributeValueMemberN{Value: strconv.Itoa(limit)},
+			":expires": &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt.Unix(), 10)},
+		},
+	})
+	if err != nil {
+		err = t.wrapError(ctx, "counting delivery", err)
+		if errors.Is(err, ErrConditionFailed) {
+			return notify.ErrRateLimited
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,847 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/dynamo"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/encryption"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/escalation"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/event"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/invitation"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/notify"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/receiver"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/user"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+const (
+	testUserTable         = "user-table"
+	testReceiverTable     = "receiver-table"
+	testEventTable        = "event-table"
+	testRelationshipTable = "relationship-table"
+	testInvitationTable   = "invitation-table"
+	testDeliveryTable     = "delivery-table"
+	testPolicyTable       = "escalation-policy-table"
+	testStateTable        = "escalation-state-table"
+)
+
+// newTestEmulator returns an emulator holding the production table layouts.
+// A page size of one makes every multi-item query span several pages.
+func newTestEmulator() *dynamo.Emulator {
+	e := dynamo.NewEmulator(TableNames{
+		Users:              testUserTable,
+		Receivers:          testReceiverTable,
+		Events:             testEventTable,
+		Relationships:      testRelationshipTable,
+		Invitations:        testInvitationTable,
+		Deliveries:         testDeliveryTable,
+		EscalationPolicies: testPolicyTable,
+		EscalationStates:   testStateTable,
+	}.Schemas()...)
+	e.PageSize = 1
+	return e
+}
+
+func TestEmulator_Users(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserRepositoryV2(testUserTable, newTestEmulator(), zap.NewNop())
+
+	assert.NoError(t, repo.CreateUser(ctx, user.User{UserID: "User#1", Email: "one@example.com", FirstName: "One"}))
+	assert.NoError(t, repo.CreateUser(ctx, user.User{UserID: "User#2", Email: "two@example.com", FirstName: "Two"}))
+
+	u, err := repo.GetUserByEmail(ctx, "two@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "User#2", u.UserID)
+
+	_, err = repo.GetUserByEmail(ctx, "missing@example.com")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	updated, err := repo.UpdateUser(ctx, user.User{UserID: "User#1", FirstName: "Uno"}, []string{"firstName"})
+	assert.NoError(t, err)
+	assert.Equal(t, user.User{UserID: "User#1", Email: "one@example.com", FirstName: "Uno"}, updated)
+
+	_, err = repo.UpdateUser(ctx, user.User{UserID: "User#missing", FirstName: "Uno"}, []string{"firstName"})
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	assert.NoError(t, repo.ChangeEmail(ctx, "User#1", "new@example.com"))
+	u, err = repo.GetUserByEmail(ctx, "new@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "User#1", u.UserID)
+
+	assert.ErrorIs(t, repo.ChangeEmail(ctx, "User#2", "new@example.com"), ErrEmailInUse)
+	assert.ErrorIs(t, repo.ChangeEmail(ctx, "User#2", "NEW@example.com"), ErrEmailInUse)
+
+	assert.NoError(t, repo.CreateUser(ctx, user.User{UserID: "User#3", Email: "Three@Example.com"}))
+	u, err = repo.GetUserByEmail(ctx, "THREE@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, user.User{UserID: "User#3", Email: "three@example.com"}, u)
+	assert.ErrorIs(t, repo.CreateUser(ctx, user.User{UserID: "User#4", Email: "three@example.com"}), ErrEmailInUse)
+
+	claim, err := repo.GetUser(ctx, emailClaimKey("three@example.com"))
+	assert.NoError(t, err)
+	assert.Equal(t, user.User{}, claim)
+}
+
+func TestEmulator_UsersBackfillEmailClaims(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserRepositoryV2(testUserTable, newTestEmulator(), zap.NewNop())
+
+	// Users stored before addresses were lowercased and claimed.
+	for _, u := range []user.User{
+		{UserID: "User#bob", Email: "Bob@Example.com"},
+		{UserID: "User#ann", Email: "ann@example.com"},
+		{UserID: "User#robert", Email: "bob@example.com"},
+	} {
+		assert.NoError(t, repo.table().Put(ctx, u))
+	}
+
+	assert.ErrorIs(t, repo.CreateUser(ctx, user.User{UserID: "User#new", Email: "Bob@Example.com"}), ErrEmailInUse)
+	assert.ErrorIs(t, repo.CreateUser(ctx, user.User{UserID: "User#new", Email: "ANN@example.com"}), ErrEmailInUse)
+
+	claimed, err := repo.BackfillEmailClaims(ctx)
+	assert.ErrorIs(t, err, ErrEmailInUse)
+	assert.Equal(t, 2, claimed)
+
+	assert.ErrorIs(t, repo.CreateUser(ctx, user.User{UserID: "User#new", Email: "BOB@example.com"}), ErrEmailInUse)
+	assert.NoError(t, repo.CreateUser(ctx, user.User{UserID: "User#new", Email: "new@example.com"}))
+
+	claimed, err = repo.BackfillEmailClaims(ctx)
+	assert.ErrorIs(t, err, ErrEmailInUse)
+	assert.Equal(t, 3, claimed)
+}
+
+func TestEmulator_Events(t *testing.T) {
+	ctx := context.Background()
+	repo := NewEventRepositoryV2(testEventTable, newTestEmulator(), zap.NewNop())
+
+	entries := []*event.Entry{}
+	for i := 0; i < 4; i++ {
+		entries = append(entries, &event.Entry{
+			ReceiverID: "Receiver#1",
+			EventID:    fmt.Sprintf("Event#%d", i),
+			StartTime:  fmt.Sprintf("2025-01-0%dT00:00:00Z", i+1),
+		})
+	}
+	entries = append(entries, &event.Entry{ReceiverID: "Receiver#2", EventID: "Event#9", StartTime: "2025-01-02T00:00:00Z"})
+	assert.NoError(t, repo.AddEvents(ctx, entries))
+
+	all, err := repo.GetEvents(ctx, "Receiver#1", TimestampBound{})
+	assert.NoError(t, err)
+	assert.Len(t, all, 4)
+
+	bounded, err := repo.GetEvents(ctx, "Receiver#1", TimestampBound{Lower: "2025-01-02T00:00:00Z", Upper: "2025-01-03T00:00:00Z"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Event#1", "Event#2"}, []string{bounded[0].EventID, bounded[1].EventID})
+
+	assert.NoError(t, repo.DeleteEvent(ctx, "Receiver#1", "Event#0"))
+	all, err = repo.GetEvents(ctx, "Receiver#1", TimestampBound{})
+	assert.NoError(t, err)
+	assert.Len(t, all, 3)
+}
+
+func TestEmulator_Relationships(t *testing.T) {
+	ctx := context.Background()
+	client := newTestEmulator()
+	repo := NewRelationshipRepositoryV2(testRelationshipTable, client, zap.NewNop())
+
+	assert.NoError(t, repo.AddRelationship(ctx, &relationship.Relationship{UserID: "User#1", ReceiverID: "Receiver#1", PrimaryCareGiver: true}))
+	assert.NoError(t, repo.AddRelationship(ctx, &relationship.Relationship{UserID: "User#2", ReceiverID: "Receiver#1"}))
+	assert.NoError(t, repo.AddRelationship(ctx, &relationship.Relationship{UserID: "User#2", ReceiverID: "Receiver#2"}))
+
+	// Only items carrying the sparse index key are returned by the index.
+	_, err := client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(testRelationshipTable),
+		Key: map[string]types.AttributeValue{
+			userID:     &types.AttributeValueMemberS{Value: "User#2"},
+			receiverID: &types.AttributeValueMemberS{Value: "Receiver#2"},
+		},
+		UpdateExpression:          aws.String("SET email_notifications = :true, email_notifications_gsi_pk = :one"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":true": &types.AttributeValueMemberBOOL{Value: true}, ":one": &types.AttributeValueMemberN{Value: "1"}},
+	})
+	assert.NoError(t, err)
+
+	byReceiver, err := repo.GetRelationshipsByReceiver(ctx, "Receiver#1")
+	assert.NoError(t, err)
+	assert.Len(t, byReceiver, 2)
+
+	byUser, err := repo.GetRelationshipsByUser(ctx, "User#2")
+	assert.NoError(t, err)
+	assert.Len(t, byUser, 2)
+
+	notified, err := repo.GetRelationshipsByEmailNotifications(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []relationship.Relationship{{UserID: "User#2", ReceiverID: "Receiver#2", EmailNotifications: true}}, notified)
+}
+
+func TestEmulator_OnboardAndDeleteReceiver(t *testing.T) {
+	ctx := context.Background()
+	client := newTestEmulator()
+	logger := zap.NewNop()
+
+	onboarding := NewOnboardingRepository(testReceiverTable, testRelationshipTable, client, logger)
+	receivers := NewReceiverRepositoryV2(testReceiverTable, client, logger)
+	events := NewEventRepositoryV2(testEventTable, client, logger)
+	relationships := NewRelationshipRepositoryV2(testRelationshipTable, client, logger)
+
+	rel := &relationship.Relationship{UserID: "User#1", ReceiverID: "Receiver#1", PrimaryCareGiver: true}
+	assert.NoError(t, onboarding.OnboardReceiver(ctx, receiver.Receiver{ReceiverID: "Receiver#1", FirstName: "Ada"}, rel))
+	assert.ErrorIs(t, onboarding.OnboardReceiver(ctx, receiver.Receiver{ReceiverID: "Receiver#1"}, rel), ErrReceiverExists)
+
+	assert.NoError(t, relationships.AddRelationship(ctx, &relationship.Relationship{UserID: "User#2", ReceiverID: "Receiver#1"}))
+	assert.NoError(t, events.AddEvent(ctx, &event.Entry{ReceiverID: "Receiver#1", EventID: "Event#1", StartTime: "2025-01-01T00:00:00Z"}))
+
+	deleter := NewReceiverDeleter(receivers, events, relationships, logger)
+	_, err := deleter.DeleteReceiver(ctx, "User#2", "Receiver#1", DeleteReceiverOptions{})
+	assert.ErrorIs(t, err, ErrNotPrimaryCareGiver)
+
+	report, err := deleter.DeleteReceiver(ctx, "User#1", "Receiver#1", DeleteReceiverOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Event#1"}, report.EventIDs)
+
+	assert.Empty(t, client.Items(testReceiverTable))
+	assert.Empty(t, client.Items(testEventTable))
+	assert.Empty(t, client.Items(testRelationshipTable))
+}
+
+func TestEmulator_Encryption(t *testing.T) {
+	ctx := context.Background()
+	client := newTestEmulator()
+	logger := zap.NewNop()
+
+	keys, err := encryption.NewKeyRing()
+	assert.NoError(t, err)
+	encryptor := encryption.NewEncryptor(keys)
+
+	plain := NewEventRepositoryV2(testEventTable, client, logger)
+	events := NewEventRepositoryV2(testEventTable, client, logger)
+	events.Encryptor = encryptor
+
+	legacy := event.Entry{ReceiverID: "Receiver#1", EventID: "Event#0", StartTime: "2025-01-01T00:00:00Z", Note: "written before encryption"}
+	assert.NoError(t, plain.AddEvent(ctx, &legacy))
+
+	first := event.Entry{ReceiverID: "Receiver#1", EventID: "Event#1", StartTime: "2025-01-02T00:00:00Z", Note: "felt dizzy", Data: []event.DataPoint{{Name: "Systolic", Value: 140.5}}}
+	assert.NoError(t, events.AddEvent(ctx, &first))
+
+	oldKey := keys.CurrentKeyID()
+	_, err = keys.Rotate()
+	assert.NoError(t, err)
+	encryptor.KeyMaxAge = 0
+
+	second := event.Entry{ReceiverID: "Receiver#1", EventID: "Event#2", StartTime: "2025-01-03T00:00:00Z", Note: "slept well"}
+	assert.NoError(t, events.AddEvents(ctx, []*event.Entry{&second}))
+
+	var keyIDs []string
+	for _, item := range client.Items(testEventTable) {
+		keyIDs = append(keyIDs, encryption.KeyIDs(item, []string{"note", "data"})...)
+	}
+	assert.ElementsMatch(t, []string{oldKey, oldKey, keys.CurrentKeyID()}, keyIDs)
+
+	got, err := events.GetEvents(ctx, "Receiver#1", TimestampBound{})
+	assert.NoError(t, err)
+	assert.Equal(t, []event.Entry{legacy, first, second}, got)
+
+	_, err = plain.GetEvents(ctx, "Receiver#1", TimestampBound{})
+	assert.Error(t, err)
+
+	moved := maps.Clone(client.Items(testEventTable)[1])
+	moved["receiver_id"] = &types.AttributeValueMemberS{Value: "Receiver#2"}
+	_, err = client.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(testEventTable), Item: moved})
+	assert.NoError(t, err)
+	_, err = events.GetEvents(ctx, "Receiver#2", TimestampBound{})
+	assert.ErrorIs(t, err, encryption.ErrInvalidEnvelope)
+
+	receivers := NewReceiverRepositoryV2(testReceiverTable, client, logger)
+	receivers.Encryptor = encryptor
+	onboarding := NewOnboardingRepository(testReceiverTable, testRelationshipTable, client, logger)
+	onboarding.Encryptor = encryptor
+
+	rel := &relationship.Relationship{UserID: "User#1", ReceiverID: "Receiver#1", PrimaryCareGiver: true}
+	assert.NoError(t, onboarding.OnboardReceiver(ctx, receiver.Receiver{ReceiverID: "Receiver#1", FirstName: "Ada", LastName: "Lovelace"}, rel))
+
+	updated, err := receivers.UpdateReceiver(ctx, receiver.Receiver{ReceiverID: "Receiver#1", FirstName: "Augusta"}, []string{"firstName"})
+	assert.NoError(t, err)
+	assert.Equal(t, receiver.Receiver{ReceiverID: "Receiver#1", FirstName: "Augusta", LastName: "Lovelace"}, updated)
+	assert.Len(t, encryption.KeyIDs(client.Items(testReceiverTable)[0], []string{"first_name", "last_name"}), 2)
+
+	got2, err := receivers.GetReceivers(ctx, []string{"Receiver#1"})
+	assert.NoError(t, err)
+	assert.Equal(t, []receiver.Receiver{updated}, got2)
+}
+
+func TestEmulator_RelationshipRoles(t *testing.T) {
+	ctx := context.Background()
+	client := newTestEmulator()
+	logger := zap.NewNop()
+
+	onboarding := NewOnboardingRepository(testReceiverTable, testRelationshipTable, client, logger)
+	relationships := NewRelationshipRepositoryV2(testRelationshipTable, client, logger)
+
+	legacy := &relationship.Relationship{UserID: "User#1", ReceiverID: "Receiver#1", PrimaryCareGiver: true}
+	assert.NoError(t, onboarding.OnboardReceiver(ctx, receiver.Receiver{ReceiverID: "Receiver#1"}, legacy))
+	assert.NoError(t, relationships.AddRelationship(ctx, &relationship.Relationship{UserID: "User#2", ReceiverID: "Receiver#1"}))
+	assert.NoError(t, relationships.AddRelationship(ctx, relationship.NewRelationshipWithRole("User#3", "Receiver#1", relationship.RoleEditor, false)))
+	assert.ErrorIs(t, relationships.AddRelationship(ctx, &relationship.Relationship{UserID: "User#4", ReceiverID: "Receiver#1", Role: "admin"}), ErrInvalidRole)
+
+	assert.ErrorIs(t, onboarding.RemoveReceiver(ctx, "Receiver#1", "User#2"), ErrNotPrimaryCareGiver)
+
+	migrated, err := relationships.MigrateRoles(ctx, "Receiver#1")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, migrated)
+
+	migrated, err = relationships.MigrateRoles(ctx, "Receiver#1")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, migrated)
+
+	got, err := relationships.GetRelationshipsByReceiver(ctx, "Receiver#1")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []relationship.Relationship{
+		{UserID: "User#1", ReceiverID: "Receiver#1", Role: relationship.RoleOwner, PrimaryCareGiver: true},
+		{UserID: "User#2", ReceiverID: "Receiver#1", Role: relationship.RoleContributor},
+		{UserID: "User#3", ReceiverID: "Receiver#1", Role: relationship.RoleEditor},
+	}, got)
+
+	assert.ErrorIs(t, onboarding.RemoveReceiver(ctx, "Receiver#1", "User#3"), ErrNotPrimaryCareGiver)
+	assert.NoError(t, onboarding.RemoveReceiver(ctx, "Receiver#1", "User#1"))
+
+	got, err = relationships.GetRelationshipsByReceiver(ctx, "Receiver#1")
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestEmulator_TransferPrimary(t *testing.T) {
+	ctx := context.Background()
+	client := newTestEmulator()
+	relationships := NewRelationshipRepositoryV2(testRelationshipTable, client, zap.NewNop())
+
+	assert.NoError(t, relationships.AddRelationship(ctx, &relationship.Relationship{UserID: "User#1", ReceiverID: "Receiver#1", PrimaryCareGiver: true, EmailNotifications: true}))
+	assert.NoError(t, relationships.AddRelationship(ctx, relationship.NewRelationship("User#2", "Receiver#1", false, true)))
+
+	assert.ErrorIs(t, relationships.TransferPrimary(ctx, "Receiver#1", "User#2", "User#1"), ErrNotPrimaryCareGiver)
+	assert.ErrorIs(t, relationships.TransferPrimary(ctx, "Receiver#1", "User#1", "User#missing"), ErrRelationshipNotFound)
+	assert.Error(t, relationships.TransferPrimary(ctx, "Receiver#1", "User#1", "User#1"))
+
+	assert.NoError(t, relationships.TransferPrimary(ctx, "Receiver#1", "User#1", "User#2"))
+
+	got, err := relationships.GetRelationshipsByReceiver(ctx, "Receiver#1")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []relationship.Relationship{
+		*relationship.NewRelationshipWithRole("User#1", "Receiver#1", relationship.RoleEditor, true),
+		*relationship.NewRelationshipWithRole("User#2", "Receiver#1", relationship.RoleOwner, true),
+	}, got)
+
+	assert.ErrorIs(t, relationships.DeleteRelationship(ctx, "User#2", "Receiver#1"), ErrLastPrimaryCareGiver)
+	assert.NoError(t, relationships.DeleteRelationship(ctx, "User#1", "Receiver#1"))
+	assert.NoError(t, relationships.DeleteRelationship(ctx, "User#2", "Receiver#1"))
+	assert.Empty(t, client.Items(testRelationshipTable))
+}
+
+func TestEmulator_EmailNotifications(t *testing.T) {
+	ctx := context.Background()
+	client := newTestEmulator()
+	relationships := NewRelationshipRepositoryV2(testRelationshipTable, client, zap.NewNop())
+
+	assert.NoError(t, relationships.AddRelationship(ctx, relationship.NewRelationship("User#1", "Receiver#1", true, false)))
+
+	digest := relationship.NotificationPreferences{Channels: []relationship.Channel{relationship.ChannelEmail}, Delivery: relationship.DeliveryDigest}
+	updated, err := relationships.UpdateNotificationPreferences(ctx, "User#1", "Receiver#1", digest)
+	assert.NoError(t, err)
+	assert.True(t, updated.EmailNotifications)
+	assert.Equal(t, &digest, updated.Notifications)
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "1"}, client.Items(testRelationshipTable)[0]["email_notifications_gsi_pk"])
+
+	_, err = relationships.UpdateNotificationPreferences(ctx, "User#1", "Receiver#1", relationship.NotificationPreferences{Delivery: relationship.DeliveryDigest})
+	assert.NoError(t, err)
+	assert.NotContains(t, client.Items(testRelationshipTable)[0], "email_notifications_gsi_pk")
+
+	_, err = relationships.UpdateNotificationPreferences(ctx, "User#1", "Receiver#1", relationship.NotificationPreferences{Delivery: "hourly"})
+	assert.ErrorIs(t, err, ErrInvalidNotificationPreferences)
+	_, err = relationships.UpdateNotificationPreferences(ctx, "User#missing", "Receiver#1", digest)
+	assert.ErrorIs(t, err, ErrRelationshipNotFound)
+
+	// Items written before the repository kept the index key in step.
+	for _, item := range []map[string]types.AttributeValue{
+		{
+			"user_id":             &types.AttributeValueMemberS{Value: "User#2"},
+			"receiver_id":         &types.AttributeValueMemberS{Value: "Receiver#1"},
+			"email_notifications": &types.AttributeValueMemberBOOL{Value: true},
+		},
+		{
+			"user_id":                    &types.AttributeValueMemberS{Value: "User#3"},
+			"receiver_id":                &types.AttributeValueMemberS{Value: "Receiver#1"},
+			"email_notifications":        &types.AttributeValueMemberBOOL{Value: false},
+			"email_notifications_gsi_pk": &types.AttributeValueMemberN{Value: "1"},
+		},
+	} {
+		_, err := client.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(testRelationshipTable), Item: item})
+		assert.NoError(t, err)
+	}
+
+	repaired, err := relationships.BackfillEmailNotifications(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, repaired)
+
+	repaired, err = relationships.BackfillEmailNotifications(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, repaired)
+
+	got, err := relationships.GetRelationshipsByEmailNotifications(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []relationship.Relationship{{UserID: "User#2", ReceiverID: "Receiver#1", EmailNotifications: true}}, got)
+}
+
+func TestEmulator_NotificationResolver(t *testing.T) {
+	ctx := context.Background()
+	relationships := NewRelationshipRepositoryV2(testRelationshipTable, newTestEmulator(), zap.NewNop())
+	resolver := NewNotificationResolver(relationships, zap.NewNop())
+	resolver.now = func() time.Time { return time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC) }
+
+	sms := relationship.NewRelationship("User#1", "Receiver#1", true, false)
+	sms.SetNotifications(relationship.NotificationPreferences{
+		Channels:    []relationship.Channel{relationship.ChannelSMS},
+		AlertLevels: []event.AlertLevel{event.AlertLevelCritical},
+		Delivery:    relationship.DeliveryImmediate,
+	})
+	assert.NoError(t, relationships.AddRelationship(ctx, sms))
+	assert.NoError(t, relationships.AddRelationship(ctx, relationship.NewRelationship("User#2", "Receiver#1", false, true)))
+	assert.ErrorIs(t, relationships.AddRelationship(ctx, &relationship.Relationship{
+		UserID:        "User#3",
+		ReceiverID:    "Receiver#1",
+		Notifications: &relationship.NotificationPreferences{Channels: []relationship.Channel{"pager"}},
+	}), ErrInvalidNotificationPreferences)
+
+	critical := relationship.Subject{EventType: "Medication", Level: event.AlertLevelCritical}
+	tests := map[string]struct {
+		uid      string
+		subject  relationship.Subject
+		expected relationship.Decision
+	}{
+		"Happy Path - Immediate SMS": {
+			uid:      "User#1",
+			subject:  critical,
+			expected: relationship.Decision{Notify: true, Channels: []relationship.Channel{relationship.ChannelSMS}, Delivery: relationship.DeliveryImmediate},
+		},
+		"Happy Path - Level Not Selected": {
+			uid:     "User#1",
+			subject: relationship.Subject{EventType: "Medication", Level: event.AlertLevelRed},
+		},
+		"Happy Path - Legacy Email Digest": {
+			uid:      "User#2",
+			subject:  critical,
+			expected: relationship.Decision{Notify: true, Channels: []relationship.Channel{relationship.ChannelEmail}, Delivery: relationship.DeliveryDigest},
+		},
+		"Happy Path - Not A Care Giver": {
+			uid:     "User#missing",
+			subject: critical,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			d, err := resolver.Resolve(ctx, tc.uid, "Receiver#1", tc.subject)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, d)
+		})
+	}
+}
+
+func TestEmulator_Invitations(t *testing.T) {
+	ctx := context.Background()
+	client := newTestEmulator()
+	logger := zap.NewNop()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	users := NewUserRepositoryV2(testUserTable, client, logger)
+	invitations := NewInvitationRepository(testInvitationTable, testRelationshipTable, users, client, logger)
+	invitations.now = func() time.Time { return now }
+	relationships := NewRelationshipRepositoryV2(testRelationshipTable, client, logger)
+
+	for _, u := range []user.User{
+		{UserID: "User#invitee", Email: "invitee@example.com"},
+		{UserID: "User#other", Email: "other@example.com"},
+		{UserID: "User#revoked", Email: "revoked@example.com"},
+		{UserID: "User#late", Email: "late@example.com"},
+	} {
+		assert.NoError(t, users.CreateUser(ctx, u))
+	}
+
+	assert.NoError(t, relationships.AddRelationship(ctx, relationship.NewRelationship("User#owner", "Receiver#1", true, true)))
+	assert.NoError(t, relationships.AddRelationship(ctx, relationship.NewRelationshipWithRole("User#editor", "Receiver#1", relationship.RoleEditor, false)))
+
+	_, _, err := invitations.Invite(ctx, "User#editor", "Receiver#1", "invitee@example.com", relationship.RoleViewer)
+	assert.ErrorIs(t, err, ErrNotPermitted)
+	_, _, err = invitations.Invite(ctx, "User#owner", "Receiver#1", "invitee@example.com", relationship.RoleOwner)
+	assert.ErrorIs(t, err, ErrInvalidRole)
+
+	inv, token, err := invitations.Invite(ctx, "User#owner", "Receiver#1", "Invitee@Example.com", relationship.RoleEditor)
+	assert.NoError(t, err)
+	for _, item := range client.Items(testInvitationTable) {
+		assert.NotEqual(t, &types.AttributeValueMemberS{Value: token}, item["token_hash"])
+	}
+
+	resent, newToken, err := invitations.ResendInvitation(ctx, "User#owner", "Receiver#1", inv.InvitationID)
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, newToken)
+	assert.Equal(t, inv.InvitationID, resent.InvitationID)
+
+	_, err = invitations.AcceptInvitation(ctx, token, "User#invitee")
+	assert.ErrorIs(t, err, ErrInvitationNotFound)
+	_, err = invitations.AcceptInvitation(ctx, newToken, "User#other")
+	assert.ErrorIs(t, err, ErrInvitationEmailMismatch)
+	_, err = invitations.AcceptInvitation(ctx, newToken, "User#missing")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	rel, err := invitations.AcceptInvitation(ctx, newToken, "User#invitee")
+	assert.NoError(t, err)
+	assert.Equal(t, relationship.NewRelationshipWithRole("User#invitee", "Receiver#1", relationship.RoleEditor, false), rel)
+
+	_, err = invitations.AcceptInvitation(ctx, newToken, "User#invitee")
+	assert.ErrorIs(t, err, ErrInvitationNotPending)
+
+	stored, err := relationships.GetRelationship(ctx, "User#invitee", "Receiver#1")
+	assert.NoError(t, err)
+	assert.Equal(t, rel, stored)
+
+	expiring, expiringToken, err := invitations.Invite(ctx, "User#owner", "Receiver#1", "late@example.com", relationship.RoleViewer)
+	assert.NoError(t, err)
+	revoked, revokedToken, err := invitations.Invite(ctx, "User#owner", "Receiver#1", "revoked@example.com", relationship.RoleViewer)
+	assert.NoError(t, err)
+	assert.NoError(t, invitations.RevokeInvitation(ctx, "User#owner", "Receiver#1", revoked.InvitationID))
+	assert.ErrorIs(t, invitations.RevokeInvitation(ctx, "User#owner", "Receiver#1", revoked.InvitationID), ErrInvitationNotPending)
+	assert.ErrorIs(t, invitations.RevokeInvitation(ctx, "User#owner", "Receiver#1", "Invitation#missing"), ErrInvitationNotFound)
+
+	_, err = invitations.AcceptInvitation(ctx, revokedToken, "User#revoked")
+	assert.ErrorIs(t, err, ErrInvitationNotPending)
+
+	now = now.Add(invitation.DefaultTTL)
+	_, err = invitations.AcceptInvitation(ctx, expiringToken, "User#late")
+	assert.ErrorIs(t, err, ErrInvitationExpired)
+
+	listed, err := invitations.ListInvitations(ctx, "User#owner", "Receiver#1")
+	assert.NoError(t, err)
+	statuses := map[string]invitation.Status{}
+	for _, i := range listed {
+		statuses[i.InvitationID] = i.Status
+	}
+	assert.Equal(t, map[string]invitation.Status{
+		inv.InvitationID:      invitation.StatusAccepted,
+		expiring.InvitationID: invitation.StatusPending,
+		revoked.InvitationID:  invitation.StatusRevoked,
+	}, statuses)
+
+	_, err = invitations.ListInvitations(ctx, "User#invitee", "Receiver#1")
+	assert.ErrorIs(t, err, ErrNotPermitted)
+}
+
+func TestEmulator_Deliveries(t *testing.T) {
+	ctx := context.Background()
+	deliveries := NewDeliveryRepository(testDeliveryTable, newTestEmulator(), zap.NewNop())
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	recipient := notify.Recipient(relationship.ChannelSMS, "+15555550100")
+
+	for i, at := range []time.Time{start.Add(-2 * time.Hour), start, start.Add(time.Minute)} {
+		m := notify.Message{ID: fmt.Sprintf("Message#%d", i), Channel: relationship.ChannelSMS, To: "+15555550100"}
+		assert.NoError(t, deliveries.RecordDelivery(ctx, notify.NewDelivery(m, notify.StatusSent, nil, at)))
+	}
+	other := notify.Message{ID: "Message#other", Channel: relationship.ChannelEmail, To: "+15555550100"}
+	assert.NoError(t, deliveries.RecordDelivery(ctx, notify.NewDelivery(other, notify.StatusSent, nil, start)))
+
+	got, err := deliveries.GetDeliveries(ctx, recipient, start)
+	assert.NoError(t, err)
+	var ids []string
+	for _, d := range got {
+		ids = append(ids, d.MessageID)
+	}
+	assert.Equal(t, []string{"Message#1", "Message#2"}, ids)
+
+	window := start.Truncate(time.Hour)
+	assert.NoError(t, deliveries.ClaimDedupeKey(ctx, recipient, "alert", window, window.Add(time.Hour)))
+	assert.ErrorIs(t, deliveries.ClaimDedupeKey(ctx, recipient, "alert", window, window.Add(time.Hour)), notify.ErrDuplicate)
+	assert.NoError(t, deliveries.ClaimDedupeKey(ctx, recipient, "alert", window.Add(time.Hour), window.Add(2*time.Hour)))
+	assert.NoError(t, deliveries.ReleaseDedupeKey(ctx, recipient, "alert", window))
+	assert.NoError(t, deliveries.ClaimDedupeKey(ctx, recipient, "alert", window, window.Add(time.Hour)))
+
+	assert.NoError(t, deliveries.CountDelivery(ctx, recipient, window, 2, window.Add(time.Hour)))
+	assert.NoError(t, deliveries.CountDelivery(ctx, recipient, window, 2, window.Add(time.Hour)))
+	assert.ErrorIs(t, deliveries.CountDelivery(ctx, recipient, window, 2, window.Add(time.Hour)), notify.ErrRateLimited)
+	assert.NoError(t, deliveries.CountDelivery(ctx, recipient, window.Add(time.Hour), 2, window.Add(2*time.Hour)))
+
+	// Claims are kept apart from the delivery log.
+	got, err = deliveries.GetDeliveries(ctx, recipient, time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, got, 3)
+
+	sender := notify.NewMemorySender()
+	dispatcher := notify.NewDispatcher(map[relationship.Channel]notify.Sender{relationship.ChannelSMS: sender}, deliveries, zap.NewNop())
+	m := notify.Message{Channel: relationship.ChannelSMS, To: "+15555550199", DedupeKey: "alert", Text: "hello"}
+	assert.NoError(t, dispatcher.Send(ctx, m))
+	assert.ErrorIs(t, dispatcher.Send(ctx, m), notify.ErrDuplicate)
+	assert.Len(t, sender.Messages(), 1)
+
+	logged, err := deliveries.GetDeliveries(ctx, notify.Recipient(relationship.ChannelSMS, "+15555550199"), time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, logged, 2)
+}
+
+func TestEmulator_Escalation(t *testing.T) {
+	ctx := context.Background()
+	client := newTestEmulator()
+	users := NewUserRepositoryV2(testUserTable, client, zap.NewNop())
+	receivers := NewReceiverRepositoryV2(testReceiverTable, client, zap.NewNop())
+	relationships := NewRelationshipRepositoryV2(testRelationshipTable, client, zap.NewNop())
+	escalations := NewEscalationRepository(testPolicyTable, testStateTable, client, zap.NewNop())
+	sender := notify.NewMemorySender()
+
+	assert.NoError(t, users.CreateUser(ctx, user.User{UserID: "User#owner", Email: "owner@example.com", FirstName: "Owner"}))
+	assert.NoError(t, users.CreateUser(ctx, user.User{UserID: "User#helper", Email: "helper@example.com", FirstName: "Helper"}))
+	assert.NoError(t, users.CreateUser(ctx, user.User{UserID: "User#digest", Email: "digest@example.com", FirstName: "Digest"}))
+	assert.NoError(t, receivers.CreateReceiver(ctx, receiver.Receiver{ReceiverID: "Receiver#1", FirstName: "Bob", LastName: "Doe"}))
+	immediate := relationship.NotificationPreferences{Channels: []relationship.Channel{relationship.ChannelEmail}, Delivery: relationship.DeliveryImmediate}
+	for _, r := range []*relationship.Relationship{
+		relationship.NewRelationship("User#owner", "Receiver#1", true, false),
+		relationship.NewRelationship("User#helper", "Receiver#1", false, false),
+	} {
+		r.SetNotifications(immediate)
+		assert.NoError(t, relationships.AddRelationship(ctx, r))
+	}
+	digest := relationship.NewRelationship("User#digest", "Receiver#1", false, true)
+	digest.SetNotifications(relationship.NotificationPreferences{Channels: []relationship.Channel{relationship.ChannelEmail}, Delivery: relationship.DeliveryDigest})
+	assert.NoError(t, relationships.AddRelationship(ctx, digest))
+
+	assert.ErrorIs(t, escalations.PutPolicy(ctx, escalation.Policy{ReceiverID: "Receiver#1", EmergencyAfterMinutes: -1}), ErrInvalidEscalationPolicy)
+	assert.NoError(t, escalations.PutPolicy(ctx, escalation.Policy{
+		ReceiverID:            "Receiver#1",
+		EventTypes:            []string{"Medication"},
+		EmergencyContact:      &escalation.Contact{Name: "Ann", Channel: relationship.ChannelSMS, To: "+15555550100"},
+		EmergencyAfterMinutes: 30,
+	}))
+
+	ee := NewEscalationEvaluator(escalations, relationships, users, receivers, sender, zap.NewNop())
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	ee.now = func() time.Time { return now }
+
+	medication := func(level event.AlertLevel) []event.MonitorStatus {
+		return []event.MonitorStatus{
+			{EventType: "Medication", Level: level, LastLogged: now.Add(-13 * time.Hour), Elapsed: 13 * time.Hour},
+			{EventType: "Shower", Level: event.AlertLevelCritical},
+		}
+	}
+	recipients := func() []string {
+		var to []string
+		for _, m := range sender.Messages() {
+			to = append(to, m.To)
+		}
+		return to
+	}
+
+	assert.NoError(t, ee.Evaluate(ctx, "Receiver#1", medication(event.AlertLevelRed)))
+	assert.NoError(t, ee.Evaluate(ctx, "Receiver#1", medication(event.AlertLevelRed)))
+	assert.Equal(t, []string{"owner@example.com"}, recipients())
+	assert.Equal(t, "Bob Doe: Medication is Red, last logged 13 hours ago.", sender.Messages()[0].Text)
+
+	assert.NoError(t, ee.Evaluate(ctx, "Receiver#1", medication(event.AlertLevelCritical)))
+	assert.ElementsMatch(t, []string{"owner@example.com", "owner@example.com", "helper@example.com", "digest@example.com"}, recipients())
+
+	now = now.Add(29 * time.Minute)
+	assert.NoError(t, ee.Evaluate(ctx, "Receiver#1", medication(event.AlertLevelCritical)))
+	assert.Len(t, sender.Messages(), 4)
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, ee.Evaluate(ctx, "Receiver#1", medication(event.AlertLevelCritical)))
+	assert.Len(t, sender.Messages(), 5)
+	assert.Equal(t, "+15555550100", sender.Messages()[4].To)
+	assert.Equal(t, relationship.ChannelSMS, sender.Messages()[4].Channel)
+
+	state, err := escalations.GetState(ctx, "Receiver#1", "Medication")
+	assert.NoError(t, err)
+	assert.Equal(t, escalation.StepEmergency, state.Step)
+
+	assert.NoError(t, ee.Evaluate(ctx, "Receiver#1", medication(event.AlertLevelGreen)))
+	state, err = escalations.GetState(ctx, "Receiver#1", "Medication")
+	assert.NoError(t, err)
+	assert.Nil(t, state)
+
+	_, err = ee.Acknowledge(ctx, "User#helper", "Receiver#1", "Medication")
+	assert.ErrorIs(t, err, ErrAlertNotFound)
+
+	assert.NoError(t, ee.Evaluate(ctx, "Receiver#1", medication(event.AlertLevelCritical)))
+	assert.Len(t, sender.Messages(), 8)
+
+	_, err = ee.Acknowledge(ctx, "User#stranger", "Receiver#1", "Medication")
+	assert.ErrorIs(t, err, ErrNotPermitted)
+	acked, err := ee.Acknowledge(ctx, "User#helper", "Receiver#1", "Medication")
+	assert.NoError(t, err)
+	assert.Equal(t, "User#helper", acked.AcknowledgedBy)
+
+	now = now.Add(time.Hour)
+	assert.NoError(t, ee.Evaluate(ctx, "Receiver#1", medication(event.AlertLevelCritical)))
+	assert.Len(t, sender.Messages(), 8)
+
+	assert.NoError(t, escalations.DeletePolicy(ctx, "Receiver#1"))
+	assert.NoError(t, ee.Evaluate(ctx, "Receiver#1", medication(event.AlertLevelGreen)))
+	state, err = escalations.GetState(ctx, "Receiver#1", "Medication")
+	assert.NoError(t, err)
+	assert.NotNil(t, state)
+}
+
+func TestEmulator_EscalationWithoutPreferences(t *testing.T) {
+	ctx := context.Background()
+	client := newTestEmulator()
+	users := NewUserRepositoryV2(testUserTable, client, zap.NewNop())
+	receivers := NewReceiverRepositoryV2(testReceiverTable, client, zap.NewNop())
+	relationships := NewRelationshipRepositoryV2(testRelationshipTable, client, zap.NewNop())
+	escalations := NewEscalationRepository(testPolicyTable, testStateTable, client, zap.NewNop())
+	sender := notify.NewMemorySender()
+
+	assert.NoError(t, users.CreateUser(ctx, user.User{UserID: "User#owner", Email: "owner@example.com"}))
+	assert.NoError(t, users.CreateUser(ctx, user.User{UserID: "User#helper", Email: "helper@example.com"}))
+	assert.NoError(t, receivers.CreateReceiver(ctx, receiver.Receiver{ReceiverID: "Receiver#1", FirstName: "Bob"}))
+	assert.NoError(t, relationships.AddRelationship(ctx, relationship.NewRelationship("User#owner", "Receiver#1", true, false)))
+	assert.NoError(t, relationships.AddRelationship(ctx, relationship.NewRelationship("User#helper", "Receiver#1", false, true)))
+	assert.NoError(t, escalations.PutPolicy(ctx, escalation.Policy{
+		ReceiverID:       "Receiver#1",
+		EmergencyContact: &escalation.Contact{Name: "Ann", Channel: relationship.ChannelSMS, To: "+15555550100"},
+	}))
+
+	ee := NewEscalationEvaluator(escalations, relationships, users, receivers, sender, zap.NewNop())
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	ee.now = func() time.Time { return now }
+	ee.Notifications.now = ee.now
+
+	assert.NoError(t, ee.Evaluate(ctx, "Receiver#1", []event.MonitorStatus{{EventType: "Medication", Level: event.AlertLevelRed}}))
+	assert.NoError(t, ee.Evaluate(ctx, "Receiver#1", []event.MonitorStatus{{EventType: "Medication", Level: event.AlertLevelCritical}}))
+	var to []string
+	for _, m := range sender.Messages() {
+		to = append(to, m.To)
+	}
+	assert.ElementsMatch(t, []string{"owner@example.com", "owner@example.com", "helper@example.com"}, to)
+}
+
+func TestEmulator_EscalationWithoutRecipients(t *testing.T) {
+	ctx := context.Background()
+	client := newTestEmulator()
+	users := NewUserRepositoryV2(testUserTable, client, zap.NewNop())
+	receivers := NewReceiverRepositoryV2(testReceiverTable, client, zap.NewNop())
+	relationships := NewRelationshipRepositoryV2(testRelationshipTable, client, zap.NewNop())
+	escalations := NewEscalationRepository(testPolicyTable, testStateTable, client, zap.NewNop())
+	sender := notify.NewMemorySender()
+
+	assert.NoError(t, users.CreateUser(ctx, user.User{UserID: "User#owner", Email: "owner@example.com"}))
+	assert.NoError(t, receivers.CreateReceiver(ctx, receiver.Receiver{ReceiverID: "Receiver#1", FirstName: "Bob"}))
+	owner := relationship.NewRelationship("User#owner", "Receiver#1", true, false)
+	owner.SetNotifications(relationship.NotificationPreferences{
+		Channels:   []relationship.Channel{relationship.ChannelEmail},
+		EventTypes: []string{"Shower"},
+		Delivery:   relationship.DeliveryImmediate,
+	})
+	assert.NoError(t, relationships.AddRelationship(ctx, owner))
+	assert.NoError(t, escalations.PutPolicy(ctx, escalation.Policy{
+		ReceiverID:            "Receiver#1",
+		EmergencyContact:      &escalation.Contact{Name: "Ann", Channel: relationship.ChannelSMS, To: "+15555550100"},
+		EmergencyAfterMinutes: 30,
+	}))
+
+	ee := NewEscalationEvaluator(escalations, relationships, users, receivers, sender, zap.NewNop())
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	ee.now = func() time.Time { return now }
+	ee.Notifications.now = ee.now
+	critical := []event.MonitorStatus{{EventType: "Medication", Level: event.AlertLevelCritical}}
+
+	assert.ErrorIs(t, ee.Evaluate(ctx, "Receiver#1", critical), ErrNoRecipients)
+	now = now.Add(time.Hour)
+	assert.ErrorIs(t, ee.Evaluate(ctx, "Receiver#1", critical), ErrNoRecipients)
+	assert.Empty(t, sender.Messages())
+
+	state, err := escalations.GetState(ctx, "Receiver#1", "Medication")
+	assert.NoError(t, err)
+	assert.Equal(t, escalation.StepNone, state.Step)
+}
+
+func TestEmulator_EscalationQuietHours(t *testing.T) {
+	ctx := context.Background()
+	client := newTestEmulator()
+	users := NewUserRepositoryV2(testUserTable, client, zap.NewNop())
+	receivers := NewReceiverRepositoryV2(testReceiverTable, client, zap.NewNop())
+	relationships := NewRelationshipRepositoryV2(testRelationshipTable, client, zap.NewNop())
+	escalations := NewEscalationRepository(testPolicyTable, testStateTable, client, zap.NewNop())
+	sender := notify.NewMemorySender()
+
+	assert.NoError(t, users.CreateUser(ctx, user.User{UserID: "User#owner", Email: "owner@example.com"}))
+	assert.NoError(t, receivers.CreateReceiver(ctx, receiver.Receiver{ReceiverID: "Receiver#1", FirstName: "Bob"}))
+	owner := relationship.NewRelationship("User#owner", "Receiver#1", true, false)
+	owner.SetNotifications(relationship.NotificationPreferences{
+		Channels:   []relationship.Channel{relationship.ChannelEmail},
+		Delivery:   relationship.DeliveryImmediate,
+		QuietHours: &relationship.QuietHours{Start: "22:00", End: "07:00"},
+	})
+	assert.NoError(t, relationships.AddRelationship(ctx, owner))
+	assert.NoError(t, escalations.PutPolicy(ctx, escalation.Policy{ReceiverID: "Receiver#1"}))
+
+	ee := NewEscalationEvaluator(escalations, relationships, users, receivers, sender, zap.NewNop())
+	now := time.Date(2025, 1, 1, 23, 0, 0, 0, time.UTC)
+	ee.now = func() time.Time { return now }
+	ee.Notifications.now = ee.now
+	red := []event.MonitorStatus{{EventType: "Medication", Level: event.AlertLevelRed}}
+
+	assert.NoError(t, ee.Evaluate(ctx, "Receiver#1", red))
+	assert.Empty(t, sender.Messages())
+	state, err := escalations.GetState(ctx, "Receiver#1", "Medication")
+	assert.NoError(t, err)
+	assert.Equal(t, escalation.StepNone, state.Step)
+
+	now = time.Date(2025, 1, 2, 7, 0, 0, 0, time.UTC)
+	assert.NoError(t, ee.Evaluate(ctx, "Receiver#1", red))
+	assert.Len(t, sender.Messages(), 1)
+	state, err = escalations.GetState(ctx, "Receiver#1", "Medication")
+	assert.NoError(t, err)
+	assert.Equal(t, escalation.StepPrimary, state.Step)
+}
+
+// acknowledgingEscalations acknowledges every alert it lists, as a care
+// giver acknowledging while an evaluation is running would.
+type acknowledgingEscalations struct {
+	EscalationRepositoryProvider
+	uid string
+}
+
+func (a acknowledgingEscalations) GetStates(ctx context.Context, rid string) ([]escalation.State, error) {
+	states, err := a.EscalationRepositoryProvider.GetStates(ctx, rid)
+	for _, s := range states {
+		s.Acknowledge(a.uid, time.Now())
+		if _, err := a.EscalationRepositoryProvider.UpdateState(ctx, s, acknowledgedStateFields); err != nil {
+			return nil, err
+		}
+	}
+	return states, err
+}
+
+func TestEmulator_EscalationKeepsConcurrentAcknowledgment(t *testing.T) {
+	ctx := context.Background()
+	client := newTestEmulator()
+	escalations := NewEscalationRepository(testPolicyTable, testStateTable, client, zap.NewNop())
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, escalations.PutPolicy(ctx, escalation.Policy{ReceiverID: "Receiver#1"}))
+	assert.NoError(t, escalations.PutState(ctx, escalation.State{ReceiverID: "Receiver#1", EventType: "Medication", Level: event.AlertLevelRed, Step: escalation.StepPrimary, RaisedAt: now.Add(-time.Hour)}))
+
+	_, err := escalations.UpdateState(ctx, escalation.State{ReceiverID: "Receiver#1", EventType: "Shower"}, evaluatedStateFields)
+	assert.ErrorIs(t, err, ErrAlertNotFound)
+
+	ee := NewEscalationEvaluator(acknowledgingEscalations{EscalationRepositoryProvider: escalations, uid: "User#helper"}, NewRelationshipRepositoryV2(testRelationshipTable, client, zap.NewNop()), nil, nil, notify.NewMemorySender(), zap.NewNop())
+	ee.now = func() time.Time { return now }
+
+	assert.NoError(t, ee.Evaluate(ctx, "Receiver#1", []event.MonitorStatus{{EventType: "Medication", Level: event.AlertLevelRed}}))
+
+	state, err := escalations.GetState(ctx, "Receiver#1", "Medication")
+	assert.NoError(t, err)
+	assert.Equal(t, "User#helper", state.AcknowledgedBy)
+	assert.Equal(t, escalation.StepPrimary, state.Step)
+}
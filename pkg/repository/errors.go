@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	conditionalCheckFailed = "ConditionalCheckFailed"
+	transactWriteLimit     = 100
+)
+
+var (
+	ErrItemNotFound    = errors.New("item not found")
+	ErrConditionFailed = errors.New("condition check failed")
+
+	ErrReceiverExists       = errors.New("receiver already exists")
+	ErrReceiverNotFound     = errors.New("receiver not found")
+	ErrRelationshipExists   = errors.New("relationship already exists")
+	ErrNotPrimaryCareGiver  = errors.New("user is not a primary care giver for the receiver")
+	ErrInvalidRole          = errors.New("invalid relationship role")
+	ErrRelationshipNotFound = errors.New("relationship not found")
+	ErrLastPrimaryCareGiver = errors.New("receiver must keep a primary care giver")
+
+	ErrInvalidNotificationPreferences = errors.New("invalid notification preferences")
+)
+
+// cancellationErrors maps each cancelled transaction item that failed its
+// condition check to the error registered for its position in the request.
+func cancellationErrors(err error, byIndex map[int]error) error {
+	var tce *types.TransactionCanceledException
+	if !errors.As(err, &tce) {
+		return err
+	}
+
+	var errs []error
+	for i, reason := range tce.CancellationReasons {
+		if reason.Code == nil || *reason.Code != conditionalCheckFailed {
+			continue
+		}
+		if mapped, ok := byIndex[i]; ok {
+			errs = append(errs, mapped)
+		}
+	}
+
+	if len(errs) == 0 {
+		return err
+	}
+	return errors.Join(errs...)
+}
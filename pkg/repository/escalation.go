@@ -0,0 +1,399 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/escalation"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/event"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/log"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/notify"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"go.uber.org/zap"
+)
+
+const (
+	escalationEventType = "event_type"
+)
+
+var (
+	escalationPolicyKeySchema = PartitionKeySchema(receiverID)
+	escalationStateKeySchema  = CompositeKeySchema(receiverID, escalationEventType, func(k EscalationStateKey) (string, string) {
+		return k.ReceiverID, k.EventType
+	})
+
+	ErrInvalidEscalationPolicy = errors.New("invalid escalation policy")
+	ErrAlertNotFound           = errors.New("alert not found")
+	ErrNoRecipients            = errors.New("no recipients to escalate to")
+
+	// errQuietHours holds back an escalation step whose recipients are all in
+	// their quiet hours, so it is retried once they are over.
+	errQuietHours = errors.New("recipients are in quiet hours")
+
+	// Evaluate and Acknowledge each update only the fields of a state they
+	// own, so neither overwrites what the other wrote concurrently.
+	evaluatedStateFields    = []string{"level", "step", "criticalAt"}
+	acknowledgedStateFields = []string{"acknowledgedBy", "acknowledgedAt", "acknowledgedLevel"}
+)
+
+type EscalationStateKey struct {
+	ReceiverID string
+	EventType  string
+}
+
+// EscalationRepositoryProvider stores the escalation policy of each
+// receiver and the escalation state of its open alerts. GetPolicy returns a
+// policy without a receiver id when the receiver has none, and GetState a
+// nil state when there is no alert. UpdateState writes only the fields of s
+// named in mask and fails with ErrAlertNotFound when there is no alert.
+type EscalationRepositoryProvider interface {
+	PutPolicy(ctx context.Context, p escalation.Policy) error
+	GetPolicy(ctx context.Context, rid string) (escalation.Policy, error)
+	DeletePolicy(ctx context.Context, rid string) error
+	GetState(ctx context.Context, rid string, eventType string) (*escalation.State, error)
+	GetStates(ctx context.Context, rid string) ([]escalation.State, error)
+	PutState(ctx context.Context, s escalation.State) error
+	UpdateState(ctx context.Context, s escalation.State, mask []string) (escalation.State, error)
+	DeleteState(ctx context.Context, rid string, eventType string) error
+}
+
+type EscalationRepository struct {
+	Client          DynamodbClientProvider
+	PolicyTableName string
+	StateTableName  string
+	logger          *zap.Logger
+}
+
+func NewEscalationRepository(policyTableName, stateTableName string, client DynamodbClientProvider, logger *zap.Logger) *EscalationRepository {
+	return &EscalationRepository{
+		Client:          client,
+		PolicyTableName: policyTableName,
+		StateTableName:  stateTableName,
+		logger:          logger,
+	}
+}
+
+func (er *EscalationRepository) policies() *Table[escalation.Policy, string] {
+	return NewTable[escalation.Policy](er.PolicyTableName, escalationPolicyKeySchema, er.Client, er.logger)
+}
+
+func (er *EscalationRepository) states() *Table[escalation.State, EscalationStateKey] {
+	return NewTable[escalation.State](er.StateTableName, escalationStateKeySchema, er.Client, er.logger)
+}
+
+func (er *EscalationRepository) PutPolicy(ctx context.Context, p escalation.Policy) error {
+	log.WithTraceContext(ctx, er.logger).Info("putting escalation policy", zap.String(log.ReceiverIDLogKey, p.ReceiverID))
+
+	if err := p.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidEscalationPolicy, err)
+	}
+	return er.policies().Put(ctx, p)
+}
+
+func (er *EscalationRepository) GetPolicy(ctx context.Context, rid string) (escalation.Policy, error) {
+	log.WithTraceContext(ctx, er.logger).Info("getting escalation policy", zap.String(log.ReceiverIDLogKey, rid))
+
+	p, err := er.policies().Get(ctx, rid)
+	if errors.Is(err, ErrItemNotFound) {
+		return escalation.Policy{}, nil
+	}
+	return p, err
+}
+
+func (er *EscalationRepository) DeletePolicy(ctx context.Context, rid string) error {
+	log.WithTraceContext(ctx, er.logger).Info("deleting escalation policy", zap.String(log.ReceiverIDLogKey, rid))
+	return er.policies().Delete(ctx, rid)
+}
+
+func (er *EscalationRepository) GetState(ctx context.Context, rid string, eventType string) (*escalation.State, error) {
+	s, err := er.states().Get(ctx, EscalationStateKey{ReceiverID: rid, EventType: eventType})
+	if errors.Is(err, ErrItemNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (er *EscalationRepository) GetStates(ctx context.Context, rid string) ([]escalation.State, error) {
+	return er.states().Query(ctx, QueryParams{
+		KeyCondition: "receiver_id = :rid",
+		Values: map[string]types.AttributeValue{
+			":rid": &types.AttributeValueMemberS{Value: rid},
+		},
+	})
+}
+
+func (er *EscalationRepository) PutState(ctx context.Context, s escalation.State) error {
+	log.WithTraceContext(ctx, er.logger).Info("putting escalation state", zap.String(log.ReceiverIDLogKey, s.ReceiverID), zap.String(log.EventLogKey, s.EventType), zap.String("step", string(s.Step)))
+	return er.states().Put(ctx, s)
+}
+
+func (er *EscalationRepository) UpdateState(ctx context.Context, s escalation.State, mask []string) (escalation.State, error) {
+	log.WithTraceContext(ctx, er.logger).Info("updating escalation state", zap.String(log.ReceiverIDLogKey, s.ReceiverID), zap.String(log.EventLogKey, s.EventType), zap.Strings("fields", mask))
+
+	updated, err := er.states().Update(ctx, EscalationStateKey{ReceiverID: s.ReceiverID, EventType: s.EventType}, s, mask)
+	if errors.Is(err, ErrConditionFailed) {
+		return escalation.State{}, ErrAlertNotFound
+	}
+	return updated, err
+}
+
+func (er *EscalationRepository) DeleteState(ctx context.Context, rid string, eventType string) error {
+	log.WithTraceContext(ctx, er.logger).Info("deleting escalation state", zap.String(log.ReceiverIDLogKey, rid), zap.String(log.EventLogKey, eventType))
+	return er.states().Delete(ctx, EscalationStateKey{ReceiverID: rid, EventType: eventType})
+}
+
+// EscalationEvaluator escalates the alerts of receivers with an escalation
+// policy. Evaluate is called with fresh monitor statuses of a receiver, for
+// example every few minutes; evaluations of the same receiver must not run
+// concurrently. Care givers are notified by email, as it is the only address
+// users have, when their notification preferences resolve to immediate email
+// notifications about the alert; those who chose a digest see red alerts
+// there. Critical alerts go out by email to everyone whose preferences cover
+// them, whatever their delivery, and care givers without stored preferences
+// get every alert by email. The emergency contact is notified on the channel
+// of the policy regardless.
+type EscalationEvaluator struct {
+	Escalations   EscalationRepositoryProvider
+	Relationships RelationshipRepositoryProviderV2
+	Users         UserRepositoryProviderV2
+	Receivers     ReceiverRepositoryProviderV2
+	Notifications *NotificationResolver
+	Sender        notify.Sender
+
+	now    func() time.Time
+	logger *zap.Logger
+}
+
+func NewEscalationEvaluator(escalations EscalationRepositoryProvider, relationships RelationshipRepositoryProviderV2, users UserRepositoryProviderV2, receivers ReceiverRepositoryProviderV2, sender notify.Sender, logger *zap.Logger) *EscalationEvaluator {
+	return &EscalationEvaluator{
+		Escalations:   escalations,
+		Relationships: relationships,
+		Users:         users,
+		Receivers:     receivers,
+		Notifications: NewNotificationResolver(relationships, logger),
+		Sender:        sender,
+		now:           time.Now,
+		logger:        logger,
+	}
+}
+
+// Evaluate advances the alerts of the receiver rid from its monitor
+// statuses, notifying whoever each alert has escalated to. Alerts whose event
+// type is no longer red or worse are closed.
+func (ee *EscalationEvaluator) Evaluate(ctx context.Context, rid string, statuses []event.MonitorStatus) error {
+	policy, err := ee.Escalations.GetPolicy(ctx, rid)
+	if err != nil {
+		return err
+	}
+	if policy.ReceiverID == "" {
+		return nil
+	}
+
+	states, err := ee.Escalations.GetStates(ctx, rid)
+	if err != nil {
+		return err
+	}
+	current := map[string]*escalation.State{}
+	for i := range states {
+		current[states[i].EventType] = &states[i]
+	}
+
+	now := ee.now()
+	var errs []error
+	for _, status := range statuses {
+		next, due := policy.Advance(current[status.EventType], status, now)
+		if next == nil {
+			if current[status.EventType] != nil {
+				log.WithTraceContext(ctx, ee.logger).Info("closing alert", zap.String(log.ReceiverIDLogKey, rid), zap.String(log.EventLogKey, status.EventType))
+				if err := ee.Escalations.DeleteState(ctx, rid, status.EventType); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			continue
+		}
+
+		c := current[status.EventType]
+		next.Step = escalation.StepNone
+		if c != nil {
+			next.Step = c.Step
+		}
+		for _, step := range due {
+			if err := ee.notify(ctx, policy, *next, status, step); err != nil {
+				if !errors.Is(err, errQuietHours) {
+					errs = append(errs, fmt.Errorf("escalating %s of receiver %s to %s: %w", status.EventType, rid, step, err))
+				}
+				break
+			}
+			next.Step = step
+		}
+
+		if c == nil {
+			err = ee.Escalations.PutState(ctx, *next)
+		} else {
+			_, err = ee.Escalations.UpdateState(ctx, *next, evaluatedStateFields)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Acknowledge records that the care giver uid is handling the alert on
+// eventType of the receiver rid, which holds back further escalation.
+func (ee *EscalationEvaluator) Acknowledge(ctx context.Context, uid string, rid string, eventType string) (*escalation.State, error) {
+	log.WithTraceContext(ctx, ee.logger).Info("acknowledging alert", zap.String(log.UserIDLogKey, uid), zap.String(log.ReceiverIDLogKey, rid), zap.String(log.EventLogKey, eventType))
+
+	r, err := ee.Relationships.GetRelationship(ctx, uid, rid)
+	if err != nil {
+		return nil, err
+	}
+	if r.UserID == "" {
+		return nil, ErrNotPermitted
+	}
+
+	s, err := ee.Escalations.GetState(ctx, rid, eventType)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, ErrAlertNotFound
+	}
+
+	s.Acknowledge(uid, ee.now())
+	updated, err := ee.Escalations.UpdateState(ctx, *s, acknowledgedStateFields)
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// notify sends the alert to the recipients of step. Messages already sent
+// for the alert are not sent again, and the step fails only when none of its
+// recipients could be notified. It fails with errQuietHours when every
+// recipient is in their quiet hours and with ErrNoRecipients when there is
+// nobody to notify, so the step is retried rather than skipped.
+func (ee *EscalationEvaluator) notify(ctx context.Context, policy escalation.Policy, s escalation.State, status event.MonitorStatus, step escalation.Step) error {
+	rec, err := ee.Receivers.GetReceiver(ctx, s.ReceiverID)
+	if err != nil {
+		return err
+	}
+	content, err := notify.Render(notify.DefaultTemplateVersion, notify.AlertTemplate, notify.Alert{
+		Receiver:   rec,
+		EventType:  status.EventType,
+		Level:      status.Level,
+		LastLogged: status.LastLogged,
+		Elapsed:    status.Elapsed,
+	})
+	if err != nil {
+		return err
+	}
+
+	messages, held, err := ee.recipients(ctx, policy, s, step)
+	if err != nil {
+		return err
+	}
+	if len(messages) == 0 && held {
+		log.WithTraceContext(ctx, ee.logger).Info("holding back escalation for quiet hours", zap.String(log.ReceiverIDLogKey, s.ReceiverID), zap.String("step", string(step)))
+		return errQuietHours
+	}
+	if len(messages) == 0 {
+		log.WithTraceContext(ctx, ee.logger).Warn("no recipients to escalate to", zap.String(log.ReceiverIDLogKey, s.ReceiverID), zap.String("step", string(step)))
+		return ErrNoRecipients
+	}
+
+	var errs []error
+	for _, m := range messages {
+		m.ReceiverID = s.ReceiverID
+		m.DedupeKey = fmt.Sprintf("%s#%s#%d#%s", s.ReceiverID, s.EventType, s.RaisedAt.Unix(), step)
+		m.Subject, m.Text, m.HTML = content.Subject, content.Text, content.HTML
+		m.TemplateVersion = notify.DefaultTemplateVersion
+
+		err := ee.Sender.Send(ctx, m)
+		if err != nil && !errors.Is(err, notify.ErrDuplicate) {
+			log.WithTraceContext(ctx, ee.logger).Error("error sending escalation", zap.String(log.UserIDLogKey, m.UserID), zap.String(log.ReceiverIDLogKey, s.ReceiverID), zap.Error(err))
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == len(messages) {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// recipients returns the messages, without content, to send for step, and
+// whether care givers were left out for being in their quiet hours.
+func (ee *EscalationEvaluator) recipients(ctx context.Context, policy escalation.Policy, s escalation.State, step escalation.Step) ([]notify.Message, bool, error) {
+	if step == escalation.StepEmergency {
+		c := policy.EmergencyContact
+		return []notify.Message{{Channel: c.Channel, To: c.To}}, false, nil
+	}
+
+	relationships, err := ee.Relationships.GetRelationshipsByReceiver(ctx, s.ReceiverID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	now := ee.now()
+	var messages []notify.Message
+	var held bool
+	for _, r := range relationships {
+		if step == escalation.StepPrimary && r.EffectiveRole() != relationship.RoleOwner {
+			continue
+		}
+		notBefore, ok, err := ee.alertable(ctx, r, s)
+		if err != nil {
+			return nil, false, err
+		}
+		if !ok {
+			continue
+		}
+		if notBefore.After(now) {
+			held = true
+			continue
+		}
+
+		u, err := ee.Users.GetUser(ctx, r.UserID)
+		if err != nil {
+			return nil, false, err
+		}
+		if u.Email == "" {
+			continue
+		}
+		messages = append(messages, notify.Message{Channel: relationship.ChannelEmail, To: u.Email, UserID: u.UserID})
+	}
+	return messages, held, nil
+}
+
+// alertable reports whether the care giver of r is alerted by email about
+// the alert s, and when, from the notification preferences of r.
+// Relationships without stored preferences are alerted right away, and
+// critical alerts are sent whatever the delivery and channels the
+// preferences ask for.
+func (ee *EscalationEvaluator) alertable(ctx context.Context, r relationship.Relationship, s escalation.State) (time.Time, bool, error) {
+	if r.Notifications == nil {
+		return time.Time{}, true, nil
+	}
+
+	d, err := ee.Notifications.Resolve(ctx, r.UserID, s.ReceiverID, relationship.Subject{EventType: s.EventType, Level: s.Level})
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	switch {
+	case !d.Notify:
+		return time.Time{}, false, nil
+	case s.Level == event.AlertLevelCritical:
+		return time.Time{}, true, nil
+	case d.Delivery != relationship.DeliveryImmediate || !slices.Contains(d.Channels, relationship.ChannelEmail):
+		return time.Time{}, false, nil
+	}
+	return d.NotBefore, true, nil
+}
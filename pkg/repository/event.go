@@ -4,25 +4,47 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/dynamo"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/encryption"
 	"github.com/care-giver-app/care-giver-golang-common/pkg/event"
 	"github.com/care-giver-app/care-giver-golang-common/pkg/log"
 	"go.uber.org/zap"
 )
 
+const (
+	eventID = "event_id"
+)
+
+var (
+	eventKeySchema = CompositeKeySchema(receiverID, eventID, func(k EventKey) (string, string) {
+		return k.ReceiverID, k.EventID
+	})
+)
+
+type EventKey struct {
+	ReceiverID string
+	EventID    string
+}
+
 type EventRepositoryProvider interface {
 	AddEvent(e *event.Entry) error
 	GetEvents(rid string, bound TimestampBound) ([]event.Entry, error)
 	DeleteEvent(rid, eid string) error
 }
 
+type EventRepositoryProviderV2 interface {
+	AddEvent(ctx context.Context, e *event.Entry) error
+	AddEvents(ctx context.Context, entries []*event.Entry) error
+	GetEvents(ctx context.Context, rid string, bound TimestampBound) ([]event.Entry, error)
+	DeleteEvent(ctx context.Context, rid, eid string) error
+}
+
 type EventRepository struct {
 	Ctx       context.Context
 	Client    DynamodbClientProvider
 	TableName string
+	Encryptor *encryption.Encryptor
 	logger    *zap.Logger
 }
 
@@ -35,96 +57,114 @@ func NewEventRespository(ctx context.Context, tableName string, client DynamodbC
 	}
 }
 
-func (er *EventRepository) AddEvent(e *event.Entry) error {
-	er.logger.Info("adding receiver event to db")
-
-	er.logger.Info("marshalling receiver event struct")
-	av, err := attributevalue.MarshalMap(e)
-	if err != nil {
-		return err
+func (er *EventRepository) v2() *EventRepositoryV2 {
+	return &EventRepositoryV2{
+		Client:    er.Client,
+		TableName: er.TableName,
+		Encryptor: er.Encryptor,
+		logger:    er.logger,
 	}
-
-	er.logger.Info("inserting item into db", zap.Any("item", av))
-	_, err = er.Client.PutItem(er.Ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(er.TableName),
-		Item:      av,
-	})
-	if err != nil {
-		return err
-	}
-	er.logger.Info("successfully inserted item")
-
-	return nil
 }
 
-type TimestampBound struct {
-	Lower string
-	Upper string
+func (er *EventRepository) AddEvent(e *event.Entry) error {
+	return er.v2().AddEvent(er.Ctx, e)
 }
 
 func (er *EventRepository) GetEvents(rid string, bound TimestampBound) ([]event.Entry, error) {
-	er.logger.Info("retrieving receiver events from db", zap.String(log.ReceiverIDLogKey, string(rid)))
+	return er.v2().GetEvents(er.Ctx, rid, bound)
+}
 
-	if rid == "" {
-		return nil, fmt.Errorf("receiver id is required")
-	}
+func (er *EventRepository) DeleteEvent(rid, eid string) error {
+	return er.v2().DeleteEvent(er.Ctx, rid, eid)
+}
 
-	keyCondition := "#rid = :rid"
+// EventRepositoryV2 encrypts the tagged fields of the items it writes when
+// Encryptor is set. Retry bounds the retries of items left unprocessed by
+// batch reads and writes.
+type EventRepositoryV2 struct {
+	Client    DynamodbClientProvider
+	TableName string
+	Encryptor *encryption.Encryptor
+	Retry     dynamo.RetryPolicy
+	logger    *zap.Logger
+}
 
-	expressionAttributeNames := map[string]string{
-		"#rid": "receiver_id",
+func NewEventRepositoryV2(tableName string, client DynamodbClientProvider, logger *zap.Logger) *EventRepositoryV2 {
+	return &EventRepositoryV2{
+		Client:    client,
+		TableName: tableName,
+		logger:    logger.With(zap.String(log.TableNameLogKey, tableName)),
 	}
+}
 
-	expressionAttributeValues := map[string]types.AttributeValue{
-		":rid": &types.AttributeValueMemberS{Value: rid},
+func (er *EventRepositoryV2) table() *Table[event.Entry, EventKey] {
+	return &Table[event.Entry, EventKey]{
+		Name:      er.TableName,
+		Client:    er.Client,
+		Schema:    eventKeySchema,
+		Encryptor: er.Encryptor,
+		Retry:     er.Retry,
+		logger:    er.logger,
 	}
+}
 
-	queryInput := &dynamodb.QueryInput{
-		TableName:                 aws.String(er.TableName),
-		KeyConditionExpression:    aws.String(keyCondition),
-		ExpressionAttributeValues: expressionAttributeValues,
-		ExpressionAttributeNames:  expressionAttributeNames,
-	}
+func (er *EventRepositoryV2) AddEvent(ctx context.Context, e *event.Entry) error {
+	log.WithTraceContext(ctx, er.logger).Info("adding receiver event to db")
+	return er.table().Put(ctx, *e)
+}
 
-	if bound.Upper != "" && bound.Lower != "" {
-		keyCondition = fmt.Sprintf("%s %s", keyCondition, "AND #ts BETWEEN :timelower AND :timeupper")
-		expressionAttributeValues[":timelower"] = &types.AttributeValueMemberS{Value: bound.Lower}
-		expressionAttributeValues[":timeupper"] = &types.AttributeValueMemberS{Value: bound.Upper}
-		expressionAttributeNames["#ts"] = "start_time"
-		queryInput.KeyConditionExpression = aws.String(keyCondition)
-		queryInput.IndexName = aws.String("receiver-start-time")
-	}
+func (er *EventRepositoryV2) AddEvents(ctx context.Context, entries []*event.Entry) error {
+	log.WithTraceContext(ctx, er.logger).Info("adding receiver events to db", zap.Int("count", len(entries)))
 
-	result, err := er.Client.Query(er.Ctx, queryInput)
-	if err != nil {
-		return nil, err
+	items := make([]event.Entry, 0, len(entries))
+	for _, e := range entries {
+		items = append(items, *e)
 	}
 
-	var eventsList []event.Entry
-	err = attributevalue.UnmarshalListOfMaps(result.Items, &eventsList)
-	if err != nil {
-		er.logger.Error("error unmarshalling events list", zap.Error(err))
-		return nil, err
+	failures := er.table().PutBatch(ctx, items)
+	if len(failures) > 0 {
+		log.WithTraceContext(ctx, er.logger).Error("failed to add receiver events", zap.Int("failed", len(failures)))
+		return newBatchError(failures)
 	}
 
-	return eventsList, nil
+	log.WithTraceContext(ctx, er.logger).Info("successfully inserted items")
+	return nil
 }
 
-func (er *EventRepository) DeleteEvent(rid, eid string) error {
-	er.logger.Info("deleting receiver event from db", zap.String(log.EventIDLogKey, eid))
+type TimestampBound struct {
+	Lower string
+	Upper string
+}
+
+func (er *EventRepositoryV2) GetEvents(ctx context.Context, rid string, bound TimestampBound) ([]event.Entry, error) {
+	log.WithTraceContext(ctx, er.logger).Info("retrieving receiver events from db", zap.String(log.ReceiverIDLogKey, string(rid)))
+
+	if rid == "" {
+		return nil, fmt.Errorf("receiver id is required")
+	}
 
-	_, err := er.Client.DeleteItem(er.Ctx, &dynamodb.DeleteItemInput{
-		TableName: aws.String(er.TableName),
-		Key: map[string]types.AttributeValue{
-			"receiver_id": &types.AttributeValueMemberS{Value: rid},
-			"event_id":    &types.AttributeValueMemberS{Value: eid},
+	params := QueryParams{
+		KeyCondition: "#rid = :rid",
+		Names: map[string]string{
+			"#rid": receiverID,
 		},
-	})
+		Values: map[string]types.AttributeValue{
+			":rid": &types.AttributeValueMemberS{Value: rid},
+		},
+	}
 
-	if err != nil {
-		return err
+	if bound.Upper != "" && bound.Lower != "" {
+		params.KeyCondition = fmt.Sprintf("%s %s", params.KeyCondition, "AND #ts BETWEEN :timelower AND :timeupper")
+		params.Values[":timelower"] = &types.AttributeValueMemberS{Value: bound.Lower}
+		params.Values[":timeupper"] = &types.AttributeValueMemberS{Value: bound.Upper}
+		params.Names["#ts"] = eventStartTime
+		params.IndexName = EventReceiverStartTimeIndex
 	}
 
-	er.logger.Info("successfully deleted event")
-	return nil
+	return er.table().Query(ctx, params)
+}
+
+func (er *EventRepositoryV2) DeleteEvent(ctx context.Context, rid, eid string) error {
+	log.WithTraceContext(ctx, er.logger).Info("deleting receiver event from db", zap.String(log.EventIDLogKey, eid))
+	return er.table().Delete(ctx, EventKey{ReceiverID: rid, EventID: eid})
 }
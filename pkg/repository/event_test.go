@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/care-giver-app/care-giver-golang-common/pkg/dynamo"
@@ -60,8 +62,28 @@ func TestGetEvents(t *testing.T) {
 		bound         TimestampBound
 		mockDynamo    *dynamo.Mock
 		expectedValue []event.Entry
+		expectedIndex string
 		expectError   bool
 	}{
+		"Happy Path - Got Events Within Bound": {
+			rid:   "Receiver#123",
+			bound: TimestampBound{Lower: "2025-01-01T00:00:00Z", Upper: "2025-01-02T00:00:00Z"},
+			mockDynamo: &dynamo.Mock{
+				QueryOutput: &dynamodb.QueryOutput{
+					Items: []map[string]types.AttributeValue{
+						{
+							"type": &types.AttributeValueMemberS{Value: "Shower"},
+						},
+					},
+				},
+			},
+			expectedValue: []event.Entry{
+				{
+					Type: "Shower",
+				},
+			},
+			expectedIndex: "receiver-start-time",
+		},
 		"Happy Path - Got Events": {
 			rid:   "Receiver#123",
 			bound: TimestampBound{},
@@ -124,6 +146,8 @@ func TestGetEvents(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tc.expectedValue, events)
+				assert.Len(t, tc.mockDynamo.OnQuery.Calls, 1)
+				assert.Equal(t, tc.expectedIndex, aws.ToString(tc.mockDynamo.OnQuery.Calls[0].IndexName))
 			}
 		})
 	}
@@ -166,3 +190,86 @@ func TestDeleteEvent(t *testing.T) {
 		})
 	}
 }
+
+func TestEventRepositoryV2(t *testing.T) {
+	var _ EventRepositoryProvider = &EventRepository{}
+	var _ EventRepositoryProviderV2 = &EventRepositoryV2{}
+
+	mockDynamo := &dynamo.Mock{
+		QueryOutput: &dynamodb.QueryOutput{
+			Items: []map[string]types.AttributeValue{
+				{
+					"type": &types.AttributeValueMemberS{Value: "Shower"},
+				},
+			},
+		},
+	}
+	testEventRepo := NewEventRepositoryV2("event-table", mockDynamo, zap.NewNop())
+	ctx := context.Background()
+
+	err := testEventRepo.AddEvent(ctx, &event.Entry{EventID: "Event#123"})
+	assert.NoError(t, err)
+
+	events, err := testEventRepo.GetEvents(ctx, "Receiver#123", TimestampBound{})
+	assert.NoError(t, err)
+	assert.Equal(t, []event.Entry{{Type: "Shower"}}, events)
+
+	err = testEventRepo.DeleteEvent(ctx, "Receiver#123", "Event#123")
+	assert.NoError(t, err)
+}
+
+func TestAddEvents(t *testing.T) {
+	tests := map[string]struct {
+		entries     []*event.Entry
+		mockDynamo  *dynamo.Mock
+		expectError bool
+	}{
+		"Happy Path - Events Added": {
+			entries: []*event.Entry{{EventID: "Event#1"}, {EventID: "Event#2"}},
+			mockDynamo: &dynamo.Mock{
+				BatchWriteOutput: &dynamodb.BatchWriteItemOutput{},
+			},
+		},
+		"Sad Path - Batch Write Error": {
+			entries: []*event.Entry{{EventID: "Event#1"}},
+			mockDynamo: &dynamo.Mock{
+				Err: errors.New("An error occured during Batch Write"),
+			},
+			expectError: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEventRepo := NewEventRepositoryV2("event-table", tc.mockDynamo, zap.NewNop())
+
+			err := testEventRepo.AddEvents(context.Background(), tc.entries)
+			if tc.expectError {
+				var batchErr *BatchError
+				assert.ErrorAs(t, err, &batchErr)
+				assert.Len(t, batchErr.Failures, len(tc.entries))
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAddEvents_RetryPolicy(t *testing.T) {
+	unprocessed := &dynamodb.BatchWriteItemOutput{
+		UnprocessedItems: map[string][]types.WriteRequest{
+			"event-table": {{PutRequest: &types.PutRequest{Item: map[string]types.AttributeValue{
+				"receiver_id": &types.AttributeValueMemberS{Value: "Receiver#1"},
+				"event_id":    &types.AttributeValueMemberS{Value: "Event#1"},
+			}}}},
+		},
+	}
+	mock := &dynamo.Mock{BatchWriteOutput: unprocessed}
+	testEventRepo := NewEventRepositoryV2("event-table", mock, zap.NewNop())
+	testEventRepo.Retry = dynamo.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Microsecond, ThrottleBaseDelay: time.Microsecond}
+
+	err := testEventRepo.AddEvents(context.Background(), []*event.Entry{{ReceiverID: "Receiver#1", EventID: "Event#1"}})
+	var batchErr *BatchError
+	assert.ErrorAs(t, err, &batchErr)
+	assert.Len(t, mock.OnBatchWrite.Calls, 2)
+}
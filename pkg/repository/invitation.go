@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/invitation"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/log"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"go.uber.org/zap"
+)
+
+const (
+	invitationID        = "invitation_id"
+	invitationTokenHash = "token_hash"
+)
+
+var (
+	invitationKeySchema = CompositeKeySchema(receiverID, invitationID, func(k InvitationKey) (string, string) {
+		return k.ReceiverID, k.InvitationID
+	})
+
+	ErrNotPermitted            = errors.New("user is not permitted to perform the action")
+	ErrInvitationNotFound      = errors.New("invitation not found")
+	ErrInvitationNotPending    = errors.New("invitation is no longer pending")
+	ErrInvitationExpired       = errors.New("invitation has expired")
+	ErrInvitationEmailMismatch = errors.New("invitation was issued to a different email")
+)
+
+type InvitationKey struct {
+	ReceiverID   string
+	InvitationID string
+}
+
+// InvitationRepositoryProvider manages invitations to care for a receiver.
+// Inviting, listing, revoking and resending need the manage care givers
+// permission for the receiver. Invite and Resend return the token to send
+// to the invitee; only its hash is stored.
+type InvitationRepositoryProvider interface {
+	Invite(ctx context.Context, uid string, rid string, email string, role relationship.Role) (invitation.Invitation, string, error)
+	ListInvitations(ctx context.Context, uid string, rid string) ([]invitation.Invitation, error)
+	RevokeInvitation(ctx context.Context, uid string, rid string, iid string) error
+	ResendInvitation(ctx context.Context, uid string, rid string, iid string) (invitation.Invitation, string, error)
+	AcceptInvitation(ctx context.Context, token string, uid string) (*relationship.Relationship, error)
+}
+
+// InvitationRepository checks invitations are accepted by the invited email
+// against the users in Users.
+type InvitationRepository struct {
+	Client                DynamodbClientProvider
+	InvitationTableName   string
+	RelationshipTableName string
+	Users                 UserRepositoryProviderV2
+	TTL                   time.Duration
+
+	now    func() time.Time
+	logger *zap.Logger
+}
+
+func NewInvitationRepository(invitationTableName, relationshipTableName string, users UserRepositoryProviderV2, client DynamodbClientProvider, logger *zap.Logger) *InvitationRepository {
+	return &InvitationRepository{
+		Client:                client,
+		InvitationTableName:   invitationTableName,
+		RelationshipTableName: relationshipTableName,
+		Users:                 users,
+		TTL:                   invitation.DefaultTTL,
+		now:                   time.Now,
+		logger:                logger.With(zap.String(log.TableNameLogKey, invitationTableName)),
+	}
+}
+
+func (ir *InvitationRepository) invitations() *Table[invitation.Invitation, InvitationKey] {
+	return &Table[invitation.Invitation, InvitationKey]{
+		Name:   ir.InvitationTableName,
+		Client: ir.Client,
+		Schema: invitationKeySchema,
+		logger: ir.logger,
+	}
+}
+
+func (ir *InvitationRepository) relationships() *RelationshipRepositoryV2 {
+	return &RelationshipRepositoryV2{
+		Client:    ir.Client,
+		TableName: ir.RelationshipTableName,
+		logger:    ir.logger,
+	}
+}
+
+func (ir *InvitationRepository) Invite(ctx context.Context, uid string, rid string, email string, role relationship.Role) (invitation.Invitation, string, error) {
+	log.WithTraceContext(ctx, ir.logger).Info("inviting care giver", zap.String(log.UserIDLogKey, uid), zap.String(log.ReceiverIDLogKey, rid))
+
+	// Ownership is handed over with a primary transfer, not an invitation.
+	if !role.Valid() || role == relationship.RoleOwner {
+		return invitation.Invitation{}, "", fmt.Errorf("%w: %q", ErrInvalidRole, role)
+	}
+	if err := ir.authorize(ctx, uid, rid); err != nil {
+		return invitation.Invitation{}, "", err
+	}
+
+	inv, token, err := invitation.NewInvitation(rid, email, role, uid, ir.now(), ir.TTL)
+	if err != nil {
+		return invitation.Invitation{}, "", err
+	}
+
+	err = ir.invitations().Put(ctx, *inv, Condition{Expression: "attribute_not_exists(invitation_id)"})
+	if err != nil {
+		return invitation.Invitation{}, "", err
+	}
+
+	return *inv, token, nil
+}
+
+func (ir *InvitationRepository) ListInvitations(ctx context.Context, uid string, rid string) ([]invitation.Invitation, error) {
+	log.WithTraceContext(ctx, ir.logger).Info("listing invitations", zap.String(log.UserIDLogKey, uid), zap.String(log.ReceiverIDLogKey, rid))
+
+	if err := ir.authorize(ctx, uid, rid); err != nil {
+		return nil, err
+	}
+
+	return ir.invitations().Query(ctx, QueryParams{
+		KeyCondition: "receiver_id = :rid",
+		Values: map[string]types.AttributeValue{
+			":rid": &types.AttributeValueMemberS{Value: rid},
+		},
+	})
+}
+
+func (ir *InvitationRepository) RevokeInvitation(ctx context.Context, uid string, rid string, iid string) error {
+	log.WithTraceContext(ctx, ir.logger).Info("revoking invitation", zap.String(log.UserIDLogKey, uid), zap.String(log.ReceiverIDLogKey, rid))
+
+	if err := ir.authorize(ctx, uid, rid); err != nil {
+		return err
+	}
+
+	inv, err := ir.getInvitation(ctx, rid, iid)
+	if err != nil {
+		return err
+	}
+	if inv.Status != invitation.StatusPending {
+		return ErrInvitationNotPending
+	}
+
+	inv.Status = invitation.StatusRevoked
+	err = ir.invitations().Put(ctx, inv, pendingCondition(inv.TokenHash))
+	if errors.Is(err, ErrConditionFailed) {
+		return ErrInvitationNotPending
+	}
+	return err
+}
+
+// ResendInvitation issues a new token for a pending invitation and restarts
+// its expiry. The previous token can no longer be used.
+func (ir *InvitationRepository) ResendInvitation(ctx context.Context, uid string, rid string, iid string) (invitation.Invitation, string, error) {
+	log.WithTraceContext(ctx, ir.logger).Info("resending invitation", zap.String(log.UserIDLogKey, uid), zap.String(log.ReceiverIDLogKey, rid))
+
+	if err := ir.authorize(ctx, uid, rid); err != nil {
+		return invitation.Invitation{}, "", err
+	}
+
+	inv, err := ir.getInvitation(ctx, rid, iid)
+	if err != nil {
+		return invitation.Invitation{}, "", err
+	}
+	if inv.Status != invitation.StatusPending {
+		return invitation.Invitation{}, "", ErrInvitationNotPending
+	}
+
+	token, err := invitation.NewToken()
+	if err != nil {
+		return invitation.Invitation{}, "", err
+	}
+	previous := inv.TokenHash
+	inv.TokenHash = invitation.HashToken(token)
+	inv.ExpiresAt = ir.now().Add(ir.TTL).UTC()
+
+	err = ir.invitations().Put(ctx, inv, pendingCondition(previous))
+	if errors.Is(err, ErrConditionFailed) {
+		return invitation.Invitation{}, "", ErrInvitationNotPending
+	}
+	if err != nil {
+		return invitation.Invitation{}, "", err
+	}
+
+	return inv, token, nil
+}
+
+// AcceptInvitation marks the invitation the token belongs to as accepted and
+// creates the relationship it grants to the user uid in one transaction. The
+// email stored for the user must match the invited email.
+func (ir *InvitationRepository) AcceptInvitation(ctx context.Context, token string, uid string) (*relationship.Relationship, error) {
+	log.WithTraceContext(ctx, ir.logger).Info("accepting invitation", zap.String(log.UserIDLogKey, uid))
+
+	u, err := ir.Users.GetUser(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if u.UserID == "" {
+		return nil, ErrUserNotFound
+	}
+
+	hash := invitation.HashToken(token)
+	invitations, err := ir.invitations().Query(ctx, QueryParams{
+		IndexName:    InvitationTokenIndex,
+		KeyCondition: "token_hash = :hash",
+		Values: map[string]types.AttributeValue{
+			":hash": &types.AttributeValueMemberS{Value: hash},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(invitations) != 1 {
+		return nil, ErrInvitationNotFound
+	}
+
+	inv := invitations[0]
+	switch {
+	case inv.Status != invitation.StatusPending:
+		return nil, ErrInvitationNotPending
+	case inv.Expired(ir.now()):
+		return nil, ErrInvitationExpired
+	case !inv.For(u.Email):
+		return nil, ErrInvitationEmailMismatch
+	}
+
+	inv.Status = invitation.StatusAccepted
+	inv.AcceptedBy = u.UserID
+	rel := relationship.NewRelationshipWithRole(u.UserID, inv.ReceiverID, inv.Role, false)
+
+	putInvitation, err := ir.invitations().PutTransactItem(ctx, inv, pendingCondition(hash))
+	if err != nil {
+		return nil, err
+	}
+	putRelationship, err := ir.relationships().table().PutTransactItem(ctx, *rel, Condition{Expression: "attribute_not_exists(user_id)"})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = ir.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{putInvitation, putRelationship},
+	})
+	if err != nil {
+		log.WithTraceContext(ctx, ir.logger).Error("error accepting invitation", zap.Error(err))
+		return nil, cancellationErrors(err, map[int]error{
+			0: ErrInvitationNotPending,
+			1: ErrRelationshipExists,
+		})
+	}
+
+	log.WithTraceContext(ctx, ir.logger).Info("successfully accepted invitation", zap.String(log.ReceiverIDLogKey, inv.ReceiverID))
+	return rel, nil
+}
+
+func (ir *InvitationRepository) authorize(ctx context.Context, uid string, rid string) error {
+	relationships, err := ir.relationships().GetRelationshipsByReceiver(ctx, rid)
+	if err != nil {
+		return err
+	}
+	if !relationship.Can(uid, rid, relationship.ActionManageCareGivers, relationships) {
+		log.WithTraceContext(ctx, ir.logger).Warn("user is not allowed to manage care givers", zap.String(log.UserIDLogKey, uid), zap.String(log.ReceiverIDLogKey, rid))
+		return ErrNotPermitted
+	}
+	return nil
+}
+
+func (ir *InvitationRepository) getInvitation(ctx context.Context, rid string, iid string) (invitation.Invitation, error) {
+	inv, err := ir.invitations().Get(ctx, InvitationKey{ReceiverID: rid, InvitationID: iid})
+	if errors.Is(err, ErrItemNotFound) {
+		return inv, ErrInvitationNotFound
+	}
+	return inv, err
+}
+
+// pendingCondition holds while the invitation is pending under the token
+// with the given hash, so a token is accepted at most once and a resent or
+// revoked invitation cannot be accepted with an old token.
+func pendingCondition(tokenHash string) Condition {
+	return Condition{
+		Expression: "#status = :pending AND token_hash = :hash",
+		Names:      map[string]string{"#status": "status"},
+		Values: map[string]types.AttributeValue{
+			":pending": &types.AttributeValueMemberS{Value: string(invitation.StatusPending)},
+			":hash":    &types.AttributeValueMemberS{Value: tokenHash},
+		},
+	}
+}
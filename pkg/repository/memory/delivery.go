@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/notify"
+)
+
+type DeliveryRepository struct {
+	mu         sync.RWMutex
+	deliveries map[string][]notify.Delivery
+	claims     map[deliveryClaimKey]bool
+	counts     map[deliveryClaimKey]int
+}
+
+// deliveryClaimKey identifies a dedupe key claim, or with an empty key the
+// rate counter, of a recipient within a window.
+type deliveryClaimKey struct {
+	recipient string
+	key       string
+	window    time.Time
+}
+
+func NewDeliveryRepository() *DeliveryRepository {
+	return &DeliveryRepository{
+		deliveries: map[string][]notify.Delivery{},
+		claims:     map[deliveryClaimKey]bool{},
+		counts:     map[deliveryClaimKey]int{},
+	}
+}
+
+func (dr *DeliveryRepository) RecordDelivery(ctx context.Context, d notify.Delivery) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	deliveries := slices.DeleteFunc(dr.deliveries[d.Recipient], func(existing notify.Delivery) bool {
+		return existing.DeliveryID == d.DeliveryID
+	})
+	deliveries = append(deliveries, d)
+	slices.SortFunc(deliveries, func(a, b notify.Delivery) int {
+		return cmp.Compare(a.DeliveryID, b.DeliveryID)
+	})
+	dr.deliveries[d.Recipient] = deliveries
+	return nil
+}
+
+func (dr *DeliveryRepository) GetDeliveries(ctx context.Context, recipient string, since time.Time) ([]notify.Delivery, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	dr.mu.RLock()
+	defer dr.mu.RUnlock()
+
+	from := notify.DeliveryIDAt(since)
+	var deliveries []notify.Delivery
+	for _, d := range dr.deliveries[recipient] {
+		if d.DeliveryID >= from {
+			deliveries = append(deliveries, d)
+		}
+	}
+	return deliveries, nil
+}
+
+func (dr *DeliveryRepository) ClaimDedupeKey(ctx context.Context, recipient string, key string, window time.Time, expiresAt time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	k := deliveryClaimKey{recipient: recipient, key: key, window: window.UTC()}
+	if dr.claims[k] {
+		return notify.ErrDuplicate
+	}
+	dr.claims[k] = true
+	return nil
+}
+
+func (dr *DeliveryRepository) ReleaseDedupeKey(ctx context.Context, recipient string, key string, window time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	delete(dr.claims, deliveryClaimKey{recipient: recipient, key: key, window: window.UTC()})
+	return nil
+}
+
+func (dr *DeliveryRepository) CountDelivery(ctx context.Context, recipient string, window time.Time, limit int, expiresAt time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	dr.mu.Lock()
+	defer dr.mu.Unlock()
+
+	k := deliveryClaimKey{recipient: recipient, window: window.UTC()}
+	if dr.counts[k] >= limit {
+		return notify.ErrRateLimited
+	}
+	dr.counts[k]++
+	return nil
+}
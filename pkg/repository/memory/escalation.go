@@ -0,0 +1,144 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/escalation"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/repository"
+)
+
+type EscalationRepository struct {
+	mu       sync.RWMutex
+	policies map[string]escalation.Policy
+	states   map[repository.EscalationStateKey]escalation.State
+}
+
+func NewEscalationRepository() *EscalationRepository {
+	return &EscalationRepository{
+		policies: map[string]escalation.Policy{},
+		states:   map[repository.EscalationStateKey]escalation.State{},
+	}
+}
+
+func (er *EscalationRepository) PutPolicy(ctx context.Context, p escalation.Policy) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := p.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", repository.ErrInvalidEscalationPolicy, err)
+	}
+
+	er.mu.Lock()
+	defer er.mu.Unlock()
+
+	er.policies[p.ReceiverID] = p
+	return nil
+}
+
+func (er *EscalationRepository) GetPolicy(ctx context.Context, rid string) (escalation.Policy, error) {
+	if err := ctx.Err(); err != nil {
+		return escalation.Policy{}, err
+	}
+
+	er.mu.RLock()
+	defer er.mu.RUnlock()
+
+	return er.policies[rid], nil
+}
+
+func (er *EscalationRepository) DeletePolicy(ctx context.Context, rid string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	er.mu.Lock()
+	defer er.mu.Unlock()
+
+	delete(er.policies, rid)
+	return nil
+}
+
+func (er *EscalationRepository) GetState(ctx context.Context, rid string, eventType string) (*escalation.State, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	er.mu.RLock()
+	defer er.mu.RUnlock()
+
+	s, ok := er.states[repository.EscalationStateKey{ReceiverID: rid, EventType: eventType}]
+	if !ok {
+		return nil, nil
+	}
+	return &s, nil
+}
+
+func (er *EscalationRepository) GetStates(ctx context.Context, rid string) ([]escalation.State, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	er.mu.RLock()
+	defer er.mu.RUnlock()
+
+	var states []escalation.State
+	for k, s := range er.states {
+		if k.ReceiverID == rid {
+			states = append(states, s)
+		}
+	}
+	slices.SortFunc(states, func(a, b escalation.State) int {
+		return cmp.Compare(a.EventType, b.EventType)
+	})
+	return states, nil
+}
+
+func (er *EscalationRepository) PutState(ctx context.Context, s escalation.State) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	er.mu.Lock()
+	defer er.mu.Unlock()
+
+	er.states[repository.EscalationStateKey{ReceiverID: s.ReceiverID, EventType: s.EventType}] = s
+	return nil
+}
+
+func (er *EscalationRepository) UpdateState(ctx context.Context, s escalation.State, mask []string) (escalation.State, error) {
+	if err := ctx.Err(); err != nil {
+		return escalation.State{}, err
+	}
+
+	er.mu.Lock()
+	defer er.mu.Unlock()
+
+	key := repository.EscalationStateKey{ReceiverID: s.ReceiverID, EventType: s.EventType}
+	updated, ok := er.states[key]
+	err := repository.ApplyFieldMask(&updated, s, mask, "receiverId", "eventType")
+	if err != nil {
+		return escalation.State{}, err
+	}
+	if !ok {
+		return escalation.State{}, repository.ErrAlertNotFound
+	}
+
+	er.states[key] = updated
+	return updated, nil
+}
+
+func (er *EscalationRepository) DeleteState(ctx context.Context, rid string, eventType string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	er.mu.Lock()
+	defer er.mu.Unlock()
+
+	delete(er.states, repository.EscalationStateKey{ReceiverID: rid, EventType: eventType})
+	return nil
+}
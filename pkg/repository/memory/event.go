@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/event"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/repository"
+)
+
+type EventRepository struct {
+	Ctx  context.Context
+	repo *EventRepositoryV2
+}
+
+func NewEventRepository(ctx context.Context) *EventRepository {
+	return &EventRepository{
+		Ctx:  ctx,
+		repo: NewEventRepositoryV2(),
+	}
+}
+
+// V2 returns the context aware repository holding this repository's events.
+func (er *EventRepository) V2() *EventRepositoryV2 {
+	return er.repo
+}
+
+func (er *EventRepository) AddEvent(e *event.Entry) error {
+	return er.repo.AddEvent(er.Ctx, e)
+}
+
+func (er *EventRepository) GetEvents(rid string, bound repository.TimestampBound) ([]event.Entry, error) {
+	return er.repo.GetEvents(er.Ctx, rid, bound)
+}
+
+func (er *EventRepository) DeleteEvent(rid, eid string) error {
+	return er.repo.DeleteEvent(er.Ctx, rid, eid)
+}
+
+type EventRepositoryV2 struct {
+	mu     sync.RWMutex
+	events map[repository.EventKey]event.Entry
+}
+
+func NewEventRepositoryV2() *EventRepositoryV2 {
+	return &EventRepositoryV2{
+		events: map[repository.EventKey]event.Entry{},
+	}
+}
+
+func (er *EventRepositoryV2) AddEvent(ctx context.Context, e *event.Entry) error {
+	return er.AddEvents(ctx, []*event.Entry{e})
+}
+
+func (er *EventRepositoryV2) AddEvents(ctx context.Context, entries []*event.Entry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	er.mu.Lock()
+	defer er.mu.Unlock()
+
+	for _, e := range entries {
+		er.events[repository.EventKey{ReceiverID: e.ReceiverID, EventID: e.EventID}] = copyEntry(*e)
+	}
+	return nil
+}
+
+// GetEvents orders events by event id, or by start time when both sides of
+// the bound are set, matching the table and index sort keys.
+func (er *EventRepositoryV2) GetEvents(ctx context.Context, rid string, bound repository.TimestampBound) ([]event.Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if rid == "" {
+		return nil, fmt.Errorf("receiver id is required")
+	}
+
+	er.mu.RLock()
+	defer er.mu.RUnlock()
+
+	bounded := bound.Upper != "" && bound.Lower != ""
+
+	var entries []event.Entry
+	for key, e := range er.events {
+		if key.ReceiverID != rid {
+			continue
+		}
+		if bounded && (e.StartTime < bound.Lower || e.StartTime > bound.Upper) {
+			continue
+		}
+		entries = append(entries, copyEntry(e))
+	}
+
+	slices.SortFunc(entries, func(a, b event.Entry) int {
+		if bounded {
+			if c := cmp.Compare(a.StartTime, b.StartTime); c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(a.EventID, b.EventID)
+	})
+
+	return entries, nil
+}
+
+func (er *EventRepositoryV2) DeleteEvent(ctx context.Context, rid, eid string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	er.mu.Lock()
+	defer er.mu.Unlock()
+
+	delete(er.events, repository.EventKey{ReceiverID: rid, EventID: eid})
+	return nil
+}
+
+func copyEntry(e event.Entry) event.Entry {
+	e.Data = slices.Clone(e.Data)
+	return e
+}
@@ -0,0 +1,312 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/escalation"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/event"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/notify"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/receiver"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/repository"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/repository/repositorytest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviderInterfaces(t *testing.T) {
+	var _ repository.UserRepositoryProvider = &UserRepository{}
+	var _ repository.UserRepositoryProviderV2 = &UserRepositoryV2{}
+	var _ repository.ReceiverRepositoryProvider = &ReceiverRepository{}
+	var _ repository.ReceiverRepositoryProviderV2 = &ReceiverRepositoryV2{}
+	var _ repository.EventRepositoryProvider = &EventRepository{}
+	var _ repository.EventRepositoryProviderV2 = &EventRepositoryV2{}
+	var _ repository.RelationshipRepositoryProvider = &RelationshipRepository{}
+	var _ repository.RelationshipRepositoryProviderV2 = &RelationshipRepositoryV2{}
+	var _ repository.OnboardingRepositoryProvider = &OnboardingRepository{}
+	var _ notify.DeliveryLogProvider = &DeliveryRepository{}
+	var _ notify.DeliveryLogProvider = &repository.DeliveryRepository{}
+	var _ repository.EscalationRepositoryProvider = &EscalationRepository{}
+	var _ repository.EscalationRepositoryProvider = &repository.EscalationRepository{}
+}
+
+func TestUserRepositoryConformance(t *testing.T) {
+	repositorytest.RunUserRepositoryConformance(t, func(t *testing.T) repository.UserRepositoryProvider {
+		return NewUserRepository(context.Background())
+	})
+	repositorytest.RunUserRepositoryV2Conformance(t, func(t *testing.T) repository.UserRepositoryProviderV2 {
+		return NewUserRepositoryV2()
+	})
+}
+
+func TestReceiverRepositoryConformance(t *testing.T) {
+	repositorytest.RunReceiverRepositoryConformance(t, func(t *testing.T) repository.ReceiverRepositoryProvider {
+		return NewReceiverRepository(context.Background())
+	})
+	repositorytest.RunReceiverRepositoryV2Conformance(t, func(t *testing.T) repository.ReceiverRepositoryProviderV2 {
+		return NewReceiverRepositoryV2()
+	})
+}
+
+func TestEventRepositoryConformance(t *testing.T) {
+	repositorytest.RunEventRepositoryConformance(t, func(t *testing.T) repository.EventRepositoryProvider {
+		return NewEventRepository(context.Background())
+	})
+	repositorytest.RunEventRepositoryV2Conformance(t, func(t *testing.T) repository.EventRepositoryProviderV2 {
+		return NewEventRepositoryV2()
+	})
+}
+
+func TestRelationshipRepositoryConformance(t *testing.T) {
+	repositorytest.RunRelationshipRepositoryConformance(t, func(t *testing.T) repository.RelationshipRepositoryProvider {
+		return NewRelationshipRepository(context.Background())
+	})
+	repositorytest.RunRelationshipRepositoryV2Conformance(t, func(t *testing.T) repository.RelationshipRepositoryProviderV2 {
+		return NewRelationshipRepositoryV2()
+	})
+}
+
+func TestOnboardingRepository(t *testing.T) {
+	tests := map[string]struct {
+		existingReceiver     bool
+		existingRelationship bool
+		remove               string
+		removeUser           string
+		expectedOnboardErrs  []error
+		expectedRemoveErrs   []error
+	}{
+		"Happy Path - Onboard And Remove": {
+			remove:     "Receiver#1",
+			removeUser: "User#1",
+		},
+		"Sad Path - Receiver And Relationship Exist": {
+			existingReceiver:     true,
+			existingRelationship: true,
+			expectedOnboardErrs:  []error{repository.ErrReceiverExists, repository.ErrRelationshipExists},
+		},
+		"Sad Path - Remove By Non Primary": {
+			remove:             "Receiver#1",
+			removeUser:         "User#2",
+			expectedRemoveErrs: []error{repository.ErrNotPrimaryCareGiver},
+		},
+		"Sad Path - Remove Missing Receiver": {
+			remove:             "Receiver#missing",
+			removeUser:         "User#2",
+			expectedRemoveErrs: []error{repository.ErrReceiverNotFound, repository.ErrNotPrimaryCareGiver},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			receivers := NewReceiverRepositoryV2()
+			relationships := NewRelationshipRepositoryV2()
+			o := NewOnboardingRepository(receivers, relationships)
+
+			rel := &relationship.Relationship{UserID: "User#1", ReceiverID: "Receiver#1", PrimaryCareGiver: true}
+			if tc.existingReceiver {
+				assert.NoError(t, receivers.CreateReceiver(ctx, receiver.Receiver{ReceiverID: "Receiver#1"}))
+			}
+			if tc.existingRelationship {
+				assert.NoError(t, relationships.AddRelationship(ctx, rel))
+			}
+
+			err := o.OnboardReceiver(ctx, receiver.Receiver{ReceiverID: "Receiver#1", FirstName: "Ada"}, rel)
+			for _, expected := range tc.expectedOnboardErrs {
+				assert.ErrorIs(t, err, expected)
+			}
+			if tc.expectedOnboardErrs == nil {
+				assert.NoError(t, err)
+				assert.NoError(t, relationships.AddRelationship(ctx, &relationship.Relationship{UserID: "User#2", ReceiverID: "Receiver#1"}))
+			}
+
+			if tc.remove == "" {
+				return
+			}
+			err = o.RemoveReceiver(ctx, tc.remove, tc.removeUser)
+			for _, expected := range tc.expectedRemoveErrs {
+				assert.ErrorIs(t, err, expected)
+			}
+			if tc.expectedRemoveErrs != nil {
+				return
+			}
+			assert.NoError(t, err)
+
+			r, err := receivers.GetReceiver(ctx, "Receiver#1")
+			assert.NoError(t, err)
+			assert.Equal(t, receiver.Receiver{}, r)
+
+			rels, err := relationships.GetRelationshipsByReceiver(ctx, "Receiver#1")
+			assert.NoError(t, err)
+			assert.Empty(t, rels)
+		})
+	}
+}
+
+func TestTransferPrimary(t *testing.T) {
+	tests := map[string]struct {
+		from         string
+		to           string
+		expectedErrs []error
+	}{
+		"Happy Path - Transfer": {
+			from: "User#1",
+			to:   "User#2",
+		},
+		"Sad Path - From Non Primary": {
+			from:         "User#2",
+			to:           "User#1",
+			expectedErrs: []error{repository.ErrNotPrimaryCareGiver},
+		},
+		"Sad Path - To Missing Care Giver": {
+			from:         "User#1",
+			to:           "User#missing",
+			expectedErrs: []error{repository.ErrRelationshipNotFound},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			relationships := NewRelationshipRepositoryV2()
+			assert.NoError(t, relationships.AddRelationship(ctx, relationship.NewRelationship("User#1", "Receiver#1", true, false)))
+			assert.NoError(t, relationships.AddRelationship(ctx, relationship.NewRelationship("User#2", "Receiver#1", false, false)))
+
+			err := relationships.TransferPrimary(ctx, "Receiver#1", tc.from, tc.to)
+			for _, expected := range tc.expectedErrs {
+				assert.ErrorIs(t, err, expected)
+			}
+			if tc.expectedErrs != nil {
+				return
+			}
+			assert.NoError(t, err)
+
+			got, err := relationships.GetRelationshipsByReceiver(ctx, "Receiver#1")
+			assert.NoError(t, err)
+			assert.Equal(t, []relationship.Relationship{
+				*relationship.NewRelationshipWithRole("User#1", "Receiver#1", relationship.RoleEditor, false),
+				*relationship.NewRelationshipWithRole("User#2", "Receiver#1", relationship.RoleOwner, false),
+			}, got)
+		})
+	}
+}
+
+func TestUpdateNotificationPreferences(t *testing.T) {
+	ctx := context.Background()
+	relationships := NewRelationshipRepositoryV2()
+	assert.NoError(t, relationships.AddRelationship(ctx, relationship.NewRelationship("User#1", "Receiver#1", true, false)))
+
+	digest := relationship.NotificationPreferences{Channels: []relationship.Channel{relationship.ChannelEmail}, Delivery: relationship.DeliveryDigest}
+	updated, err := relationships.UpdateNotificationPreferences(ctx, "User#1", "Receiver#1", digest)
+	assert.NoError(t, err)
+	assert.True(t, updated.EmailNotifications)
+	assert.Equal(t, &digest, updated.Notifications)
+
+	got, err := relationships.GetRelationshipsByEmailNotifications(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []relationship.Relationship{*updated}, got)
+
+	_, err = relationships.UpdateNotificationPreferences(ctx, "User#1", "Receiver#1", relationship.NotificationPreferences{})
+	assert.ErrorIs(t, err, repository.ErrInvalidNotificationPreferences)
+	_, err = relationships.UpdateNotificationPreferences(ctx, "User#missing", "Receiver#1", digest)
+	assert.ErrorIs(t, err, repository.ErrRelationshipNotFound)
+}
+
+func TestDeliveryRepository(t *testing.T) {
+	ctx := context.Background()
+	deliveries := NewDeliveryRepository()
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	m := notify.Message{ID: "Message#1", Channel: relationship.ChannelSMS, To: "+15555550100"}
+
+	assert.NoError(t, deliveries.RecordDelivery(ctx, notify.NewDelivery(m, notify.StatusSent, nil, start.Add(time.Minute))))
+	assert.NoError(t, deliveries.RecordDelivery(ctx, notify.NewDelivery(m, notify.StatusFailed, nil, start.Add(-time.Minute))))
+
+	got, err := deliveries.GetDeliveries(ctx, notify.Recipient(m.Channel, m.To), start)
+	assert.NoError(t, err)
+	assert.Len(t, got, 1)
+	assert.Equal(t, notify.StatusSent, got[0].Status)
+
+	got, err = deliveries.GetDeliveries(ctx, notify.Recipient(m.Channel, m.To), time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, []notify.Status{notify.StatusFailed, notify.StatusSent}, []notify.Status{got[0].Status, got[1].Status})
+
+	recipient := notify.Recipient(m.Channel, m.To)
+	window := start.Truncate(time.Hour)
+	assert.NoError(t, deliveries.ClaimDedupeKey(ctx, recipient, "alert", window, window.Add(time.Hour)))
+	assert.ErrorIs(t, deliveries.ClaimDedupeKey(ctx, recipient, "alert", window, window.Add(time.Hour)), notify.ErrDuplicate)
+	assert.NoError(t, deliveries.ClaimDedupeKey(ctx, recipient, "alert", window.Add(time.Hour), window.Add(2*time.Hour)))
+	assert.NoError(t, deliveries.ReleaseDedupeKey(ctx, recipient, "alert", window))
+	assert.NoError(t, deliveries.ClaimDedupeKey(ctx, recipient, "alert", window, window.Add(time.Hour)))
+
+	assert.NoError(t, deliveries.CountDelivery(ctx, recipient, window, 2, window.Add(time.Hour)))
+	assert.NoError(t, deliveries.CountDelivery(ctx, recipient, window, 2, window.Add(time.Hour)))
+	assert.ErrorIs(t, deliveries.CountDelivery(ctx, recipient, window, 2, window.Add(time.Hour)), notify.ErrRateLimited)
+	assert.NoError(t, deliveries.CountDelivery(ctx, recipient, window.Add(time.Hour), 2, window.Add(2*time.Hour)))
+}
+
+func TestEscalationRepository(t *testing.T) {
+	ctx := context.Background()
+	escalations := NewEscalationRepository()
+
+	assert.ErrorIs(t, escalations.PutPolicy(ctx, escalation.Policy{}), repository.ErrInvalidEscalationPolicy)
+	assert.NoError(t, escalations.PutPolicy(ctx, escalation.Policy{ReceiverID: "Receiver#1"}))
+	p, err := escalations.GetPolicy(ctx, "Receiver#1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Receiver#1", p.ReceiverID)
+
+	assert.NoError(t, escalations.PutState(ctx, escalation.State{ReceiverID: "Receiver#1", EventType: "Shower", Step: escalation.StepPrimary}))
+	assert.NoError(t, escalations.PutState(ctx, escalation.State{ReceiverID: "Receiver#1", EventType: "Medication", Step: escalation.StepCareGivers}))
+	assert.NoError(t, escalations.PutState(ctx, escalation.State{ReceiverID: "Receiver#2", EventType: "Medication"}))
+
+	states, err := escalations.GetStates(ctx, "Receiver#1")
+	assert.NoError(t, err)
+	assert.Len(t, states, 2)
+	assert.Equal(t, "Medication", states[0].EventType)
+
+	assert.NoError(t, escalations.DeleteState(ctx, "Receiver#1", "Medication"))
+	s, err := escalations.GetState(ctx, "Receiver#1", "Medication")
+	assert.NoError(t, err)
+	assert.Nil(t, s)
+
+	updated, err := escalations.UpdateState(ctx, escalation.State{ReceiverID: "Receiver#1", EventType: "Shower", Step: escalation.StepCareGivers, AcknowledgedBy: "User#1"}, []string{"acknowledgedBy"})
+	assert.NoError(t, err)
+	assert.Equal(t, escalation.State{ReceiverID: "Receiver#1", EventType: "Shower", Step: escalation.StepPrimary, AcknowledgedBy: "User#1"}, updated)
+	_, err = escalations.UpdateState(ctx, escalation.State{ReceiverID: "Receiver#1", EventType: "Medication"}, []string{"step"})
+	assert.ErrorIs(t, err, repository.ErrAlertNotFound)
+
+	assert.NoError(t, escalations.DeletePolicy(ctx, "Receiver#1"))
+	p, err = escalations.GetPolicy(ctx, "Receiver#1")
+	assert.NoError(t, err)
+	assert.Empty(t, p.ReceiverID)
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	ctx := context.Background()
+	events := NewEventRepositoryV2()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_ = events.AddEvent(ctx, &event.Entry{ReceiverID: "Receiver#1", EventID: fmt.Sprintf("Event#%02d", i)})
+			_, _ = events.GetEvents(ctx, "Receiver#1", repository.TimestampBound{})
+		}(i)
+	}
+	wg.Wait()
+
+	all, err := events.GetEvents(ctx, "Receiver#1", repository.TimestampBound{})
+	assert.NoError(t, err)
+	assert.Len(t, all, 20)
+}
+
+func TestCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewUserRepositoryV2().GetUser(ctx, "User#1")
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/receiver"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/repository"
+)
+
+// OnboardingRepository applies its changes to the given receiver and
+// relationship repositories atomically.
+type OnboardingRepository struct {
+	Receivers     *ReceiverRepositoryV2
+	Relationships *RelationshipRepositoryV2
+}
+
+func NewOnboardingRepository(receivers *ReceiverRepositoryV2, relationships *RelationshipRepositoryV2) *OnboardingRepository {
+	return &OnboardingRepository{
+		Receivers:     receivers,
+		Relationships: relationships,
+	}
+}
+
+func (o *OnboardingRepository) OnboardReceiver(ctx context.Context, r receiver.Receiver, rel *relationship.Relationship) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if rel.ReceiverID != r.ReceiverID {
+		return fmt.Errorf("relationship receiver id %s does not match receiver id %s", rel.ReceiverID, r.ReceiverID)
+	}
+	rel, err := repository.ValidateRelationship(rel)
+	if err != nil {
+		return err
+	}
+	if rel.EffectiveRole() != relationship.RoleOwner {
+		return repository.ErrNotPrimaryCareGiver
+	}
+
+	unlock := o.lock()
+	defer unlock()
+
+	var errs []error
+	if _, ok := o.Receivers.receivers[r.ReceiverID]; ok {
+		errs = append(errs, repository.ErrReceiverExists)
+	}
+	if _, ok := o.Relationships.relationships[relationshipKey(*rel)]; ok {
+		errs = append(errs, repository.ErrRelationshipExists)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	o.Receivers.receivers[r.ReceiverID] = r
+	o.Relationships.relationships[relationshipKey(*rel)] = *rel
+	return nil
+}
+
+func (o *OnboardingRepository) RemoveReceiver(ctx context.Context, rid string, uid string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	unlock := o.lock()
+	defer unlock()
+
+	key := repository.RelationshipKey{UserID: uid, ReceiverID: rid}
+
+	var errs []error
+	if _, ok := o.Receivers.receivers[rid]; !ok {
+		errs = append(errs, repository.ErrReceiverNotFound)
+	}
+	if rel, ok := o.Relationships.relationships[key]; !ok || rel.EffectiveRole() != relationship.RoleOwner {
+		errs = append(errs, repository.ErrNotPrimaryCareGiver)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	delete(o.Receivers.receivers, rid)
+	for k, rel := range o.Relationships.relationships {
+		if rel.ReceiverID == rid {
+			delete(o.Relationships.relationships, k)
+		}
+	}
+	return nil
+}
+
+// lock always takes the receiver lock first so concurrent onboardings cannot
+// deadlock.
+func (o *OnboardingRepository) lock() func() {
+	o.Receivers.mu.Lock()
+	o.Relationships.mu.Lock()
+	return func() {
+		o.Relationships.mu.Unlock()
+		o.Receivers.mu.Unlock()
+	}
+}
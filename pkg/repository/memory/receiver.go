@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/receiver"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/repository"
+)
+
+type ReceiverRepository struct {
+	Ctx  context.Context
+	repo *ReceiverRepositoryV2
+}
+
+func NewReceiverRepository(ctx context.Context) *ReceiverRepository {
+	return &ReceiverRepository{
+		Ctx:  ctx,
+		repo: NewReceiverRepositoryV2(),
+	}
+}
+
+// V2 returns the context aware repository holding this repository's
+// receivers.
+func (rr *ReceiverRepository) V2() *ReceiverRepositoryV2 {
+	return rr.repo
+}
+
+func (rr *ReceiverRepository) CreateReceiver(r receiver.Receiver) error {
+	return rr.repo.CreateReceiver(rr.Ctx, r)
+}
+
+func (rr *ReceiverRepository) GetReceiver(rid string) (receiver.Receiver, error) {
+	return rr.repo.GetReceiver(rr.Ctx, rid)
+}
+
+type ReceiverRepositoryV2 struct {
+	mu        sync.RWMutex
+	receivers map[string]receiver.Receiver
+}
+
+func NewReceiverRepositoryV2() *ReceiverRepositoryV2 {
+	return &ReceiverRepositoryV2{
+		receivers: map[string]receiver.Receiver{},
+	}
+}
+
+func (rr *ReceiverRepositoryV2) CreateReceiver(ctx context.Context, r receiver.Receiver) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	rr.receivers[r.ReceiverID] = r
+	return nil
+}
+
+func (rr *ReceiverRepositoryV2) GetReceiver(ctx context.Context, rid string) (receiver.Receiver, error) {
+	if err := ctx.Err(); err != nil {
+		return receiver.Receiver{}, err
+	}
+
+	rr.mu.RLock()
+	defer rr.mu.RUnlock()
+
+	return rr.receivers[rid], nil
+}
+
+func (rr *ReceiverRepositoryV2) GetReceivers(ctx context.Context, rids []string) ([]receiver.Receiver, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rr.mu.RLock()
+	defer rr.mu.RUnlock()
+
+	var seen []string
+	receivers := []receiver.Receiver{}
+	for _, rid := range rids {
+		if slices.Contains(seen, rid) {
+			continue
+		}
+		seen = append(seen, rid)
+
+		if r, ok := rr.receivers[rid]; ok {
+			receivers = append(receivers, r)
+		}
+	}
+
+	return receivers, nil
+}
+
+func (rr *ReceiverRepositoryV2) UpdateReceiver(ctx context.Context, r receiver.Receiver, mask []string) (receiver.Receiver, error) {
+	if err := ctx.Err(); err != nil {
+		return receiver.Receiver{}, err
+	}
+
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	updated, ok := rr.receivers[r.ReceiverID]
+	err := repository.ApplyFieldMask(&updated, r, mask, "receiverId")
+	if err != nil {
+		return receiver.Receiver{}, err
+	}
+	if !ok {
+		return receiver.Receiver{}, repository.ErrReceiverNotFound
+	}
+
+	rr.receivers[r.ReceiverID] = updated
+	return updated, nil
+}
+
+func (rr *ReceiverRepositoryV2) DeleteReceiver(ctx context.Context, rid string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	delete(rr.receivers, rid)
+	return nil
+}
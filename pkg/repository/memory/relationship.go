@@ -0,0 +1,238 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/repository"
+)
+
+type RelationshipRepository struct {
+	Ctx  context.Context
+	repo *RelationshipRepositoryV2
+}
+
+func NewRelationshipRepository(ctx context.Context) *RelationshipRepository {
+	return &RelationshipRepository{
+		Ctx:  ctx,
+		repo: NewRelationshipRepositoryV2(),
+	}
+}
+
+// V2 returns the context aware repository holding this repository's
+// relationships.
+func (rr *RelationshipRepository) V2() *RelationshipRepositoryV2 {
+	return rr.repo
+}
+
+func (rr *RelationshipRepository) AddRelationship(r *relationship.Relationship) error {
+	return rr.repo.AddRelationship(rr.Ctx, r)
+}
+
+func (rr *RelationshipRepository) GetRelationship(userID string, receiverID string) (*relationship.Relationship, error) {
+	return rr.repo.GetRelationship(rr.Ctx, userID, receiverID)
+}
+
+func (rr *RelationshipRepository) GetRelationshipsByUser(userID string) ([]relationship.Relationship, error) {
+	return rr.repo.GetRelationshipsByUser(rr.Ctx, userID)
+}
+
+func (rr *RelationshipRepository) GetRelationshipsByReceiver(receiverID string) ([]relationship.Relationship, error) {
+	return rr.repo.GetRelationshipsByReceiver(rr.Ctx, receiverID)
+}
+
+func (rr *RelationshipRepository) DeleteRelationship(userID string, receiverID string) error {
+	return rr.repo.DeleteRelationship(rr.Ctx, userID, receiverID)
+}
+
+func (rr *RelationshipRepository) GetRelationshipsByEmailNotifications() ([]relationship.Relationship, error) {
+	return rr.repo.GetRelationshipsByEmailNotifications(rr.Ctx)
+}
+
+type RelationshipRepositoryV2 struct {
+	mu            sync.RWMutex
+	relationships map[repository.RelationshipKey]relationship.Relationship
+}
+
+func NewRelationshipRepositoryV2() *RelationshipRepositoryV2 {
+	return &RelationshipRepositoryV2{
+		relationships: map[repository.RelationshipKey]relationship.Relationship{},
+	}
+}
+
+func (rr *RelationshipRepositoryV2) AddRelationship(ctx context.Context, r *relationship.Relationship) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r, err := repository.ValidateRelationship(r)
+	if err != nil {
+		return err
+	}
+
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	if r.EffectiveRole() != relationship.RoleOwner && !rr.keepsOwner(relationshipKey(*r), false) {
+		return repository.ErrLastPrimaryCareGiver
+	}
+
+	rr.relationships[relationshipKey(*r)] = *r
+	return nil
+}
+
+func (rr *RelationshipRepositoryV2) GetRelationship(ctx context.Context, userID string, receiverID string) (*relationship.Relationship, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rr.mu.RLock()
+	defer rr.mu.RUnlock()
+
+	r := rr.relationships[repository.RelationshipKey{UserID: userID, ReceiverID: receiverID}]
+	return &r, nil
+}
+
+func (rr *RelationshipRepositoryV2) GetRelationshipsByUser(ctx context.Context, userID string) ([]relationship.Relationship, error) {
+	return rr.filter(ctx, func(r relationship.Relationship) bool {
+		return r.UserID == userID
+	})
+}
+
+func (rr *RelationshipRepositoryV2) GetRelationshipsByReceiver(ctx context.Context, receiverID string) ([]relationship.Relationship, error) {
+	return rr.filter(ctx, func(r relationship.Relationship) bool {
+		return r.ReceiverID == receiverID
+	})
+}
+
+func (rr *RelationshipRepositoryV2) DeleteRelationship(ctx context.Context, userID string, receiverID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	key := repository.RelationshipKey{UserID: userID, ReceiverID: receiverID}
+	if !rr.keepsOwner(key, true) {
+		return repository.ErrLastPrimaryCareGiver
+	}
+
+	delete(rr.relationships, key)
+	return nil
+}
+
+func (rr *RelationshipRepositoryV2) UpdateNotificationPreferences(ctx context.Context, userID string, receiverID string, p relationship.NotificationPreferences) (*relationship.Relationship, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", repository.ErrInvalidNotificationPreferences, err)
+	}
+
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	key := repository.RelationshipKey{UserID: userID, ReceiverID: receiverID}
+	r, ok := rr.relationships[key]
+	if !ok {
+		return nil, repository.ErrRelationshipNotFound
+	}
+
+	r.SetNotifications(p)
+	rr.relationships[key] = r
+	return &r, nil
+}
+
+func (rr *RelationshipRepositoryV2) TransferPrimary(ctx context.Context, receiverID string, fromUID string, toUID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if fromUID == toUID {
+		return fmt.Errorf("cannot transfer primary care giver from user %s to itself", fromUID)
+	}
+
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	fromKey := repository.RelationshipKey{UserID: fromUID, ReceiverID: receiverID}
+	toKey := repository.RelationshipKey{UserID: toUID, ReceiverID: receiverID}
+
+	var errs []error
+	from, ok := rr.relationships[fromKey]
+	if !ok || from.EffectiveRole() != relationship.RoleOwner {
+		errs = append(errs, repository.ErrNotPrimaryCareGiver)
+	}
+	to, ok := rr.relationships[toKey]
+	if !ok {
+		errs = append(errs, repository.ErrRelationshipNotFound)
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	from.Role, from.PrimaryCareGiver = relationship.RoleEditor, false
+	to.Role, to.PrimaryCareGiver = relationship.RoleOwner, true
+	rr.relationships[fromKey] = from
+	rr.relationships[toKey] = to
+	return nil
+}
+
+// keepsOwner reports whether the receiver of key still has an owner once the
+// relationship stored under key stops being one. With allowLast it also holds
+// when that relationship is the receiver's only one. rr.mu must be held.
+func (rr *RelationshipRepositoryV2) keepsOwner(key repository.RelationshipKey, allowLast bool) bool {
+	current, ok := rr.relationships[key]
+	if !ok || current.EffectiveRole() != relationship.RoleOwner {
+		return true
+	}
+
+	others := 0
+	for k, r := range rr.relationships {
+		if k == key || k.ReceiverID != key.ReceiverID {
+			continue
+		}
+		if r.EffectiveRole() == relationship.RoleOwner {
+			return true
+		}
+		others++
+	}
+	return allowLast && others == 0
+}
+
+func (rr *RelationshipRepositoryV2) GetRelationshipsByEmailNotifications(ctx context.Context) ([]relationship.Relationship, error) {
+	return rr.filter(ctx, func(r relationship.Relationship) bool {
+		return r.EmailNotifications
+	})
+}
+
+func (rr *RelationshipRepositoryV2) filter(ctx context.Context, match func(relationship.Relationship) bool) ([]relationship.Relationship, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rr.mu.RLock()
+	defer rr.mu.RUnlock()
+
+	var relationships []relationship.Relationship
+	for _, r := range rr.relationships {
+		if match(r) {
+			relationships = append(relationships, r)
+		}
+	}
+
+	slices.SortFunc(relationships, func(a, b relationship.Relationship) int {
+		return cmp.Or(cmp.Compare(a.UserID, b.UserID), cmp.Compare(a.ReceiverID, b.ReceiverID))
+	})
+
+	return relationships, nil
+}
+
+func relationshipKey(r relationship.Relationship) repository.RelationshipKey {
+	return repository.RelationshipKey{UserID: r.UserID, ReceiverID: r.ReceiverID}
+}
@@ -0,0 +1,164 @@
+// Package memory provides thread-safe in-memory implementations of the
+// repository Provider interfaces with the same semantics as the DynamoDB
+// repositories, for unit tests and local demos.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/repository"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/user"
+)
+
+type UserRepository struct {
+	Ctx  context.Context
+	repo *UserRepositoryV2
+}
+
+func NewUserRepository(ctx context.Context) *UserRepository {
+	return &UserRepository{
+		Ctx:  ctx,
+		repo: NewUserRepositoryV2(),
+	}
+}
+
+// V2 returns the context aware repository holding this repository's users.
+func (ur *UserRepository) V2() *UserRepositoryV2 {
+	return ur.repo
+}
+
+func (ur *UserRepository) CreateUser(u user.User) error {
+	return ur.repo.CreateUser(ur.Ctx, u)
+}
+
+func (ur *UserRepository) GetUser(uid string) (user.User, error) {
+	return ur.repo.GetUser(ur.Ctx, uid)
+}
+
+func (ur *UserRepository) GetUserByEmail(email string) (user.User, error) {
+	return ur.repo.GetUserByEmail(ur.Ctx, email)
+}
+
+type UserRepositoryV2 struct {
+	mu    sync.RWMutex
+	users map[string]user.User
+}
+
+func NewUserRepositoryV2() *UserRepositoryV2 {
+	return &UserRepositoryV2{
+		users: map[string]user.User{},
+	}
+}
+
+func (ur *UserRepositoryV2) CreateUser(ctx context.Context, u user.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	u.Email = repository.NormalizeEmail(u.Email)
+	if u.Email != "" && ur.emailTaken(u.Email, u.UserID) {
+		return repository.ErrEmailInUse
+	}
+
+	ur.users[u.UserID] = u
+	return nil
+}
+
+func (ur *UserRepositoryV2) GetUser(ctx context.Context, uid string) (user.User, error) {
+	if err := ctx.Err(); err != nil {
+		return user.User{}, err
+	}
+
+	ur.mu.RLock()
+	defer ur.mu.RUnlock()
+
+	return ur.users[uid], nil
+}
+
+func (ur *UserRepositoryV2) GetUserByEmail(ctx context.Context, email string) (user.User, error) {
+	if err := ctx.Err(); err != nil {
+		return user.User{}, err
+	}
+
+	ur.mu.RLock()
+	defer ur.mu.RUnlock()
+
+	var matches []user.User
+	for _, u := range ur.users {
+		if email != "" && u.Email == repository.NormalizeEmail(email) {
+			matches = append(matches, u)
+		}
+	}
+
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+
+	return user.User{}, fmt.Errorf("user with email not found: %w", repository.ErrUserNotFound)
+}
+
+func (ur *UserRepositoryV2) UpdateUser(ctx context.Context, u user.User, mask []string) (user.User, error) {
+	if err := ctx.Err(); err != nil {
+		return user.User{}, err
+	}
+
+	if slices.Contains(mask, "email") {
+		return user.User{}, repository.ErrEmailImmutable
+	}
+
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	updated, ok := ur.users[u.UserID]
+	err := repository.ApplyFieldMask(&updated, u, mask, "userId")
+	if err != nil {
+		return user.User{}, err
+	}
+	if !ok {
+		return user.User{}, repository.ErrUserNotFound
+	}
+
+	ur.users[u.UserID] = updated
+	return updated, nil
+}
+
+func (ur *UserRepositoryV2) ChangeEmail(ctx context.Context, uid string, email string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ur.mu.Lock()
+	defer ur.mu.Unlock()
+
+	email = repository.NormalizeEmail(email)
+	current, ok := ur.users[uid]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	if current.Email == email {
+		return nil
+	}
+	if ur.emailTaken(email, uid) {
+		return repository.ErrEmailInUse
+	}
+
+	current.Email = email
+	ur.users[uid] = current
+	return nil
+}
+
+// emailTaken reports whether a user other than uid has email.
+func (ur *UserRepositoryV2) emailTaken(email string, uid string) bool {
+	for _, u := range ur.users {
+		if u.UserID != uid && u.Email == email {
+			return true
+		}
+	}
+	return false
+}
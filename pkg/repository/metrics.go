@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/dynamo"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/event"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/metrics"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/receiver"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/user"
+)
+
+const (
+	MethodDurationMetric = "repository_method_duration_seconds"
+	MethodErrorsMetric   = "repository_method_errors_total"
+
+	RepositoryLabel = "repository"
+	MethodLabel     = "method"
+)
+
+var errorTypes = []struct {
+	err  error
+	name string
+}{
+	{ErrItemNotFound, "ItemNotFound"},
+	{ErrConditionFailed, "ConditionFailed"},
+	{ErrReceiverExists, "ReceiverExists"},
+	{ErrReceiverNotFound, "ReceiverNotFound"},
+	{ErrRelationshipExists, "RelationshipExists"},
+	{ErrNotPrimaryCareGiver, "NotPrimaryCareGiver"},
+	{ErrInvalidRole, "InvalidRole"},
+	{ErrRelationshipNotFound, "RelationshipNotFound"},
+	{ErrLastPrimaryCareGiver, "LastPrimaryCareGiver"},
+	{ErrInvalidNotificationPreferences, "InvalidNotificationPreferences"},
+	{ErrNotPermitted, "NotPermitted"},
+	{ErrInvitationNotFound, "InvitationNotFound"},
+	{ErrInvitationNotPending, "InvitationNotPending"},
+	{ErrInvitationExpired, "InvitationExpired"},
+	{ErrInvitationEmailMismatch, "InvitationEmailMismatch"},
+	{ErrInvalidEscalationPolicy, "InvalidEscalationPolicy"},
+	{ErrAlertNotFound, "AlertNotFound"},
+	{ErrNoRecipients, "NoRecipients"},
+	{ErrUserNotFound, "UserNotFound"},
+	{ErrEmailInUse, "EmailInUse"},
+	{ErrEmailImmutable, "EmailImmutable"},
+	{ErrEmailConflict, "EmailConflict"},
+	{ErrEmptyFieldMask, "EmptyFieldMask"},
+	{ErrInvalidFieldMask, "InvalidFieldMask"},
+	{ErrUnprocessed, "Unprocessed"},
+}
+
+// ErrorType names the repository error err matches, falling back to the
+// DynamoDB error type.
+func ErrorType(err error) string {
+	for _, t := range errorTypes {
+		if errors.Is(err, t.err) {
+			return t.name
+		}
+	}
+	return dynamo.ErrorType(err)
+}
+
+func observe(sink metrics.Sink, repository, method string, start time.Time, err *error) {
+	labels := metrics.Labels{RepositoryLabel: repository, MethodLabel: method}
+	sink.Observe(MethodDurationMetric, labels, time.Since(start).Seconds())
+	if *err != nil {
+		sink.Add(MethodErrorsMetric, metrics.Labels{RepositoryLabel: repository, MethodLabel: method, dynamo.ErrorLabel: ErrorType(*err)}, 1)
+	}
+}
+
+// MetricsUserRepository records the latency and errors of every method of
+// the repository it wraps.
+type MetricsUserRepository struct {
+	Repo UserRepositoryProviderV2
+	Sink metrics.Sink
+}
+
+func NewMetricsUserRepository(repo UserRepositoryProviderV2, sink metrics.Sink) *MetricsUserRepository {
+	return &MetricsUserRepository{Repo: repo, Sink: sink}
+}
+
+func (r *MetricsUserRepository) CreateUser(ctx context.Context, u user.User) (err error) {
+	defer observe(r.Sink, "user", "CreateUser", time.Now(), &err)
+	return r.Repo.CreateUser(ctx, u)
+}
+
+func (r *MetricsUserRepository) GetUser(ctx context.Context, uid string) (_ user.User, err error) {
+	defer observe(r.Sink, "user", "GetUser", time.Now(), &err)
+	return r.Repo.GetUser(ctx, uid)
+}
+
+func (r *MetricsUserRepository) GetUserByEmail(ctx context.Context, email string) (_ user.User, err error) {
+	defer observe(r.Sink, "user", "GetUserByEmail", time.Now(), &err)
+	return r.Repo.GetUserByEmail(ctx, email)
+}
+
+func (r *MetricsUserRepository) UpdateUser(ctx context.Context, u user.User, mask []string) (_ user.User, err error) {
+	defer observe(r.Sink, "user", "UpdateUser", time.Now(), &err)
+	return r.Repo.UpdateUser(ctx, u, mask)
+}
+
+func (r *MetricsUserRepository) ChangeEmail(ctx context.Context, uid string, email string) (err error) {
+	defer observe(r.Sink, "user", "ChangeEmail", time.Now(), &err)
+	return r.Repo.ChangeEmail(ctx, uid, email)
+}
+
+type MetricsReceiverRepository struct {
+	Repo ReceiverRepositoryProviderV2
+	Sink metrics.Sink
+}
+
+func NewMetricsReceiverRepository(repo ReceiverRepositoryProviderV2, sink metrics.Sink) *MetricsReceiverRepository {
+	return &MetricsReceiverRepository{Repo: repo, Sink: sink}
+}
+
+func (r *MetricsReceiverRepository) CreateReceiver(ctx context.Context, rec receiver.Receiver) (err error) {
+	defer observe(r.Sink, "receiver", "CreateReceiver", time.Now(), &err)
+	return r.Repo.CreateReceiver(ctx, rec)
+}
+
+func (r *MetricsReceiverRepository) GetReceiver(ctx context.Context, rid string) (_ receiver.Receiver, err error) {
+	defer observe(r.Sink, "receiver", "GetReceiver", time.Now(), &err)
+	return r.Repo.GetReceiver(ctx, rid)
+}
+
+func (r *MetricsReceiverRepository) GetReceivers(ctx context.Context, rids []string) (_ []receiver.Receiver, err error) {
+	defer observe(r.Sink, "receiver", "GetReceivers", time.Now(), &err)
+	return r.Repo.GetReceivers(ctx, rids)
+}
+
+func (r *MetricsReceiverRepository) UpdateReceiver(ctx context.Context, rec receiver.Receiver, mask []string) (_ receiver.Receiver, err error) {
+	defer observe(r.Sink, "receiver", "UpdateReceiver", time.Now(), &err)
+	return r.Repo.UpdateReceiver(ctx, rec, mask)
+}
+
+func (r *MetricsReceiverRepository) DeleteReceiver(ctx context.Context, rid string) (err error) {
+	defer observe(r.Sink, "receiver", "DeleteReceiver", time.Now(), &err)
+	return r.Repo.DeleteReceiver(ctx, rid)
+}
+
+type MetricsEventRepository struct {
+	Repo EventRepositoryProviderV2
+	Sink metrics.Sink
+}
+
+func NewMetricsEventRepository(repo EventRepositoryProviderV2, sink metrics.Sink) *MetricsEventRepository {
+	return &MetricsEventRepository{Repo: repo, Sink: sink}
+}
+
+func (r *MetricsEventRepository) AddEvent(ctx context.Context, e *event.Entry) (err error) {
+	defer observe(r.Sink, "event", "AddEvent", time.Now(), &err)
+	return r.Repo.AddEvent(ctx, e)
+}
+
+func (r *MetricsEventRepository) AddEvents(ctx context.Context, entries []*event.Entry) (err error) {
+	defer observe(r.Sink, "event", "AddEvents", time.Now(), &err)
+	return r.Repo.AddEvents(ctx, entries)
+}
+
+func (r *MetricsEventRepository) GetEvents(ctx context.Context, rid string, bound TimestampBound) (_ []event.Entry, err error) {
+	defer observe(r.Sink, "event", "GetEvents", time.Now(), &err)
+	return r.Repo.GetEvents(ctx, rid, bound)
+}
+
+func (r *MetricsEventRepository) DeleteEvent(ctx context.Context, rid, eid string) (err error) {
+	defer observe(r.Sink, "event", "DeleteEvent", time.Now(), &err)
+	return r.Repo.DeleteEvent(ctx, rid, eid)
+}
+
+type MetricsRelationshipRepository struct {
+	Repo RelationshipRepositoryProviderV2
+	Sink metrics.Sink
+}
+
+func NewMetricsRelationshipRepository(repo RelationshipRepositoryProviderV2, sink metrics.Sink) *MetricsRelationshipRepository {
+	return &MetricsRelationshipRepository{Repo: repo, Sink: sink}
+}
+
+func (r *MetricsRelationshipRepository) AddRelationship(ctx context.Context, rel *relationship.Relationship) (err error) {
+	defer observe(r.Sink, "relationship", "AddRelationship", time.Now(), &err)
+	return r.Repo.AddRelationship(ctx, rel)
+}
+
+func (r *MetricsRelationshipRepository) GetRelationship(ctx context.Context, userID string, receiverID string) (_ *relationship.Relationship, err error) {
+	defer observe(r.Sink, "relationship", "GetRelationship", time.Now(), &err)
+	return r.Repo.GetRelationship(ctx, userID, receiverID)
+}
+
+func (r *MetricsRelationshipRepository) GetRelationshipsByUser(ctx context.Context, userID string) (_ []relationship.Relationship, err error) {
+	defer observe(r.Sink, "relationship", "GetRelationshipsByUser", time.Now(), &err)
+	return r.Repo.GetRelationshipsByUser(ctx, userID)
+}
+
+func (r *MetricsRelationshipRepository) GetRelationshipsByReceiver(ctx context.Context, receiverID string) (_ []relationship.Relationship, err error) {
+	defer observe(r.Sink, "relationship", "GetRelationshipsByReceiver", time.Now(), &err)
+	return r.Repo.GetRelationshipsByReceiver(ctx, receiverID)
+}
+
+func (r *MetricsRelationshipRepository) DeleteRelationship(ctx context.Context, userID string, receiverID string) (err error) {
+	defer observe(r.Sink, "relationship", "DeleteRelationship", time.Now(), &err)
+	return r.Repo.DeleteRelationship(ctx, userID, receiverID)
+}
+
+func (r *MetricsRelationshipRepository) GetRelationshipsByEmailNotifications(ctx context.Context) (_ []relationship.Relationship, err error) {
+	defer observe(r.Sink, "relationship", "GetRelationshipsByEmailNotifications", time.Now(), &err)
+	return r.Repo.GetRelationshipsByEmailNotifications(ctx)
+}
+
+func (r *MetricsRelationshipRepository) TransferPrimary(ctx context.Context, receiverID string, fromUID string, toUID string) (err error) {
+	defer observe(r.Sink, "relationship", "TransferPrimary", time.Now(), &err)
+	return r.Repo.TransferPrimary(ctx, receiverID, fromUID, toUID)
+}
+
+func (r *MetricsRelationshipRepository) UpdateNotificationPreferences(ctx context.Context, userID string, receiverID string, p relationship.NotificationPreferences) (_ *relationship.Relationship, err error) {
+	defer observe(r.Sink, "relationship", "UpdateNotificationPreferences", time.Now(), &err)
+	return r.Repo.UpdateNotificationPreferences(ctx, userID, receiverID, p)
+}
+
+type MetricsOnboardingRepository struct {
+	Repo OnboardingRepositoryProvider
+	Sink metrics.Sink
+}
+
+func NewMetricsOnboardingRepository(repo OnboardingRepositoryProvider, sink metrics.Sink) *MetricsOnboardingRepository {
+	return &MetricsOnboardingRepository{Repo: repo, Sink: sink}
+}
+
+func (r *MetricsOnboardingRepository) OnboardReceiver(ctx context.Context, rec receiver.Receiver, rel *relationship.Relationship) (err error) {
+	defer observe(r.Sink, "onboarding", "OnboardReceiver", time.Now(), &err)
+	return r.Repo.OnboardReceiver(ctx, rec, rel)
+}
+
+func (r *MetricsOnboardingRepository) RemoveReceiver(ctx context.Context, rid string, uid string) (err error) {
+	defer observe(r.Sink, "onboarding", "RemoveReceiver", time.Now(), &err)
+	return r.Repo.RemoveReceiver(ctx, rid, uid)
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/dynamo"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/metrics"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/user"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestMetricsUserRepository(t *testing.T) {
+	ctx := context.Background()
+	sink := metrics.NewMemory()
+	repo := NewMetricsUserRepository(NewUserRepositoryV2(testUserTable, newTestEmulator(), zap.NewNop()), sink)
+
+	assert.NoError(t, repo.CreateUser(ctx, user.User{UserID: "User#1", Email: "one@example.com"}))
+	_, err := repo.GetUser(ctx, "User#1")
+	assert.NoError(t, err)
+	_, err = repo.GetUserByEmail(ctx, "missing@example.com")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	assert.Len(t, sink.Observations(MethodDurationMetric, metrics.Labels{RepositoryLabel: "user", MethodLabel: "CreateUser"}), 1)
+	assert.Len(t, sink.Observations(MethodDurationMetric, metrics.Labels{RepositoryLabel: "user", MethodLabel: "GetUser"}), 1)
+	assert.Equal(t, float64(1), sink.Counter(MethodErrorsMetric, metrics.Labels{RepositoryLabel: "user", MethodLabel: "GetUserByEmail", dynamo.ErrorLabel: "UserNotFound"}))
+	assert.Equal(t, float64(0), sink.Counter(MethodErrorsMetric, metrics.Labels{RepositoryLabel: "user", MethodLabel: "GetUser", dynamo.ErrorLabel: "UserNotFound"}))
+}
+
+func TestErrorType(t *testing.T) {
+	tests := map[string]struct {
+		err          error
+		expectedType string
+	}{
+		"Happy Path - Repository Error": {
+			err:          ErrReceiverExists,
+			expectedType: "ReceiverExists",
+		},
+		"Happy Path - Wrapped Repository Error": {
+			err:          fmt.Errorf("adding event: %w", ErrUnprocessed),
+			expectedType: "Unprocessed",
+		},
+		"Happy Path - Context Error": {
+			err:          context.Canceled,
+			expectedType: "ContextCanceled",
+		},
+		"Sad Path - Unknown Error": {
+			err:          assert.AnError,
+			expectedType: "Unknown",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedType, ErrorType(tc.err))
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/log"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"go.uber.org/zap"
+)
+
+// NotificationResolver answers whether, and how, a care giver should be
+// notified about a subject on a receiver right now, from the notification
+// preferences of their relationship. Users who are not care givers of the
+// receiver are never notified.
+type NotificationResolver struct {
+	Relationships RelationshipRepositoryProviderV2
+	now           func() time.Time
+	logger        *zap.Logger
+}
+
+func NewNotificationResolver(relationships RelationshipRepositoryProviderV2, logger *zap.Logger) *NotificationResolver {
+	return &NotificationResolver{
+		Relationships: relationships,
+		now:           time.Now,
+		logger:        logger,
+	}
+}
+
+func (nr *NotificationResolver) Resolve(ctx context.Context, uid string, rid string, s relationship.Subject) (relationship.Decision, error) {
+	r, err := nr.Relationships.GetRelationship(ctx, uid, rid)
+	if err != nil {
+		return relationship.Decision{}, err
+	}
+	if r.UserID == "" {
+		return relationship.Decision{}, nil
+	}
+
+	d := r.EffectiveNotifications().Resolve(s, nr.now())
+	log.WithTraceContext(ctx, nr.logger).Debug("resolved notification",
+		zap.String(log.UserIDLogKey, uid),
+		zap.String(log.ReceiverIDLogKey, rid),
+		zap.String(log.EventLogKey, s.EventType),
+		zap.Bool("notify", d.Notify),
+		zap.String("delivery", string(d.Delivery)))
+	return d, nil
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/encryption"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/log"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/receiver"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"go.uber.org/zap"
+)
+
+type OnboardingRepositoryProvider interface {
+	OnboardReceiver(ctx context.Context, r receiver.Receiver, rel *relationship.Relationship) error
+	RemoveReceiver(ctx context.Context, rid string, uid string) error
+}
+
+// OnboardingRepository encrypts the tagged fields of the receivers it writes
+// when Encryptor is set.
+type OnboardingRepository struct {
+	Client                DynamodbClientProvider
+	ReceiverTableName     string
+	RelationshipTableName string
+	Encryptor             *encryption.Encryptor
+	logger                *zap.Logger
+}
+
+func NewOnboardingRepository(receiverTableName, relationshipTableName string, client DynamodbClientProvider, logger *zap.Logger) *OnboardingRepository {
+	return &OnboardingRepository{
+		Client:                client,
+		ReceiverTableName:     receiverTableName,
+		RelationshipTableName: relationshipTableName,
+		logger:                logger,
+	}
+}
+
+func (o *OnboardingRepository) receivers() *Table[receiver.Receiver, string] {
+	t := NewTable[receiver.Receiver](o.ReceiverTableName, receiverKeySchema, o.Client, o.logger)
+	t.Encryptor = o.Encryptor
+	return t
+}
+
+func (o *OnboardingRepository) relationships() *Table[relationship.Relationship, RelationshipKey] {
+	t := NewTable[relationship.Relationship](o.RelationshipTableName, relationshipKeySchema, o.Client, o.logger)
+	t.Derived = relationshipDerived
+	return t
+}
+
+func (o *OnboardingRepository) OnboardReceiver(ctx context.Context, r receiver.Receiver, rel *relationship.Relationship) error {
+	log.WithTraceContext(ctx, o.logger).Info("onboarding receiver with primary care giver", zap.String(log.ReceiverIDLogKey, r.ReceiverID), zap.String(log.UserIDLogKey, rel.UserID))
+
+	if rel.ReceiverID != r.ReceiverID {
+		return fmt.Errorf("relationship receiver id %s does not match receiver id %s", rel.ReceiverID, r.ReceiverID)
+	}
+	rel, err := ValidateRelationship(rel)
+	if err != nil {
+		return err
+	}
+	if rel.EffectiveRole() != relationship.RoleOwner {
+		return ErrNotPrimaryCareGiver
+	}
+
+	putReceiver, err := o.receivers().PutTransactItem(ctx, r, Condition{Expression: "attribute_not_exists(receiver_id)"})
+	if err != nil {
+		return err
+	}
+
+	putRelationship, err := o.relationships().PutTransactItem(ctx, *rel, Condition{Expression: "attribute_not_exists(user_id)"})
+	if err != nil {
+		return err
+	}
+
+	_, err = o.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{putReceiver, putRelationship},
+	})
+	if err != nil {
+		log.WithTraceContext(ctx, o.logger).Error("error onboarding receiver", zap.Error(err))
+		return cancellationErrors(err, map[int]error{
+			0: ErrReceiverExists,
+			1: ErrRelationshipExists,
+		})
+	}
+
+	log.WithTraceContext(ctx, o.logger).Info("successfully onboarded receiver")
+	return nil
+}
+
+// RemoveReceiver deletes the receiver rid together with every relationship to
+// it, provided uid is its primary care giver. It undoes OnboardReceiver; use
+// ReceiverDeleter to also delete the events and notes of a receiver.
+//
+// The relationships are read from the receiver index before the transaction
+// deletes them, and AddRelationship does not check that the receiver exists,
+// so a relationship added while RemoveReceiver runs survives it, pointing at
+// a receiver that no longer exists. Callers must not add relationships to a
+// receiver that is being removed, or remove such leftovers with
+// DeleteRelationship.
+func (o *OnboardingRepository) RemoveReceiver(ctx context.Context, rid string, uid string) error {
+	log.WithTraceContext(ctx, o.logger).Info("removing receiver with primary care giver", zap.String(log.ReceiverIDLogKey, rid), zap.String(log.UserIDLogKey, uid))
+
+	relationships, err := o.relationships().Query(ctx, QueryParams{
+		IndexName:    RelationshipReceiverIndex,
+		KeyCondition: "receiver_id = :rid",
+		Values: map[string]types.AttributeValue{
+			":rid": &types.AttributeValueMemberS{Value: rid},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	items := []types.TransactWriteItem{
+		o.receivers().DeleteTransactItem(rid, Condition{Expression: "attribute_exists(receiver_id)"}),
+		o.relationships().DeleteTransactItem(RelationshipKey{UserID: uid, ReceiverID: rid}, ownerCondition),
+	}
+	for _, r := range relationships {
+		if r.UserID == uid {
+			continue
+		}
+		items = append(items, o.relationships().DeleteTransactItem(RelationshipKey{UserID: r.UserID, ReceiverID: rid}))
+	}
+	if len(items) > transactWriteLimit {
+		return fmt.Errorf("receiver %s has too many relationships to remove at once: %d", rid, len(relationships))
+	}
+
+	_, err = o.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: items,
+	})
+	if err != nil {
+		log.WithTraceContext(ctx, o.logger).Error("error removing receiver", zap.Error(err))
+		return cancellationErrors(err, map[int]error{
+			0: ErrReceiverNotFound,
+			1: ErrNotPrimaryCareGiver,
+		})
+	}
+
+	log.WithTraceContext(ctx, o.logger).Info("successfully removed receiver", zap.Int("relationships", len(items)-1))
+	return nil
+}
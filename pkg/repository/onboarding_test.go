@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/dynamo"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/receiver"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func cancelled(codes ...string) error {
+	reasons := make([]types.CancellationReason, len(codes))
+	for i, code := range codes {
+		reasons[i] = types.CancellationReason{Code: aws.String(code)}
+	}
+	return &types.TransactionCanceledException{CancellationReasons: reasons}
+}
+
+func TestOnboardReceiver(t *testing.T) {
+	tests := map[string]struct {
+		receiver      receiver.Receiver
+		relationship  *relationship.Relationship
+		mockDynamo    *dynamo.Mock
+		expectedError error
+	}{
+		"Happy Path - Receiver Onboarded": {
+			receiver:     receiver.Receiver{ReceiverID: "Receiver#123"},
+			relationship: relationship.NewRelationship("User#123", "Receiver#123", true, true),
+			mockDynamo: &dynamo.Mock{
+				TransactOutput: &dynamodb.TransactWriteItemsOutput{},
+			},
+		},
+		"Sad Path - Not Primary": {
+			receiver:      receiver.Receiver{ReceiverID: "Receiver#123"},
+			relationship:  relationship.NewRelationship("User#123", "Receiver#123", false, true),
+			mockDynamo:    &dynamo.Mock{},
+			expectedError: ErrNotPrimaryCareGiver,
+		},
+		"Sad Path - Receiver Exists": {
+			receiver:     receiver.Receiver{ReceiverID: "Receiver#123"},
+			relationship: relationship.NewRelationship("User#123", "Receiver#123", true, true),
+			mockDynamo: &dynamo.Mock{
+				Err: cancelled(conditionalCheckFailed, "None"),
+			},
+			expectedError: ErrReceiverExists,
+		},
+		"Sad Path - Relationship Exists": {
+			receiver:     receiver.Receiver{ReceiverID: "Receiver#123"},
+			relationship: relationship.NewRelationship("User#123", "Receiver#123", true, true),
+			mockDynamo: &dynamo.Mock{
+				Err: cancelled("None", conditionalCheckFailed),
+			},
+			expectedError: ErrRelationshipExists,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			repo := NewOnboardingRepository("receiver-table", "relationship-table", tc.mockDynamo, zap.NewNop())
+
+			err := repo.OnboardReceiver(context.Background(), tc.receiver, tc.relationship)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("Sad Path - Mismatched Receiver", func(t *testing.T) {
+		repo := NewOnboardingRepository("receiver-table", "relationship-table", &dynamo.Mock{}, zap.NewNop())
+
+		err := repo.OnboardReceiver(context.Background(), receiver.Receiver{ReceiverID: "Receiver#123"}, relationship.NewRelationship("User#123", "Receiver#456", true, true))
+		assert.Error(t, err)
+	})
+}
+
+func TestRemoveReceiver(t *testing.T) {
+	careGivers := &dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{
+		{"user_id": &types.AttributeValueMemberS{Value: "User#123"}, "receiver_id": &types.AttributeValueMemberS{Value: "Receiver#123"}},
+		{"user_id": &types.AttributeValueMemberS{Value: "User#456"}, "receiver_id": &types.AttributeValueMemberS{Value: "Receiver#123"}},
+	}}
+
+	tests := map[string]struct {
+		mockDynamo    *dynamo.Mock
+		expectedItems int
+		expectedError error
+	}{
+		"Happy Path - Receiver And Relationships Removed": {
+			mockDynamo: &dynamo.Mock{
+				QueryOutput:    careGivers,
+				TransactOutput: &dynamodb.TransactWriteItemsOutput{},
+			},
+			expectedItems: 3,
+		},
+		"Sad Path - Receiver Not Found": {
+			mockDynamo: &dynamo.Mock{
+				QueryOutput: &dynamodb.QueryOutput{},
+				OnTransact:  dynamo.MockMethod[dynamodb.TransactWriteItemsInput, dynamodb.TransactWriteItemsOutput]{Err: cancelled(conditionalCheckFailed, "None")},
+			},
+			expectedItems: 2,
+			expectedError: ErrReceiverNotFound,
+		},
+		"Sad Path - Not Primary": {
+			mockDynamo: &dynamo.Mock{
+				QueryOutput: careGivers,
+				OnTransact:  dynamo.MockMethod[dynamodb.TransactWriteItemsInput, dynamodb.TransactWriteItemsOutput]{Err: cancelled("None", conditionalCheckFailed, "None")},
+			},
+			expectedItems: 3,
+			expectedError: ErrNotPrimaryCareGiver,
+		},
+		"Sad Path - Query Error": {
+			mockDynamo: &dynamo.Mock{
+				Err: errors.New("An error occured during Query"),
+			},
+			expectedError: errors.New("An error occured during Query"),
+		},
+		"Sad Path - Other Error": {
+			mockDynamo: &dynamo.Mock{
+				QueryOutput: careGivers,
+				OnTransact:  dynamo.MockMethod[dynamodb.TransactWriteItemsInput, dynamodb.TransactWriteItemsOutput]{Err: errors.New("An error occured during Transact Write")},
+			},
+			expectedItems: 3,
+			expectedError: errors.New("An error occured during Transact Write"),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			repo := NewOnboardingRepository("receiver-table", "relationship-table", tc.mockDynamo, zap.NewNop())
+
+			err := repo.RemoveReceiver(context.Background(), "Receiver#123", "User#123")
+			if tc.expectedError != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if tc.expectedItems > 0 {
+				assert.Len(t, tc.mockDynamo.OnTransact.Calls[0].TransactItems, tc.expectedItems)
+			}
+		})
+	}
+}
@@ -2,11 +2,10 @@ package repository
 
 import (
 	"context"
+	"errors"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/dynamo"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/encryption"
 	"github.com/care-giver-app/care-giver-golang-common/pkg/log"
 	"github.com/care-giver-app/care-giver-golang-common/pkg/receiver"
 	"go.uber.org/zap"
@@ -16,15 +15,28 @@ const (
 	receiverID = "receiver_id"
 )
 
+var (
+	receiverKeySchema = PartitionKeySchema(receiverID)
+)
+
 type ReceiverRepositoryProvider interface {
 	CreateReceiver(r receiver.Receiver) error
 	GetReceiver(rid string) (receiver.Receiver, error)
 }
 
+type ReceiverRepositoryProviderV2 interface {
+	CreateReceiver(ctx context.Context, r receiver.Receiver) error
+	GetReceiver(ctx context.Context, rid string) (receiver.Receiver, error)
+	GetReceivers(ctx context.Context, rids []string) ([]receiver.Receiver, error)
+	UpdateReceiver(ctx context.Context, r receiver.Receiver, mask []string) (receiver.Receiver, error)
+	DeleteReceiver(ctx context.Context, rid string) error
+}
+
 type ReceiverRepository struct {
 	Ctx       context.Context
 	Client    DynamodbClientProvider
 	TableName string
+	Encryptor *encryption.Encryptor
 	logger    *zap.Logger
 }
 
@@ -37,45 +49,118 @@ func NewReceiverRespository(ctx context.Context, tableName string, client Dynamo
 	}
 }
 
+func (rr *ReceiverRepository) v2() *ReceiverRepositoryV2 {
+	return &ReceiverRepositoryV2{
+		Client:    rr.Client,
+		TableName: rr.TableName,
+		Encryptor: rr.Encryptor,
+		logger:    rr.logger,
+	}
+}
+
 func (rr *ReceiverRepository) CreateReceiver(r receiver.Receiver) error {
-	rr.logger.Info("adding receiver to db", zap.Any(log.ReceiverIDLogKey, r.ReceiverID))
+	return rr.v2().CreateReceiver(rr.Ctx, r)
+}
 
-	rr.logger.Info("marshalling receiver struct")
-	av, err := attributevalue.MarshalMap(r)
-	if err != nil {
-		return err
+func (rr *ReceiverRepository) GetReceiver(rid string) (receiver.Receiver, error) {
+	return rr.v2().GetReceiver(rr.Ctx, rid)
+}
+
+// ReceiverRepositoryV2 encrypts the tagged fields of the items it writes when
+// Encryptor is set. Retry bounds the retries of items left unprocessed by
+// batch reads and writes.
+type ReceiverRepositoryV2 struct {
+	Client    DynamodbClientProvider
+	TableName string
+	Encryptor *encryption.Encryptor
+	Retry     dynamo.RetryPolicy
+	logger    *zap.Logger
+}
+
+func NewReceiverRepositoryV2(tableName string, client DynamodbClientProvider, logger *zap.Logger) *ReceiverRepositoryV2 {
+	return &ReceiverRepositoryV2{
+		Client:    client,
+		TableName: tableName,
+		logger:    logger.With(zap.String(log.TableNameLogKey, tableName)),
 	}
+}
 
-	rr.logger.Info("inserting item into db", zap.Any("item", av))
-	_, err = rr.Client.PutItem(rr.Ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(rr.TableName),
-		Item:      av,
-	})
-	if err != nil {
-		return err
+func (rr *ReceiverRepositoryV2) table() *Table[receiver.Receiver, string] {
+	return &Table[receiver.Receiver, string]{
+		Name:      rr.TableName,
+		Client:    rr.Client,
+		Schema:    receiverKeySchema,
+		Encryptor: rr.Encryptor,
+		Retry:     rr.Retry,
+		logger:    rr.logger,
 	}
-	rr.logger.Info("successfully inserted item")
+}
 
-	return nil
+func (rr *ReceiverRepositoryV2) CreateReceiver(ctx context.Context, r receiver.Receiver) error {
+	log.WithTraceContext(ctx, rr.logger).Info("adding receiver to db", zap.Any(log.ReceiverIDLogKey, r.ReceiverID))
+	return rr.table().Put(ctx, r)
 }
 
-func (rr *ReceiverRepository) GetReceiver(rid string) (receiver.Receiver, error) {
-	rr.logger.Info("getting receiver from db", zap.Any(log.ReceiverIDLogKey, rid))
-	result, err := rr.Client.GetItem(rr.Ctx, &dynamodb.GetItemInput{
-		TableName: &rr.TableName,
-		Key: map[string]types.AttributeValue{
-			receiverID: &types.AttributeValueMemberS{Value: rid},
-		},
-	})
-	if err != nil {
+func (rr *ReceiverRepositoryV2) GetReceiver(ctx context.Context, rid string) (receiver.Receiver, error) {
+	log.WithTraceContext(ctx, rr.logger).Info("getting receiver from db", zap.Any(log.ReceiverIDLogKey, rid))
+
+	r, err := rr.table().Get(ctx, rid)
+	if err != nil && !errors.Is(err, ErrItemNotFound) {
 		return receiver.Receiver{}, err
 	}
 
-	var r receiver.Receiver
-	err = attributevalue.UnmarshalMap(result.Item, &r)
+	return r, nil
+}
+
+func (rr *ReceiverRepositoryV2) GetReceivers(ctx context.Context, rids []string) ([]receiver.Receiver, error) {
+	log.WithTraceContext(ctx, rr.logger).Info("getting receivers from db", zap.Int("count", len(rids)))
+
+	keys := make([]string, 0, len(rids))
+	seen := make(map[string]bool, len(rids))
+	for _, rid := range rids {
+		if !seen[rid] {
+			seen[rid] = true
+			keys = append(keys, rid)
+		}
+	}
+
+	items, failures := rr.table().GetBatch(ctx, keys)
+
+	found := make(map[string]receiver.Receiver, len(items))
+	for _, r := range items {
+		found[r.ReceiverID] = r
+	}
+
+	receivers := make([]receiver.Receiver, 0, len(found))
+	for _, rid := range keys {
+		if r, ok := found[rid]; ok {
+			receivers = append(receivers, r)
+		}
+	}
+
+	if len(failures) > 0 {
+		log.WithTraceContext(ctx, rr.logger).Error("failed to get receivers", zap.Int("failed", len(failures)))
+		return receivers, newBatchError(failures)
+	}
+
+	return receivers, nil
+}
+
+func (rr *ReceiverRepositoryV2) UpdateReceiver(ctx context.Context, r receiver.Receiver, mask []string) (receiver.Receiver, error) {
+	log.WithTraceContext(ctx, rr.logger).Info("updating receiver in db", zap.String(log.ReceiverIDLogKey, r.ReceiverID), zap.Strings("fields", mask))
+
+	updated, err := rr.table().Update(ctx, r.ReceiverID, r, mask)
+	if errors.Is(err, ErrConditionFailed) {
+		return receiver.Receiver{}, ErrReceiverNotFound
+	}
 	if err != nil {
 		return receiver.Receiver{}, err
 	}
 
-	return r, nil
+	return updated, nil
+}
+
+func (rr *ReceiverRepositoryV2) DeleteReceiver(ctx context.Context, rid string) error {
+	log.WithTraceContext(ctx, rr.logger).Info("deleting receiver from db", zap.String(log.ReceiverIDLogKey, rid))
+	return rr.table().Delete(ctx, rid)
 }
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/log"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"go.uber.org/zap"
+)
+
+type DeleteReceiverOptions struct {
+	DryRun bool
+}
+
+type ReceiverDeletionReport struct {
+	ReceiverID    string
+	DryRun        bool
+	EventIDs      []string
+	Relationships []relationship.Relationship
+}
+
+// ReceiverDeleter removes a receiver together with its events and
+// relationships. Every step is idempotent and the requesting primary care
+// giver's relationship is removed last, so a failed deletion can be retried
+// by the same user until it completes.
+type ReceiverDeleter struct {
+	Receivers     ReceiverRepositoryProviderV2
+	Events        EventRepositoryProviderV2
+	Relationships RelationshipRepositoryProviderV2
+	logger        *zap.Logger
+}
+
+func NewReceiverDeleter(receivers ReceiverRepositoryProviderV2, events EventRepositoryProviderV2, relationships RelationshipRepositoryProviderV2, logger *zap.Logger) *ReceiverDeleter {
+	return &ReceiverDeleter{
+		Receivers:     receivers,
+		Events:        events,
+		Relationships: relationships,
+		logger:        logger,
+	}
+}
+
+func (rd *ReceiverDeleter) DeleteReceiver(ctx context.Context, uid string, rid string, opts DeleteReceiverOptions) (*ReceiverDeletionReport, error) {
+	logger := log.WithTraceContext(ctx, rd.logger).With(zap.String(log.UserIDLogKey, uid), zap.String(log.ReceiverIDLogKey, rid), zap.Bool("dry run", opts.DryRun))
+	logger.Info("deleting receiver and associated data")
+
+	relationships, err := rd.Relationships.GetRelationshipsByReceiver(ctx, rid)
+	if err != nil {
+		return nil, err
+	}
+
+	if !relationship.Can(uid, rid, relationship.ActionDeleteReceiver, relationships) {
+		logger.Warn("user is not allowed to delete receiver")
+		return nil, ErrNotPrimaryCareGiver
+	}
+
+	events, err := rd.Events.GetEvents(ctx, rid, TimestampBound{})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReceiverDeletionReport{
+		ReceiverID:    rid,
+		DryRun:        opts.DryRun,
+		EventIDs:      make([]string, 0, len(events)),
+		Relationships: relationships,
+	}
+	for _, e := range events {
+		report.EventIDs = append(report.EventIDs, e.EventID)
+	}
+
+	logger.Info("found receiver data to delete", zap.Int("events", len(report.EventIDs)), zap.Int("relationships", len(relationships)))
+	if opts.DryRun {
+		return report, nil
+	}
+
+	for _, eid := range report.EventIDs {
+		err := rd.Events.DeleteEvent(ctx, rid, eid)
+		if err != nil {
+			return report, fmt.Errorf("deleting event %s: %w", eid, err)
+		}
+	}
+
+	var primaries []relationship.Relationship
+	for _, r := range relationships {
+		if r.EffectiveRole() == relationship.RoleOwner {
+			primaries = append(primaries, r)
+			continue
+		}
+		err := rd.Relationships.DeleteRelationship(ctx, r.UserID, rid)
+		if err != nil {
+			return report, fmt.Errorf("deleting relationship for user %s: %w", r.UserID, err)
+		}
+	}
+
+	err = rd.Receivers.DeleteReceiver(ctx, rid)
+	if err != nil {
+		return report, fmt.Errorf("deleting receiver: %w", err)
+	}
+
+	// The requesting user goes last so the authorization check above still
+	// passes if this loop is interrupted and the deletion is retried.
+	for _, r := range primaries {
+		if r.UserID == uid {
+			continue
+		}
+		err := rd.Relationships.DeleteRelationship(ctx, r.UserID, rid)
+		if err != nil {
+			return report, fmt.Errorf("deleting relationship for user %s: %w", r.UserID, err)
+		}
+	}
+	err = rd.Relationships.DeleteRelationship(ctx, uid, rid)
+	if err != nil {
+		return report, fmt.Errorf("deleting relationship for user %s: %w", uid, err)
+	}
+
+	logger.Info("successfully deleted receiver")
+	return report, nil
+}
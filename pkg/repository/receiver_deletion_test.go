@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/dynamo"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestDeleteReceiverCascade(t *testing.T) {
+	relationshipsPage := &dynamodb.QueryOutput{
+		Items: []map[string]types.AttributeValue{
+			{
+				"user_id":            &types.AttributeValueMemberS{Value: "User#1"},
+				"receiver_id":        &types.AttributeValueMemberS{Value: "Receiver#123"},
+				"primary_care_giver": &types.AttributeValueMemberBOOL{Value: true},
+			},
+			{
+				"user_id":            &types.AttributeValueMemberS{Value: "User#2"},
+				"receiver_id":        &types.AttributeValueMemberS{Value: "Receiver#123"},
+				"primary_care_giver": &types.AttributeValueMemberBOOL{Value: false},
+			},
+		},
+	}
+	eventsPage := &dynamodb.QueryOutput{
+		Items: []map[string]types.AttributeValue{
+			{"event_id": &types.AttributeValueMemberS{Value: "Event#1"}},
+			{"event_id": &types.AttributeValueMemberS{Value: "Event#2"}},
+		},
+	}
+	expectedRelationships := []relationship.Relationship{
+		{UserID: "User#1", ReceiverID: "Receiver#123", PrimaryCareGiver: true},
+		{UserID: "User#2", ReceiverID: "Receiver#123"},
+	}
+
+	tests := map[string]struct {
+		uid            string
+		opts           DeleteReceiverOptions
+		mockDynamo     *dynamo.Mock
+		expectedReport *ReceiverDeletionReport
+		expectedError  error
+		expectError    bool
+	}{
+		"Happy Path - Receiver Deleted": {
+			uid: "User#1",
+			mockDynamo: &dynamo.Mock{
+				QueryOutputs: []*dynamodb.QueryOutput{relationshipsPage, eventsPage},
+			},
+			expectedReport: &ReceiverDeletionReport{
+				ReceiverID:    "Receiver#123",
+				EventIDs:      []string{"Event#1", "Event#2"},
+				Relationships: expectedRelationships,
+			},
+		},
+		"Happy Path - Dry Run": {
+			uid:  "User#1",
+			opts: DeleteReceiverOptions{DryRun: true},
+			mockDynamo: &dynamo.Mock{
+				QueryOutputs: []*dynamodb.QueryOutput{relationshipsPage, eventsPage},
+			},
+			expectedReport: &ReceiverDeletionReport{
+				ReceiverID:    "Receiver#123",
+				DryRun:        true,
+				EventIDs:      []string{"Event#1", "Event#2"},
+				Relationships: expectedRelationships,
+			},
+		},
+		"Sad Path - Not Primary Care Giver": {
+			uid: "User#2",
+			mockDynamo: &dynamo.Mock{
+				QueryOutputs: []*dynamodb.QueryOutput{relationshipsPage},
+			},
+			expectedError: ErrNotPrimaryCareGiver,
+		},
+		"Sad Path - Query Error": {
+			uid: "User#1",
+			mockDynamo: &dynamo.Mock{
+				Err: errors.New("An error occured during Query"),
+			},
+			expectError: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			deleter := NewReceiverDeleter(
+				NewReceiverRepositoryV2("receiver-table", tc.mockDynamo, zap.NewNop()),
+				NewEventRepositoryV2("event-table", tc.mockDynamo, zap.NewNop()),
+				NewRelationshipRepositoryV2("relationship-table", tc.mockDynamo, zap.NewNop()),
+				zap.NewNop(),
+			)
+
+			report, err := deleter.DeleteReceiver(context.Background(), tc.uid, "Receiver#123", tc.opts)
+			switch {
+			case tc.expectedError != nil:
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, report)
+			case tc.expectError:
+				assert.Error(t, err)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedReport, report)
+			}
+		})
+	}
+}
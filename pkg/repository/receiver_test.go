@@ -118,3 +118,190 @@ func TestGetReceiver(t *testing.T) {
 		})
 	}
 }
+
+func TestReceiverRepositoryV2(t *testing.T) {
+	var _ ReceiverRepositoryProvider = &ReceiverRepository{}
+	var _ ReceiverRepositoryProviderV2 = &ReceiverRepositoryV2{}
+
+	mockDynamo := &dynamo.Mock{
+		GetOutput: &dynamodb.GetItemOutput{
+			Item: map[string]types.AttributeValue{
+				"receiver_id": &types.AttributeValueMemberS{Value: "Receiver#123"},
+			},
+		},
+	}
+	testReceiverRepo := NewReceiverRepositoryV2("receiver-table", mockDynamo, zap.NewNop())
+	ctx := context.Background()
+
+	err := testReceiverRepo.CreateReceiver(ctx, receiver.Receiver{ReceiverID: "Receiver#123"})
+	assert.Nil(t, err)
+
+	r, err := testReceiverRepo.GetReceiver(ctx, "Receiver#123")
+	assert.Nil(t, err)
+	assert.Equal(t, "Receiver#123", r.ReceiverID)
+}
+
+func TestGetReceivers(t *testing.T) {
+	item := func(rid string) map[string]types.AttributeValue {
+		return map[string]types.AttributeValue{
+			"receiver_id": &types.AttributeValueMemberS{Value: rid},
+			"first_name":  &types.AttributeValueMemberS{Value: "testFirstName"},
+		}
+	}
+
+	tests := map[string]struct {
+		receiverIDs       []string
+		mockDynamo        *dynamo.Mock
+		expectedReceivers []receiver.Receiver
+		expectError       bool
+	}{
+		"Happy Path - Ordered By Input": {
+			receiverIDs: []string{"Receiver#1", "Receiver#2", "Receiver#1"},
+			mockDynamo: &dynamo.Mock{
+				BatchGetOutput: &dynamodb.BatchGetItemOutput{
+					Responses: map[string][]map[string]types.AttributeValue{
+						"receiver-table": {item("Receiver#2"), item("Receiver#1")},
+					},
+				},
+			},
+			expectedReceivers: []receiver.Receiver{
+				{ReceiverID: "Receiver#1", FirstName: "testFirstName"},
+				{ReceiverID: "Receiver#2", FirstName: "testFirstName"},
+			},
+		},
+		"Happy Path - Missing Receiver Omitted": {
+			receiverIDs: []string{"Receiver#1", "Receiver#404"},
+			mockDynamo: &dynamo.Mock{
+				BatchGetOutput: &dynamodb.BatchGetItemOutput{
+					Responses: map[string][]map[string]types.AttributeValue{
+						"receiver-table": {item("Receiver#1")},
+					},
+				},
+			},
+			expectedReceivers: []receiver.Receiver{
+				{ReceiverID: "Receiver#1", FirstName: "testFirstName"},
+			},
+		},
+		"Sad Path - Batch Get Error": {
+			receiverIDs: []string{"Receiver#1"},
+			mockDynamo: &dynamo.Mock{
+				Err: errors.New("An error occured during Batch Get"),
+			},
+			expectedReceivers: []receiver.Receiver{},
+			expectError:       true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			testReceiverRepo := NewReceiverRepositoryV2("receiver-table", tc.mockDynamo, zap.NewNop())
+
+			receivers, err := testReceiverRepo.GetReceivers(context.Background(), tc.receiverIDs)
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedReceivers, receivers)
+		})
+	}
+}
+
+func TestDeleteReceiver(t *testing.T) {
+	tests := map[string]struct {
+		mockDynamo  *dynamo.Mock
+		expectError bool
+	}{
+		"Happy Path - Receiver Deleted": {
+			mockDynamo: &dynamo.Mock{},
+		},
+		"Sad Path - Delete Error": {
+			mockDynamo: &dynamo.Mock{
+				Err: errors.New("An error occured during Delete"),
+			},
+			expectError: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			testReceiverRepo := NewReceiverRepositoryV2("receiver-table", tc.mockDynamo, zap.NewNop())
+
+			err := testReceiverRepo.DeleteReceiver(context.Background(), "Receiver#123")
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUpdateReceiver(t *testing.T) {
+	tests := map[string]struct {
+		receiver         receiver.Receiver
+		mask             []string
+		mockDynamo       *dynamo.Mock
+		expectedReceiver receiver.Receiver
+		expectedError    error
+		expectError      bool
+	}{
+		"Happy Path - Receiver Updated": {
+			receiver: receiver.Receiver{ReceiverID: "Receiver#123", FirstName: "newFirstName"},
+			mask:     []string{"firstName"},
+			mockDynamo: &dynamo.Mock{
+				UpdateOutput: &dynamodb.UpdateItemOutput{
+					Attributes: map[string]types.AttributeValue{
+						"receiver_id": &types.AttributeValueMemberS{Value: "Receiver#123"},
+						"first_name":  &types.AttributeValueMemberS{Value: "newFirstName"},
+						"last_name":   &types.AttributeValueMemberS{Value: "testLastName"},
+					},
+				},
+			},
+			expectedReceiver: receiver.Receiver{
+				ReceiverID: "Receiver#123",
+				FirstName:  "newFirstName",
+				LastName:   "testLastName",
+			},
+		},
+		"Sad Path - Receiver Not Found": {
+			receiver: receiver.Receiver{ReceiverID: "Receiver#404"},
+			mask:     []string{"firstName"},
+			mockDynamo: &dynamo.Mock{
+				Err: &types.ConditionalCheckFailedException{},
+			},
+			expectedError: ErrReceiverNotFound,
+		},
+		"Sad Path - Key In Mask": {
+			receiver:      receiver.Receiver{ReceiverID: "Receiver#123"},
+			mask:          []string{"receiverId"},
+			mockDynamo:    &dynamo.Mock{},
+			expectedError: ErrInvalidFieldMask,
+		},
+		"Sad Path - Update Error": {
+			receiver: receiver.Receiver{ReceiverID: "Receiver#123"},
+			mask:     []string{"lastName"},
+			mockDynamo: &dynamo.Mock{
+				Err: errors.New("An error occured during Update Item"),
+			},
+			expectError: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			testReceiverRepo := NewReceiverRepositoryV2("receiver-table", tc.mockDynamo, zap.NewNop())
+
+			r, err := testReceiverRepo.UpdateReceiver(context.Background(), tc.receiver, tc.mask)
+			switch {
+			case tc.expectedError != nil:
+				assert.ErrorIs(t, err, tc.expectedError)
+			case tc.expectError:
+				assert.Error(t, err)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedReceiver, r)
+			}
+		})
+	}
+}
@@ -2,9 +2,9 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/care-giver-app/care-giver-golang-common/pkg/log"
@@ -12,6 +12,48 @@ import (
 	"go.uber.org/zap"
 )
 
+var (
+	relationshipKeySchema = CompositeKeySchema(userID, receiverID, func(k RelationshipKey) (string, string) {
+		return k.UserID, k.ReceiverID
+	})
+
+	// ownerCondition holds for owners, including relationships stored before
+	// roles that are marked as primary care giver.
+	ownerCondition = Condition{
+		Expression: "#role = :owner OR (attribute_not_exists(#role) AND primary_care_giver = :primary)",
+		Names:      map[string]string{"#role": "role"},
+		Values: map[string]types.AttributeValue{
+			":owner":   &types.AttributeValueMemberS{Value: string(relationship.RoleOwner)},
+			":primary": &types.AttributeValueMemberBOOL{Value: true},
+		},
+	}
+	// relationshipDerived keeps the key of the sparse email notifications
+	// index in step with EmailNotifications.
+	relationshipDerived = []DerivedAttribute[relationship.Relationship]{
+		{
+			Name:   emailNotificationsGSIKey,
+			Fields: []string{"emailNotifications"},
+			Value: func(r relationship.Relationship) types.AttributeValue {
+				if !r.EmailNotifications {
+					return nil
+				}
+				return &types.AttributeValueMemberN{Value: "1"}
+			},
+		},
+	}
+
+	notOwnerCondition = Condition{
+		Expression: "NOT (" + ownerCondition.Expression + ")",
+		Names:      ownerCondition.Names,
+		Values:     ownerCondition.Values,
+	}
+)
+
+type RelationshipKey struct {
+	UserID     string
+	ReceiverID string
+}
+
 type RelationshipRepositoryProvider interface {
 	AddRelationship(r *relationship.Relationship) error
 	GetRelationship(userID string, receiverID string) (*relationship.Relationship, error)
@@ -21,6 +63,17 @@ type RelationshipRepositoryProvider interface {
 	GetRelationshipsByEmailNotifications() ([]relationship.Relationship, error)
 }
 
+type RelationshipRepositoryProviderV2 interface {
+	AddRelationship(ctx context.Context, r *relationship.Relationship) error
+	GetRelationship(ctx context.Context, userID string, receiverID string) (*relationship.Relationship, error)
+	GetRelationshipsByUser(ctx context.Context, userID string) ([]relationship.Relationship, error)
+	GetRelationshipsByReceiver(ctx context.Context, receiverID string) ([]relationship.Relationship, error)
+	DeleteRelationship(ctx context.Context, userID string, receiverID string) error
+	GetRelationshipsByEmailNotifications(ctx context.Context) ([]relationship.Relationship, error)
+	TransferPrimary(ctx context.Context, receiverID string, fromUID string, toUID string) error
+	UpdateNotificationPreferences(ctx context.Context, userID string, receiverID string, p relationship.NotificationPreferences) (*relationship.Relationship, error)
+}
+
 type RelationshipRepository struct {
 	Ctx       context.Context
 	Client    DynamodbClientProvider
@@ -37,162 +90,352 @@ func NewRelationshipRepository(ctx context.Context, tableName string, client Dyn
 	}
 }
 
+func (rr *RelationshipRepository) v2() *RelationshipRepositoryV2 {
+	return &RelationshipRepositoryV2{
+		Client:    rr.Client,
+		TableName: rr.TableName,
+		logger:    rr.logger,
+	}
+}
+
 func (rr *RelationshipRepository) AddRelationship(r *relationship.Relationship) error {
-	rr.logger.Info("adding user receiver relationship to db")
+	return rr.v2().AddRelationship(rr.Ctx, r)
+}
 
-	rr.logger.Info("marshalling user receiver relationship struct")
-	av, err := attributevalue.MarshalMap(r)
+func (rr *RelationshipRepository) GetRelationship(userID string, receiverID string) (*relationship.Relationship, error) {
+	return rr.v2().GetRelationship(rr.Ctx, userID, receiverID)
+}
+
+func (rr *RelationshipRepository) GetRelationshipsByUser(userID string) ([]relationship.Relationship, error) {
+	return rr.v2().GetRelationshipsByUser(rr.Ctx, userID)
+}
+
+func (rr *RelationshipRepository) GetRelationshipsByReceiver(receiverID string) ([]relationship.Relationship, error) {
+	return rr.v2().GetRelationshipsByReceiver(rr.Ctx, receiverID)
+}
+
+func (rr *RelationshipRepository) DeleteRelationship(userID string, receiverID string) error {
+	return rr.v2().DeleteRelationship(rr.Ctx, userID, receiverID)
+}
+
+func (rr *RelationshipRepository) GetRelationshipsByEmailNotifications() ([]relationship.Relationship, error) {
+	return rr.v2().GetRelationshipsByEmailNotifications(rr.Ctx)
+}
+
+type RelationshipRepositoryV2 struct {
+	Client    DynamodbClientProvider
+	TableName string
+	logger    *zap.Logger
+}
+
+func NewRelationshipRepositoryV2(tableName string, client DynamodbClientProvider, logger *zap.Logger) *RelationshipRepositoryV2 {
+	return &RelationshipRepositoryV2{
+		Client:    client,
+		TableName: tableName,
+		logger:    logger.With(zap.String(log.TableNameLogKey, tableName)),
+	}
+}
+
+func (rr *RelationshipRepositoryV2) table() *Table[relationship.Relationship, RelationshipKey] {
+	return &Table[relationship.Relationship, RelationshipKey]{
+		Name:    rr.TableName,
+		Client:  rr.Client,
+		Schema:  relationshipKeySchema,
+		Derived: relationshipDerived,
+		logger:  rr.logger,
+	}
+}
+
+// AddRelationship stores r, replacing any relationship the user already has
+// with the receiver. Replacing an owner with a non-owner fails with
+// ErrLastPrimaryCareGiver unless the receiver has another owner. When r has
+// notification preferences EmailNotifications is stored in step with them.
+func (rr *RelationshipRepositoryV2) AddRelationship(ctx context.Context, r *relationship.Relationship) error {
+	log.WithTraceContext(ctx, rr.logger).Info("adding user receiver relationship to db")
+	r, err := ValidateRelationship(r)
 	if err != nil {
 		return err
 	}
+	if r.EffectiveRole() == relationship.RoleOwner {
+		return rr.table().Put(ctx, *r)
+	}
 
-	rr.logger.Info("inserting item into db", zap.Any("item", av))
-	_, err = rr.Client.PutItem(rr.Ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(rr.TableName),
-		Item:      av,
-	})
-	if err != nil {
+	err = rr.table().Put(ctx, *r, notOwnerCondition)
+	if !errors.Is(err, ErrConditionFailed) {
 		return err
 	}
-	rr.logger.Info("successfully inserted item")
 
-	return nil
+	put, err := rr.table().PutTransactItem(ctx, *r)
+	if err != nil {
+		return err
+	}
+	return rr.writeKeepingOwner(ctx, r.UserID, r.ReceiverID, put, false)
 }
 
-func (rr *RelationshipRepository) GetRelationship(userID string, receiverID string) (*relationship.Relationship, error) {
-	rr.logger.Info("getting user receiver relationship from db", zap.String(log.UserIDLogKey, userID), zap.String(log.ReceiverIDLogKey, receiverID))
-
-	result, err := rr.Client.GetItem(rr.Ctx, &dynamodb.GetItemInput{
-		TableName: &rr.TableName,
-		Key: map[string]types.AttributeValue{
-			"user_id":     &types.AttributeValueMemberS{Value: userID},
-			"receiver_id": &types.AttributeValueMemberS{Value: receiverID},
-		},
-	})
-	if err != nil {
-		return nil, err
+// ValidateRelationship checks the role and notification preferences of r and
+// returns r with PrimaryCareGiver in step with its role and EmailNotifications
+// in step with its preferences. It is the validation AddRelationship applies,
+// for alternative implementations.
+func ValidateRelationship(r *relationship.Relationship) (*relationship.Relationship, error) {
+	if r.Role != "" && !r.Role.Valid() {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRole, r.Role)
+	}
+	// Relationships stored without a role keep deriving it from
+	// PrimaryCareGiver; for the others the flag follows the role, so readers
+	// of either field agree.
+	normalized := *r
+	normalized.PrimaryCareGiver = r.EffectiveRole() == relationship.RoleOwner
+	if r.Notifications == nil {
+		return &normalized, nil
+	}
+	if err := r.Notifications.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidNotificationPreferences, err)
 	}
 
-	var r relationship.Relationship
-	err = attributevalue.UnmarshalMap(result.Item, &r)
-	if err != nil {
+	normalized.SetNotifications(*r.Notifications)
+	return &normalized, nil
+}
+
+func (rr *RelationshipRepositoryV2) GetRelationship(ctx context.Context, userID string, receiverID string) (*relationship.Relationship, error) {
+	log.WithTraceContext(ctx, rr.logger).Info("getting user receiver relationship from db", zap.String(log.UserIDLogKey, userID), zap.String(log.ReceiverIDLogKey, receiverID))
+
+	r, err := rr.table().Get(ctx, RelationshipKey{UserID: userID, ReceiverID: receiverID})
+	if err != nil && !errors.Is(err, ErrItemNotFound) {
 		return nil, err
 	}
 
 	return &r, nil
 }
 
-func (rr *RelationshipRepository) GetRelationshipsByUser(userID string) ([]relationship.Relationship, error) {
-	rr.logger.Info("getting user receiver relationships from db", zap.String(log.UserIDLogKey, userID))
+func (rr *RelationshipRepositoryV2) GetRelationshipsByUser(ctx context.Context, userID string) ([]relationship.Relationship, error) {
+	log.WithTraceContext(ctx, rr.logger).Info("getting user receiver relationships from db", zap.String(log.UserIDLogKey, userID))
 
-	keyCondition := "user_id = :uid"
-	expressionAttributeValues := map[string]types.AttributeValue{
-		":uid": &types.AttributeValueMemberS{Value: userID},
+	return rr.table().Query(ctx, QueryParams{
+		KeyCondition: "user_id = :uid",
+		Values: map[string]types.AttributeValue{
+			":uid": &types.AttributeValueMemberS{Value: userID},
+		},
+	})
+}
+
+func (rr *RelationshipRepositoryV2) GetRelationshipsByReceiver(ctx context.Context, receiverID string) ([]relationship.Relationship, error) {
+	log.WithTraceContext(ctx, rr.logger).Info("getting relationships by receiver from db", zap.String(log.ReceiverIDLogKey, receiverID))
+
+	return rr.table().Query(ctx, QueryParams{
+		IndexName:    RelationshipReceiverIndex,
+		KeyCondition: "receiver_id = :rid",
+		Values: map[string]types.AttributeValue{
+			":rid": &types.AttributeValueMemberS{Value: receiverID},
+		},
+	})
+}
+
+// DeleteRelationship removes the relationship between the user and the
+// receiver. An owner can only be removed while the receiver has another owner
+// or no other care givers, otherwise ErrLastPrimaryCareGiver is returned.
+func (rr *RelationshipRepositoryV2) DeleteRelationship(ctx context.Context, userID string, receiverID string) error {
+	log.WithTraceContext(ctx, rr.logger).Info("deleting user receiver relationship from db", zap.String(log.UserIDLogKey, userID), zap.String(log.ReceiverIDLogKey, receiverID))
+
+	key := RelationshipKey{UserID: userID, ReceiverID: receiverID}
+	err := rr.table().Delete(ctx, key, notOwnerCondition)
+	if !errors.Is(err, ErrConditionFailed) {
+		return err
 	}
 
-	queryInput := &dynamodb.QueryInput{
-		TableName:                 aws.String(rr.TableName),
-		KeyConditionExpression:    aws.String(keyCondition),
-		ExpressionAttributeValues: expressionAttributeValues,
+	return rr.writeKeepingOwner(ctx, userID, receiverID, rr.table().DeleteTransactItem(key), true)
+}
+
+// TransferPrimary makes toUID the owner of receiverID in place of fromUID in
+// one transaction. fromUID stays on as an editor. It fails with
+// ErrNotPrimaryCareGiver when fromUID is not an owner and with
+// ErrRelationshipNotFound when toUID is not a care giver of the receiver.
+func (rr *RelationshipRepositoryV2) TransferPrimary(ctx context.Context, receiverID string, fromUID string, toUID string) error {
+	log.WithTraceContext(ctx, rr.logger).Info("transferring primary care giver", zap.String(log.ReceiverIDLogKey, receiverID), zap.String(log.UserIDLogKey, fromUID), zap.String(log.TargetUserIDLogKey, toUID))
+	if fromUID == toUID {
+		return fmt.Errorf("cannot transfer primary care giver from user %s to itself", fromUID)
 	}
 
-	result, err := rr.Client.Query(rr.Ctx, queryInput)
+	mask := []string{"role", "primaryCareGiver"}
+	from, err := rr.table().UpdateTransactItem(ctx,
+		RelationshipKey{UserID: fromUID, ReceiverID: receiverID},
+		*relationship.NewRelationshipWithRole(fromUID, receiverID, relationship.RoleEditor, false),
+		mask, ownerCondition)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	to, err := rr.table().UpdateTransactItem(ctx,
+		RelationshipKey{UserID: toUID, ReceiverID: receiverID},
+		*relationship.NewRelationshipWithRole(toUID, receiverID, relationship.RoleOwner, false),
+		mask)
+	if err != nil {
+		return err
 	}
 
-	var relationshipsList []relationship.Relationship
-	err = attributevalue.UnmarshalListOfMaps(result.Items, &relationshipsList)
+	_, err = rr.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{from, to},
+	})
 	if err != nil {
-		rr.logger.Error("error unmarshalling relationships list", zap.Error(err))
-		return nil, err
+		log.WithTraceContext(ctx, rr.logger).Error("error transferring primary care giver", zap.Error(err))
+		return cancellationErrors(err, map[int]error{
+			0: ErrNotPrimaryCareGiver,
+			1: ErrRelationshipNotFound,
+		})
 	}
 
-	return relationshipsList, nil
+	log.WithTraceContext(ctx, rr.logger).Info("successfully transferred primary care giver")
+	return nil
 }
 
-func (rr *RelationshipRepository) GetRelationshipsByReceiver(receiverID string) ([]relationship.Relationship, error) {
-	rr.logger.Info("getting relationships by receiver from db", zap.String(log.ReceiverIDLogKey, receiverID))
+// writeKeepingOwner applies write, which removes the owner role from userID,
+// together with a check that another owner of receiverID still exists. When
+// allowLast is set and the receiver has no other care givers the write is
+// applied on its own.
+func (rr *RelationshipRepositoryV2) writeKeepingOwner(ctx context.Context, userID string, receiverID string, write types.TransactWriteItem, allowLast bool) error {
+	relationships, err := rr.GetRelationshipsByReceiver(ctx, receiverID)
+	if err != nil {
+		return err
+	}
 
-	queryInput := &dynamodb.QueryInput{
-		TableName:              aws.String(rr.TableName),
-		IndexName:              aws.String("receiver_id"),
-		KeyConditionExpression: aws.String("receiver_id = :rid"),
-		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":rid": &types.AttributeValueMemberS{Value: receiverID},
-		},
+	var others, owners []relationship.Relationship
+	for _, r := range relationships {
+		if r.UserID == userID {
+			continue
+		}
+		others = append(others, r)
+		if r.EffectiveRole() == relationship.RoleOwner {
+			owners = append(owners, r)
+		}
 	}
 
-	result, err := rr.Client.Query(rr.Ctx, queryInput)
-	if err != nil {
-		return nil, err
+	items := []types.TransactWriteItem{write}
+	switch {
+	case len(owners) > 0:
+		items = append(items, rr.table().ConditionCheckTransactItem(RelationshipKey{UserID: owners[0].UserID, ReceiverID: receiverID}, ownerCondition))
+	case len(others) > 0 || !allowLast:
+		log.WithTraceContext(ctx, rr.logger).Warn("refusing to remove the last primary care giver", zap.String(log.UserIDLogKey, userID), zap.String(log.ReceiverIDLogKey, receiverID))
+		return ErrLastPrimaryCareGiver
 	}
 
-	var relationshipsList []relationship.Relationship
-	err = attributevalue.UnmarshalListOfMaps(result.Items, &relationshipsList)
+	_, err = rr.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items})
 	if err != nil {
-		rr.logger.Error("error unmarshalling relationships list", zap.Error(err))
-		return nil, err
+		log.WithTraceContext(ctx, rr.logger).Error("error removing primary care giver", zap.Error(err))
+		return cancellationErrors(err, map[int]error{1: ErrLastPrimaryCareGiver})
 	}
-
-	return relationshipsList, nil
+	return nil
 }
 
-func (rr *RelationshipRepository) DeleteRelationship(userID string, receiverID string) error {
-	rr.logger.Info("deleting user receiver relationship from db", zap.String(log.UserIDLogKey, userID), zap.String(log.ReceiverIDLogKey, receiverID))
+func (rr *RelationshipRepositoryV2) GetRelationshipsByEmailNotifications(ctx context.Context) ([]relationship.Relationship, error) {
+	log.WithTraceContext(ctx, rr.logger).Info("getting relationships with email notifications enabled")
 
-	_, err := rr.Client.DeleteItem(rr.Ctx, &dynamodb.DeleteItemInput{
-		TableName: aws.String(rr.TableName),
-		Key: map[string]types.AttributeValue{
-			"user_id":     &types.AttributeValueMemberS{Value: userID},
-			"receiver_id": &types.AttributeValueMemberS{Value: receiverID},
+	relationships, err := rr.table().Query(ctx, QueryParams{
+		IndexName:    RelationshipEmailNotificationsIndex,
+		KeyCondition: "email_notifications_gsi_pk = :email_notifications_gsi_pk",
+		Values: map[string]types.AttributeValue{
+			":email_notifications_gsi_pk": &types.AttributeValueMemberN{Value: "1"},
 		},
 	})
-
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	rr.logger.Info("successfully deleted relationship")
-	return nil
+	log.WithTraceContext(ctx, rr.logger).Info("successfully retrieved relationships with email notifications",
+		zap.Int("count", len(relationships)))
+
+	return relationships, nil
 }
 
-func (r *RelationshipRepository) GetRelationshipsByEmailNotifications() ([]relationship.Relationship, error) {
-	r.logger.Info("getting relationships with email notifications enabled")
+// UpdateNotificationPreferences stores the notification preferences the user
+// has for the receiver and returns the updated relationship.
+func (rr *RelationshipRepositoryV2) UpdateNotificationPreferences(ctx context.Context, userID string, receiverID string, p relationship.NotificationPreferences) (*relationship.Relationship, error) {
+	log.WithTraceContext(ctx, rr.logger).Info("updating notification preferences", zap.String(log.UserIDLogKey, userID), zap.String(log.ReceiverIDLogKey, receiverID))
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidNotificationPreferences, err)
+	}
 
-	input := &dynamodb.QueryInput{
-		TableName:              aws.String(r.TableName),
-		IndexName:              aws.String("email_notifications"),
-		KeyConditionExpression: aws.String("email_notifications_gsi_pk = :email_notifications_gsi_pk"),
-		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":email_notifications_gsi_pk": &types.AttributeValueMemberN{Value: "1"},
-		},
+	r := relationship.Relationship{UserID: userID, ReceiverID: receiverID}
+	r.SetNotifications(p)
+	updated, err := rr.table().Update(ctx, RelationshipKey{UserID: userID, ReceiverID: receiverID}, r, []string{"notifications", "emailNotifications"})
+	if errors.Is(err, ErrConditionFailed) {
+		return nil, ErrRelationshipNotFound
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	var relationships []relationship.Relationship
+	return &updated, nil
+}
 
-	paginator := dynamodb.NewQueryPaginator(r.Client, input)
-	for paginator.HasMorePages() {
-		page, err := paginator.NextPage(r.Ctx)
-		if err != nil {
-			r.logger.Error("failed to query relationships by email notification",
-				zap.Error(err))
-			return nil, err
+// BackfillEmailNotifications repairs the email notifications index key of
+// every relationship whose key does not match its EmailNotifications flag and
+// returns how many were repaired. Relationships whose flag changes
+// concurrently are left to that change.
+func (rr *RelationshipRepositoryV2) BackfillEmailNotifications(ctx context.Context) (int, error) {
+	mismatched, err := rr.table().Scan(ctx, ScanParams{
+		Filter: "(email_notifications = :enabled AND attribute_not_exists(#key)) OR (attribute_exists(#key) AND NOT email_notifications = :enabled)",
+		Names:  map[string]string{"#key": emailNotificationsGSIKey},
+		Values: map[string]types.AttributeValue{
+			":enabled": &types.AttributeValueMemberBOOL{Value: true},
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	repaired := 0
+	for _, r := range mismatched {
+		unchanged := Condition{
+			Expression: "email_notifications = :enabled",
+			Values: map[string]types.AttributeValue{
+				":enabled": &types.AttributeValueMemberBOOL{Value: true},
+			},
+		}
+		if !r.EmailNotifications {
+			unchanged.Expression = "NOT email_notifications = :enabled"
 		}
 
-		var pageRelationships []relationship.Relationship
-		err = attributevalue.UnmarshalListOfMaps(page.Items, &pageRelationships)
+		_, err := rr.table().Update(ctx, RelationshipKey{UserID: r.UserID, ReceiverID: r.ReceiverID}, r, []string{"emailNotifications"}, unchanged)
+		if errors.Is(err, ErrConditionFailed) {
+			continue
+		}
 		if err != nil {
-			r.logger.Error("failed to unmarshal relationships",
-				zap.Error(err))
-			return nil, err
+			return repaired, fmt.Errorf("repairing relationship of user %s and receiver %s: %w", r.UserID, r.ReceiverID, err)
 		}
+		repaired++
+	}
+
+	log.WithTraceContext(ctx, rr.logger).Info("backfilled email notifications index", zap.Int("count", repaired))
+	return repaired, nil
+}
 
-		relationships = append(relationships, pageRelationships...)
+// MigrateRoles stores the role of every relationship of receiverID that was
+// stored before roles existed, derived from its primary care giver flag, and
+// returns how many were migrated. Relationships given a role concurrently are
+// left alone.
+func (rr *RelationshipRepositoryV2) MigrateRoles(ctx context.Context, receiverID string) (int, error) {
+	relationships, err := rr.GetRelationshipsByReceiver(ctx, receiverID)
+	if err != nil {
+		return 0, err
 	}
 
-	r.logger.Info("successfully retrieved relationships with email notifications",
-		zap.Int("count", len(relationships)))
+	migrated := 0
+	for _, r := range relationships {
+		if r.Role != "" {
+			continue
+		}
 
-	return relationships, nil
+		_, err := rr.table().Update(ctx, RelationshipKey{UserID: r.UserID, ReceiverID: r.ReceiverID}, relationship.Migrate(r), []string{"role", "primaryCareGiver"}, Condition{
+			Expression: "attribute_not_exists(#role)",
+			Names:      map[string]string{"#role": "role"},
+		})
+		if errors.Is(err, ErrConditionFailed) {
+			continue
+		}
+		if err != nil {
+			return migrated, fmt.Errorf("migrating relationship for user %s: %w", r.UserID, err)
+		}
+		migrated++
+	}
+
+	log.WithTraceContext(ctx, rr.logger).Info("migrated relationship roles", zap.String(log.ReceiverIDLogKey, receiverID), zap.Int("count", migrated))
+	return migrated, nil
 }
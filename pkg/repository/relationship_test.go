@@ -447,3 +447,47 @@ func TestGetRelationshipsByEmailNotifications(t *testing.T) {
 		})
 	}
 }
+
+func TestRelationshipRepositoryV2(t *testing.T) {
+	var _ RelationshipRepositoryProvider = &RelationshipRepository{}
+	var _ RelationshipRepositoryProviderV2 = &RelationshipRepositoryV2{}
+
+	item := map[string]types.AttributeValue{
+		"user_id":             &types.AttributeValueMemberS{Value: "User#123"},
+		"receiver_id":         &types.AttributeValueMemberS{Value: "Receiver#123"},
+		"primary_care_giver":  &types.AttributeValueMemberBOOL{Value: true},
+		"email_notifications": &types.AttributeValueMemberBOOL{Value: true},
+	}
+	expected := relationship.Relationship{
+		UserID:             "User#123",
+		ReceiverID:         "Receiver#123",
+		PrimaryCareGiver:   true,
+		EmailNotifications: true,
+	}
+	mockDynamo := &dynamo.Mock{
+		GetOutput:   &dynamodb.GetItemOutput{Item: item},
+		QueryOutput: &dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{item}},
+	}
+	repo := NewRelationshipRepositoryV2("relationship-table", mockDynamo, zap.NewNop())
+	ctx := context.Background()
+
+	assert.NoError(t, repo.AddRelationship(ctx, &expected))
+
+	r, err := repo.GetRelationship(ctx, "User#123", "Receiver#123")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, *r)
+
+	rs, err := repo.GetRelationshipsByUser(ctx, "User#123")
+	assert.NoError(t, err)
+	assert.Equal(t, []relationship.Relationship{expected}, rs)
+
+	rs, err = repo.GetRelationshipsByReceiver(ctx, "Receiver#123")
+	assert.NoError(t, err)
+	assert.Equal(t, []relationship.Relationship{expected}, rs)
+
+	rs, err = repo.GetRelationshipsByEmailNotifications(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []relationship.Relationship{expected}, rs)
+
+	assert.NoError(t, repo.DeleteRelationship(ctx, "User#123", "Receiver#123"))
+}
@@ -0,0 +1,176 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/event"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type eventCore interface {
+	AddEvent(ctx context.Context, e *event.Entry) error
+	GetEvents(ctx context.Context, rid string, bound repository.TimestampBound) ([]event.Entry, error)
+	DeleteEvent(ctx context.Context, rid, eid string) error
+}
+
+type eventV1 struct {
+	repo repository.EventRepositoryProvider
+}
+
+func (e eventV1) AddEvent(_ context.Context, entry *event.Entry) error {
+	return e.repo.AddEvent(entry)
+}
+
+func (e eventV1) GetEvents(_ context.Context, rid string, bound repository.TimestampBound) ([]event.Entry, error) {
+	return e.repo.GetEvents(rid, bound)
+}
+
+func (e eventV1) DeleteEvent(_ context.Context, rid, eid string) error {
+	return e.repo.DeleteEvent(rid, eid)
+}
+
+func RunEventRepositoryConformance(t *testing.T, factory func(t *testing.T) repository.EventRepositoryProvider) {
+	runEventCore(t, func(t *testing.T) eventCore {
+		return eventV1{repo: factory(t)}
+	})
+}
+
+func RunEventRepositoryV2Conformance(t *testing.T, factory func(t *testing.T) repository.EventRepositoryProviderV2) {
+	runEventCore(t, func(t *testing.T) eventCore {
+		return factory(t)
+	})
+
+	t.Run("AddEvents stores every entry", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		entries := []*event.Entry{
+			testEntry("Receiver#1", "Event#1", "2025-01-01T00:00:00Z"),
+			testEntry("Receiver#1", "Event#2", "2025-01-02T00:00:00Z"),
+			testEntry("Receiver#2", "Event#3", "2025-01-03T00:00:00Z"),
+		}
+
+		require.NoError(t, repo.AddEvents(ctx, entries))
+
+		got, err := repo.GetEvents(ctx, "Receiver#1", repository.TimestampBound{})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []event.Entry{*entries[0], *entries[1]}, got)
+	})
+
+	t.Run("AddEvents of no entries succeeds", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+
+		assert.NoError(t, repo.AddEvents(ctx, nil))
+	})
+}
+
+func runEventCore(t *testing.T, factory func(t *testing.T) eventCore) {
+	t.Run("GetEvents returns only the receiver's events", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		first := testEntry("Receiver#1", "Event#1", "2025-01-01T00:00:00Z")
+		second := testEntry("Receiver#1", "Event#2", "2025-01-02T00:00:00Z")
+		for _, e := range []*event.Entry{first, second, testEntry("Receiver#2", "Event#3", "2025-01-01T00:00:00Z")} {
+			require.NoError(t, repo.AddEvent(ctx, e))
+		}
+
+		got, err := repo.GetEvents(ctx, "Receiver#1", repository.TimestampBound{})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []event.Entry{*first, *second}, got)
+	})
+
+	t.Run("GetEvents of receiver without events returns nothing", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+
+		got, err := repo.GetEvents(ctx, "Receiver#missing", repository.TimestampBound{})
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("GetEvents without receiver id fails", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+
+		_, err := repo.GetEvents(ctx, "", repository.TimestampBound{})
+		assert.Error(t, err)
+	})
+
+	t.Run("GetEvents with bound returns inclusive range ordered by start time", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		for _, e := range []*event.Entry{
+			testEntry("Receiver#1", "Event#a", "2025-01-04T00:00:00Z"),
+			testEntry("Receiver#1", "Event#b", "2025-01-02T00:00:00Z"),
+			testEntry("Receiver#1", "Event#c", "2025-01-01T00:00:00Z"),
+			testEntry("Receiver#1", "Event#d", "2025-01-03T00:00:00Z"),
+		} {
+			require.NoError(t, repo.AddEvent(ctx, e))
+		}
+
+		got, err := repo.GetEvents(ctx, "Receiver#1", repository.TimestampBound{Lower: "2025-01-02T00:00:00Z", Upper: "2025-01-04T00:00:00Z"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Event#b", "Event#d", "Event#a"}, eventIDs(got))
+	})
+
+	t.Run("GetEvents with one sided bound is unbounded", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		require.NoError(t, repo.AddEvent(ctx, testEntry("Receiver#1", "Event#1", "2025-01-01T00:00:00Z")))
+		require.NoError(t, repo.AddEvent(ctx, testEntry("Receiver#1", "Event#2", "2025-01-05T00:00:00Z")))
+
+		got, err := repo.GetEvents(ctx, "Receiver#1", repository.TimestampBound{Lower: "2025-01-03T00:00:00Z"})
+		require.NoError(t, err)
+		assert.Len(t, got, 2)
+	})
+
+	t.Run("AddEvent replaces an event with the same id", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		require.NoError(t, repo.AddEvent(ctx, testEntry("Receiver#1", "Event#1", "2025-01-01T00:00:00Z")))
+
+		replacement := testEntry("Receiver#1", "Event#1", "2025-01-02T00:00:00Z")
+		replacement.Note = "updated"
+		require.NoError(t, repo.AddEvent(ctx, replacement))
+
+		got, err := repo.GetEvents(ctx, "Receiver#1", repository.TimestampBound{})
+		require.NoError(t, err)
+		assert.Equal(t, []event.Entry{*replacement}, got)
+	})
+
+	t.Run("DeleteEvent removes the event and is idempotent", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		require.NoError(t, repo.AddEvent(ctx, testEntry("Receiver#1", "Event#1", "2025-01-01T00:00:00Z")))
+		require.NoError(t, repo.AddEvent(ctx, testEntry("Receiver#1", "Event#2", "2025-01-02T00:00:00Z")))
+
+		require.NoError(t, repo.DeleteEvent(ctx, "Receiver#1", "Event#1"))
+		require.NoError(t, repo.DeleteEvent(ctx, "Receiver#1", "Event#1"))
+
+		got, err := repo.GetEvents(ctx, "Receiver#1", repository.TimestampBound{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Event#2"}, eventIDs(got))
+	})
+}
+
+func testEntry(rid, eid, startTime string) *event.Entry {
+	return &event.Entry{
+		ReceiverID: rid,
+		EventID:    eid,
+		UserID:     "User#1",
+		StartTime:  startTime,
+		EndTime:    startTime,
+		Type:       "Shower",
+	}
+}
+
+func eventIDs(entries []event.Entry) []string {
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.EventID)
+	}
+	return ids
+}
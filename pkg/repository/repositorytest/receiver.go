@@ -0,0 +1,133 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/receiver"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type receiverCore interface {
+	CreateReceiver(ctx context.Context, r receiver.Receiver) error
+	GetReceiver(ctx context.Context, rid string) (receiver.Receiver, error)
+}
+
+type receiverV1 struct {
+	repo repository.ReceiverRepositoryProvider
+}
+
+func (r receiverV1) CreateReceiver(_ context.Context, rcv receiver.Receiver) error {
+	return r.repo.CreateReceiver(rcv)
+}
+
+func (r receiverV1) GetReceiver(_ context.Context, rid string) (receiver.Receiver, error) {
+	return r.repo.GetReceiver(rid)
+}
+
+func RunReceiverRepositoryConformance(t *testing.T, factory func(t *testing.T) repository.ReceiverRepositoryProvider) {
+	runReceiverCore(t, func(t *testing.T) receiverCore {
+		return receiverV1{repo: factory(t)}
+	})
+}
+
+func RunReceiverRepositoryV2Conformance(t *testing.T, factory func(t *testing.T) repository.ReceiverRepositoryProviderV2) {
+	runReceiverCore(t, func(t *testing.T) receiverCore {
+		return factory(t)
+	})
+
+	t.Run("GetReceivers returns found receivers in request order", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		for _, rid := range []string{"Receiver#1", "Receiver#2", "Receiver#3"} {
+			require.NoError(t, repo.CreateReceiver(ctx, receiver.Receiver{ReceiverID: rid}))
+		}
+
+		got, err := repo.GetReceivers(ctx, []string{"Receiver#3", "Receiver#missing", "Receiver#1", "Receiver#3"})
+		require.NoError(t, err)
+		assert.Equal(t, []receiver.Receiver{{ReceiverID: "Receiver#3"}, {ReceiverID: "Receiver#1"}}, got)
+	})
+
+	t.Run("GetReceivers of no ids returns nothing", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+
+		got, err := repo.GetReceivers(ctx, nil)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("UpdateReceiver applies only masked fields", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		require.NoError(t, repo.CreateReceiver(ctx, receiver.Receiver{ReceiverID: "Receiver#1", FirstName: "Ada", LastName: "Lovelace"}))
+
+		updated, err := repo.UpdateReceiver(ctx, receiver.Receiver{ReceiverID: "Receiver#1", LastName: "Byron"}, []string{"lastName"})
+		require.NoError(t, err)
+
+		expected := receiver.Receiver{ReceiverID: "Receiver#1", FirstName: "Ada", LastName: "Byron"}
+		assert.Equal(t, expected, updated)
+
+		stored, err := repo.GetReceiver(ctx, "Receiver#1")
+		require.NoError(t, err)
+		assert.Equal(t, expected, stored)
+	})
+
+	t.Run("UpdateReceiver rejects invalid masks", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		require.NoError(t, repo.CreateReceiver(ctx, receiver.Receiver{ReceiverID: "Receiver#1"}))
+
+		_, err := repo.UpdateReceiver(ctx, receiver.Receiver{ReceiverID: "Receiver#1"}, nil)
+		assert.ErrorIs(t, err, repository.ErrEmptyFieldMask)
+
+		_, err = repo.UpdateReceiver(ctx, receiver.Receiver{ReceiverID: "Receiver#1"}, []string{"receiverId"})
+		assert.ErrorIs(t, err, repository.ErrInvalidFieldMask)
+	})
+
+	t.Run("UpdateReceiver of missing receiver returns ErrReceiverNotFound", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+
+		_, err := repo.UpdateReceiver(ctx, receiver.Receiver{ReceiverID: "Receiver#missing", FirstName: "Ada"}, []string{"firstName"})
+		assert.ErrorIs(t, err, repository.ErrReceiverNotFound)
+	})
+
+	t.Run("DeleteReceiver removes the receiver and is idempotent", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		require.NoError(t, repo.CreateReceiver(ctx, receiver.Receiver{ReceiverID: "Receiver#1"}))
+
+		require.NoError(t, repo.DeleteReceiver(ctx, "Receiver#1"))
+		require.NoError(t, repo.DeleteReceiver(ctx, "Receiver#1"))
+
+		got, err := repo.GetReceiver(ctx, "Receiver#1")
+		require.NoError(t, err)
+		assert.Equal(t, receiver.Receiver{}, got)
+	})
+}
+
+func runReceiverCore(t *testing.T, factory func(t *testing.T) receiverCore) {
+	t.Run("CreateReceiver then GetReceiver returns the receiver", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		r := receiver.Receiver{ReceiverID: "Receiver#1", FirstName: "Ada", LastName: "Lovelace"}
+
+		require.NoError(t, repo.CreateReceiver(ctx, r))
+
+		got, err := repo.GetReceiver(ctx, "Receiver#1")
+		require.NoError(t, err)
+		assert.Equal(t, r, got)
+	})
+
+	t.Run("GetReceiver of missing receiver returns zero receiver", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+
+		got, err := repo.GetReceiver(ctx, "Receiver#missing")
+		require.NoError(t, err)
+		assert.Equal(t, receiver.Receiver{}, got)
+	})
+}
@@ -0,0 +1,259 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type relationshipCore interface {
+	AddRelationship(ctx context.Context, r *relationship.Relationship) error
+	GetRelationship(ctx context.Context, userID string, receiverID string) (*relationship.Relationship, error)
+	GetRelationshipsByUser(ctx context.Context, userID string) ([]relationship.Relationship, error)
+	GetRelationshipsByReceiver(ctx context.Context, receiverID string) ([]relationship.Relationship, error)
+	DeleteRelationship(ctx context.Context, userID string, receiverID string) error
+	GetRelationshipsByEmailNotifications(ctx context.Context) ([]relationship.Relationship, error)
+}
+
+type relationshipV1 struct {
+	repo repository.RelationshipRepositoryProvider
+}
+
+func (r relationshipV1) AddRelationship(_ context.Context, rel *relationship.Relationship) error {
+	return r.repo.AddRelationship(rel)
+}
+
+func (r relationshipV1) GetRelationship(_ context.Context, userID string, receiverID string) (*relationship.Relationship, error) {
+	return r.repo.GetRelationship(userID, receiverID)
+}
+
+func (r relationshipV1) GetRelationshipsByUser(_ context.Context, userID string) ([]relationship.Relationship, error) {
+	return r.repo.GetRelationshipsByUser(userID)
+}
+
+func (r relationshipV1) GetRelationshipsByReceiver(_ context.Context, receiverID string) ([]relationship.Relationship, error) {
+	return r.repo.GetRelationshipsByReceiver(receiverID)
+}
+
+func (r relationshipV1) DeleteRelationship(_ context.Context, userID string, receiverID string) error {
+	return r.repo.DeleteRelationship(userID, receiverID)
+}
+
+func (r relationshipV1) GetRelationshipsByEmailNotifications(_ context.Context) ([]relationship.Relationship, error) {
+	return r.repo.GetRelationshipsByEmailNotifications()
+}
+
+func RunRelationshipRepositoryConformance(t *testing.T, factory func(t *testing.T) repository.RelationshipRepositoryProvider) {
+	runRelationshipCore(t, func(t *testing.T) relationshipCore {
+		return relationshipV1{repo: factory(t)}
+	})
+}
+
+func RunRelationshipRepositoryV2Conformance(t *testing.T, factory func(t *testing.T) repository.RelationshipRepositoryProviderV2) {
+	runRelationshipCore(t, func(t *testing.T) relationshipCore {
+		return factory(t)
+	})
+
+	t.Run("TransferPrimary swaps the owner and editor roles", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		require.NoError(t, repo.AddRelationship(ctx, relationship.NewRelationship("User#1", "Receiver#1", true, false)))
+		require.NoError(t, repo.AddRelationship(ctx, relationship.NewRelationship("User#2", "Receiver#1", false, false)))
+
+		assert.ErrorIs(t, repo.TransferPrimary(ctx, "Receiver#1", "User#2", "User#1"), repository.ErrNotPrimaryCareGiver)
+		assert.ErrorIs(t, repo.TransferPrimary(ctx, "Receiver#1", "User#1", "User#missing"), repository.ErrRelationshipNotFound)
+		assert.Error(t, repo.TransferPrimary(ctx, "Receiver#1", "User#1", "User#1"))
+
+		require.NoError(t, repo.TransferPrimary(ctx, "Receiver#1", "User#1", "User#2"))
+
+		got, err := repo.GetRelationshipsByReceiver(ctx, "Receiver#1")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []relationship.Relationship{
+			*relationship.NewRelationshipWithRole("User#1", "Receiver#1", relationship.RoleEditor, false),
+			*relationship.NewRelationshipWithRole("User#2", "Receiver#1", relationship.RoleOwner, false),
+		}, got)
+	})
+
+	t.Run("UpdateNotificationPreferences stores preferences and keeps email notifications in step", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		require.NoError(t, repo.AddRelationship(ctx, relationship.NewRelationship("User#1", "Receiver#1", true, false)))
+
+		digest := relationship.NotificationPreferences{Channels: []relationship.Channel{relationship.ChannelEmail}, Delivery: relationship.DeliveryDigest}
+		updated, err := repo.UpdateNotificationPreferences(ctx, "User#1", "Receiver#1", digest)
+		require.NoError(t, err)
+		assert.True(t, updated.EmailNotifications)
+		assert.Equal(t, &digest, updated.Notifications)
+
+		got, err := repo.GetRelationship(ctx, "User#1", "Receiver#1")
+		require.NoError(t, err)
+		assert.Equal(t, *updated, *got)
+
+		byNotifications, err := repo.GetRelationshipsByEmailNotifications(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []relationship.Relationship{*updated}, byNotifications)
+
+		_, err = repo.UpdateNotificationPreferences(ctx, "User#1", "Receiver#1", relationship.NotificationPreferences{Delivery: "hourly"})
+		assert.ErrorIs(t, err, repository.ErrInvalidNotificationPreferences)
+		_, err = repo.UpdateNotificationPreferences(ctx, "User#missing", "Receiver#1", digest)
+		assert.ErrorIs(t, err, repository.ErrRelationshipNotFound)
+	})
+}
+
+func runRelationshipCore(t *testing.T, factory func(t *testing.T) relationshipCore) {
+	t.Run("AddRelationship then GetRelationship returns the relationship", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		rel := relationship.Relationship{UserID: "User#1", ReceiverID: "Receiver#1", PrimaryCareGiver: true}
+
+		require.NoError(t, repo.AddRelationship(ctx, &rel))
+
+		got, err := repo.GetRelationship(ctx, "User#1", "Receiver#1")
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, rel, *got)
+	})
+
+	t.Run("AddRelationship stores the role and rejects unknown roles", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		rel := relationship.NewRelationshipWithRole("User#1", "Receiver#1", relationship.RoleViewer, false)
+
+		require.NoError(t, repo.AddRelationship(ctx, rel))
+		assert.ErrorIs(t, repo.AddRelationship(ctx, &relationship.Relationship{UserID: "User#2", ReceiverID: "Receiver#1", Role: "admin"}), repository.ErrInvalidRole)
+
+		got, err := repo.GetRelationshipsByReceiver(ctx, "Receiver#1")
+		require.NoError(t, err)
+		assert.Equal(t, []relationship.Relationship{*rel}, got)
+	})
+
+	t.Run("AddRelationship keeps PrimaryCareGiver in step with the role", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+
+		require.NoError(t, repo.AddRelationship(ctx, &relationship.Relationship{UserID: "User#1", ReceiverID: "Receiver#1", Role: relationship.RoleOwner}))
+		require.NoError(t, repo.AddRelationship(ctx, &relationship.Relationship{UserID: "User#2", ReceiverID: "Receiver#1", Role: relationship.RoleEditor, PrimaryCareGiver: true}))
+
+		got, err := repo.GetRelationshipsByReceiver(ctx, "Receiver#1")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []relationship.Relationship{
+			{UserID: "User#1", ReceiverID: "Receiver#1", Role: relationship.RoleOwner, PrimaryCareGiver: true},
+			{UserID: "User#2", ReceiverID: "Receiver#1", Role: relationship.RoleEditor},
+		}, got)
+	})
+
+	t.Run("GetRelationship of missing relationship returns zero relationship", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+
+		got, err := repo.GetRelationship(ctx, "User#1", "Receiver#missing")
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, relationship.Relationship{}, *got)
+	})
+
+	t.Run("GetRelationshipsByUser and ByReceiver select matching relationships", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		rels := []relationship.Relationship{
+			{UserID: "User#1", ReceiverID: "Receiver#1", PrimaryCareGiver: true},
+			{UserID: "User#1", ReceiverID: "Receiver#2", PrimaryCareGiver: true},
+			{UserID: "User#2", ReceiverID: "Receiver#1"},
+		}
+		for i := range rels {
+			require.NoError(t, repo.AddRelationship(ctx, &rels[i]))
+		}
+
+		byUser, err := repo.GetRelationshipsByUser(ctx, "User#1")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, rels[:2], byUser)
+
+		byReceiver, err := repo.GetRelationshipsByReceiver(ctx, "Receiver#1")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []relationship.Relationship{rels[0], rels[2]}, byReceiver)
+
+		none, err := repo.GetRelationshipsByUser(ctx, "User#missing")
+		require.NoError(t, err)
+		assert.Empty(t, none)
+	})
+
+	t.Run("DeleteRelationship removes the relationship and is idempotent", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		require.NoError(t, repo.AddRelationship(ctx, &relationship.Relationship{UserID: "User#1", ReceiverID: "Receiver#1"}))
+		require.NoError(t, repo.AddRelationship(ctx, &relationship.Relationship{UserID: "User#2", ReceiverID: "Receiver#1"}))
+
+		require.NoError(t, repo.DeleteRelationship(ctx, "User#1", "Receiver#1"))
+		require.NoError(t, repo.DeleteRelationship(ctx, "User#1", "Receiver#1"))
+
+		byReceiver, err := repo.GetRelationshipsByReceiver(ctx, "Receiver#1")
+		require.NoError(t, err)
+		assert.Equal(t, []relationship.Relationship{{UserID: "User#2", ReceiverID: "Receiver#1"}}, byReceiver)
+	})
+
+	t.Run("DeleteRelationship and AddRelationship keep a primary care giver", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		owner := relationship.NewRelationship("User#1", "Receiver#1", true, false)
+		require.NoError(t, repo.AddRelationship(ctx, owner))
+		require.NoError(t, repo.AddRelationship(ctx, relationship.NewRelationship("User#2", "Receiver#1", false, false)))
+
+		assert.ErrorIs(t, repo.DeleteRelationship(ctx, "User#1", "Receiver#1"), repository.ErrLastPrimaryCareGiver)
+		assert.ErrorIs(t, repo.AddRelationship(ctx, relationship.NewRelationshipWithRole("User#1", "Receiver#1", relationship.RoleEditor, false)), repository.ErrLastPrimaryCareGiver)
+
+		got, err := repo.GetRelationship(ctx, "User#1", "Receiver#1")
+		require.NoError(t, err)
+		assert.Equal(t, *owner, *got)
+
+		require.NoError(t, repo.AddRelationship(ctx, relationship.NewRelationship("User#2", "Receiver#1", true, false)))
+		require.NoError(t, repo.AddRelationship(ctx, relationship.NewRelationshipWithRole("User#1", "Receiver#1", relationship.RoleEditor, false)))
+		require.NoError(t, repo.DeleteRelationship(ctx, "User#1", "Receiver#1"))
+
+		// A receiver's only care giver can be removed, which is how receivers
+		// are deleted.
+		require.NoError(t, repo.DeleteRelationship(ctx, "User#2", "Receiver#1"))
+
+		byReceiver, err := repo.GetRelationshipsByReceiver(ctx, "Receiver#1")
+		require.NoError(t, err)
+		assert.Empty(t, byReceiver)
+	})
+
+	t.Run("GetRelationshipsByEmailNotifications excludes relationships without notifications", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		require.NoError(t, repo.AddRelationship(ctx, &relationship.Relationship{UserID: "User#1", ReceiverID: "Receiver#1"}))
+
+		got, err := repo.GetRelationshipsByEmailNotifications(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("GetRelationshipsByEmailNotifications returns relationships with notifications", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		rels := []relationship.Relationship{
+			{UserID: "User#1", ReceiverID: "Receiver#1", PrimaryCareGiver: true, EmailNotifications: true},
+			{UserID: "User#2", ReceiverID: "Receiver#1", EmailNotifications: true},
+			{UserID: "User#3", ReceiverID: "Receiver#1"},
+		}
+		for i := range rels {
+			require.NoError(t, repo.AddRelationship(ctx, &rels[i]))
+		}
+
+		got, err := repo.GetRelationshipsByEmailNotifications(ctx)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, rels[:2], got)
+
+		// Replacing a relationship with notifications turned off drops it.
+		rels[1].EmailNotifications = false
+		require.NoError(t, repo.AddRelationship(ctx, &rels[1]))
+
+		got, err = repo.GetRelationshipsByEmailNotifications(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, rels[:1], got)
+	})
+}
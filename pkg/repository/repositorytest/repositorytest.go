@@ -0,0 +1,16 @@
+// Package repositorytest checks that implementations of the repository
+// Provider interfaces behave like the DynamoDB backed repositories. Each Run
+// function takes a factory that must return a new, empty repository for
+// every call.
+package repositorytest
+
+import (
+	"context"
+	"testing"
+)
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	return ctx
+}
@@ -0,0 +1,64 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/dynamo"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	userTable         = "user-table"
+	receiverTable     = "receiver-table"
+	eventTable        = "event-table"
+	relationshipTable = "relationship-table"
+)
+
+func newEmulator() *dynamo.Emulator {
+	e := dynamo.NewEmulator(repository.TableNames{
+		Users:         userTable,
+		Receivers:     receiverTable,
+		Events:        eventTable,
+		Relationships: relationshipTable,
+	}.Schemas()...)
+	e.PageSize = 1
+	return e
+}
+
+func TestDynamoUserRepository(t *testing.T) {
+	RunUserRepositoryConformance(t, func(t *testing.T) repository.UserRepositoryProvider {
+		return repository.NewUserRespository(context.Background(), userTable, newEmulator(), zap.NewNop())
+	})
+	RunUserRepositoryV2Conformance(t, func(t *testing.T) repository.UserRepositoryProviderV2 {
+		return repository.NewUserRepositoryV2(userTable, newEmulator(), zap.NewNop())
+	})
+}
+
+func TestDynamoReceiverRepository(t *testing.T) {
+	RunReceiverRepositoryConformance(t, func(t *testing.T) repository.ReceiverRepositoryProvider {
+		return repository.NewReceiverRespository(context.Background(), receiverTable, newEmulator(), zap.NewNop())
+	})
+	RunReceiverRepositoryV2Conformance(t, func(t *testing.T) repository.ReceiverRepositoryProviderV2 {
+		return repository.NewReceiverRepositoryV2(receiverTable, newEmulator(), zap.NewNop())
+	})
+}
+
+func TestDynamoEventRepository(t *testing.T) {
+	RunEventRepositoryConformance(t, func(t *testing.T) repository.EventRepositoryProvider {
+		return repository.NewEventRespository(context.Background(), eventTable, newEmulator(), zap.NewNop())
+	})
+	RunEventRepositoryV2Conformance(t, func(t *testing.T) repository.EventRepositoryProviderV2 {
+		return repository.NewEventRepositoryV2(eventTable, newEmulator(), zap.NewNop())
+	})
+}
+
+func TestDynamoRelationshipRepository(t *testing.T) {
+	RunRelationshipRepositoryConformance(t, func(t *testing.T) repository.RelationshipRepositoryProvider {
+		return repository.NewRelationshipRepository(context.Background(), relationshipTable, newEmulator(), zap.NewNop())
+	})
+	RunRelationshipRepositoryV2Conformance(t, func(t *testing.T) repository.RelationshipRepositoryProviderV2 {
+		return repository.NewRelationshipRepositoryV2(relationshipTable, newEmulator(), zap.NewNop())
+	})
+}
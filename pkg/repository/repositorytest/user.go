@@ -0,0 +1,197 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/repository"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type userCore interface {
+	CreateUser(ctx context.Context, u user.User) error
+	GetUser(ctx context.Context, uid string) (user.User, error)
+	GetUserByEmail(ctx context.Context, email string) (user.User, error)
+}
+
+type userV1 struct {
+	repo repository.UserRepositoryProvider
+}
+
+func (u userV1) CreateUser(_ context.Context, usr user.User) error {
+	return u.repo.CreateUser(usr)
+}
+
+func (u userV1) GetUser(_ context.Context, uid string) (user.User, error) {
+	return u.repo.GetUser(uid)
+}
+
+func (u userV1) GetUserByEmail(_ context.Context, email string) (user.User, error) {
+	return u.repo.GetUserByEmail(email)
+}
+
+func RunUserRepositoryConformance(t *testing.T, factory func(t *testing.T) repository.UserRepositoryProvider) {
+	runUserCore(t, func(t *testing.T) userCore {
+		return userV1{repo: factory(t)}
+	})
+}
+
+func RunUserRepositoryV2Conformance(t *testing.T, factory func(t *testing.T) repository.UserRepositoryProviderV2) {
+	runUserCore(t, func(t *testing.T) userCore {
+		return factory(t)
+	})
+
+	t.Run("UpdateUser applies only masked fields", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		require.NoError(t, repo.CreateUser(ctx, user.User{UserID: "User#1", Email: "one@example.com", FirstName: "Ada", LastName: "Lovelace"}))
+
+		updated, err := repo.UpdateUser(ctx, user.User{UserID: "User#1", FirstName: "Grace", LastName: "ignored"}, []string{"firstName"})
+		require.NoError(t, err)
+
+		expected := user.User{UserID: "User#1", Email: "one@example.com", FirstName: "Grace", LastName: "Lovelace"}
+		assert.Equal(t, expected, updated)
+
+		stored, err := repo.GetUser(ctx, "User#1")
+		require.NoError(t, err)
+		assert.Equal(t, expected, stored)
+	})
+
+	t.Run("UpdateUser rejects invalid masks", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		require.NoError(t, repo.CreateUser(ctx, user.User{UserID: "User#1", Email: "one@example.com"}))
+
+		_, err := repo.UpdateUser(ctx, user.User{UserID: "User#1"}, nil)
+		assert.ErrorIs(t, err, repository.ErrEmptyFieldMask)
+
+		_, err = repo.UpdateUser(ctx, user.User{UserID: "User#1"}, []string{"unknown"})
+		assert.ErrorIs(t, err, repository.ErrInvalidFieldMask)
+
+		_, err = repo.UpdateUser(ctx, user.User{UserID: "User#1"}, []string{"userId"})
+		assert.ErrorIs(t, err, repository.ErrInvalidFieldMask)
+
+		_, err = repo.UpdateUser(ctx, user.User{UserID: "User#1", Email: "two@example.com"}, []string{"email"})
+		assert.ErrorIs(t, err, repository.ErrEmailImmutable)
+	})
+
+	t.Run("UpdateUser of missing user returns ErrUserNotFound", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+
+		_, err := repo.UpdateUser(ctx, user.User{UserID: "User#missing", FirstName: "Ada"}, []string{"firstName"})
+		assert.ErrorIs(t, err, repository.ErrUserNotFound)
+
+		u, err := repo.GetUser(ctx, "User#missing")
+		require.NoError(t, err)
+		assert.Equal(t, user.User{}, u)
+	})
+
+	t.Run("ChangeEmail moves the user to the new address", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		require.NoError(t, repo.CreateUser(ctx, user.User{UserID: "User#1", Email: "old@example.com"}))
+
+		require.NoError(t, repo.ChangeEmail(ctx, "User#1", "new@example.com"))
+
+		u, err := repo.GetUserByEmail(ctx, "new@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "User#1", u.UserID)
+
+		_, err = repo.GetUserByEmail(ctx, "old@example.com")
+		assert.ErrorIs(t, err, repository.ErrUserNotFound)
+
+		assert.NoError(t, repo.ChangeEmail(ctx, "User#1", "new@example.com"))
+	})
+
+	t.Run("ChangeEmail rejects an address in use", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		require.NoError(t, repo.CreateUser(ctx, user.User{UserID: "User#1", Email: "one@example.com"}))
+		require.NoError(t, repo.CreateUser(ctx, user.User{UserID: "User#2", Email: "two@example.com"}))
+
+		assert.ErrorIs(t, repo.ChangeEmail(ctx, "User#1", "two@example.com"), repository.ErrEmailInUse)
+
+		u, err := repo.GetUser(ctx, "User#1")
+		require.NoError(t, err)
+		assert.Equal(t, "one@example.com", u.Email)
+	})
+
+	t.Run("ChangeEmail of missing user returns ErrUserNotFound", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+
+		assert.ErrorIs(t, repo.ChangeEmail(ctx, "User#missing", "new@example.com"), repository.ErrUserNotFound)
+	})
+}
+
+func runUserCore(t *testing.T, factory func(t *testing.T) userCore) {
+	t.Run("CreateUser then GetUser returns the user", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		u := user.User{UserID: "User#1", Email: "one@example.com", FirstName: "Ada", LastName: "Lovelace"}
+
+		require.NoError(t, repo.CreateUser(ctx, u))
+
+		got, err := repo.GetUser(ctx, "User#1")
+		require.NoError(t, err)
+		assert.Equal(t, u, got)
+	})
+
+	t.Run("CreateUser replaces an existing user", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		require.NoError(t, repo.CreateUser(ctx, user.User{UserID: "User#1", Email: "one@example.com", FirstName: "Ada"}))
+
+		replacement := user.User{UserID: "User#1", Email: "one@example.com", FirstName: "Grace"}
+		require.NoError(t, repo.CreateUser(ctx, replacement))
+
+		got, err := repo.GetUser(ctx, "User#1")
+		require.NoError(t, err)
+		assert.Equal(t, replacement, got)
+	})
+
+	t.Run("GetUser of missing user returns zero user", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+
+		got, err := repo.GetUser(ctx, "User#missing")
+		require.NoError(t, err)
+		assert.Equal(t, user.User{}, got)
+	})
+
+	t.Run("GetUserByEmail finds the matching user", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		require.NoError(t, repo.CreateUser(ctx, user.User{UserID: "User#1", Email: "one@example.com"}))
+		require.NoError(t, repo.CreateUser(ctx, user.User{UserID: "User#2", Email: "two@example.com"}))
+
+		got, err := repo.GetUserByEmail(ctx, "two@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, user.User{UserID: "User#2", Email: "two@example.com"}, got)
+	})
+
+	t.Run("Email addresses are case insensitive", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		require.NoError(t, repo.CreateUser(ctx, user.User{UserID: "User#1", Email: "One@Example.com"}))
+
+		got, err := repo.GetUserByEmail(ctx, "ONE@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, user.User{UserID: "User#1", Email: "one@example.com"}, got)
+
+		assert.ErrorIs(t, repo.CreateUser(ctx, user.User{UserID: "User#2", Email: "one@EXAMPLE.com"}), repository.ErrEmailInUse)
+	})
+
+	t.Run("GetUserByEmail of unknown address returns ErrUserNotFound", func(t *testing.T) {
+		ctx := testContext(t)
+		repo := factory(t)
+		require.NoError(t, repo.CreateUser(ctx, user.User{UserID: "User#1", Email: "one@example.com"}))
+
+		got, err := repo.GetUserByEmail(ctx, "missing@example.com")
+		assert.ErrorIs(t, err, repository.ErrUserNotFound)
+		assert.Equal(t, user.User{}, got)
+	})
+}
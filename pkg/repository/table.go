@@ -0,0 +1,577 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/dynamo"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/encryption"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/log"
+	"go.uber.org/zap"
+)
+
+// KeySchema describes the primary key of a table and how a key value of type
+// K maps onto its attributes. All key attributes are strings.
+type KeySchema[K any] struct {
+	PartitionKey string
+	SortKey      string
+	values       func(K) (string, string)
+}
+
+func PartitionKeySchema(partitionKey string) KeySchema[string] {
+	return KeySchema[string]{
+		PartitionKey: partitionKey,
+		values: func(k string) (string, string) {
+			return k, ""
+		},
+	}
+}
+
+func CompositeKeySchema[K any](partitionKey, sortKey string, values func(K) (string, string)) KeySchema[K] {
+	return KeySchema[K]{
+		PartitionKey: partitionKey,
+		SortKey:      sortKey,
+		values:       values,
+	}
+}
+
+func (s KeySchema[K]) Key(k K) map[string]types.AttributeValue {
+	pk, sk := s.values(k)
+	key := map[string]types.AttributeValue{
+		s.PartitionKey: &types.AttributeValueMemberS{Value: pk},
+	}
+	if s.SortKey != "" {
+		key[s.SortKey] = &types.AttributeValueMemberS{Value: sk}
+	}
+	return key
+}
+
+// ItemID names an item in logs and batch failures: the sort key when the
+// table has one, otherwise the partition key.
+func (s KeySchema[K]) ItemID(item map[string]types.AttributeValue) string {
+	if s.SortKey != "" {
+		return stringKey(item, s.SortKey)
+	}
+	return stringKey(item, s.PartitionKey)
+}
+
+type Condition struct {
+	Expression string
+	Names      map[string]string
+	Values     map[string]types.AttributeValue
+}
+
+type QueryParams struct {
+	IndexName    string
+	KeyCondition string
+	Filter       string
+	Names        map[string]string
+	Values       map[string]types.AttributeValue
+}
+
+type ScanParams struct {
+	IndexName string
+	Filter    string
+	Names     map[string]string
+	Values    map[string]types.AttributeValue
+}
+
+// DerivedAttribute is an attribute stored with every item that is computed
+// from the fields of T named in Fields, such as the key of a sparse index.
+// Value returns nil when the item should not have the attribute.
+type DerivedAttribute[T any] struct {
+	Name   string
+	Fields []string
+	Value  func(item T) types.AttributeValue
+}
+
+// Table reads and writes items of type T. When Encryptor is set, the
+// attributes of the fields of T tagged as encrypted are encrypted on writes
+// and decrypted on reads. Derived attributes are written with every item and
+// by updates whose mask names one of their fields.
+type Table[T any, K any] struct {
+	Name      string
+	Client    DynamodbClientProvider
+	Schema    KeySchema[K]
+	Encryptor *encryption.Encryptor
+	Derived   []DerivedAttribute[T]
+	// Retry bounds the retries of items left unprocessed by PutBatch and
+	// GetBatch. Zero fields take the values of dynamo.DefaultRetryPolicy.
+	Retry  dynamo.RetryPolicy
+	logger *zap.Logger
+}
+
+func NewTable[T any, K any](name string, schema KeySchema[K], client DynamodbClientProvider, logger *zap.Logger) *Table[T, K] {
+	return &Table[T, K]{
+		Name:   name,
+		Client: client,
+		Schema: schema,
+		logger: logger.With(zap.String(log.TableNameLogKey, name)),
+	}
+}
+
+func (t *Table[T, K]) Put(ctx context.Context, item T, conds ...Condition) error {
+	log.WithTraceContext(ctx, t.logger).Info("marshalling item")
+	av, err := t.marshal(ctx, item)
+	if err != nil {
+		return err
+	}
+
+	cond := joinConditions(conds)
+	log.WithTraceContext(ctx, t.logger).Info("inserting item into db", log.Redacted("item", item))
+	_, err = t.Client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                 aws.String(t.Name),
+		Item:                      av,
+		ConditionExpression:       cond.expression(),
+		ExpressionAttributeNames:  cond.names(),
+		ExpressionAttributeValues: cond.values(),
+	})
+	if err != nil {
+		return t.wrapError(ctx, "inserting item", err)
+	}
+	log.WithTraceContext(ctx, t.logger).Info("successfully inserted item")
+
+	return nil
+}
+
+func (t *Table[T, K]) Get(ctx context.Context, key K) (T, error) {
+	var item T
+
+	result, err := t.Client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(t.Name),
+		Key:       t.Schema.Key(key),
+	})
+	if err != nil {
+		return item, t.wrapError(ctx, "getting item", err)
+	}
+
+	if result == nil || len(result.Item) == 0 {
+		return item, ErrItemNotFound
+	}
+
+	err = t.unmarshal(ctx, result.Item, &item)
+	if err != nil {
+		log.WithTraceContext(ctx, t.logger).Error("error unmarshalling item", zap.Error(err))
+		return item, err
+	}
+
+	return item, nil
+}
+
+func (t *Table[T, K]) Delete(ctx context.Context, key K, conds ...Condition) error {
+	cond := joinConditions(conds)
+	_, err := t.Client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:                 aws.String(t.Name),
+		Key:                       t.Schema.Key(key),
+		ConditionExpression:       cond.expression(),
+		ExpressionAttributeNames:  cond.names(),
+		ExpressionAttributeValues: cond.values(),
+	})
+	if err != nil {
+		return t.wrapError(ctx, "deleting item", err)
+	}
+	log.WithTraceContext(ctx, t.logger).Info("successfully deleted item")
+
+	return nil
+}
+
+func (t *Table[T, K]) Query(ctx context.Context, params QueryParams) ([]T, error) {
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(t.Name),
+		KeyConditionExpression:    aws.String(params.KeyCondition),
+		ExpressionAttributeNames:  params.Names,
+		ExpressionAttributeValues: params.Values,
+	}
+	if params.IndexName != "" {
+		input.IndexName = aws.String(params.IndexName)
+	}
+	if params.Filter != "" {
+		input.FilterExpression = aws.String(params.Filter)
+	}
+
+	var items []T
+
+	paginator := dynamodb.NewQueryPaginator(t.Client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			log.WithTraceContext(ctx, t.logger).Error("error querying items", zap.Error(err))
+			return nil, err
+		}
+
+		for _, av := range page.Items {
+			var item T
+			err = t.unmarshal(ctx, av, &item)
+			if err != nil {
+				log.WithTraceContext(ctx, t.logger).Error("error unmarshalling items", zap.Error(err))
+				return nil, err
+			}
+			items = append(items, item)
+		}
+	}
+
+	return items, nil
+}
+
+// Scan reads every item of the table, or of the index when one is named,
+// that matches the filter.
+func (t *Table[T, K]) Scan(ctx context.Context, params ScanParams) ([]T, error) {
+	input := &dynamodb.ScanInput{
+		TableName:                 aws.String(t.Name),
+		ExpressionAttributeNames:  params.Names,
+		ExpressionAttributeValues: params.Values,
+	}
+	if params.IndexName != "" {
+		input.IndexName = aws.String(params.IndexName)
+	}
+	if params.Filter != "" {
+		input.FilterExpression = aws.String(params.Filter)
+	}
+
+	var items []T
+
+	paginator := dynamodb.NewScanPaginator(t.Client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			log.WithTraceContext(ctx, t.logger).Error("error scanning items", zap.Error(err))
+			return nil, err
+		}
+
+		for _, av := range page.Items {
+			var item T
+			err = t.unmarshal(ctx, av, &item)
+			if err != nil {
+				log.WithTraceContext(ctx, t.logger).Error("error unmarshalling items", zap.Error(err))
+				return nil, err
+			}
+			items = append(items, item)
+		}
+	}
+
+	return items, nil
+}
+
+// Update applies the fields named in mask from item to the stored item with
+// the given key. The item must already exist; a failed existence check or
+// any additional condition is reported as ErrConditionFailed.
+func (t *Table[T, K]) Update(ctx context.Context, key K, item T, mask []string, conds ...Condition) (T, error) {
+	var updated T
+
+	update, cond, err := t.buildUpdate(ctx, key, item, mask, conds)
+	if err != nil {
+		return updated, err
+	}
+
+	result, err := t.Client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(t.Name),
+		Key:                       t.Schema.Key(key),
+		UpdateExpression:          aws.String(update.expression()),
+		ConditionExpression:       aws.String(cond.Expression),
+		ExpressionAttributeNames:  update.Names,
+		ExpressionAttributeValues: nilIfEmpty(update.Values),
+		ReturnValues:              types.ReturnValueAllNew,
+	})
+	if err != nil {
+		return updated, t.wrapError(ctx, "updating item", err)
+	}
+
+	err = t.unmarshal(ctx, result.Attributes, &updated)
+	if err != nil {
+		return updated, err
+	}
+	log.WithTraceContext(ctx, t.logger).Info("successfully updated item")
+
+	return updated, nil
+}
+
+// buildUpdate builds the update expression and condition shared by Update
+// and UpdateTransactItem. The condition's names and values are merged into
+// the update's.
+func (t *Table[T, K]) buildUpdate(ctx context.Context, key K, item T, mask []string, conds []Condition) (*updateExpression, Condition, error) {
+	protected := []string{}
+	for jsonName, avName := range attributeNamesByJSONName(item) {
+		if avName == t.Schema.PartitionKey || avName == t.Schema.SortKey {
+			protected = append(protected, jsonName)
+		}
+	}
+
+	update, err := buildUpdateExpression(item, mask, protected...)
+	if err != nil {
+		return nil, Condition{}, err
+	}
+	for _, d := range t.Derived {
+		if slices.ContainsFunc(d.Fields, func(f string) bool { return slices.Contains(mask, f) }) {
+			update.assign(d.Name, d.Value(item))
+		}
+	}
+	err = t.encryptUpdate(ctx, key, update)
+	if err != nil {
+		return nil, Condition{}, err
+	}
+
+	exists := Condition{
+		Expression: "attribute_exists(#pk)",
+		Names:      map[string]string{"#pk": t.Schema.PartitionKey},
+	}
+	cond := joinConditions(append([]Condition{exists}, conds...))
+	maps.Copy(update.Names, cond.Names)
+	maps.Copy(update.Values, cond.Values)
+
+	return update, cond, nil
+}
+
+func (t *Table[T, K]) PutBatch(ctx context.Context, items []T) []BatchItemError {
+	var failures []BatchItemError
+	requests := make([]types.WriteRequest, 0, len(items))
+	for _, item := range items {
+		av, err := t.marshal(ctx, item)
+		if err != nil {
+			failures = append(failures, BatchItemError{ID: t.itemID(item), Err: err})
+			continue
+		}
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: av}})
+	}
+
+	return append(failures, batchWrite(ctx, t.Client, t.logger, t.Retry, t.Name, requests, func(r types.WriteRequest) string {
+		return t.Schema.ItemID(r.PutRequest.Item)
+	})...)
+}
+
+func (t *Table[T, K]) GetBatch(ctx context.Context, keys []K) ([]T, []BatchItemError) {
+	avKeys := make([]map[string]types.AttributeValue, 0, len(keys))
+	for _, k := range keys {
+		avKeys = append(avKeys, t.Schema.Key(k))
+	}
+
+	results, failures := batchGet(ctx, t.Client, t.logger, t.Retry, t.Name, avKeys, t.Schema.ItemID)
+
+	items := make([]T, 0, len(results))
+	for _, result := range results {
+		var item T
+		err := t.unmarshal(ctx, result, &item)
+		if err != nil {
+			failures = append(failures, BatchItemError{ID: t.Schema.ItemID(result), Err: err})
+			continue
+		}
+		items = append(items, item)
+	}
+
+	return items, failures
+}
+
+func (t *Table[T, K]) PutTransactItem(ctx context.Context, item T, conds ...Condition) (types.TransactWriteItem, error) {
+	av, err := t.marshal(ctx, item)
+	if err != nil {
+		return types.TransactWriteItem{}, err
+	}
+
+	cond := joinConditions(conds)
+	return types.TransactWriteItem{
+		Put: &types.Put{
+			TableName:                 aws.String(t.Name),
+			Item:                      av,
+			ConditionExpression:       cond.expression(),
+			ExpressionAttributeNames:  cond.names(),
+			ExpressionAttributeValues: cond.values(),
+		},
+	}, nil
+}
+
+func (t *Table[T, K]) DeleteTransactItem(key K, conds ...Condition) types.TransactWriteItem {
+	cond := joinConditions(conds)
+	return types.TransactWriteItem{
+		Delete: &types.Delete{
+			TableName:                 aws.String(t.Name),
+			Key:                       t.Schema.Key(key),
+			ConditionExpression:       cond.expression(),
+			ExpressionAttributeNames:  cond.names(),
+			ExpressionAttributeValues: cond.values(),
+		},
+	}
+}
+
+// UpdateTransactItem is the transaction counterpart of Update.
+func (t *Table[T, K]) UpdateTransactItem(ctx context.Context, key K, item T, mask []string, conds ...Condition) (types.TransactWriteItem, error) {
+	update, cond, err := t.buildUpdate(ctx, key, item, mask, conds)
+	if err != nil {
+		return types.TransactWriteItem{}, err
+	}
+
+	return types.TransactWriteItem{
+		Update: &types.Update{
+			TableName:                 aws.String(t.Name),
+			Key:                       t.Schema.Key(key),
+			UpdateExpression:          aws.String(update.expression()),
+			ConditionExpression:       aws.String(cond.Expression),
+			ExpressionAttributeNames:  update.Names,
+			ExpressionAttributeValues: nilIfEmpty(update.Values),
+		},
+	}, nil
+}
+
+func (t *Table[T, K]) ConditionCheckTransactItem(key K, conds ...Condition) types.TransactWriteItem {
+	cond := joinConditions(conds)
+	return types.TransactWriteItem{
+		ConditionCheck: &types.ConditionCheck{
+			TableName:                 aws.String(t.Name),
+			Key:                       t.Schema.Key(key),
+			ConditionExpression:       cond.expression(),
+			ExpressionAttributeNames:  cond.names(),
+			ExpressionAttributeValues: cond.values(),
+		},
+	}
+}
+
+func (t *Table[T, K]) marshal(ctx context.Context, item T) (map[string]types.AttributeValue, error) {
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return av, err
+	}
+	for _, d := range t.Derived {
+		if v := d.Value(item); v != nil {
+			av[d.Name] = v
+		}
+	}
+	if t.Encryptor == nil {
+		return av, nil
+	}
+	return av, t.Encryptor.EncryptAttributes(ctx, av, t.aadFor(av), encryptedAttributes[T]())
+}
+
+func (t *Table[T, K]) unmarshal(ctx context.Context, av map[string]types.AttributeValue, item *T) error {
+	if t.Encryptor != nil {
+		av = maps.Clone(av)
+		err := t.Encryptor.DecryptAttributes(ctx, av, t.aadFor(av), encryptedAttributes[T]())
+		if err != nil {
+			return err
+		}
+	}
+	return attributevalue.UnmarshalMap(av, item)
+}
+
+// encryptUpdate encrypts the values an update expression sets on encrypted
+// attributes, binding them to the item the same way marshal does.
+func (t *Table[T, K]) encryptUpdate(ctx context.Context, key K, update *updateExpression) error {
+	if t.Encryptor == nil {
+		return nil
+	}
+
+	encrypted := encryptedAttributes[T]()
+	aad := t.aadFor(t.Schema.Key(key))
+	for _, set := range update.sets {
+		name := update.Names[set.name]
+		if !slices.Contains(encrypted, name) {
+			continue
+		}
+
+		av := map[string]types.AttributeValue{name: update.Values[set.value]}
+		err := t.Encryptor.EncryptAttributes(ctx, av, aad, []string{name})
+		if err != nil {
+			return err
+		}
+		update.Values[set.value] = av[name]
+	}
+	return nil
+}
+
+// aadFor identifies the item holding the attributes of item by its full
+// primary key, which is bound to its encrypted attributes so they cannot be
+// moved to another item.
+func (t *Table[T, K]) aadFor(item map[string]types.AttributeValue) string {
+	aad := stringKey(item, t.Schema.PartitionKey)
+	if t.Schema.SortKey != "" {
+		aad += "\x00" + stringKey(item, t.Schema.SortKey)
+	}
+	return aad
+}
+
+// itemID names item the way Schema.ItemID names its attributes, reading the
+// key field from item itself so that items that fail to marshal are named
+// too.
+func (t *Table[T, K]) itemID(item T) string {
+	name := t.Schema.PartitionKey
+	if t.Schema.SortKey != "" {
+		name = t.Schema.SortKey
+	}
+
+	v := reflect.ValueOf(item)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if tagName(f.Tag.Get("dynamodbav"), f.Name) == name && f.Type.Kind() == reflect.String {
+			return v.Field(i).String()
+		}
+	}
+	return ""
+}
+
+func encryptedAttributes[T any]() []string {
+	return encryption.TaggedAttributes(reflect.TypeFor[T]())
+}
+
+func (t *Table[T, K]) wrapError(ctx context.Context, action string, err error) error {
+	log.WithTraceContext(ctx, t.logger).Error("error "+action, zap.Error(err))
+
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		return errors.Join(ErrConditionFailed, err)
+	}
+	return err
+}
+
+func joinConditions(conds []Condition) Condition {
+	joined := Condition{
+		Names:  map[string]string{},
+		Values: map[string]types.AttributeValue{},
+	}
+
+	var expressions []string
+	for _, c := range conds {
+		if c.Expression == "" {
+			continue
+		}
+		expressions = append(expressions, "("+c.Expression+")")
+		maps.Copy(joined.Names, c.Names)
+		maps.Copy(joined.Values, c.Values)
+	}
+
+	if len(expressions) == 1 {
+		joined.Expression = expressions[0][1 : len(expressions[0])-1]
+	} else {
+		joined.Expression = strings.Join(expressions, " AND ")
+	}
+
+	return joined
+}
+
+func (c Condition) expression() *string {
+	if c.Expression == "" {
+		return nil
+	}
+	return aws.String(c.Expression)
+}
+
+func (c Condition) names() map[string]string {
+	if len(c.Names) == 0 {
+		return nil
+	}
+	return c.Names
+}
+
+func (c Condition) values() map[string]types.AttributeValue {
+	return nilIfEmpty(c.Values)
+}
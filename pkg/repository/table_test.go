@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/dynamo"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/receiver"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestKeySchema(t *testing.T) {
+	assert.Equal(t, map[string]types.AttributeValue{
+		"receiver_id": &types.AttributeValueMemberS{Value: "Receiver#123"},
+	}, receiverKeySchema.Key("Receiver#123"))
+
+	key := eventKeySchema.Key(EventKey{ReceiverID: "Receiver#123", EventID: "Event#123"})
+	assert.Equal(t, map[string]types.AttributeValue{
+		"receiver_id": &types.AttributeValueMemberS{Value: "Receiver#123"},
+		"event_id":    &types.AttributeValueMemberS{Value: "Event#123"},
+	}, key)
+
+	assert.Equal(t, "Event#123", eventKeySchema.ItemID(key))
+	assert.Equal(t, "Receiver#123", receiverKeySchema.ItemID(key))
+}
+
+func TestJoinConditions(t *testing.T) {
+	tests := map[string]struct {
+		conds    []Condition
+		expected Condition
+	}{
+		"No Conditions": {
+			expected: Condition{Names: map[string]string{}, Values: map[string]types.AttributeValue{}},
+		},
+		"Single Condition": {
+			conds: []Condition{{Expression: "attribute_exists(#pk)", Names: map[string]string{"#pk": "user_id"}}},
+			expected: Condition{
+				Expression: "attribute_exists(#pk)",
+				Names:      map[string]string{"#pk": "user_id"},
+				Values:     map[string]types.AttributeValue{},
+			},
+		},
+		"Multiple Conditions": {
+			conds: []Condition{
+				{Expression: "attribute_exists(#pk)", Names: map[string]string{"#pk": "user_id"}},
+				{Expression: "email = :email", Values: map[string]types.AttributeValue{":email": &types.AttributeValueMemberS{Value: "a@b.c"}}},
+			},
+			expected: Condition{
+				Expression: "(attribute_exists(#pk)) AND (email = :email)",
+				Names:      map[string]string{"#pk": "user_id"},
+				Values:     map[string]types.AttributeValue{":email": &types.AttributeValueMemberS{Value: "a@b.c"}},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, joinConditions(tc.conds))
+		})
+	}
+}
+
+type failingMarshal struct{}
+
+func (failingMarshal) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	return nil, errors.New("cannot marshal")
+}
+
+func TestTable(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Get - Not Found", func(t *testing.T) {
+		table := NewTable[receiver.Receiver]("receiver-table", receiverKeySchema, &dynamo.Mock{GetOutput: &dynamodb.GetItemOutput{}}, zap.NewNop())
+
+		_, err := table.Get(ctx, "Receiver#404")
+		assert.ErrorIs(t, err, ErrItemNotFound)
+	})
+
+	t.Run("Put - Condition Failed", func(t *testing.T) {
+		table := NewTable[receiver.Receiver]("receiver-table", receiverKeySchema, &dynamo.Mock{Err: &types.ConditionalCheckFailedException{}}, zap.NewNop())
+
+		err := table.Put(ctx, receiver.Receiver{ReceiverID: "Receiver#123"}, Condition{Expression: "attribute_not_exists(receiver_id)"})
+		assert.ErrorIs(t, err, ErrConditionFailed)
+	})
+
+	t.Run("Put - Logs Redacted Item", func(t *testing.T) {
+		core, logs := observer.New(zap.InfoLevel)
+		table := NewTable[receiver.Receiver]("receiver-table", receiverKeySchema, &dynamo.Mock{}, zap.New(core))
+
+		err := table.Put(ctx, receiver.Receiver{ReceiverID: "Receiver#123", FirstName: "Jane", LastName: "Doe"})
+		assert.NoError(t, err)
+
+		item := logs.FilterMessage("inserting item into db").All()[0].ContextMap()["item"].(map[string]any)
+		assert.Equal(t, "Receiver#123", item["receiverId"])
+		assert.NotEqual(t, "Jane", item["firstName"])
+		assert.NotEqual(t, "Doe", item["lastName"])
+	})
+
+	t.Run("Delete - Error", func(t *testing.T) {
+		table := NewTable[receiver.Receiver]("receiver-table", receiverKeySchema, &dynamo.Mock{Err: errors.New("An error occured during Delete")}, zap.NewNop())
+
+		err := table.Delete(ctx, "Receiver#123")
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrConditionFailed)
+	})
+
+	t.Run("Update - Key Field Protected", func(t *testing.T) {
+		table := NewTable[receiver.Receiver]("receiver-table", receiverKeySchema, &dynamo.Mock{}, zap.NewNop())
+
+		_, err := table.Update(ctx, "Receiver#123", receiver.Receiver{}, []string{"receiverId"})
+		assert.ErrorIs(t, err, ErrInvalidFieldMask)
+	})
+
+	t.Run("PutBatch - Marshal Error Names Item", func(t *testing.T) {
+		type unmarshallable struct {
+			EventID string         `dynamodbav:"event_id"`
+			Value   failingMarshal `dynamodbav:"value"`
+		}
+		table := NewTable[unmarshallable]("event-table", eventKeySchema, &dynamo.Mock{}, zap.NewNop())
+
+		failures := table.PutBatch(ctx, []unmarshallable{{EventID: "Event#123"}})
+		assert.Len(t, failures, 1)
+		assert.Equal(t, "Event#123", failures[0].ID)
+		assert.Error(t, failures[0].Err)
+	})
+
+	t.Run("Query - Multiple Pages", func(t *testing.T) {
+		table := NewTable[receiver.Receiver]("receiver-table", receiverKeySchema, &dynamo.Mock{
+			QueryOutputs: []*dynamodb.QueryOutput{
+				{
+					Items:            []map[string]types.AttributeValue{receiverKeySchema.Key("Receiver#1")},
+					LastEvaluatedKey: receiverKeySchema.Key("Receiver#1"),
+				},
+				{
+					Items: []map[string]types.AttributeValue{receiverKeySchema.Key("Receiver#2")},
+				},
+			},
+		}, zap.NewNop())
+
+		receivers, err := table.Query(ctx, QueryParams{KeyCondition: "receiver_id = :rid"})
+		assert.NoError(t, err)
+		assert.Equal(t, []receiver.Receiver{{ReceiverID: "Receiver#1"}, {ReceiverID: "Receiver#2"}}, receivers)
+	})
+
+	t.Run("Transact Items", func(t *testing.T) {
+		table := NewTable[receiver.Receiver]("receiver-table", receiverKeySchema, &dynamo.Mock{}, zap.NewNop())
+
+		put, err := table.PutTransactItem(ctx, receiver.Receiver{ReceiverID: "Receiver#123"}, Condition{Expression: "attribute_not_exists(receiver_id)"})
+		assert.NoError(t, err)
+		assert.Equal(t, "attribute_not_exists(receiver_id)", *put.Put.ConditionExpression)
+		assert.Nil(t, put.Put.ExpressionAttributeValues)
+
+		del := table.DeleteTransactItem("Receiver#123")
+		assert.Nil(t, del.Delete.ConditionExpression)
+		assert.Equal(t, receiverKeySchema.Key("Receiver#123"), del.Delete.Key)
+	})
+}
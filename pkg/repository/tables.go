@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"github.com/care-giver-app/care-giver-golang-common/pkg/dynamo"
+)
+
+const (
+	UserEmailIndex                      = "email"
+	EventReceiverStartTimeIndex         = "receiver-start-time"
+	RelationshipReceiverIndex           = "receiver_id"
+	RelationshipEmailNotificationsIndex = "email_notifications"
+	InvitationTokenIndex                = "token_hash"
+
+	userEmail                = "email"
+	eventStartTime           = "start_time"
+	emailNotificationsGSIKey = "email_notifications_gsi_pk"
+)
+
+// TableNames holds the table name of each repository. Schemas returns the
+// tables and indexes the repositories query so they can be created with
+// dynamo.EnsureTables. The invitations, deliveries and escalation tables are
+// optional and left out when they have no name.
+type TableNames struct {
+	Users              string
+	Receivers          string
+	Events             string
+	Relationships      string
+	Invitations        string
+	Deliveries         string
+	EscalationPolicies string
+	EscalationStates   string
+}
+
+func (n TableNames) Schemas() []dynamo.TableSchema {
+	schemas := []dynamo.TableSchema{
+		UserTableSchema(n.Users),
+		ReceiverTableSchema(n.Receivers),
+		EventTableSchema(n.Events),
+		RelationshipTableSchema(n.Relationships),
+	}
+	if n.Invitations != "" {
+		schemas = append(schemas, InvitationTableSchema(n.Invitations))
+	}
+	if n.Deliveries != "" {
+		schemas = append(schemas, DeliveryTableSchema(n.Deliveries))
+	}
+	if n.EscalationPolicies != "" {
+		schemas = append(schemas, EscalationPolicyTableSchema(n.EscalationPolicies))
+	}
+	if n.EscalationStates != "" {
+		schemas = append(schemas, EscalationStateTableSchema(n.EscalationStates))
+	}
+	return schemas
+}
+
+func UserTableSchema(tableName string) dynamo.TableSchema {
+	return dynamo.TableSchema{
+		Name:         tableName,
+		PartitionKey: dynamo.StringKey(userID),
+		Indexes: []dynamo.IndexSchema{
+			{Name: UserEmailIndex, PartitionKey: dynamo.StringKey(userEmail)},
+		},
+	}
+}
+
+func ReceiverTableSchema(tableName string) dynamo.TableSchema {
+	return dynamo.TableSchema{
+		Name:         tableName,
+		PartitionKey: dynamo.StringKey(receiverID),
+	}
+}
+
+func EventTableSchema(tableName string) dynamo.TableSchema {
+	return dynamo.TableSchema{
+		Name:         tableName,
+		PartitionKey: dynamo.StringKey(receiverID),
+		SortKey:      dynamo.StringKey(eventID),
+		Indexes: []dynamo.IndexSchema{
+			{Name: EventReceiverStartTimeIndex, PartitionKey: dynamo.StringKey(receiverID), SortKey: dynamo.StringKey(eventStartTime)},
+		},
+	}
+}
+
+func RelationshipTableSchema(tableName string) dynamo.TableSchema {
+	return dynamo.TableSchema{
+		Name:         tableName,
+		PartitionKey: dynamo.StringKey(userID),
+		SortKey:      dynamo.StringKey(receiverID),
+		Indexes: []dynamo.IndexSchema{
+			{Name: RelationshipReceiverIndex, PartitionKey: dynamo.StringKey(receiverID)},
+			{Name: RelationshipEmailNotificationsIndex, PartitionKey: dynamo.NumberKey(emailNotificationsGSIKey)},
+		},
+	}
+}
+
+func InvitationTableSchema(tableName string) dynamo.TableSchema {
+	return dynamo.TableSchema{
+		Name:         tableName,
+		PartitionKey: dynamo.StringKey(receiverID),
+		SortKey:      dynamo.StringKey(invitationID),
+		Indexes: []dynamo.IndexSchema{
+			{Name: InvitationTokenIndex, PartitionKey: dynamo.StringKey(invitationTokenHash)},
+		},
+	}
+}
+
+func DeliveryTableSchema(tableName string) dynamo.TableSchema {
+	return dynamo.TableSchema{
+		Name:         tableName,
+		PartitionKey: dynamo.StringKey(deliveryRecipient),
+		SortKey:      dynamo.StringKey(deliveryID),
+		TTLAttribute: deliveryExpiresAt,
+	}
+}
+
+func EscalationPolicyTableSchema(tableName string) dynamo.TableSchema {
+	return dynamo.TableSchema{
+		Name:         tableName,
+		PartitionKey: dynamo.StringKey(receiverID),
+	}
+}
+
+func EscalationStateTableSchema(tableName string) dynamo.TableSchema {
+	return dynamo.TableSchema{
+		Name:         tableName,
+		PartitionKey: dynamo.StringKey(receiverID),
+		SortKey:      dynamo.StringKey(escalationEventType),
+	}
+}
@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/event"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/log"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/receiver"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/user"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const TracerName = "github.com/care-giver-app/care-giver-golang-common/pkg/repository"
+
+type tracing struct {
+	tracer     trace.Tracer
+	repository string
+}
+
+// newTracing uses the global tracer provider when tp is nil.
+func newTracing(tp trace.TracerProvider, repository string) tracing {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return tracing{tracer: tp.Tracer(TracerName), repository: repository}
+}
+
+func (t tracing) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, t.repository+"."+method, trace.WithAttributes(attrs...))
+}
+
+func endSpan(span trace.Span, err error, attrs ...attribute.KeyValue) {
+	span.SetAttributes(attrs...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, ErrorType(err))
+	}
+	span.End()
+}
+
+func itemCount(n int) attribute.KeyValue {
+	return attribute.Int(log.ItemCountLogKey, n)
+}
+
+// TracingUserRepository wraps every method of the repository it wraps in a
+// span carrying the IDs the method was called with.
+type TracingUserRepository struct {
+	Repo UserRepositoryProviderV2
+	tracing
+}
+
+func NewTracingUserRepository(repo UserRepositoryProviderV2, tp trace.TracerProvider) *TracingUserRepository {
+	return &TracingUserRepository{Repo: repo, tracing: newTracing(tp, "UserRepository")}
+}
+
+func (r *TracingUserRepository) CreateUser(ctx context.Context, u user.User) (err error) {
+	ctx, span := r.start(ctx, "CreateUser", attribute.String(log.UserIDLogKey, u.UserID))
+	defer func() { endSpan(span, err) }()
+	return r.Repo.CreateUser(ctx, u)
+}
+
+func (r *TracingUserRepository) GetUser(ctx context.Context, uid string) (_ user.User, err error) {
+	ctx, span := r.start(ctx, "GetUser", attribute.String(log.UserIDLogKey, uid))
+	defer func() { endSpan(span, err) }()
+	return r.Repo.GetUser(ctx, uid)
+}
+
+func (r *TracingUserRepository) GetUserByEmail(ctx context.Context, email string) (u user.User, err error) {
+	ctx, span := r.start(ctx, "GetUserByEmail")
+	defer func() { endSpan(span, err, attribute.String(log.UserIDLogKey, u.UserID)) }()
+	return r.Repo.GetUserByEmail(ctx, email)
+}
+
+func (r *TracingUserRepository) UpdateUser(ctx context.Context, u user.User, mask []string) (_ user.User, err error) {
+	ctx, span := r.start(ctx, "UpdateUser", attribute.String(log.UserIDLogKey, u.UserID), attribute.StringSlice("fields", mask))
+	defer func() { endSpan(span, err) }()
+	return r.Repo.UpdateUser(ctx, u, mask)
+}
+
+func (r *TracingUserRepository) ChangeEmail(ctx context.Context, uid string, email string) (err error) {
+	ctx, span := r.start(ctx, "ChangeEmail", attribute.String(log.UserIDLogKey, uid))
+	defer func() { endSpan(span, err) }()
+	return r.Repo.ChangeEmail(ctx, uid, email)
+}
+
+type TracingReceiverRepository struct {
+	Repo ReceiverRepositoryProviderV2
+	tracing
+}
+
+func NewTracingReceiverRepository(repo ReceiverRepositoryProviderV2, tp trace.TracerProvider) *TracingReceiverRepository {
+	return &TracingReceiverRepository{Repo: repo, tracing: newTracing(tp, "ReceiverRepository")}
+}
+
+func (r *TracingReceiverRepository) CreateReceiver(ctx context.Context, rec receiver.Receiver) (err error) {
+	ctx, span := r.start(ctx, "CreateReceiver", attribute.String(log.ReceiverIDLogKey, rec.ReceiverID))
+	defer func() { endSpan(span, err) }()
+	return r.Repo.CreateReceiver(ctx, rec)
+}
+
+func (r *TracingReceiverRepository) GetReceiver(ctx context.Context, rid string) (_ receiver.Receiver, err error) {
+	ctx, span := r.start(ctx, "GetReceiver", attribute.String(log.ReceiverIDLogKey, rid))
+	defer func() { endSpan(span, err) }()
+	return r.Repo.GetReceiver(ctx, rid)
+}
+
+func (r *TracingReceiverRepository) GetReceivers(ctx context.Context, rids []string) (receivers []receiver.Receiver, err error) {
+	ctx, span := r.start(ctx, "GetReceivers", attribute.StringSlice(log.ReceiverIDLogKey, rids))
+	defer func() { endSpan(span, err, itemCount(len(receivers))) }()
+	return r.Repo.GetReceivers(ctx, rids)
+}
+
+func (r *TracingReceiverRepository) UpdateReceiver(ctx context.Context, rec receiver.Receiver, mask []string) (_ receiver.Receiver, err error) {
+	ctx, span := r.start(ctx, "UpdateReceiver", attribute.String(log.ReceiverIDLogKey, rec.ReceiverID), attribute.StringSlice("fields", mask))
+	defer func() { endSpan(span, err) }()
+	return r.Repo.UpdateReceiver(ctx, rec, mask)
+}
+
+func (r *TracingReceiverRepository) DeleteReceiver(ctx context.Context, rid string) (err error) {
+	ctx, span := r.start(ctx, "DeleteReceiver", attribute.String(log.ReceiverIDLogKey, rid))
+	defer func() { endSpan(span, err) }()
+	return r.Repo.DeleteReceiver(ctx, rid)
+}
+
+type TracingEventRepository struct {
+	Repo EventRepositoryProviderV2
+	tracing
+}
+
+func NewTracingEventRepository(repo EventRepositoryProviderV2, tp trace.TracerProvider) *TracingEventRepository {
+	return &TracingEventRepository{Repo: repo, tracing: newTracing(tp, "EventRepository")}
+}
+
+func (r *TracingEventRepository) AddEvent(ctx context.Context, e *event.Entry) (err error) {
+	ctx, span := r.start(ctx, "AddEvent", attribute.String(log.ReceiverIDLogKey, e.ReceiverID), attribute.String(log.EventIDLogKey, e.EventID))
+	defer func() { endSpan(span, err) }()
+	return r.Repo.AddEvent(ctx, e)
+}
+
+func (r *TracingEventRepository) AddEvents(ctx context.Context, entries []*event.Entry) (err error) {
+	ctx, span := r.start(ctx, "AddEvents", itemCount(len(entries)))
+	defer func() { endSpan(span, err) }()
+	return r.Repo.AddEvents(ctx, entries)
+}
+
+func (r *TracingEventRepository) GetEvents(ctx context.Context, rid string, bound TimestampBound) (entries []event.Entry, err error) {
+	ctx, span := r.start(ctx, "GetEvents", attribute.String(log.ReceiverIDLogKey, rid))
+	defer func() { endSpan(span, err, itemCount(len(entries))) }()
+	return r.Repo.GetEvents(ctx, rid, bound)
+}
+
+func (r *TracingEventRepository) DeleteEvent(ctx context.Context, rid, eid string) (err error) {
+	ctx, span := r.start(ctx, "DeleteEvent", attribute.String(log.ReceiverIDLogKey, rid), attribute.String(log.EventIDLogKey, eid))
+	defer func() { endSpan(span, err) }()
+	return r.Repo.DeleteEvent(ctx, rid, eid)
+}
+
+type TracingRelationshipRepository struct {
+	Repo RelationshipRepositoryProviderV2
+	tracing
+}
+
+func NewTracingRelationshipRepository(repo RelationshipRepositoryProviderV2, tp trace.TracerProvider) *TracingRelationshipRepository {
+	return &TracingRelationshipRepository{Repo: repo, tracing: newTracing(tp, "RelationshipRepository")}
+}
+
+func (r *TracingRelationshipRepository) AddRelationship(ctx context.Context, rel *relationship.Relationship) (err error) {
+	ctx, span := r.start(ctx, "AddRelationship", attribute.String(log.UserIDLogKey, rel.UserID), attribute.String(log.ReceiverIDLogKey, rel.ReceiverID))
+	defer func() { endSpan(span, err) }()
+	return r.Repo.AddRelationship(ctx, rel)
+}
+
+func (r *TracingRelationshipRepository) GetRelationship(ctx context.Context, userID string, receiverID string) (_ *relationship.Relationship, err error) {
+	ctx, span := r.start(ctx, "GetRelationship", attribute.String(log.UserIDLogKey, userID), attribute.String(log.ReceiverIDLogKey, receiverID))
+	defer func() { endSpan(span, err) }()
+	return r.Repo.GetRelationship(ctx, userID, receiverID)
+}
+
+func (r *TracingRelationshipRepository) GetRelationshipsByUser(ctx context.Context, userID string) (relationships []relationship.Relationship, err error) {
+	ctx, span := r.start(ctx, "GetRelationshipsByUser", attribute.String(log.UserIDLogKey, userID))
+	defer func() { endSpan(span, err, itemCount(len(relationships))) }()
+	return r.Repo.GetRelationshipsByUser(ctx, userID)
+}
+
+func (r *TracingRelationshipRepository) GetRelationshipsByReceiver(ctx context.Context, receiverID string) (relationships []relationship.Relationship, err error) {
+	ctx, span := r.start(ctx, "GetRelationshipsByReceiver", attribute.String(log.ReceiverIDLogKey, receiverID))
+	defer func() { endSpan(span, err, itemCount(len(relationships))) }()
+	return r.Repo.GetRelationshipsByReceiver(ctx, receiverID)
+}
+
+func (r *TracingRelationshipRepository) DeleteRelationship(ctx context.Context, userID string, receiverID string) (err error) {
+	ctx, span := r.start(ctx, "DeleteRelationship", attribute.String(log.UserIDLogKey, userID), attribute.String(log.ReceiverIDLogKey, receiverID))
+	defer func() { endSpan(span, err) }()
+	return r.Repo.DeleteRelationship(ctx, userID, receiverID)
+}
+
+func (r *TracingRelationshipRepository) GetRelationshipsByEmailNotifications(ctx context.Context) (relationships []relationship.Relationship, err error) {
+	ctx, span := r.start(ctx, "GetRelationshipsByEmailNotifications")
+	defer func() { endSpan(span, err, itemCount(len(relationships))) }()
+	return r.Repo.GetRelationshipsByEmailNotifications(ctx)
+}
+
+func (r *TracingRelationshipRepository) TransferPrimary(ctx context.Context, receiverID string, fromUID string, toUID string) (err error) {
+	ctx, span := r.start(ctx, "TransferPrimary", attribute.String(log.ReceiverIDLogKey, receiverID), attribute.String(log.UserIDLogKey, fromUID), attribute.String(log.TargetUserIDLogKey, toUID))
+	defer func() { endSpan(span, err) }()
+	return r.Repo.TransferPrimary(ctx, receiverID, fromUID, toUID)
+}
+
+func (r *TracingRelationshipRepository) UpdateNotificationPreferences(ctx context.Context, userID string, receiverID string, p relationship.NotificationPreferences) (_ *relationship.Relationship, err error) {
+	ctx, span := r.start(ctx, "UpdateNotificationPreferences", attribute.String(log.UserIDLogKey, userID), attribute.String(log.ReceiverIDLogKey, receiverID))
+	defer func() { endSpan(span, err) }()
+	return r.Repo.UpdateNotificationPreferences(ctx, userID, receiverID, p)
+}
+
+type TracingOnboardingRepository struct {
+	Repo OnboardingRepositoryProvider
+	tracing
+}
+
+func NewTracingOnboardingRepository(repo OnboardingRepositoryProvider, tp trace.TracerProvider) *TracingOnboardingRepository {
+	return &TracingOnboardingRepository{Repo: repo, tracing: newTracing(tp, "OnboardingRepository")}
+}
+
+func (r *TracingOnboardingRepository) OnboardReceiver(ctx context.Context, rec receiver.Receiver, rel *relationship.Relationship) (err error) {
+	ctx, span := r.start(ctx, "OnboardReceiver", attribute.String(log.ReceiverIDLogKey, rec.ReceiverID), attribute.String(log.UserIDLogKey, rel.UserID))
+	defer func() { endSpan(span, err) }()
+	return r.Repo.OnboardReceiver(ctx, rec, rel)
+}
+
+func (r *TracingOnboardingRepository) RemoveReceiver(ctx context.Context, rid string, uid string) (err error) {
+	ctx, span := r.start(ctx, "RemoveReceiver", attribute.String(log.ReceiverIDLogKey, rid), attribute.String(log.UserIDLogKey, uid))
+	defer func() { endSpan(span, err) }()
+	return r.Repo.RemoveReceiver(ctx, rid, uid)
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/care-giver-app/care-giver-golang-common/pkg/dynamo"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/log"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/relationship"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestTracingRelationshipRepository(t *testing.T) {
+	ctx := context.Background()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	core, logs := observer.New(zap.InfoLevel)
+	client := dynamo.NewTracingClient(newTestEmulator(), tp)
+	repo := NewTracingRelationshipRepository(NewRelationshipRepositoryV2(testRelationshipTable, client, zap.New(core)), tp)
+
+	assert.NoError(t, repo.AddRelationship(ctx, &relationship.Relationship{UserID: "User#1", ReceiverID: "Receiver#1"}))
+	assert.NoError(t, repo.AddRelationship(ctx, &relationship.Relationship{UserID: "User#2", ReceiverID: "Receiver#1"}))
+
+	got, err := repo.GetRelationshipsByReceiver(ctx, "Receiver#1")
+	assert.NoError(t, err)
+	assert.Len(t, got, 2)
+
+	spans := recorder.Ended()
+	var parent sdktrace.ReadOnlySpan
+	var queries []sdktrace.ReadOnlySpan
+	for _, s := range spans {
+		switch s.Name() {
+		case "RelationshipRepository.GetRelationshipsByReceiver":
+			parent = s
+		case "DynamoDB.Query":
+			queries = append(queries, s)
+		}
+	}
+	if !assert.NotNil(t, parent) || !assert.NotEmpty(t, queries) {
+		return
+	}
+	assert.Contains(t, parent.Attributes(), attribute.String(log.ReceiverIDLogKey, "Receiver#1"))
+	assert.Contains(t, parent.Attributes(), attribute.Int(log.ItemCountLogKey, 2))
+	for _, q := range queries {
+		assert.Equal(t, parent.SpanContext().SpanID(), q.Parent().SpanID())
+		assert.Contains(t, q.Attributes(), attribute.String("aws.dynamodb.index_name", RelationshipReceiverIndex))
+	}
+
+	entries := logs.FilterMessage("getting relationships by receiver from db").All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, parent.SpanContext().TraceID().String(), entries[0].ContextMap()[log.TraceIDLogKey])
+	}
+}
+
+func TestTracingUserRepository_Error(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	repo := NewTracingUserRepository(NewUserRepositoryV2(testUserTable, newTestEmulator(), zap.NewNop()), tp)
+
+	_, err := repo.GetUserByEmail(context.Background(), "missing@example.com")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "UserRepository.GetUserByEmail", spans[0].Name())
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+		assert.Equal(t, "UserNotFound", spans[0].Status().Description)
+	}
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var (
+	ErrEmptyFieldMask   = errors.New("field mask is empty")
+	ErrInvalidFieldMask = errors.New("invalid field mask")
+)
+
+type updateExpression struct {
+	Names  map[string]string
+	Values map[string]types.AttributeValue
+	// sets holds the placeholders of each attribute the update sets and of
+	// its value, and removes those of each attribute it removes.
+	sets    []updateAssignment
+	removes []string
+}
+
+type updateAssignment struct {
+	name  string
+	value string
+}
+
+// buildUpdateExpression turns a field mask of json field names into an
+// UpdateItem expression using the values held by item. Fields in the mask
+// that marshal to nothing (omitempty) are removed from the stored item.
+func buildUpdateExpression(item any, mask []string, protected ...string) (*updateExpression, error) {
+	names, err := fieldMaskAttributes(item, mask, protected...)
+	if err != nil {
+		return nil, err
+	}
+
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return nil, err
+	}
+
+	update := &updateExpression{
+		Names:  map[string]string{},
+		Values: map[string]types.AttributeValue{},
+	}
+
+	for i, name := range names {
+		placeholder := fmt.Sprintf("#f%d", i)
+		update.Names[placeholder] = name
+
+		value, ok := av[name]
+		if !ok {
+			update.removes = append(update.removes, placeholder)
+			continue
+		}
+
+		valuePlaceholder := fmt.Sprintf(":v%d", i)
+		update.Values[valuePlaceholder] = value
+		update.sets = append(update.sets, updateAssignment{name: placeholder, value: valuePlaceholder})
+	}
+
+	return update, nil
+}
+
+// assign adds an action to the update that sets the attribute to value, or
+// removes it when value is nil.
+func (u *updateExpression) assign(name string, value types.AttributeValue) {
+	placeholder := fmt.Sprintf("#a%d", len(u.Names))
+	u.Names[placeholder] = name
+	if value == nil {
+		u.removes = append(u.removes, placeholder)
+		return
+	}
+
+	valuePlaceholder := fmt.Sprintf(":a%d", len(u.Names))
+	u.Values[valuePlaceholder] = value
+	u.sets = append(u.sets, updateAssignment{name: placeholder, value: valuePlaceholder})
+}
+
+// expression renders the actions of the update as an UpdateExpression.
+func (u *updateExpression) expression() string {
+	var clauses []string
+	if len(u.sets) > 0 {
+		sets := make([]string, len(u.sets))
+		for i, s := range u.sets {
+			sets[i] = s.name + " = " + s.value
+		}
+		clauses = append(clauses, "SET "+strings.Join(sets, ", "))
+	}
+	if len(u.removes) > 0 {
+		clauses = append(clauses, "REMOVE "+strings.Join(u.removes, ", "))
+	}
+	return strings.Join(clauses, " ")
+}
+
+// ApplyFieldMask copies the fields named in mask from src to dst. The mask is
+// validated the same way the repositories validate update masks, so
+// alternative Provider implementations can share their semantics.
+func ApplyFieldMask[T any](dst *T, src T, mask []string, protected ...string) error {
+	if _, err := fieldMaskAttributes(src, mask, protected...); err != nil {
+		return err
+	}
+
+	fields := jsonFields(reflect.TypeOf(src))
+	dv := reflect.ValueOf(dst).Elem()
+	sv := reflect.ValueOf(src)
+	for _, field := range mask {
+		index := fields[field].Index
+		dv.FieldByIndex(index).Set(sv.FieldByIndex(index))
+	}
+
+	return nil
+}
+
+func fieldMaskAttributes(item any, mask []string, protected ...string) ([]string, error) {
+	if len(mask) == 0 {
+		return nil, ErrEmptyFieldMask
+	}
+
+	attributes := attributeNamesByJSONName(item)
+
+	names := make([]string, 0, len(mask))
+	for _, field := range mask {
+		name, ok := attributes[field]
+		if !ok || slices.Contains(protected, field) {
+			return nil, fmt.Errorf("%w: field %s cannot be updated", ErrInvalidFieldMask, field)
+		}
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+func attributeNamesByJSONName(item any) map[string]string {
+	t := reflect.TypeOf(item)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	fields := jsonFields(t)
+	names := make(map[string]string, len(fields))
+	for jsonName, f := range fields {
+		avName := tagName(f.Tag.Get("dynamodbav"), f.Name)
+		if avName == "-" {
+			continue
+		}
+		names[jsonName] = avName
+	}
+
+	return names
+}
+
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		jsonName := tagName(f.Tag.Get("json"), f.Name)
+		if jsonName == "-" {
+			continue
+		}
+		fields[jsonName] = f
+	}
+
+	return fields
+}
+
+func tagName(tag string, fallback string) string {
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		return fallback
+	}
+	return name
+}
+
+func nilIfEmpty(values map[string]types.AttributeValue) map[string]types.AttributeValue {
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
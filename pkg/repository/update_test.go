@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/event"
+	"github.com/care-giver-app/care-giver-golang-common/pkg/user"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildUpdateExpression(t *testing.T) {
+	tests := map[string]struct {
+		item          any
+		mask          []string
+		protected     []string
+		expected      *updateExpression
+		expectedExpr  string
+		expectedError error
+	}{
+		"Happy Path - Set Fields": {
+			item: user.User{FirstName: "Demo", LastName: "Daniel"},
+			mask: []string{"firstName", "lastName"},
+			expected: &updateExpression{
+				Names: map[string]string{"#f0": "first_name", "#f1": "last_name"},
+				Values: map[string]types.AttributeValue{
+					":v0": &types.AttributeValueMemberS{Value: "Demo"},
+					":v1": &types.AttributeValueMemberS{Value: "Daniel"},
+				},
+				sets: []updateAssignment{{name: "#f0", value: ":v0"}, {name: "#f1", value: ":v1"}},
+			},
+			expectedExpr: "SET #f0 = :v0, #f1 = :v1",
+		},
+		"Happy Path - Remove Omitted Field": {
+			item: &event.Entry{Type: "Shower"},
+			mask: []string{"type", "note"},
+			expected: &updateExpression{
+				Names: map[string]string{"#f0": "type", "#f1": "note"},
+				Values: map[string]types.AttributeValue{
+					":v0": &types.AttributeValueMemberS{Value: "Shower"},
+				},
+				sets:    []updateAssignment{{name: "#f0", value: ":v0"}},
+				removes: []string{"#f1"},
+			},
+			expectedExpr: "SET #f0 = :v0 REMOVE #f1",
+		},
+		"Sad Path - Empty Mask": {
+			item:          user.User{},
+			expectedError: ErrEmptyFieldMask,
+		},
+		"Sad Path - Unknown Field": {
+			item:          user.User{},
+			mask:          []string{"middleName"},
+			expectedError: ErrInvalidFieldMask,
+		},
+		"Sad Path - Protected Field": {
+			item:          user.User{},
+			mask:          []string{"userId"},
+			protected:     []string{"userId"},
+			expectedError: ErrInvalidFieldMask,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			update, err := buildUpdateExpression(tc.item, tc.mask, tc.protected...)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, update)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, update)
+				assert.Equal(t, tc.expectedExpr, update.expression())
+			}
+		})
+	}
+}
+
+func TestUpdateExpressionAssign(t *testing.T) {
+	update, err := buildUpdateExpression(&event.Entry{Type: "Shower"}, []string{"type", "note"})
+	assert.NoError(t, err)
+
+	update.assign("type_timestamp", &types.AttributeValueMemberS{Value: "Shower#2025"})
+	update.assign("legacy", nil)
+
+	assert.Equal(t, "SET #f0 = :v0, #a2 = :a3 REMOVE #f1, #a3", update.expression())
+	assert.Equal(t, "type_timestamp", update.Names["#a2"])
+	assert.Equal(t, "legacy", update.Names["#a3"])
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "Shower#2025"}, update.Values[":a3"])
+}
+
+func TestApplyFieldMask(t *testing.T) {
+	tests := map[string]struct {
+		mask          []string
+		protected     []string
+		expected      user.User
+		expectedError error
+	}{
+		"Happy Path - Masked Fields Copied": {
+			mask:     []string{"firstName", "lastName"},
+			expected: user.User{UserID: "User#1", Email: "old@example.com", FirstName: "Demo", LastName: ""},
+		},
+		"Sad Path - Empty Mask": {
+			expectedError: ErrEmptyFieldMask,
+		},
+		"Sad Path - Unknown Field": {
+			mask:          []string{"middleName"},
+			expectedError: ErrInvalidFieldMask,
+		},
+		"Sad Path - Protected Field": {
+			mask:          []string{"userId"},
+			protected:     []string{"userId"},
+			expectedError: ErrInvalidFieldMask,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dst := user.User{UserID: "User#1", Email: "old@example.com", FirstName: "Old", LastName: "Name"}
+			src := user.User{UserID: "User#2", Email: "new@example.com", FirstName: "Demo"}
+
+			err := ApplyFieldMask(&dst, src, tc.mask, tc.protected...)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, dst)
+		})
+	}
+}
@@ -2,10 +2,12 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/care-giver-app/care-giver-golang-common/pkg/log"
@@ -18,7 +20,11 @@ type DynamodbClientProvider interface {
 	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
 	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
 	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
 	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+	BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error)
+	TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error)
 }
 
 type UserRepositoryProvider interface {
@@ -27,13 +33,32 @@ type UserRepositoryProvider interface {
 	GetUserByEmail(email string) (user.User, error)
 }
 
+type UserRepositoryProviderV2 interface {
+	CreateUser(ctx context.Context, u user.User) error
+	GetUser(ctx context.Context, uid string) (user.User, error)
+	GetUserByEmail(ctx context.Context, email string) (user.User, error)
+	UpdateUser(ctx context.Context, u user.User, mask []string) (user.User, error)
+	ChangeEmail(ctx context.Context, uid string, email string) error
+}
+
 const (
 	PrimaryReceiverList    string = "primary_care_receivers"
 	AdditionalReceiverList string = "additional_care_receivers"
 )
 
+const (
+	emailClaimPrefix = "UserEmail#"
+)
+
 var (
 	userID = "user_id"
+
+	userKeySchema = PartitionKeySchema(userID)
+
+	ErrUserNotFound   = errors.New("user not found")
+	ErrEmailInUse     = errors.New("email is already in use")
+	ErrEmailImmutable = errors.New("email must be changed with ChangeEmail")
+	ErrEmailConflict  = errors.New("email was changed concurrently")
 )
 
 type UserRepository struct {
@@ -52,77 +77,288 @@ func NewUserRespository(ctx context.Context, tableName string, client DynamodbCl
 	}
 }
 
+func (ur *UserRepository) v2() *UserRepositoryV2 {
+	return &UserRepositoryV2{
+		Client:    ur.Client,
+		TableName: ur.TableName,
+		logger:    ur.logger,
+	}
+}
+
 func (ur *UserRepository) CreateUser(u user.User) error {
-	ur.logger.Info("adding user to db", zap.Any(log.UserIDLogKey, u.UserID))
+	return ur.v2().CreateUser(ur.Ctx, u)
+}
+
+func (ur *UserRepository) GetUser(uid string) (user.User, error) {
+	return ur.v2().GetUser(ur.Ctx, uid)
+}
+
+func (ur *UserRepository) GetUserByEmail(email string) (user.User, error) {
+	return ur.v2().GetUserByEmail(ur.Ctx, email)
+}
+
+// UserRepositoryV2 treats email addresses as case insensitive: they are
+// stored lowercased, and the user table holds a claim item per address, keyed
+// by emailClaimKey, that makes sure no two users share one. Claim items have
+// no email attribute, so they stay out of the email index, and GetUser never
+// returns them.
+type UserRepositoryV2 struct {
+	Client    DynamodbClientProvider
+	TableName string
+	logger    *zap.Logger
+}
+
+func NewUserRepositoryV2(tableName string, client DynamodbClientProvider, logger *zap.Logger) *UserRepositoryV2 {
+	return &UserRepositoryV2{
+		Client:    client,
+		TableName: tableName,
+		logger:    logger.With(zap.String(log.TableNameLogKey, tableName)),
+	}
+}
+
+func (ur *UserRepositoryV2) table() *Table[user.User, string] {
+	return &Table[user.User, string]{
+		Name:   ur.TableName,
+		Client: ur.Client,
+		Schema: userKeySchema,
+		logger: ur.logger,
+	}
+}
+
+// CreateUser stores u and claims its email address in the same transaction.
+// Users stored before addresses were claimed hold no claim, so the address is
+// also looked up as given and lowercased, until BackfillEmailClaims has run.
+func (ur *UserRepositoryV2) CreateUser(ctx context.Context, u user.User) error {
+	log.WithTraceContext(ctx, ur.logger).Info("adding user to db", zap.Any(log.UserIDLogKey, u.UserID))
+
+	if u.Email == "" {
+		return ur.table().Put(ctx, u)
+	}
+
+	for _, email := range slices.Compact([]string{u.Email, NormalizeEmail(u.Email)}) {
+		users, err := ur.queryEmail(ctx, email)
+		if err != nil {
+			return err
+		}
+		if slices.ContainsFunc(users, func(existing user.User) bool { return existing.UserID != u.UserID }) {
+			return ErrEmailInUse
+		}
+	}
 
-	ur.logger.Info("marshalling user struct")
-	av, err := attributevalue.MarshalMap(u)
+	u.Email = NormalizeEmail(u.Email)
+	putUser, err := ur.table().PutTransactItem(ctx, u)
 	if err != nil {
 		return err
 	}
 
-	ur.logger.Info("inserting item into db", zap.Any("item", av))
-	_, err = ur.Client.PutItem(ur.Ctx, &dynamodb.PutItemInput{
-		TableName: aws.String(ur.TableName),
-		Item:      av,
+	_, err = ur.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{putUser, ur.claimEmailItem(u.UserID, u.Email)},
 	})
 	if err != nil {
-		return err
+		log.WithTraceContext(ctx, ur.logger).Error("error adding user", zap.Error(err))
+		return cancellationErrors(err, map[int]error{
+			1: ErrEmailInUse,
+		})
 	}
-	ur.logger.Info("successfully inserted item")
-
 	return nil
 }
 
-func (ur *UserRepository) GetUser(uid string) (user.User, error) {
-	ur.logger.Info("getting user from db", zap.Any(log.UserIDLogKey, uid))
-	result, err := ur.Client.GetItem(ur.Ctx, &dynamodb.GetItemInput{
-		TableName: &ur.TableName,
-		Key: map[string]types.AttributeValue{
-			userID: &types.AttributeValueMemberS{Value: string(uid)},
-		},
-	})
+func (ur *UserRepositoryV2) GetUser(ctx context.Context, uid string) (user.User, error) {
+	log.WithTraceContext(ctx, ur.logger).Info("getting user from db", zap.Any(log.UserIDLogKey, uid))
+
+	if strings.HasPrefix(uid, emailClaimPrefix) {
+		return user.User{}, nil
+	}
+
+	u, err := ur.table().Get(ctx, uid)
+	if err != nil && !errors.Is(err, ErrItemNotFound) {
+		return user.User{}, err
+	}
+
+	return u, nil
+}
+
+func (ur *UserRepositoryV2) GetUserByEmail(ctx context.Context, email string) (user.User, error) {
+	log.WithTraceContext(ctx, ur.logger).Info("getting user from db")
+
+	users, err := ur.queryEmail(ctx, NormalizeEmail(email))
+	// Users stored before addresses were lowercased are still found by
+	// their address as it was stored.
+	if err == nil && len(users) == 0 && email != NormalizeEmail(email) {
+		users, err = ur.queryEmail(ctx, email)
+	}
 	if err != nil {
 		return user.User{}, err
 	}
 
-	var u user.User
-	err = attributevalue.UnmarshalMap(result.Item, &u)
+	switch len(users) {
+	case 0:
+		return user.User{}, fmt.Errorf("user with email not found: %w", ErrUserNotFound)
+	case 1:
+		return users[0], nil
+	default:
+		return user.User{}, fmt.Errorf("%d users share the email: %w", len(users), ErrEmailInUse)
+	}
+}
+
+func (ur *UserRepositoryV2) queryEmail(ctx context.Context, email string) ([]user.User, error) {
+	return ur.table().Query(ctx, QueryParams{
+		IndexName:    UserEmailIndex,
+		KeyCondition: "email = :email",
+		Values: map[string]types.AttributeValue{
+			":email": &types.AttributeValueMemberS{Value: email},
+		},
+	})
+}
+
+func (ur *UserRepositoryV2) UpdateUser(ctx context.Context, u user.User, mask []string) (user.User, error) {
+	log.WithTraceContext(ctx, ur.logger).Info("updating user in db", zap.String(log.UserIDLogKey, u.UserID), zap.Strings("fields", mask))
+
+	if slices.Contains(mask, "email") {
+		return user.User{}, ErrEmailImmutable
+	}
+
+	updated, err := ur.table().Update(ctx, u.UserID, u, mask)
+	if errors.Is(err, ErrConditionFailed) {
+		return user.User{}, ErrUserNotFound
+	}
 	if err != nil {
 		return user.User{}, err
 	}
 
-	return u, nil
+	return updated, nil
 }
 
-func (ur *UserRepository) GetUserByEmail(email string) (user.User, error) {
-	ur.logger.Info("getting user from db")
+// ChangeEmail moves a user to a new email address, claiming the new address
+// and releasing the old one in the same transaction. It fails with
+// ErrEmailConflict when the address of the user changes while it runs.
+func (ur *UserRepositoryV2) ChangeEmail(ctx context.Context, uid string, email string) error {
+	log.WithTraceContext(ctx, ur.logger).Info("changing user email", zap.String(log.UserIDLogKey, uid))
+
+	email = NormalizeEmail(email)
+	current, err := ur.GetUser(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if current.UserID == "" {
+		return ErrUserNotFound
+	}
+	if current.Email == email {
+		return nil
+	}
 
-	keyCondition := "email = :email"
-	expressionAttributeValues := map[string]types.AttributeValue{
-		":email": &types.AttributeValueMemberS{Value: string(email)},
+	existing, err := ur.GetUserByEmail(ctx, email)
+	switch {
+	case err == nil && existing.UserID != uid:
+		return ErrEmailInUse
+	case err != nil && !errors.Is(err, ErrUserNotFound):
+		return err
 	}
 
-	queryInput := &dynamodb.QueryInput{
-		TableName:                 aws.String(ur.TableName),
-		IndexName:                 aws.String("email"),
-		KeyConditionExpression:    aws.String(keyCondition),
-		ExpressionAttributeValues: expressionAttributeValues,
+	items := []types.TransactWriteItem{
+		{
+			Update: &types.Update{
+				TableName: aws.String(ur.TableName),
+				Key: map[string]types.AttributeValue{
+					userID: &types.AttributeValueMemberS{Value: uid},
+				},
+				UpdateExpression:    aws.String("SET email = :email"),
+				ConditionExpression: aws.String("attribute_exists(user_id) AND email = :current"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":email":   &types.AttributeValueMemberS{Value: email},
+					":current": &types.AttributeValueMemberS{Value: current.Email},
+				},
+			},
+		},
+		ur.claimEmailItem(uid, email),
+	}
+	if current.Email != "" && emailClaimKey(current.Email) != emailClaimKey(email) {
+		items = append(items, types.TransactWriteItem{
+			Delete: &types.Delete{
+				TableName: aws.String(ur.TableName),
+				Key: map[string]types.AttributeValue{
+					userID: &types.AttributeValueMemberS{Value: emailClaimKey(current.Email)},
+				},
+			},
+		})
 	}
 
-	result, err := ur.Client.Query(ur.Ctx, queryInput)
+	_, err = ur.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: items})
 	if err != nil {
-		return user.User{}, err
+		log.WithTraceContext(ctx, ur.logger).Error("error changing user email", zap.Error(err))
+		return cancellationErrors(err, map[int]error{
+			0: ErrEmailConflict,
+			1: ErrEmailInUse,
+		})
+	}
+
+	log.WithTraceContext(ctx, ur.logger).Info("successfully changed user email")
+	return nil
+}
+
+// BackfillEmailClaims claims the email address of every user stored before
+// addresses were claimed and returns how many users hold a claim. Users whose
+// address another user has claimed are left unclaimed and reported in the
+// returned error, wrapping ErrEmailInUse, to be resolved by hand.
+func (ur *UserRepositoryV2) BackfillEmailClaims(ctx context.Context) (int, error) {
+	users, err := ur.table().Scan(ctx, ScanParams{
+		Filter: "attribute_exists(email)",
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	if len(result.Items) == 1 {
-		var u user.User
-		err = attributevalue.UnmarshalMap(result.Items[0], &u)
+	claimed := 0
+	var errs []error
+	for _, u := range users {
+		if u.Email == "" {
+			continue
+		}
+
+		_, err := ur.Client.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+			TransactItems: []types.TransactWriteItem{ur.claimEmailItem(u.UserID, u.Email)},
+		})
+		err = cancellationErrors(err, map[int]error{
+			0: ErrEmailInUse,
+		})
+		if errors.Is(err, ErrEmailInUse) {
+			log.WithTraceContext(ctx, ur.logger).Warn("email of user is claimed by another user", zap.String(log.UserIDLogKey, u.UserID))
+			errs = append(errs, fmt.Errorf("claiming email of user %s: %w", u.UserID, err))
+			continue
+		}
 		if err != nil {
-			return user.User{}, err
+			return claimed, fmt.Errorf("claiming email of user %s: %w", u.UserID, err)
 		}
-		return u, nil
+		claimed++
+	}
+
+	log.WithTraceContext(ctx, ur.logger).Info("backfilled email claims", zap.Int(log.ItemCountLogKey, claimed))
+	return claimed, errors.Join(errs...)
+}
+
+// claimEmailItem claims email for uid, failing when another user holds it.
+func (ur *UserRepositoryV2) claimEmailItem(uid string, email string) types.TransactWriteItem {
+	return types.TransactWriteItem{
+		Put: &types.Put{
+			TableName: aws.String(ur.TableName),
+			Item: map[string]types.AttributeValue{
+				userID:          &types.AttributeValueMemberS{Value: emailClaimKey(email)},
+				"owner_user_id": &types.AttributeValueMemberS{Value: uid},
+			},
+			ConditionExpression: aws.String("attribute_not_exists(user_id) OR owner_user_id = :uid"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":uid": &types.AttributeValueMemberS{Value: uid},
+			},
+		},
 	}
+}
+
+// NormalizeEmail returns email the way users store it. Addresses that
+// normalize the same belong to the same user.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(email)
+}
 
-	return user.User{}, fmt.Errorf("user with email %s not found", email)
+func emailClaimKey(email string) string {
+	return emailClaimPrefix + NormalizeEmail(email)
 }
@@ -31,6 +31,48 @@ func TestCreateUser(t *testing.T) {
 			},
 			expectError: false,
 		},
+		"Happy Path - User Created With Email Claim": {
+			user: user.User{
+				UserID: "User#123",
+				Email:  "Test@Example.com",
+			},
+			mockDynamo: &dynamo.Mock{
+				QueryOutput:    &dynamodb.QueryOutput{},
+				TransactOutput: &dynamodb.TransactWriteItemsOutput{},
+			},
+			expectError: false,
+		},
+		"Sad Path - Email In Use": {
+			user: user.User{
+				UserID: "User#123",
+				Email:  "taken@example.com",
+			},
+			mockDynamo: &dynamo.Mock{
+				QueryOutput: &dynamodb.QueryOutput{},
+				OnTransact: dynamo.MockMethod[dynamodb.TransactWriteItemsInput, dynamodb.TransactWriteItemsOutput]{
+					Err: cancelled("None", conditionalCheckFailed),
+				},
+			},
+			expectError: true,
+		},
+		"Sad Path - Email Of Unclaimed Legacy User": {
+			user: user.User{
+				UserID: "User#123",
+				Email:  "Legacy@Example.com",
+			},
+			mockDynamo: &dynamo.Mock{
+				QueryOutput: &dynamodb.QueryOutput{
+					Items: []map[string]types.AttributeValue{
+						{
+							"user_id": &types.AttributeValueMemberS{Value: "User#legacy"},
+							"email":   &types.AttributeValueMemberS{Value: "Legacy@Example.com"},
+						},
+					},
+				},
+				TransactOutput: &dynamodb.TransactWriteItemsOutput{},
+			},
+			expectError: true,
+		},
 		"Sad Path - Error Putting Item": {
 			user: user.User{
 				UserID: "Error",
@@ -184,3 +226,168 @@ func TestGetUserByEmail(t *testing.T) {
 		})
 	}
 }
+
+func TestUserRepositoryV2(t *testing.T) {
+	var _ UserRepositoryProvider = &UserRepository{}
+	var _ UserRepositoryProviderV2 = &UserRepositoryV2{}
+
+	mockDynamo := &dynamo.Mock{
+		GetOutput: &dynamodb.GetItemOutput{
+			Item: map[string]types.AttributeValue{
+				"user_id": &types.AttributeValueMemberS{Value: "User#123"},
+			},
+		},
+		QueryOutput: &dynamodb.QueryOutput{
+			Items: []map[string]types.AttributeValue{
+				{
+					"user_id": &types.AttributeValueMemberS{Value: "User#123"},
+					"email":   &types.AttributeValueMemberS{Value: "valid@example.com"},
+				},
+			},
+		},
+	}
+	testUserRepo := NewUserRepositoryV2("user-table", mockDynamo, zap.NewNop())
+	ctx := context.Background()
+
+	err := testUserRepo.CreateUser(ctx, user.User{UserID: "User#123"})
+	assert.Nil(t, err)
+
+	u, err := testUserRepo.GetUser(ctx, "User#123")
+	assert.Nil(t, err)
+	assert.Equal(t, "User#123", u.UserID)
+
+	u, err = testUserRepo.GetUserByEmail(ctx, "valid@example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, "valid@example.com", u.Email)
+}
+
+func TestUpdateUser(t *testing.T) {
+	tests := map[string]struct {
+		user          user.User
+		mask          []string
+		mockDynamo    *dynamo.Mock
+		expectedUser  user.User
+		expectedError error
+	}{
+		"Happy Path - User Updated": {
+			user: user.User{UserID: "User#123", LastName: "newLastName"},
+			mask: []string{"lastName"},
+			mockDynamo: &dynamo.Mock{
+				UpdateOutput: &dynamodb.UpdateItemOutput{
+					Attributes: map[string]types.AttributeValue{
+						"user_id":    &types.AttributeValueMemberS{Value: "User#123"},
+						"first_name": &types.AttributeValueMemberS{Value: "testFirstName"},
+						"last_name":  &types.AttributeValueMemberS{Value: "newLastName"},
+					},
+				},
+			},
+			expectedUser: user.User{
+				UserID:    "User#123",
+				FirstName: "testFirstName",
+				LastName:  "newLastName",
+			},
+		},
+		"Sad Path - Email In Mask": {
+			user:          user.User{UserID: "User#123", Email: "new@example.com"},
+			mask:          []string{"email"},
+			mockDynamo:    &dynamo.Mock{},
+			expectedError: ErrEmailImmutable,
+		},
+		"Sad Path - User Not Found": {
+			user: user.User{UserID: "User#404"},
+			mask: []string{"firstName"},
+			mockDynamo: &dynamo.Mock{
+				Err: &types.ConditionalCheckFailedException{},
+			},
+			expectedError: ErrUserNotFound,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			testUserRepo := NewUserRepositoryV2("user-table", tc.mockDynamo, zap.NewNop())
+
+			u, err := testUserRepo.UpdateUser(context.Background(), tc.user, tc.mask)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tc.expectedUser, u)
+			}
+		})
+	}
+}
+
+func TestChangeEmail(t *testing.T) {
+	currentUser := &dynamodb.GetItemOutput{
+		Item: map[string]types.AttributeValue{
+			"user_id": &types.AttributeValueMemberS{Value: "User#123"},
+			"email":   &types.AttributeValueMemberS{Value: "old@example.com"},
+		},
+	}
+
+	tests := map[string]struct {
+		email         string
+		mockDynamo    *dynamo.Mock
+		expectedError error
+	}{
+		"Happy Path - Email Changed": {
+			email: "new@example.com",
+			mockDynamo: &dynamo.Mock{
+				GetOutput:      currentUser,
+				QueryOutput:    &dynamodb.QueryOutput{},
+				TransactOutput: &dynamodb.TransactWriteItemsOutput{},
+			},
+		},
+		"Happy Path - Unchanged Email": {
+			email: "old@example.com",
+			mockDynamo: &dynamo.Mock{
+				GetOutput: currentUser,
+			},
+		},
+		"Sad Path - User Not Found": {
+			email: "new@example.com",
+			mockDynamo: &dynamo.Mock{
+				GetOutput: &dynamodb.GetItemOutput{},
+			},
+			expectedError: ErrUserNotFound,
+		},
+		"Sad Path - Email Changed Concurrently": {
+			email: "new@example.com",
+			mockDynamo: &dynamo.Mock{
+				GetOutput:   currentUser,
+				QueryOutput: &dynamodb.QueryOutput{},
+				OnTransact:  dynamo.MockMethod[dynamodb.TransactWriteItemsInput, dynamodb.TransactWriteItemsOutput]{Err: cancelled(conditionalCheckFailed, "None", "None")},
+			},
+			expectedError: ErrEmailConflict,
+		},
+		"Sad Path - Email Used By Another User": {
+			email: "taken@example.com",
+			mockDynamo: &dynamo.Mock{
+				GetOutput: currentUser,
+				QueryOutput: &dynamodb.QueryOutput{
+					Items: []map[string]types.AttributeValue{
+						{
+							"user_id": &types.AttributeValueMemberS{Value: "User#456"},
+							"email":   &types.AttributeValueMemberS{Value: "taken@example.com"},
+						},
+					},
+				},
+			},
+			expectedError: ErrEmailInUse,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			testUserRepo := NewUserRepositoryV2("user-table", tc.mockDynamo, zap.NewNop())
+
+			err := testUserRepo.ChangeEmail(context.Background(), "User#123", tc.email)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
@@ -15,9 +15,9 @@ type UserID string
 
 type User struct {
 	UserID    string `json:"userId" dynamodbav:"user_id"`
-	Email     string `json:"email" dynamodbav:"email"`
-	FirstName string `json:"firstName" dynamodbav:"first_name"`
-	LastName  string `json:"lastName" dynamodbav:"last_name"`
+	Email     string `json:"email" dynamodbav:"email" log:"pii"`
+	FirstName string `json:"firstName" dynamodbav:"first_name" log:"pii"`
+	LastName  string `json:"lastName" dynamodbav:"last_name" log:"pii"`
 }
 
 func NewUser(email string, firstName string, lastName string) (*User, error) {